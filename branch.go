@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+)
+
+// branchesCommand は branches サブコマンドを定義する
+func branchesCommand(fs *flag.FlagSet) runFunc {
+	var base string
+	var clean, dryRun bool
+	fs.StringVar(&base, "base", "", "マージ済みかを判定するブランチ (省略時は origin/HEAD、main、master の順に探す)")
+	fs.BoolVar(&clean, "clean", false, "マージ済みのタスクのブランチを削除する")
+	fs.BoolVar(&dryRun, "dry-run", false, "--clean で削除するブランチを表示するだけにする")
+
+	return func(c *cli, args []string) (result, error) {
+		if err := requireArgs("branches", args); err != nil {
+			return nil, err
+		}
+		if dryRun && !clean {
+			return nil, newUsageError("branches", "--dry-run は --clean と一緒に指定してください")
+		}
+
+		repo, err := git.Open(c.workDir)
+		if err != nil {
+			return nil, err
+		}
+		if base == "" {
+			if base, err = repo.DefaultBranch(); err != nil {
+				return nil, fmt.Errorf("%w (--base で指定してください)", err)
+			}
+		}
+
+		branches, err := repo.Branches(tasks.BranchPrefix, base)
+		if err != nil {
+			return nil, err
+		}
+		owners, err := c.branchOwners()
+		if err != nil {
+			return nil, err
+		}
+
+		res := branchListResult{base: base, clean: clean, dryRun: dryRun}
+		for _, b := range branches {
+			entry := branchEntry{Branch: b, owner: owners[b.Name]}
+			// チェックアウト中のブランチ (他のworktreeを含む) は削除しない
+			if clean && b.Merged && !b.Current && b.Worktree == "" {
+				if dryRun {
+					entry.deleted = true
+				} else if err := repo.DeleteBranch(b.Name); err != nil {
+					// 1つの失敗で残りのブランチの削除を中断しない
+					slog.Warn("ブランチを削除できませんでした", "component", "cli", "branch", b.Name, "err", err)
+					entry.err = err
+				} else {
+					entry.deleted = true
+				}
+			}
+			res.branches = append(res.branches, entry)
+		}
+		return res, nil
+	}
+}
+
+// branchOwners はブランチ名からそのブランチを使うタスクへの対応を返す
+// メタ情報にブランチがないタスクは task/<タスク名> を使うものとみなす
+func (c *cli) branchOwners() (map[string]tasks.Task, error) {
+	m := c.manager()
+	list, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	archived, err := m.ListArchived()
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string]tasks.Task)
+	// アーカイブ済みのタスクより現在のタスクを優先する
+	for _, task := range append(archived, list...) {
+		branch := task.Branch
+		if branch == "" {
+			branch = tasks.BranchName(task.Name)
+		}
+		owners[branch] = task
+	}
+	return owners, nil
+}
+
+// createTaskBranch はタスクのブランチ task/<タスク名> を作成してチェックアウトし、メタ情報に記録する
+func (c *cli) createTaskBranch(repo *git.Repo, task *tasks.Task) error {
+	branch := tasks.BranchName(task.Name)
+	if err := repo.CreateBranch(branch); err != nil {
+		return err
+	}
+	if err := c.manager().SetBranch(task.Name, branch); err != nil {
+		return err
+	}
+	task.Branch = branch
+	return nil
+}
+
+// branchSwitch はタスクの開始時にブランチを切り替えるかの指定
+type branchSwitch struct {
+	checkout bool // 確認せずに切り替える
+	stash    bool // コミットしていない変更をstashに退避して切り替える
+}
+
+// switchTaskBranch はタスクのブランチが現在のブランチと異なる場合に切り替える
+// 指定がない場合、端末では切り替えるか確認し、それ以外では切り替え方を案内するだけにする
+func (c *cli) switchTaskBranch(task *tasks.Task, opts branchSwitch) error {
+	// worktreeではタスクのブランチをチェックアウト済みのため切り替えない
+	if task.Worktree != "" {
+		if opts.checkout || opts.stash {
+			fmt.Fprintf(c.stderr, "タスク %s はworktree %s で作業するためブランチを切り替えません\n", task.Name, task.Worktree)
+		}
+		return nil
+	}
+	if task.Branch == "" {
+		if opts.checkout || opts.stash {
+			return fmt.Errorf("タスク %s にはブランチがありません ('ccforge new --branch' で作成できます)", task.Name)
+		}
+		return nil
+	}
+
+	repo, err := git.Open(c.workDir)
+	if err != nil {
+		return err
+	}
+	current, err := repo.CurrentBranch()
+	if err != nil {
+		return err
+	}
+	if current == task.Branch {
+		return nil
+	}
+
+	exists, err := repo.BranchExists(task.Branch)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		fmt.Fprintf(c.stderr, "タスク %s のブランチ %s がありません (削除済みの可能性があります)\n", task.Name, task.Branch)
+		return nil
+	}
+
+	if !opts.checkout && !opts.stash {
+		if !isTerminal(c.stdin) {
+			fmt.Fprintf(c.stderr, "現在のブランチは %s です。タスクのブランチ %s に切り替えるには --checkout を指定してください\n", current, task.Branch)
+			return nil
+		}
+		if !confirm(c.stdin, c.stdout, fmt.Sprintf("タスクのブランチ %s に切り替えますか? (現在: %s) [y/N]: ", task.Branch, current)) {
+			return nil
+		}
+	}
+
+	stashed, err := repo.Switch(task.Branch, opts.stash)
+	if errors.Is(err, git.ErrDirtyTree) {
+		return fmt.Errorf("%w (--stash で変更を退避して切り替えられます)", err)
+	}
+	if err != nil {
+		return err
+	}
+	if stashed {
+		fmt.Fprintf(c.stdout, "%s の変更をstashに退避しました ('git stash pop' で戻せます)\n", current)
+	}
+	fmt.Fprintf(c.stdout, "ブランチ %s に切り替えました\n", task.Branch)
+	slog.Info("タスクのブランチに切り替えました", "component", "cli", "task", task.Name, "branch", task.Branch, "stashed", stashed)
+	return nil
+}
+
+// confirm は質問を表示し、y または yes と答えた場合にtrueを返す
+func confirm(r io.Reader, w io.Writer, question string) bool {
+	fmt.Fprint(w, question)
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// branchEntry はタスクのブランチと対応するタスク
+type branchEntry struct {
+	git.Branch
+	owner   tasks.Task // ブランチを使うタスク (ない場合はゼロ値)
+	deleted bool       // 削除した (--dry-run の場合は削除する) か
+	err     error      // 削除に失敗した場合のエラー
+}
+
+// branchJSON はタスクのブランチのJSON表現
+type branchJSON struct {
+	Name      string    `json:"name"`
+	Task      string    `json:"task,omitempty"`
+	Archived  bool      `json:"archived"`
+	Head      string    `json:"head"`
+	Subject   string    `json:"subject"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Current   bool      `json:"current"`
+	Merged    bool      `json:"merged"`
+	Deleted   bool      `json:"deleted"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// branchListResult は branches の結果
+type branchListResult struct {
+	base     string
+	branches []branchEntry
+	clean    bool
+	dryRun   bool
+}
+
+func (r branchListResult) kind() string { return "task_branches" }
+
+func (r branchListResult) data() any {
+	list := make([]branchJSON, 0, len(r.branches))
+	for _, b := range r.branches {
+		var errText string
+		if b.err != nil {
+			errText = b.err.Error()
+		}
+		list = append(list, branchJSON{
+			Name:      b.Name,
+			Task:      b.owner.Name,
+			Archived:  b.owner.Archived,
+			Head:      b.Head,
+			Subject:   b.Subject,
+			UpdatedAt: b.UpdatedAt.UTC(),
+			Current:   b.Current,
+			Merged:    b.Merged,
+			Deleted:   b.deleted && !r.dryRun,
+			Error:     errText,
+		})
+	}
+	return struct {
+		Base     string       `json:"base"`
+		DryRun   bool         `json:"dry_run"`
+		Branches []branchJSON `json:"branches"`
+	}{Base: r.base, DryRun: r.dryRun, Branches: list}
+}
+
+func (r branchListResult) writeText(w io.Writer) {
+	if len(r.branches) == 0 {
+		fmt.Fprintf(w, "タスクのブランチ (%s*) がありません ('ccforge new --branch <タスク名>' で作成できます)\n", tasks.BranchPrefix)
+		return
+	}
+
+	deleted := 0
+	for _, b := range r.branches {
+		switch {
+		case b.deleted && r.dryRun:
+			fmt.Fprintf(w, "削除予定: %s\n", b.Name)
+			deleted++
+		case b.deleted:
+			fmt.Fprintf(w, "削除しました: %s\n", b.Name)
+			deleted++
+		case b.err != nil:
+			fmt.Fprintf(w, "削除に失敗しました: %s (%v)\n", b.Name, b.err)
+		case !r.clean:
+			fmt.Fprintf(w, "%s %s (%s, %s)\n", currentMark(b.Branch), b.Name, branchTaskText(b), r.mergedText(b.Branch))
+		}
+	}
+	if r.clean && deleted == 0 {
+		fmt.Fprintf(w, "%s にマージ済みのタスクのブランチはありません\n", r.base)
+	}
+}
+
+func (r branchListResult) writeTable(w io.Writer) {
+	if len(r.branches) == 0 || r.clean {
+		r.writeText(w)
+		return
+	}
+
+	rows := make([][]string, 0, len(r.branches))
+	for _, b := range r.branches {
+		rows = append(rows, []string{currentMark(b.Branch) + " " + b.Name, branchTaskText(b), b.Head, r.mergedText(b.Branch), formatTime(b.UpdatedAt)})
+	}
+	printTable(w, []string{"  ブランチ", "タスク", "コミット", "状態", "更新日時"}, rows)
+}
+
+// mergedText はブランチのマージの状態の表示名を返す
+func (r branchListResult) mergedText(b git.Branch) string {
+	if b.Merged {
+		return r.base + " にマージ済み"
+	}
+	return "未マージ"
+}
+
+// currentMark はチェックアウト中のブランチの印を返す
+func currentMark(b git.Branch) string {
+	if b.Current {
+		return "*"
+	}
+	return " "
+}
+
+// branchTaskText はブランチを使うタスクの表示名を返す
+func branchTaskText(b branchEntry) string {
+	switch {
+	case b.owner.Name == "":
+		return "タスクなし"
+	case b.owner.Archived:
+		return b.owner.Name + " (アーカイブ済み)"
+	default:
+		return b.owner.Name
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initGitProject はプロジェクトのディレクトリをコミットが1つあるgitリポジトリにする
+func initGitProject(t *testing.T, dir string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("gitコマンドが見つかりません")
+	}
+	runGitCmd(t, dir, "init", "-q", "-b", "main")
+	runGitCmd(t, dir, "config", "user.name", "ccforge test")
+	runGitCmd(t, dir, "config", "user.email", "test@example.com")
+	runGitCmd(t, dir, "config", "commit.gpgsign", "false")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# project\n"), 0o644))
+	runGitCmd(t, dir, "add", "README.md")
+	runGitCmd(t, dir, "commit", "-q", "-m", "init")
+}
+
+// runGitCmd はテスト用にgitコマンドを実行して標準出力を返す
+func runGitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	require.NoError(t, err, "git %s", strings.Join(args, " "))
+	return string(out)
+}
+
+// currentBranch はチェックアウト中のブランチ名を返す
+func currentBranch(t *testing.T, dir string) string {
+	t.Helper()
+	return strings.TrimSpace(runGitCmd(t, dir, "rev-parse", "--abbrev-ref", "HEAD"))
+}
+
+func TestCLI_NewBranch(t *testing.T) {
+	t.Run("ブランチを作成してタスクに記録する", func(t *testing.T) {
+		c, out, _ := newTestCLI(t)
+		initGitProject(t, c.workDir)
+
+		require.NoError(t, c.run([]string{"new", "--branch", "auth"}))
+		assert.Contains(t, out.String(), "ブランチ task/auth を作成してチェックアウトしました")
+		assert.Equal(t, "task/auth", currentBranch(t, c.workDir))
+
+		task, err := tasks.NewManager(c.workDir).Get("auth")
+		require.NoError(t, err)
+		assert.Equal(t, "task/auth", task.Branch)
+	})
+
+	t.Run("リポジトリでない場合はタスクを作成しない", func(t *testing.T) {
+		t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+		c, _, _ := newTestCLI(t)
+
+		err := c.run([]string{"new", "--branch", "auth"})
+		assert.ErrorIs(t, err, git.ErrNotRepository)
+		assert.NoDirExists(t, filepath.Join(c.workDir, tasks.DirName, "auth"))
+	})
+}
+
+func TestCLI_StartSwitchBranch(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		dirty      bool
+		wantBranch string
+		wantErr    error
+		wantStderr string
+		wantStash  bool
+	}{
+		{
+			name:       "指定がない場合は案内だけする",
+			args:       []string{"start", "auth"},
+			wantBranch: "main",
+			wantStderr: "タスクのブランチ task/auth に切り替えるには --checkout を指定してください",
+		},
+		{
+			name:       "checkoutで切り替える",
+			args:       []string{"start", "--checkout", "auth"},
+			wantBranch: "task/auth",
+		},
+		{
+			name:       "変更がある場合は切り替えない",
+			args:       []string{"start", "--checkout", "auth"},
+			dirty:      true,
+			wantBranch: "main",
+			wantErr:    git.ErrDirtyTree,
+		},
+		{
+			name:       "stashで退避して切り替える",
+			args:       []string{"start", "--stash", "auth"},
+			dirty:      true,
+			wantBranch: "task/auth",
+			wantStash:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, out, launched := newTestCLI(t)
+			initGitProject(t, c.workDir)
+			require.NoError(t, c.run([]string{"new", "--branch", "auth"}))
+			runGitCmd(t, c.workDir, "checkout", "-q", "main")
+			if tt.dirty {
+				require.NoError(t, os.WriteFile(filepath.Join(c.workDir, "README.md"), []byte("# changed\n"), 0o644))
+			}
+			out.Reset()
+
+			err := c.run(tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, err.Error(), "--stash")
+				assert.Empty(t, *launched)
+			} else {
+				require.NoError(t, err)
+				assert.Len(t, *launched, 1)
+			}
+			assert.Equal(t, tt.wantBranch, currentBranch(t, c.workDir))
+			assert.Contains(t, c.stderr.(*bytes.Buffer).String(), tt.wantStderr)
+			if tt.wantStash {
+				assert.Contains(t, out.String(), "main の変更をstashに退避しました")
+				assert.Contains(t, runGitCmd(t, c.workDir, "stash", "list"), "task/auth")
+			}
+		})
+	}
+
+	t.Run("ブランチのないタスク", func(t *testing.T) {
+		c, _, _ := newTestCLI(t)
+		initGitProject(t, c.workDir)
+		require.NoError(t, c.run([]string{"new", "auth"}))
+
+		require.NoError(t, c.run([]string{"start", "auth"}))
+		assert.ErrorContains(t, c.run([]string{"start", "--checkout", "auth"}), "ブランチがありません")
+	})
+}
+
+func TestCLI_Branches(t *testing.T) {
+	c, out, _ := newTestCLI(t)
+	initGitProject(t, c.workDir)
+	dir := c.workDir
+
+	// マージ済みのタスク
+	require.NoError(t, c.run([]string{"new", "--branch", "done"}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "done.txt"), []byte("done\n"), 0o644))
+	runGitCmd(t, dir, "add", "done.txt")
+	runGitCmd(t, dir, "commit", "-q", "-m", "done")
+	runGitCmd(t, dir, "checkout", "-q", "main")
+	runGitCmd(t, dir, "merge", "-q", "--no-ff", "-m", "merge done", "task/done")
+	_, err := tasks.NewManager(dir).Archive("done")
+	require.NoError(t, err)
+	// 作業中のタスク
+	require.NoError(t, c.run([]string{"new", "--branch", "wip"}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wip.txt"), []byte("wip\n"), 0o644))
+	runGitCmd(t, dir, "add", "wip.txt")
+	runGitCmd(t, dir, "commit", "-q", "-m", "wip")
+	// タスクのないブランチ
+	runGitCmd(t, dir, "branch", "task/orphan", "main")
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"branches"}))
+	assert.Contains(t, out.String(), "  task/done    done (アーカイブ済み)")
+	assert.Contains(t, out.String(), "main にマージ済み")
+	assert.Contains(t, out.String(), "* task/wip     wip")
+	assert.Contains(t, out.String(), "task/orphan  タスクなし")
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"branches", "--clean", "--dry-run"}))
+	assert.Equal(t, "削除予定: task/done\n", out.String())
+	assert.Contains(t, runGitCmd(t, dir, "branch"), "task/done")
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"branches", "--clean"}))
+	assert.Equal(t, "削除しました: task/done\n", out.String())
+	assert.NotContains(t, runGitCmd(t, dir, "branch"), "task/done")
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"branches", "--clean"}))
+	assert.Equal(t, "main にマージ済みのタスクのブランチはありません\n", out.String())
+
+	assert.Equal(t, exitUsage, exitCode(c.run([]string{"branches", "--dry-run"})))
+}
+
+func TestCLI_BranchesCleanFromTaskBranch(t *testing.T) {
+	c, out, _ := newTestCLI(t)
+	initGitProject(t, c.workDir)
+	dir := c.workDir
+
+	// 作業していないタスクのブランチ
+	runGitCmd(t, dir, "branch", "task/idle")
+	// マージするタスクのブランチ
+	runGitCmd(t, dir, "checkout", "-q", "-b", "task/a")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644))
+	runGitCmd(t, dir, "add", "a.txt")
+	runGitCmd(t, dir, "commit", "-q", "-m", "a")
+	runGitCmd(t, dir, "checkout", "-q", "-b", "task/shared")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared.txt"), []byte("shared\n"), 0o644))
+	runGitCmd(t, dir, "add", "shared.txt")
+	runGitCmd(t, dir, "commit", "-q", "-m", "shared")
+	// マージより前に作成したブランチで --clean を実行する
+	runGitCmd(t, dir, "checkout", "-q", "-b", "task/b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b\n"), 0o644))
+	runGitCmd(t, dir, "add", "b.txt")
+	runGitCmd(t, dir, "commit", "-q", "-m", "b")
+	runGitCmd(t, dir, "checkout", "-q", "main")
+	runGitCmd(t, dir, "merge", "-q", "--no-ff", "-m", "merge a", "task/a")
+	runGitCmd(t, dir, "merge", "-q", "--no-ff", "-m", "merge shared", "task/shared")
+	// マージ済みのブランチを別のworktreeでチェックアウトする
+	runGitCmd(t, dir, "worktree", "add", "-q", filepath.Join(t.TempDir(), "shared"), "task/shared")
+	runGitCmd(t, dir, "checkout", "-q", "task/b")
+
+	require.NoError(t, c.run([]string{"branches"}))
+	assert.Regexp(t, `task/shared .* main にマージ済み`, out.String())
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"branches", "--clean"}))
+	assert.Equal(t, "削除しました: task/a\n", out.String())
+	branches := runGitCmd(t, dir, "branch")
+	assert.NotContains(t, branches, "task/a\n")
+	for _, name := range []string{"task/b", "task/idle", "task/shared"} {
+		assert.Contains(t, branches, name)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   bool
+	}{
+		{answer: "y\n", want: true},
+		{answer: " YES \n", want: true},
+		{answer: "n\n", want: false},
+		{answer: "\n", want: false},
+		{answer: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			var out bytes.Buffer
+			assert.Equal(t, tt.want, confirm(strings.NewReader(tt.answer), &out, "切り替えますか? [y/N]: "))
+			assert.Equal(t, "切り替えますか? [y/N]: ", out.String())
+		})
+	}
+}
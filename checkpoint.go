@@ -0,0 +1,318 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+)
+
+// checkpointPromptWidth は一覧に表示するプロンプトの最大幅
+const checkpointPromptWidth = 60
+
+// checkpointCommand は checkpoint サブコマンドを定義する
+func checkpointCommand(*flag.FlagSet) runFunc {
+	return func(c *cli, args []string) (result, error) {
+		if len(args) == 0 {
+			return nil, newUsageError("checkpoint", "list, diff, restore のいずれかを指定してください")
+		}
+
+		switch action, rest := args[0], args[1:]; action {
+		case "list":
+			return c.checkpointList(rest)
+		case "diff":
+			return c.checkpointDiff(rest)
+		case "restore":
+			return c.checkpointRestore(rest)
+		default:
+			return nil, newUsageError("checkpoint", "不明な操作です: %s", action)
+		}
+	}
+}
+
+// checkpointList はタスクのチェックポイントをプロンプトとそのプロンプトによる変更の規模とともに返す
+func (c *cli) checkpointList(args []string) (result, error) {
+	if err := requireArgs("checkpoint", args, "<タスク名>"); err != nil {
+		return nil, err
+	}
+	task, repo, exclude, err := c.checkpointTask(args[0])
+	if err != nil {
+		return nil, err
+	}
+	list, err := repo.Checkpoints(task.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	res := checkpointListResult{task: task.Name}
+	for i, cp := range list {
+		// 次のチェックポイント (最新の場合は現在の作業ツリー) までの変更をプロンプトによる変更とみなす
+		next := ""
+		if i+1 < len(list) {
+			next = list[i+1].Commit
+		}
+		stat, err := repo.CheckpointStat(cp.Commit, next, exclude)
+		if err != nil {
+			return nil, err
+		}
+		res.checkpoints = append(res.checkpoints, checkpointEntry{Checkpoint: cp, stat: stat})
+	}
+	return res, nil
+}
+
+// checkpointDiff は2つのチェックポイントの差分を返す
+// 比較先を省略した場合は現在の作業ツリーと比較する
+func (c *cli) checkpointDiff(args []string) (result, error) {
+	if len(args) == 2 {
+		args = append(args, "")
+	}
+	if len(args) != 3 {
+		return nil, newUsageError("checkpoint", "diff には <タスク名> <番号> [<番号>] を指定してください")
+	}
+	task, repo, exclude, err := c.checkpointTask(args[0])
+	if err != nil {
+		return nil, err
+	}
+	from, err := lookupCheckpoint(repo, task.Name, args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	res := checkpointDiffResult{task: task.Name, from: from.ID}
+	target := ""
+	if args[2] != "" {
+		to, err := lookupCheckpoint(repo, task.Name, args[2])
+		if err != nil {
+			return nil, err
+		}
+		res.to = to.ID
+		target = to.Commit
+	}
+	if res.diff, err = repo.CheckpointDiff(from.Commit, target, exclude); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// checkpointRestore は作業ツリーをチェックポイントの状態に戻す
+// 戻す前の状態は新しいチェックポイントとして保存する
+func (c *cli) checkpointRestore(args []string) (result, error) {
+	if err := requireArgs("checkpoint", args, "<タスク名>", "<番号>"); err != nil {
+		return nil, err
+	}
+	task, repo, exclude, err := c.checkpointTask(args[0])
+	if err != nil {
+		return nil, err
+	}
+	cp, err := lookupCheckpoint(repo, task.Name, args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	backup, err := repo.CreateCheckpoint(task.Name, fmt.Sprintf("ccforge: #%d の復元前の状態", cp.ID), exclude)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.RestoreCheckpoint(cp, exclude); err != nil {
+		return nil, err
+	}
+	slog.Info("チェックポイントを復元しました", "component", "cli", "task", task.Name, "id", cp.ID, "backup", backup.ID)
+
+	return messageResult{
+		kindName: "checkpoint_restored",
+		payload: struct {
+			Task     string `json:"task"`
+			Restored int    `json:"restored"`
+			Backup   int    `json:"backup"`
+		}{Task: task.Name, Restored: cp.ID, Backup: backup.ID},
+		lines: []string{
+			fmt.Sprintf("チェックポイント #%d (%s) の状態に戻しました", cp.ID, cp.Subject()),
+			fmt.Sprintf("戻す前の状態はチェックポイント #%d に保存しました ('ccforge checkpoint restore %s %d' で元に戻せます)", backup.ID, task.Name, backup.ID),
+		},
+	}, nil
+}
+
+// checkpointTask はタスクとチェックポイントを保存するリポジトリ、スナップショットから除外するパスを返す
+// タスクにworktreeがある場合はworktreeのリポジトリを使う
+func (c *cli) checkpointTask(name string) (*tasks.Task, *git.Repo, []string, error) {
+	task, err := c.manager().Get(name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	project, err := git.Open(c.workDir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	exclude, err := c.checkpointExclude(project)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	repo := project
+	if task.Worktree != "" {
+		if repo, err = git.Open(task.Worktree); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return task, repo, exclude, nil
+}
+
+// checkpointExclude はスナップショットから除外するccforgeのディレクトリのリポジトリからの相対パスを返す
+// タスクのSpecsや履歴はチェックポイントの復元で戻さない
+func (c *cli) checkpointExclude(repo *git.Repo) ([]string, error) {
+	dir := filepath.Join(c.workDir, tasks.DirName)
+	if resolved, err := filepath.EvalSymlinks(c.workDir); err == nil {
+		dir = filepath.Join(resolved, tasks.DirName)
+	}
+	rel, err := filepath.Rel(repo.Root(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("除外するパスの解決に失敗しました: %w", err)
+	}
+	return []string{filepath.ToSlash(rel)}, nil
+}
+
+// saveCheckpoint はプロンプトの送信前に作業ツリーのチェックポイントを保存する
+// 保存できない場合も送信は続けるため、警告を表示するだけにする
+func (c *cli) saveCheckpoint(task *tasks.Task, prompt string) {
+	if !c.config.Checkpoint.Enabled {
+		return
+	}
+	_, repo, exclude, err := c.checkpointTask(task.Name)
+	if err != nil {
+		slog.Debug("チェックポイントを保存しません", "component", "cli", "task", task.Name, "err", err)
+		return
+	}
+	cp, err := repo.CreateCheckpoint(task.Name, prompt, exclude)
+	if err != nil {
+		slog.Warn("チェックポイントを保存できませんでした", "component", "cli", "task", task.Name, "err", err)
+		fmt.Fprintf(c.stderr, "警告: チェックポイントを保存できませんでした: %v\n", err)
+		return
+	}
+	slog.Debug("チェックポイントを保存しました", "component", "cli", "task", task.Name, "id", cp.ID)
+
+	if keep := c.config.Checkpoint.Keep; keep > 0 {
+		if _, err := repo.DeleteCheckpoints(task.Name, keep); err != nil {
+			slog.Warn("古いチェックポイントを削除できませんでした", "component", "cli", "task", task.Name, "err", err)
+		}
+	}
+}
+
+// lookupCheckpoint は番号 (先頭の # は省略可) からチェックポイントを取得する
+func lookupCheckpoint(repo *git.Repo, task, arg string) (git.Checkpoint, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil {
+		return git.Checkpoint{}, newUsageError("checkpoint", "チェックポイントの番号が不正です: %s", arg)
+	}
+	return repo.Checkpoint(task, id)
+}
+
+// checkpointEntry はチェックポイントとそのプロンプトによる変更の規模
+type checkpointEntry struct {
+	git.Checkpoint
+	stat git.DiffStat
+}
+
+// checkpointJSON はチェックポイントのJSON表現
+type checkpointJSON struct {
+	ID         int       `json:"id"`
+	Commit     string    `json:"commit"`
+	Prompt     string    `json:"prompt"`
+	CreatedAt  time.Time `json:"created_at"`
+	Files      int       `json:"files"`
+	Insertions int       `json:"insertions"`
+	Deletions  int       `json:"deletions"`
+}
+
+// checkpointListResult は checkpoint list の結果
+type checkpointListResult struct {
+	task        string
+	checkpoints []checkpointEntry
+}
+
+func (r checkpointListResult) kind() string { return "checkpoint_list" }
+
+func (r checkpointListResult) data() any {
+	list := make([]checkpointJSON, 0, len(r.checkpoints))
+	for _, cp := range r.checkpoints {
+		list = append(list, checkpointJSON{
+			ID:         cp.ID,
+			Commit:     cp.Commit,
+			Prompt:     cp.Message,
+			CreatedAt:  cp.CreatedAt.UTC(),
+			Files:      cp.stat.Files,
+			Insertions: cp.stat.Insertions,
+			Deletions:  cp.stat.Deletions,
+		})
+	}
+	return struct {
+		Task        string           `json:"task"`
+		Checkpoints []checkpointJSON `json:"checkpoints"`
+	}{Task: r.task, Checkpoints: list}
+}
+
+func (r checkpointListResult) writeText(w io.Writer) {
+	if len(r.checkpoints) == 0 {
+		fmt.Fprintf(w, "タスク %s のチェックポイントはありません ('ccforge run' でプロンプトを送信する前に保存されます)\n", r.task)
+		return
+	}
+	for _, cp := range r.checkpoints {
+		fmt.Fprintf(w, "#%d %s %s %s\n", cp.ID, formatTime(cp.CreatedAt), diffStatText(cp.stat), ansi.Truncate(cp.Subject(), checkpointPromptWidth, "…"))
+	}
+}
+
+func (r checkpointListResult) writeTable(w io.Writer) {
+	if len(r.checkpoints) == 0 {
+		r.writeText(w)
+		return
+	}
+	rows := make([][]string, 0, len(r.checkpoints))
+	for _, cp := range r.checkpoints {
+		rows = append(rows, []string{fmt.Sprintf("#%d", cp.ID), formatTime(cp.CreatedAt), diffStatText(cp.stat), ansi.Truncate(cp.Subject(), checkpointPromptWidth, "…")})
+	}
+	printTable(w, []string{"番号", "日時", "変更", "プロンプト"}, rows)
+}
+
+// diffStatText は差分の規模の表示文字列を返す
+func diffStatText(s git.DiffStat) string {
+	if s.Files == 0 {
+		return "変更なし"
+	}
+	return fmt.Sprintf("%dファイル +%d -%d", s.Files, s.Insertions, s.Deletions)
+}
+
+// checkpointDiffResult は checkpoint diff の結果
+type checkpointDiffResult struct {
+	task string
+	from int
+	to   int // 0の場合は現在の作業ツリー
+	diff string
+}
+
+func (r checkpointDiffResult) kind() string { return "checkpoint_diff" }
+
+func (r checkpointDiffResult) data() any {
+	return struct {
+		Task string `json:"task"`
+		From int    `json:"from"`
+		To   int    `json:"to,omitempty"`
+		Diff string `json:"diff"`
+	}{Task: r.task, From: r.from, To: r.to, Diff: r.diff}
+}
+
+func (r checkpointDiffResult) writeText(w io.Writer) {
+	if r.diff == "" {
+		fmt.Fprintln(w, "差分はありません")
+		return
+	}
+	fmt.Fprint(w, r.diff)
+}
+
+func (r checkpointDiffResult) writeTable(w io.Writer) { r.writeText(w) }
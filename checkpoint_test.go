@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLI_Checkpoint(t *testing.T) {
+	c, out := newRunTestCLI(t, 0)
+	initGitProject(t, c.workDir)
+	dir := c.workDir
+	write := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	// プロンプトの送信前に保存する
+	write("a.txt", "v1\n")
+	require.NoError(t, c.run([]string{"run", "--task", "auth", "--prompt", "最初のプロンプト", "--save-history"}))
+	write("a.txt", "v2\n")
+	write("b.txt", "b\n")
+	require.NoError(t, c.run([]string{"run", "--task", "auth", "--prompt", "次のプロンプト\n詳細"}))
+	write("a.txt", "v3\n")
+	assert.Empty(t, runGitCmd(t, dir, "diff", "--cached", "--name-only"))
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"checkpoint", "list", "auth"}))
+	assert.Contains(t, out.String(), "2ファイル +2 -1 最初のプロンプト\n")
+	assert.Contains(t, out.String(), "1ファイル +1 -1 次のプロンプト\n")
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"checkpoint", "diff", "auth", "1", "#2"}))
+	assert.Contains(t, out.String(), "+++ b/b.txt")
+	assert.NotContains(t, out.String(), "ccforge/")
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"checkpoint", "diff", "auth", "2"}))
+	assert.Contains(t, out.String(), "+v3")
+
+	// 戻す前の状態を保存してから戻す
+	out.Reset()
+	require.NoError(t, c.run([]string{"checkpoint", "restore", "auth", "1"}))
+	assert.Contains(t, out.String(), "チェックポイント #1 (最初のプロンプト) の状態に戻しました")
+	assert.Contains(t, out.String(), "チェックポイント #3 に保存しました")
+	content, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1\n", string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "b.txt"))
+	// タスクの履歴は戻さない
+	history, err := c.manager().History("auth")
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"checkpoint", "diff", "auth", "1"}))
+	assert.Equal(t, "差分はありません\n", out.String())
+
+	err = c.run([]string{"checkpoint", "restore", "auth", "9"})
+	assert.ErrorIs(t, err, git.ErrCheckpointNotFound)
+	assert.Equal(t, exitUsage, exitCode(c.run([]string{"checkpoint", "restore", "auth", "x"})))
+	assert.Equal(t, exitUsage, exitCode(c.run([]string{"checkpoint", "diff", "auth"})))
+	assert.Equal(t, exitUsage, exitCode(c.run([]string{"checkpoint"})))
+}
+
+func TestCLI_CheckpointConfig(t *testing.T) {
+	c, out := newRunTestCLI(t, 0)
+	initGitProject(t, c.workDir)
+
+	require.NoError(t, c.run([]string{"--set", "checkpoint.enabled=false", "run", "--task", "auth", "--prompt", "保存しない"}))
+	out.Reset()
+	require.NoError(t, c.run([]string{"checkpoint", "list", "auth"}))
+	assert.Contains(t, out.String(), "チェックポイントはありません")
+
+	// 古いものから削除して checkpoint.keep 個残す
+	for _, prompt := range []string{"1", "2", "3"} {
+		require.NoError(t, c.run([]string{"--set", "checkpoint.keep=2", "run", "--task", "auth", "--prompt", prompt}))
+	}
+	repo, err := git.Open(c.workDir)
+	require.NoError(t, err)
+	list, err := repo.Checkpoints("auth")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "2", list[0].Message)
+	assert.Equal(t, "3", list[1].Message)
+}
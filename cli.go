@@ -0,0 +1,788 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mzkmnk/ccforge/internal/claude"
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/mzkmnk/ccforge/internal/export"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/logging"
+	"github.com/mzkmnk/ccforge/internal/project"
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/mzkmnk/ccforge/internal/tui"
+	"github.com/mzkmnk/ccforge/internal/watch"
+)
+
+// 終了コード
+const (
+	exitOK    = 0 // 正常終了
+	exitError = 1 // 実行時エラー
+	exitUsage = 2 // 引数・使用法の誤り
+)
+
+// usageError はコマンドの使用法の誤りを表すエラー
+type usageError struct {
+	command string // 対象のサブコマンド (ルートの場合は空)
+	err     error  // 元のエラー
+}
+
+// Error はエラーメッセージを返す
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap は元のエラーを返す
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// hint はヘルプの参照方法を返す
+func (e *usageError) hint() string {
+	if e.command == "" {
+		return "詳しくは 'ccforge --help' を参照してください"
+	}
+	return fmt.Sprintf("詳しくは 'ccforge %s --help' を参照してください", e.command)
+}
+
+// newUsageError はサブコマンドの使用法の誤りを作成する
+func newUsageError(command, format string, args ...any) error {
+	return &usageError{command: command, err: fmt.Errorf(format, args...)}
+}
+
+// exitCode はエラーに対応する終了コードを返す
+// Claude Code CLIが失敗した場合はその終了コードを引き継ぐ
+func exitCode(err error) int {
+	var usageErr *usageError
+	var childErr *claude.ExitError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &childErr) && childErr.Code > 0:
+		return childErr.Code
+	default:
+		return exitError
+	}
+}
+
+// cli はサブコマンドの実行環境
+type cli struct {
+	stdin      io.Reader                      // 標準入力
+	stdout     io.Writer                      // 標準出力
+	stderr     io.Writer                      // 標準エラー出力
+	workDir    string                         // プロジェクトのディレクトリ (setup後はルート)
+	configPath string                         // 明示された設定ファイル
+	config     *config.Config                 // 読み込んだ設定
+	configErr  error                          // 設定の読み込みエラー
+	reload     func() (*config.Config, error) // 起動時と同じ順序で設定を読み込み直す
+	watched    []string                       // 再読み込みのために監視する設定ファイル
+	logPath    string                         // 既定のログファイル (空の場合はキャッシュディレクトリ)
+	closeLog   func() error                   // ログファイルを閉じる
+	launch     func(opts launchOptions) error // TUIの起動 (テストで差し替える)
+	claude     claude.Runner                  // Claude Code CLIの実行設定
+}
+
+// newCLI は標準出力と作業ディレクトリを使う実行環境を作成する
+func newCLI() (*cli, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("作業ディレクトリの取得に失敗しました: %w", err)
+	}
+
+	return &cli{
+		stdin:   os.Stdin,
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+		workDir: cwd,
+		launch:  launchTUI,
+		claude:  claude.Runner{Bin: claude.BinFromEnv()},
+	}, nil
+}
+
+// setup はグローバルオプションからプロジェクトのルートとロガーを設定する
+// lenientがtrueの場合は設定の読み込みに失敗しても既定値で続行する
+func (c *cli) setup(g globalOptions, lenient bool) error {
+	root, err := project.Resolve(g.project, c.workDir)
+	if err != nil {
+		return err
+	}
+	c.workDir = root
+	c.configPath = g.config
+
+	c.config, c.configErr = loadConfig(root, g)
+	c.watched, _ = configPaths(root, g.config)
+	c.reload = func() (*config.Config, error) { return loadConfig(root, g) }
+	if c.configErr != nil {
+		if !lenient {
+			return c.configErr
+		}
+		c.config = config.Default()
+	}
+	if c.config.Claude.Bin != "" {
+		c.claude.Bin = c.config.Claude.Bin
+	}
+
+	logPath := c.config.Log.File
+	if logPath == "" {
+		logPath = c.logPath
+	}
+	closeLog, err := logging.Setup(logging.Options{Path: logPath, Verbose: c.config.Log.Verbose})
+	switch {
+	case err != nil && c.config.Log.File != "":
+		return err
+	case err != nil:
+		// 既定のログファイルを開けない場合はログなしで続行する
+		logging.Discard()
+	default:
+		c.closeLog = closeLog
+	}
+
+	slog.Info("ccforgeを起動しました", "component", "cli", "project", c.workDir, "config", c.configPath, "verbose", c.config.Log.Verbose)
+	return nil
+}
+
+// teardown はログファイルを閉じる
+func (c *cli) teardown() {
+	if c.closeLog != nil {
+		_ = c.closeLog()
+		c.closeLog = nil
+	}
+}
+
+// launchOptions はTUI起動時の設定
+type launchOptions struct {
+	workDir  string                         // プロジェクトのルート
+	worktree string                         // タスクのgit worktree (省略時は空)
+	config   *config.Config                 // 読み込んだ設定
+	watched  []string                       // 変更を監視する設定ファイル
+	reload   func() (*config.Config, error) // 設定の再読み込み
+	task     string                         // アクティブにするタスク (省略時は空)
+
+	taskID       string                     // 画面の状態を保存するタスクのID
+	sessionID    string                     // タスクのClaude CodeのセッションID
+	saveSnapshot func(store.Snapshot) error // 画面の状態の保存 (nilの場合は保存しない)
+	resume       *store.Snapshot            // 復元する画面の状態
+
+	search     func(query string) ([]store.SearchHit, error)                      // トランスクリプトの全文検索
+	transcript func(sessionID string) ([]store.PromptTranscript, error)           // セッションのトランスクリプトの読み込み
+	export     func(task, sessionID string, format export.Format) (string, error) // トランスクリプトのエクスポート
+}
+
+// launchOptions はセットアップ済みの設定でTUI起動時の設定を作る
+func (c *cli) launchOptions(task string) launchOptions {
+	return launchOptions{
+		workDir:    c.workDir,
+		config:     c.config,
+		watched:    c.watched,
+		reload:     c.reload,
+		task:       task,
+		search:     c.searchFunc(),
+		transcript: c.transcriptFunc(),
+		export:     c.exportFunc(),
+	}
+}
+
+// launchTUI はTUIアプリケーションを初期化して実行する
+func launchTUI(opts launchOptions) error {
+	modelOpts := []tui.Option{tui.WithWorkDir(opts.workDir), tui.WithConfig(opts.config)}
+	// 変更の監視と差分、gitの状態はworktreeがあればworktreeを対象にする
+	codeDir := opts.workDir
+	if opts.worktree != "" {
+		codeDir = opts.worktree
+		modelOpts = append(modelOpts, tui.WithWorktree(opts.worktree))
+	}
+	if opts.reload != nil {
+		modelOpts = append(modelOpts, tui.WithConfigReload(opts.watched, opts.reload))
+	}
+	if opts.saveSnapshot != nil {
+		modelOpts = append(modelOpts, tui.WithSnapshots(opts.taskID, opts.sessionID, opts.saveSnapshot))
+	}
+	if opts.search != nil {
+		modelOpts = append(modelOpts, tui.WithTranscriptSearch(opts.search, opts.transcript))
+	}
+	if opts.export != nil {
+		modelOpts = append(modelOpts, tui.WithTranscriptExport(opts.export))
+	}
+	if opts.task != "" {
+		modelOpts = append(modelOpts, tui.WithActiveTask(opts.task))
+	}
+	if opts.resume != nil {
+		modelOpts = append(modelOpts, tui.WithResume(*opts.resume))
+	}
+
+	// タスクのディレクトリの変更をTUIに反映する (監視できない場合も起動は続ける)
+	watcher, err := watch.New(tasks.NewManager(opts.workDir).Dir(), 0)
+	if err != nil {
+		slog.Warn("タスクの監視を開始できませんでした", "component", "cli", "err", err)
+	} else {
+		defer watcher.Close()
+		modelOpts = append(modelOpts, tui.WithWatcher(watcher))
+	}
+
+	// セッション中に変更されたプロジェクトのファイルを記録する (監視できない場合も起動は続ける)
+	feed, err := watch.WatchProject(codeDir, watch.FeedOptions{
+		Ignore:       opts.config.Watch.Ignore,
+		MaxWatches:   opts.config.Watch.MaxWatches,
+		PollInterval: opts.config.Watch.PollInterval,
+	})
+	if err != nil {
+		slog.Warn("プロジェクトの監視を開始できませんでした", "component", "cli", "err", err)
+	} else {
+		defer feed.Close()
+		slog.Info("プロジェクトの監視を開始しました", "component", "cli", "mode", feed.Mode())
+		modelOpts = append(modelOpts, tui.WithChangeFeed(feed, opts.sessionID))
+	}
+
+	// ステータスバーにgitリポジトリの状態を表示する (リポジトリでない場合は表示しない)
+	if repo, err := git.Open(codeDir); err != nil {
+		slog.Debug("gitの状態を表示しません", "component", "cli", "err", err)
+	} else {
+		modelOpts = append(modelOpts, tui.WithGitStatus(repo.Summary))
+	}
+
+	app, err := initializeApp(modelOpts...)
+	if err != nil {
+		return fmt.Errorf("初期化エラー: %w", err)
+	}
+
+	if err := runApp(app); err != nil {
+		return fmt.Errorf("実行エラー: %w", err)
+	}
+	return nil
+}
+
+// runFunc はフラグ解析後のサブコマンドの処理
+// 非対話のコマンドは出力する結果を返す
+type runFunc func(c *cli, args []string) (result, error)
+
+// subcommand はccforgeのサブコマンドの定義
+type subcommand struct {
+	name        string                         // コマンド名
+	usage       string                         // 引数の書式
+	summary     string                         // 一覧に表示する説明
+	description string                         // ヘルプに表示する詳しい説明
+	output      outputFormat                   // 既定の出力形式 (空の場合は --output に対応しない)
+	passthrough bool                           // "--" 以降の引数だけを処理へ渡す
+	lenient     bool                           // 設定の読み込みに失敗しても実行する
+	flags       func(fs *flag.FlagSet) runFunc // フラグを定義して処理を返す
+}
+
+// subcommands はサブコマンドを表示順に並べたもの
+var subcommands = []subcommand{
+	{
+		name:        "new",
+		usage:       "[オプション] <タスク名>",
+		summary:     "新しいタスクを作成する",
+		description: "ccforge/<タスク名>/ にrequirements.md・design.md・tasks.mdのテンプレートを作成します。\n--branch でgitブランチ task/<タスク名> を作成してチェックアウトし、タスクに記録します。\n--worktree でブランチ task/<タスク名> をチェックアウトしたgit worktreeを git.worktree_dir に作成します。",
+		output:      formatText,
+		flags:       newCommand,
+	},
+	{
+		name:        "start",
+		usage:       "[オプション] <タスク名>",
+		summary:     "タスクをアクティブにしてTUIを起動する",
+		description: "指定したタスクをアクティブにした状態でTUIを起動します。\n画面の状態は終了時と定期的に保存され、--resume で復元できます。\nタスクのブランチが現在のブランチと異なる場合は切り替えるか確認します (--checkout で確認せずに切り替え)。\nコミットしていない変更がある場合は切り替えず、--stash を指定するとstashに退避してから切り替えます。\nタスクにworktreeがある場合はブランチを切り替えず、変更の監視と差分パネルをworktreeに限定します。",
+		flags:       startCommand,
+	},
+	{
+		name:        "list",
+		usage:       "[オプション]",
+		summary:     "タスクの一覧を状態と進捗付きで表示する",
+		description: "tasks.mdのチェックリストから集計した状態と進捗を表示します。",
+		output:      formatTable,
+		flags:       listCommand,
+	},
+	{
+		name:        "status",
+		usage:       "[オプション] <タスク名>",
+		summary:     "タスクの状態と未完了の項目を表示する",
+		description: "タスクの状態・進捗とtasks.mdのチェックリストを表示します。",
+		output:      formatText,
+		flags:       statusCommand,
+	},
+	{
+		name:        "show",
+		usage:       "[オプション] <タスク名>",
+		summary:     "タスクのSpecsを表示する",
+		description: "タスクのrequirements.md・design.md・tasks.mdの内容を表示します。",
+		output:      formatText,
+		flags:       showCommand,
+	},
+	{
+		name:        "archive",
+		usage:       "[オプション] <タスク名>",
+		summary:     "タスクをアーカイブする",
+		description: "タスクを ccforge/.archive/ へ移動します。一覧には表示されなくなります。",
+		output:      formatText,
+		flags:       archiveCommand,
+	},
+	{
+		name:        "branches",
+		usage:       "[オプション]",
+		summary:     "タスクのブランチを一覧表示し、マージ済みのものを削除する",
+		description: "task/ で始まるブランチを、使っているタスクと既定のブランチ (--base) にマージ済みかとともに表示します。\n--clean でマージ済みのブランチを削除します。チェックアウト中のブランチとまだコミットのないブランチは削除しません。",
+		output:      formatTable,
+		flags:       branchesCommand,
+	},
+	{
+		name:        "worktree",
+		usage:       "[オプション] <add <タスク名> | list | remove <タスク名> | prune>",
+		summary:     "タスクごとのgit worktreeを作成・一覧表示・削除する",
+		description: "add はタスクのブランチ (なければ task/<タスク名> を作成) をチェックアウトしたworktreeを git.worktree_dir/<タスク名> に作成します。\nlist はworktreeを使っているタスクと変更の有無とともに表示します。\nremove はworktreeを削除します。コミットしていない変更がある場合は --force を指定しない限り削除せず、ブランチは残します。\nprune はディレクトリが削除されたworktreeの登録とタスクの記録を整理します。",
+		output:      formatText,
+		flags:       worktreeCommand,
+	},
+	{
+		name:        "checkpoint",
+		usage:       "[オプション] <list <タスク名> | diff <タスク名> <番号> [<番号>] | restore <タスク名> <番号>>",
+		summary:     "プロンプトごとのチェックポイントを一覧表示・比較・復元する",
+		description: "ccforge run はプロンプトを送信する前に作業ツリーのスナップショットを refs/ccforge/checkpoints/<タスク名>/<番号> に保存します (checkpoint.enabled)。\nスナップショットはインデックスとブランチを変更せず、未追跡のファイルを含み、.gitignore で無視するファイルと ccforge/ を含みません。\nlist はチェックポイントをプロンプトとそのプロンプトによる変更の規模とともに表示します。\ndiff は2つのチェックポイント (2つ目を省略した場合は現在の作業ツリー) の差分を表示します。\nrestore は作業ツリーをチェックポイントの状態に戻します。戻す前の状態は新しいチェックポイントとして保存します。",
+		output:      formatText,
+		flags:       checkpointCommand,
+	},
+	{
+		name:        "run",
+		usage:       "[オプション] --task <タスク名> [--prompt <プロンプト>] [-- <claudeの引数>...]",
+		summary:     "タスクのセッションでプロンプトを非対話で実行する (CI向け)",
+		description: "TUIを起動せずにClaude Codeへプロンプトを送信し、出力を標準出力へ流します。\n--prompt を省略した場合は標準入力からプロンプトを読み込みます。\ngitリポジトリでは送信前に作業ツリーのチェックポイントを保存します (ccforge checkpoint で確認・復元できます)。\n終了コードはClaude Code CLIの終了コードを引き継ぎます。",
+		passthrough: true,
+		flags:       runCommand,
+	},
+	{
+		name:        "search",
+		usage:       "[オプション] <検索語>...",
+		summary:     "すべてのタスクのトランスクリプトを全文検索する",
+		description: "run --save-history で残したやり取りを検索し、一致箇所の抜粋をタスク、セッション、日時とともに新しい順に表示します。\n空白で区切った検索語をすべて含むものに一致します。英字の大文字と小文字は区別しません。\n--open で結果の番号を指定すると、一致したやり取りからトランスクリプトを表示します。",
+		output:      formatText,
+		flags:       searchCommand,
+	},
+	{
+		name:        "export",
+		usage:       "[オプション] <タスク名>",
+		summary:     "セッションのトランスクリプトをMarkdown・HTML・JSONLで書き出す",
+		description: "run --save-history で残したセッションのやり取りを書き出します。省略時は最新のセッションを標準出力へ書き出します。\nmd はプロンプトと応答を見出しで区切ったMarkdown、html はANSIカラーを保ったHTML、jsonl は日時と役割付きのJSON Linesです。\n--save でタスクのディレクトリの exports/ に保存します。APIキーやパスワードらしき文字列は --no-redact を指定しない限り伏せます。",
+		flags:       exportCommand,
+	},
+	{
+		name:        "config",
+		usage:       "[オプション] <get [キー] | set <キー> <値> | validate [ファイル...]>",
+		summary:     "設定を表示・変更・検証する",
+		description: "設定は 既定値 < グローバル設定 < プロジェクト設定 < 環境変数 (CCFORGE_*) < フラグ (--set) の順に優先されます。\nget は有効な値を、set はプロジェクト設定 (--global の場合はグローバル設定) への書き込みを、\nvalidate は設定ファイルと環境変数の検証を行います。",
+		output:      formatText,
+		lenient:     true,
+		flags:       configCommand,
+	},
+}
+
+// lookupSubcommand は名前からサブコマンドを取得する
+func lookupSubcommand(name string) (subcommand, bool) {
+	for _, sc := range subcommands {
+		if sc.name == name {
+			return sc, true
+		}
+	}
+	return subcommand{}, false
+}
+
+// run はCLI引数を解析してサブコマンドを実行する
+// --output json の場合はエラーもJSONで標準エラー出力へ書き出す
+func (c *cli) run(args []string) error {
+	format := detectFormat(args)
+	// ロガーを設定するまでのログは標準エラー出力に漏らさない
+	logging.Discard()
+	defer c.teardown()
+
+	err := c.dispatch(args, &format)
+	if err != nil {
+		slog.Error("コマンドが失敗しました", "component", "cli", "args", args, "err", err)
+	}
+	if err != nil && format == formatJSON {
+		if writeErr := writeErrorJSON(c.stderr, err); writeErr != nil {
+			return writeErr
+		}
+		return &reportedError{err: err}
+	}
+	return err
+}
+
+// dispatch はサブコマンドを選んで実行する
+func (c *cli) dispatch(args []string, format *outputFormat) error {
+	opts, err := parseCLIArgs(args)
+	if err != nil {
+		return &usageError{err: fmt.Errorf("引数パースエラー: %w", err)}
+	}
+
+	if opts.help {
+		showHelp(c.stdout)
+		return nil
+	}
+
+	switch opts.command {
+	case "":
+		if err := c.setup(opts.globalOptions, false); err != nil {
+			return err
+		}
+		return c.launch(c.launchOptions(""))
+	case "help":
+		return c.help(opts.args)
+	}
+
+	sc, ok := lookupSubcommand(opts.command)
+	if !ok {
+		return &usageError{err: fmt.Errorf("不明なコマンドです: %s", opts.command)}
+	}
+	return c.runSubcommand(sc, opts.args, opts.globalOptions, format)
+}
+
+// help はルートまたはサブコマンドのヘルプを表示する
+func (c *cli) help(args []string) error {
+	if len(args) == 0 {
+		showHelp(c.stdout)
+		return nil
+	}
+
+	sc, ok := lookupSubcommand(args[0])
+	if !ok {
+		return &usageError{err: fmt.Errorf("不明なコマンドです: %s", args[0])}
+	}
+	fs, _ := newFlagSet(sc, new(outputFormat), new(globalOptions))
+	printSubcommandHelp(c.stdout, sc, fs)
+	return nil
+}
+
+// runSubcommand はサブコマンドのフラグを解析して実行し、結果を出力する
+// ルートで指定したグローバルオプションはサブコマンドの後ろで上書きできる
+func (c *cli) runSubcommand(sc subcommand, args []string, global globalOptions, format *outputFormat) error {
+	parsed := sc.output
+	fs, run := newFlagSet(sc, &parsed, &global)
+	var help bool
+	fs.BoolVar(&help, "h", false, "このヘルプを表示")
+	fs.BoolVar(&help, "help", false, "このヘルプを表示")
+
+	positional, rest, err := parseInterspersed(fs, args)
+	if err != nil {
+		return newUsageError(sc.name, "%v", err)
+	}
+	if sc.passthrough {
+		if len(positional) > 0 {
+			return newUsageError(sc.name, "余分な引数があります: %s (コマンドへ渡す引数は -- の後に指定してください)", strings.Join(positional, " "))
+		}
+		positional = rest
+	} else {
+		positional = append(positional, rest...)
+	}
+	*format = parsed
+	if help {
+		printSubcommandHelp(c.stdout, sc, fs)
+		return nil
+	}
+
+	if err := c.setup(global, sc.lenient); err != nil {
+		return err
+	}
+	slog.Debug("サブコマンドを実行します", "component", "cli", "command", sc.name, "args", positional, "output", *format)
+
+	res, err := run(c, positional)
+	if err != nil || res == nil {
+		return err
+	}
+	return writeResult(c.stdout, *format, res)
+}
+
+// newFlagSet はサブコマンドのフラグセットを作成する
+func newFlagSet(sc subcommand, format *outputFormat, global *globalOptions) (*flag.FlagSet, runFunc) {
+	fs := flag.NewFlagSet("ccforge "+sc.name, flag.ContinueOnError)
+	// エラーは呼び出し側でまとめて表示する
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	registerGlobalFlags(fs, global)
+
+	if sc.output != "" {
+		usage := fmt.Sprintf("出力形式 (json, table, text。既定: %s)", sc.output)
+		fs.Var(format, "o", usage)
+		fs.Var(format, "output", usage)
+	}
+	return fs, sc.flags(fs)
+}
+
+// parseInterspersed は位置引数の後ろに置かれたフラグも解析する
+// "--" 以降はフラグとして解析せずにrestとして返す
+func parseInterspersed(fs *flag.FlagSet, args []string) (positional, rest []string, err error) {
+	for i, arg := range args {
+		if arg == "--" {
+			rest = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return positional, rest, nil
+}
+
+// printSubcommandHelp はサブコマンドのヘルプを表示する
+func printSubcommandHelp(w io.Writer, sc subcommand, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "使用法: ccforge %s %s\n\n%s\n", sc.name, sc.usage, sc.description)
+
+	var buf bytes.Buffer
+	fs.SetOutput(&buf)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+	if buf.Len() > 0 {
+		fmt.Fprintf(w, "\nオプション:\n%s", buf.String())
+	}
+}
+
+// requireArgs は位置引数の数を検証する
+func requireArgs(command string, args []string, names ...string) error {
+	if len(args) < len(names) {
+		return newUsageError(command, "%s を指定してください", strings.Join(names[len(args):], " "))
+	}
+	if len(args) > len(names) {
+		return newUsageError(command, "余分な引数があります: %s", strings.Join(args[len(names):], " "))
+	}
+	return nil
+}
+
+// manager はプロジェクトのタスクマネージャーを返す
+func (c *cli) manager() *tasks.Manager {
+	return tasks.NewManager(c.workDir)
+}
+
+// newCommand は new サブコマンドを定義する
+func newCommand(fs *flag.FlagSet) runFunc {
+	var description string
+	var start, branch, worktree bool
+	fs.StringVar(&description, "d", "", "タスクの概要 (requirements.mdに記載)")
+	fs.StringVar(&description, "description", "", "タスクの概要 (requirements.mdに記載)")
+	fs.BoolVar(&start, "start", false, "作成後にタスクを開始してTUIを起動")
+	fs.BoolVar(&branch, "branch", false, "gitブランチ task/<タスク名> を作成してチェックアウト")
+	fs.BoolVar(&worktree, "worktree", false, "ブランチ task/<タスク名> をチェックアウトしたgit worktreeを作成")
+
+	return func(c *cli, args []string) (result, error) {
+		if err := requireArgs("new", args, "<タスク名>"); err != nil {
+			return nil, err
+		}
+
+		if branch && worktree {
+			return nil, newUsageError("new", "--branch と --worktree は同時に指定できません")
+		}
+
+		// リポジトリでない場合はタスクを作る前に失敗させる
+		var repo *git.Repo
+		if branch || worktree {
+			var err error
+			if repo, err = git.Open(c.workDir); err != nil {
+				return nil, err
+			}
+		}
+
+		task, err := c.manager().Create(args[0], description)
+		if errors.Is(err, tasks.ErrInvalidName) {
+			return nil, newUsageError("new", "%v", err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case branch:
+			if err := c.createTaskBranch(repo, task); err != nil {
+				return nil, fmt.Errorf("タスク %s は作成しましたがブランチを作成できませんでした: %w", task.Name, err)
+			}
+		case worktree:
+			if err := c.createTaskWorktree(repo, task); err != nil {
+				return nil, fmt.Errorf("タスク %s は作成しましたがworktreeを作成できませんでした: %w", task.Name, err)
+			}
+		}
+
+		lines := []string{fmt.Sprintf("タスク %s を作成しました: %s", task.Name, task.Dir)}
+		for _, spec := range tasks.SpecFiles {
+			lines = append(lines, fmt.Sprintf("  %s (%s)", spec.FileName, spec.Title))
+		}
+		switch {
+		case task.Worktree != "":
+			lines = append(lines, worktreeCreatedResult(*task).lines...)
+		case task.Branch != "":
+			lines = append(lines, fmt.Sprintf("ブランチ %s を作成してチェックアウトしました", task.Branch))
+		}
+		res := messageResult{
+			kindName: "task_created",
+			payload: struct {
+				Task taskJSON `json:"task"`
+			}{Task: newTaskJSON(*task)},
+			lines: lines,
+		}
+
+		// TUIを起動する場合は対話モードのため作成結果をテキストで表示する
+		if start {
+			res.writeText(c.stdout)
+			return nil, c.startTask(task, false)
+		}
+		return res, nil
+	}
+}
+
+// startCommand は start サブコマンドを定義する
+func startCommand(fs *flag.FlagSet) runFunc {
+	var resume bool
+	var sw branchSwitch
+	fs.BoolVar(&resume, "resume", false, "前回終了したときの画面と入力を復元する")
+	fs.BoolVar(&sw.checkout, "checkout", false, "確認せずにタスクのブランチへ切り替える")
+	fs.BoolVar(&sw.stash, "stash", false, "コミットしていない変更をstashに退避してタスクのブランチへ切り替える")
+
+	return func(c *cli, args []string) (result, error) {
+		if err := requireArgs("start", args, "<タスク名>"); err != nil {
+			return nil, err
+		}
+
+		task, err := c.manager().Get(args[0])
+		if errors.Is(err, tasks.ErrTaskNotFound) {
+			return nil, fmt.Errorf("%w ('ccforge new %s' で作成できます)", err, args[0])
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := c.switchTaskBranch(task, sw); err != nil {
+			return nil, err
+		}
+		return nil, c.startTask(task, resume)
+	}
+}
+
+// startTask はタスクをアクティブにしてTUIを起動する
+// resumeまたは ui.auto_resume が指定されていれば前回の画面を復元する
+func (c *cli) startTask(task *tasks.Task, resume bool) error {
+	opts := c.launchOptions(task.Name)
+	if task.Worktree != "" {
+		if _, err := os.Stat(task.Worktree); err != nil {
+			return fmt.Errorf("タスク %s のworktree %s が見つかりません ('ccforge worktree prune' で記録を整理できます): %w", task.Name, task.Worktree, err)
+		}
+		opts.worktree = task.Worktree
+	}
+	closeStore := c.attachSnapshots(task, resume || c.config.UI.AutoResume, &opts)
+	defer closeStore()
+	return c.launch(opts)
+}
+
+// listCommand は list サブコマンドを定義する
+func listCommand(fs *flag.FlagSet) runFunc {
+	var all bool
+	fs.BoolVar(&all, "a", false, "アーカイブ済みのタスクも表示")
+	fs.BoolVar(&all, "all", false, "アーカイブ済みのタスクも表示")
+
+	return func(c *cli, args []string) (result, error) {
+		if err := requireArgs("list", args); err != nil {
+			return nil, err
+		}
+
+		list, err := c.manager().List()
+		if err != nil {
+			return nil, err
+		}
+		if all {
+			archived, err := c.manager().ListArchived()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, archived...)
+		}
+
+		return taskListResult{tasks: list}, nil
+	}
+}
+
+// statusCommand は status サブコマンドを定義する
+func statusCommand(*flag.FlagSet) runFunc {
+	return func(c *cli, args []string) (result, error) {
+		if err := requireArgs("status", args, "<タスク名>"); err != nil {
+			return nil, err
+		}
+
+		task, err := c.manager().Get(args[0])
+		if err != nil {
+			return nil, err
+		}
+		items, err := c.manager().Checklist(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return taskStatusResult{task: *task, items: items}, nil
+	}
+}
+
+// showCommand は show サブコマンドを定義する
+func showCommand(fs *flag.FlagSet) runFunc {
+	var file string
+	fs.StringVar(&file, "f", "", "表示するファイル (requirements, design, tasks)")
+	fs.StringVar(&file, "file", "", "表示するファイル (requirements, design, tasks)")
+
+	return func(c *cli, args []string) (result, error) {
+		if err := requireArgs("show", args, "<タスク名>"); err != nil {
+			return nil, err
+		}
+
+		var only tasks.SpecFile
+		if file != "" {
+			var ok bool
+			if only, ok = tasks.LookupSpecFile(file); !ok {
+				return nil, newUsageError("show", "不明なファイルです: %s (requirements, design, tasks のいずれか)", file)
+			}
+		}
+
+		specs, err := c.manager().Specs(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		res := taskSpecsResult{task: args[0]}
+		for _, spec := range specs {
+			if file == "" || spec.Kind == only.Kind {
+				res.specs = append(res.specs, spec)
+			}
+		}
+		return res, nil
+	}
+}
+
+// archiveCommand は archive サブコマンドを定義する
+func archiveCommand(*flag.FlagSet) runFunc {
+	return func(c *cli, args []string) (result, error) {
+		if err := requireArgs("archive", args, "<タスク名>"); err != nil {
+			return nil, err
+		}
+
+		dest, err := c.manager().Archive(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return messageResult{
+			kindName: "task_archived",
+			payload: struct {
+				Name       string `json:"name"`
+				ArchivedTo string `json:"archived_to"`
+			}{Name: args[0], ArchivedTo: dest},
+			lines: []string{fmt.Sprintf("タスク %s をアーカイブしました: %s", args[0], dest)},
+		}, nil
+	}
+}
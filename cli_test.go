@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCLI はテスト用の実行環境を作成する
+// TUIは起動せず、起動時の設定を記録する
+func newTestCLI(t *testing.T) (*cli, *bytes.Buffer, *[]launchOptions) {
+	t.Helper()
+
+	// ログは一時ディレクトリに書き出し、既定のロガーはテスト後に戻す
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	// ユーザーのグローバル設定を読み込まない
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var out bytes.Buffer
+	var launched []launchOptions
+	c := &cli{
+		stdout:  &out,
+		stderr:  &bytes.Buffer{},
+		workDir: t.TempDir(),
+		logPath: filepath.Join(t.TempDir(), "ccforge.log"),
+		launch: func(opts launchOptions) error {
+			// 設定と画面の保存は個別のテストで確認する
+			launched = append(launched, launchOptions{workDir: opts.workDir, worktree: opts.worktree, task: opts.task})
+			return nil
+		},
+	}
+	return c, &out, &launched
+}
+
+func TestCLI_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{name: "正常系_ヘルプ", args: []string{"--help"}, wantCode: exitOK, wantOut: "archive"},
+		{name: "正常系_helpコマンド", args: []string{"help", "new"}, wantCode: exitOK, wantOut: "-description"},
+		{name: "正常系_サブコマンドのヘルプ", args: []string{"list", "-h"}, wantCode: exitOK, wantOut: "使用法: ccforge list"},
+		{name: "異常系_不明なコマンド", args: []string{"unknown"}, wantCode: exitUsage},
+		{name: "異常系_不明なフラグ", args: []string{"list", "--bogus"}, wantCode: exitUsage},
+		{name: "異常系_引数不足", args: []string{"new"}, wantCode: exitUsage},
+		{name: "異常系_余分な引数", args: []string{"show", "a", "b"}, wantCode: exitUsage},
+		{name: "異常系_不正なタスク名", args: []string{"new", "a/b"}, wantCode: exitUsage},
+		{name: "異常系_存在しないタスク", args: []string{"start", "missing"}, wantCode: exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, out, _ := newTestCLI(t)
+
+			err := c.run(tt.args)
+			assert.Equal(t, tt.wantCode, exitCode(err), "err = %v", err)
+			assert.Contains(t, out.String(), tt.wantOut)
+		})
+	}
+}
+
+func TestCLI_Launch(t *testing.T) {
+	c, _, launched := newTestCLI(t)
+
+	// コマンド省略時はタスクなしで起動する
+	require.NoError(t, c.run(nil))
+
+	_, err := tasks.NewManager(c.workDir).Create("auth", "")
+	require.NoError(t, err)
+	require.NoError(t, c.run([]string{"start", "auth"}))
+
+	assert.Equal(t, []launchOptions{
+		{workDir: c.workDir},
+		{workDir: c.workDir, task: "auth"},
+	}, *launched)
+}
+
+func TestCLI_GlobalFlags(t *testing.T) {
+	t.Run("作業ディレクトリからプロジェクトのルートを探索", func(t *testing.T) {
+		c, _, launched := newTestCLI(t)
+		root := c.workDir
+		c.workDir = filepath.Join(root, "src", "pkg")
+		require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+		require.NoError(t, os.MkdirAll(c.workDir, 0o755))
+
+		require.NoError(t, c.run(nil))
+		assert.Equal(t, []launchOptions{{workDir: root}}, *launched)
+	})
+
+	t.Run("--projectと--configはサブコマンドの後ろにも置ける", func(t *testing.T) {
+		c, _, launched := newTestCLI(t)
+		other := t.TempDir()
+		_, err := tasks.NewManager(other).Create("auth", "")
+		require.NoError(t, err)
+		configFile := filepath.Join(t.TempDir(), "custom.toml")
+		require.NoError(t, os.WriteFile(configFile, []byte("[ui]\nmax_output_lines = 50\n"), 0o644))
+
+		require.NoError(t, c.run([]string{"start", "auth", "--project", other, "--config", configFile}))
+		assert.Equal(t, []launchOptions{{workDir: other, task: "auth"}}, *launched)
+		assert.Equal(t, 50, c.config.UI.MaxOutputLines)
+	})
+
+	t.Run("再読み込みは起動時と同じフラグで設定を重ねる", func(t *testing.T) {
+		c, _, _ := newTestCLI(t)
+		var opts launchOptions
+		c.launch = func(o launchOptions) error {
+			opts = o
+			return nil
+		}
+		configFile := filepath.Join(t.TempDir(), "custom.toml")
+		require.NoError(t, os.WriteFile(configFile, []byte("[ui]\nmax_output_lines = 50\n"), 0o644))
+
+		require.NoError(t, c.run([]string{"--config", configFile, "--set", "diff.side_by_side_min_width=90"}))
+		assert.Equal(t, []string{configFile}, opts.watched)
+
+		require.NoError(t, os.WriteFile(configFile, []byte("[ui]\nmax_output_lines = 70\n"), 0o644))
+		cfg, err := opts.reload()
+		require.NoError(t, err)
+		assert.Equal(t, 70, cfg.UI.MaxOutputLines)
+		assert.Equal(t, 90, cfg.Diff.SideBySideMinWidth)
+	})
+
+	t.Run("存在しない設定ファイル", func(t *testing.T) {
+		c, _, launched := newTestCLI(t)
+		err := c.run([]string{"--config", filepath.Join(c.workDir, "missing.toml")})
+		assert.ErrorContains(t, err, "設定ファイルを開けません")
+		assert.Empty(t, *launched)
+	})
+
+	t.Run("存在しないプロジェクト", func(t *testing.T) {
+		c, _, _ := newTestCLI(t)
+		err := c.run([]string{"--project", filepath.Join(c.workDir, "missing"), "list"})
+		assert.Equal(t, exitError, exitCode(err))
+	})
+
+	t.Run("--verboseでデバッグログを書き出す", func(t *testing.T) {
+		c, _, _ := newTestCLI(t)
+		logFile := filepath.Join(t.TempDir(), "debug.log")
+
+		require.NoError(t, c.run([]string{"-v", "--log-file", logFile, "list"}))
+		content, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "level=INFO")
+		assert.Contains(t, string(content), "level=DEBUG msg=サブコマンドを実行します component=cli command=list")
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	root := t.TempDir()
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	writeFile(filepath.Join(xdg, "ccforge", "config.toml"), "[ui]\nmax_output_lines = 10\n[diff]\nside_by_side_min_width = 80\nrefresh_interval = \"5s\"\n")
+	writeFile(filepath.Join(root, "ccforge", "config.toml"), "[ui]\nmax_output_lines = 20\n[diff]\nside_by_side_min_width = 90\n")
+	t.Setenv("CCFORGE_UI_MAX_OUTPUT_LINES", "30")
+
+	// 既定値 < グローバル設定 < プロジェクト設定 < 環境変数 < フラグ の順に優先される
+	cfg, err := loadConfig(root, globalOptions{set: []string{"diff.side_by_side_min_width=100"}, verbose: true})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, cfg.Diff.RefreshInterval)
+	assert.Equal(t, 30, cfg.UI.MaxOutputLines)
+	assert.Equal(t, 100, cfg.Diff.SideBySideMinWidth)
+	assert.True(t, cfg.Log.Verbose)
+
+	// 明示した設定ファイルはグローバル設定とプロジェクト設定の代わりに読み込む
+	explicit := filepath.Join(t.TempDir(), "custom.toml")
+	writeFile(explicit, "[[commands]]\nname = \"ship\"\nsteps = [{ prompt = \"/test\" }]\n")
+	cfg, err = loadConfig(root, globalOptions{config: explicit})
+	require.NoError(t, err)
+	require.Len(t, cfg.Commands, 1)
+	assert.Equal(t, 120, cfg.Diff.SideBySideMinWidth)
+
+	_, err = loadConfig(root, globalOptions{config: filepath.Join(root, "missing.toml")})
+	assert.ErrorContains(t, err, "設定ファイルを開けません")
+
+	_, err = loadConfig(root, globalOptions{set: []string{"ui.colour=red"}})
+	assert.Equal(t, exitUsage, exitCode(err))
+
+	t.Setenv("CCFORGE_LOG_VERBOSE", "yes")
+	_, err = loadConfig(root, globalOptions{})
+	assert.ErrorContains(t, err, "環境変数 CCFORGE_LOG_VERBOSE")
+}
+
+func TestCLI_New(t *testing.T) {
+	c, out, launched := newTestCLI(t)
+
+	// フラグは位置引数の後ろにも置ける
+	require.NoError(t, c.run([]string{"new", "auth", "-d", "認証の整理", "--start"}))
+	assert.Contains(t, out.String(), "タスク auth を作成しました")
+	assert.Equal(t, []launchOptions{{workDir: c.workDir, task: "auth"}}, *launched)
+
+	content, err := os.ReadFile(filepath.Join(c.workDir, tasks.DirName, "auth", tasks.RequirementsFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "認証の整理")
+
+	err = c.run([]string{"new", "auth"})
+	assert.True(t, errors.Is(err, tasks.ErrTaskExists))
+	assert.Equal(t, exitError, exitCode(err))
+}
+
+func TestCLI_List(t *testing.T) {
+	c, out, _ := newTestCLI(t)
+
+	require.NoError(t, c.run([]string{"list"}))
+	assert.Contains(t, out.String(), "タスクがありません")
+
+	m := tasks.NewManager(c.workDir)
+	task, err := m.Create("auth", "")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(task.Dir, tasks.TasksFile), []byte("- [x] a\n- [ ] b\n"), 0o644))
+	_, err = m.Create("old", "")
+	require.NoError(t, err)
+	_, err = m.Archive("old")
+	require.NoError(t, err)
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"list"}))
+	assert.Contains(t, out.String(), "タスク")
+	assert.Contains(t, out.String(), "auth    進行中  1/2 (50%)")
+	assert.NotContains(t, out.String(), "old")
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"list", "--all"}))
+	assert.Contains(t, out.String(), "アーカイブ済み")
+}
+
+func TestCLI_Show(t *testing.T) {
+	c, out, _ := newTestCLI(t)
+	_, err := tasks.NewManager(c.workDir).Create("auth", "")
+	require.NoError(t, err)
+
+	require.NoError(t, c.run([]string{"show", "auth"}))
+	assert.Contains(t, out.String(), "==> requirements.md (要件定義) <==")
+	assert.Contains(t, out.String(), "==> tasks.md (タスク) <==")
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"show", "--file", "design", "auth"}))
+	assert.Contains(t, out.String(), "# auth 設計書")
+	assert.NotContains(t, out.String(), "requirements.md")
+
+	err = c.run([]string{"show", "-f", "unknown", "auth"})
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestCLI_Archive(t *testing.T) {
+	c, out, _ := newTestCLI(t)
+	_, err := tasks.NewManager(c.workDir).Create("auth", "")
+	require.NoError(t, err)
+
+	require.NoError(t, c.run([]string{"archive", "auth"}))
+	assert.Contains(t, out.String(), "タスク auth をアーカイブしました")
+	assert.DirExists(t, filepath.Join(c.workDir, tasks.DirName, tasks.ArchiveDirName, "auth"))
+
+	err = c.run([]string{"archive", "auth"})
+	assert.ErrorIs(t, err, tasks.ErrTaskNotFound)
+}
+
+func TestParseInterspersed(t *testing.T) {
+	sc, _ := lookupSubcommand("new")
+	fs, _ := newFlagSet(sc, new(outputFormat), new(globalOptions))
+
+	positional, rest, err := parseInterspersed(fs, []string{"a", "--start", "b", "--", "-d"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, positional)
+	assert.Equal(t, []string{"-d"}, rest)
+}
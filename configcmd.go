@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mzkmnk/ccforge/internal/config"
+)
+
+// configCommand は config サブコマンドを定義する
+func configCommand(fs *flag.FlagSet) runFunc {
+	var global bool
+	fs.BoolVar(&global, "global", false, "set でグローバル設定に書き込む")
+
+	return func(c *cli, args []string) (result, error) {
+		if len(args) == 0 {
+			return nil, newUsageError("config", "get, set, validate のいずれかを指定してください")
+		}
+
+		switch action, rest := args[0], args[1:]; action {
+		case "get":
+			return c.configGet(rest)
+		case "set":
+			return c.configSet(rest, global)
+		case "validate":
+			return c.configValidate(rest)
+		default:
+			return nil, newUsageError("config", "不明な操作です: %s", action)
+		}
+	}
+}
+
+// configGet は有効な設定値を返す
+// キーを省略した場合はすべての項目を返す
+func (c *cli) configGet(args []string) (result, error) {
+	if len(args) > 1 {
+		return nil, newUsageError("config", "余分な引数があります: %s", args[1])
+	}
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+
+	fields := config.Fields
+	if len(args) == 1 {
+		f, ok := config.LookupField(args[0])
+		if !ok {
+			return nil, newUsageError("config", "不明なキーです: %s", args[0])
+		}
+		fields = []config.Field{f}
+	}
+	return configValuesResult{cfg: c.config, fields: fields, single: len(args) == 1}, nil
+}
+
+// configSet は設定ファイルに値を書き込む
+// 書き込み先は --config の指定、--global の場合はグローバル設定、それ以外はプロジェクト設定
+func (c *cli) configSet(args []string, global bool) (result, error) {
+	if err := requireArgs("config", args, "<キー>", "<値>"); err != nil {
+		return nil, err
+	}
+
+	path := c.configPath
+	switch {
+	case global:
+		var err error
+		if path, err = config.GlobalPath(); err != nil {
+			return nil, err
+		}
+	case path == "":
+		path = config.ProjectPath(c.workDir)
+	}
+
+	key, value := args[0], args[1]
+	if _, ok := config.LookupField(key); !ok {
+		return nil, newUsageError("config", "不明なキーです: %s", key)
+	}
+	if err := config.SetInFile(path, key, value); err != nil {
+		return nil, err
+	}
+	return messageResult{
+		kindName: "config_set",
+		payload: struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+			Path  string `json:"path"`
+		}{Key: key, Value: value, Path: path},
+		lines: []string{fmt.Sprintf("%s を %s に設定しました: %s", key, value, path)},
+	}, nil
+}
+
+// configValidate は設定ファイルと環境変数を検証する
+// ファイルを指定した場合はそのファイルのみを検証する
+func (c *cli) configValidate(paths []string) (result, error) {
+	checkEnv := len(paths) == 0
+	if checkEnv {
+		var err error
+		if paths, err = configPaths(c.workDir, c.configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs config.ValidationErrors
+	var checked []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) && checkEnv {
+			continue
+		}
+		checked = append(checked, path)
+
+		var fileErrs config.ValidationErrors
+		if _, err := config.Load(path); errors.As(err, &fileErrs) {
+			errs = append(errs, fileErrs...)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if checkEnv {
+		var envErrs config.ValidationErrors
+		if err := config.Default().ApplyEnv(os.LookupEnv); errors.As(err, &envErrs) {
+			errs = append(errs, envErrs...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	lines := []string{"設定は有効です"}
+	for _, path := range checked {
+		lines = append(lines, "  "+path)
+	}
+	return messageResult{
+		kindName: "config_valid",
+		payload: struct {
+			Files []string `json:"files"`
+		}{Files: append([]string{}, checked...)},
+		lines: lines,
+	}, nil
+}
+
+// configValueJSON は設定項目のJSON表現
+type configValueJSON struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type"`
+	Env         string `json:"env"`
+	Description string `json:"description"`
+}
+
+// configValuesResult は config get の結果
+type configValuesResult struct {
+	cfg    *config.Config
+	fields []config.Field
+	single bool // キーを指定して取得したか
+}
+
+func (r configValuesResult) kind() string { return "config_values" }
+
+func (r configValuesResult) data() any {
+	values := make([]configValueJSON, 0, len(r.fields))
+	for _, f := range r.fields {
+		value, _ := r.cfg.Get(f.Key)
+		values = append(values, configValueJSON{
+			Key:         f.Key,
+			Value:       value,
+			Type:        f.Kind.String(),
+			Env:         f.EnvName(),
+			Description: f.Description,
+		})
+	}
+	return struct {
+		Values []configValueJSON `json:"values"`
+	}{Values: values}
+}
+
+func (r configValuesResult) writeText(w io.Writer) {
+	for _, f := range r.fields {
+		value, _ := r.cfg.Get(f.Key)
+		if r.single {
+			fmt.Fprintln(w, value)
+			continue
+		}
+		fmt.Fprintf(w, "%s = %s\n", f.Key, value)
+	}
+}
+
+func (r configValuesResult) writeTable(w io.Writer) {
+	rows := make([][]string, 0, len(r.fields))
+	for _, f := range r.fields {
+		value, _ := r.cfg.Get(f.Key)
+		rows = append(rows, []string{f.Key, value, f.EnvName(), f.Description})
+	}
+	printTable(w, []string{"キー", "値", "環境変数", "説明"}, rows)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigCommand_Get(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "1項目", args: []string{"config", "get", "ui.max_output_lines"}, want: "1000\n"},
+		{name: "フラグの上書き", args: []string{"config", "get", "diff.refresh_interval", "--set", "diff.refresh_interval=1m"}, want: "1m0s\n"},
+		{name: "全項目", args: []string{"config", "get"}, want: "ui.max_output_lines = 1000\nui.theme = auto\nui.auto_resume = false\ndiff.refresh_interval = 2s\n"},
+		{name: "表形式", args: []string{"config", "get", "-o", "table"}, want: "ui.max_output_lines          1000   CCFORGE_UI_MAX_OUTPUT_LINES"},
+		{name: "不明なキー", args: []string{"config", "get", "ui.colour"}, wantErr: "不明なキーです"},
+		{name: "操作なし", args: []string{"config"}, wantErr: "get, set, validate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, out, _ := newTestCLI(t)
+
+			err := c.run(tt.args)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Equal(t, exitUsage, exitCode(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, out.String(), tt.want)
+		})
+	}
+}
+
+func TestConfigCommand_Set(t *testing.T) {
+	c, out, _ := newTestCLI(t)
+
+	require.NoError(t, c.run([]string{"config", "set", "ui.max_output_lines", "300"}))
+	projectPath := config.ProjectPath(c.workDir)
+	assert.Contains(t, out.String(), "ui.max_output_lines を 300 に設定しました: "+projectPath)
+
+	content, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[ui]\nmax_output_lines = 300\n", string(content))
+
+	// --global はグローバル設定に書き込む
+	require.NoError(t, c.run([]string{"config", "set", "--global", "log.verbose", "true"}))
+	globalPath, err := config.GlobalPath()
+	require.NoError(t, err)
+	assert.FileExists(t, globalPath)
+
+	// 書き込んだ値は次の実行から有効になる
+	out.Reset()
+	require.NoError(t, c.run([]string{"config", "get", "ui.max_output_lines"}))
+	assert.Equal(t, "300\n", out.String())
+
+	err = c.run([]string{"config", "set", "ui.max_output_lines", "many"})
+	assert.ErrorContains(t, err, "整数で指定してください")
+	assert.Equal(t, exitError, exitCode(err))
+
+	err = c.run([]string{"config", "set", "ui.colour", "red"})
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestConfigCommand_Validate(t *testing.T) {
+	c, out, _ := newTestCLI(t)
+
+	require.NoError(t, c.run([]string{"config", "validate"}))
+	assert.Equal(t, "設定は有効です\n", out.String())
+
+	projectPath := config.ProjectPath(c.workDir)
+	require.NoError(t, os.MkdirAll(filepath.Dir(projectPath), 0o755))
+	require.NoError(t, os.WriteFile(projectPath, []byte("[ui]\nmax_output_lines = \"many\"\n\n[diff]\nrefresh = \"1s\"\n"), 0o644))
+	t.Setenv("CCFORGE_LOG_VERBOSE", "maybe")
+
+	// 不正な設定でもvalidateは実行でき、すべての誤りを行番号付きで報告する
+	err := c.run([]string{"config", "validate"})
+	assert.Equal(t, exitError, exitCode(err))
+	assert.Equal(t, strings.Join([]string{
+		projectPath + ":5: diff.refresh: 不明なキーです",
+		projectPath + ":2: ui.max_output_lines: 整数で指定してください",
+		"環境変数 CCFORGE_LOG_VERBOSE: log.verbose: 真偽値で指定してください: \"maybe\"",
+	}, "\n"), err.Error())
+
+	// 不正な設定ではvalidate以外は実行しない
+	assert.ErrorContains(t, c.run([]string{"config", "get"}), "不明なキーです")
+	assert.ErrorContains(t, c.run([]string{"list"}), "不明なキーです")
+
+	// ファイルを指定した場合はそのファイルのみを検証する
+	valid := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(valid, []byte("[ui]\nmax_output_lines = 5\n"), 0o644))
+	out.Reset()
+	require.NoError(t, c.run([]string{"config", "validate", valid}))
+	assert.Equal(t, "設定は有効です\n  "+valid+"\n", out.String())
+
+	// JSON形式ではconfig_invalidとして報告する
+	err = c.run([]string{"config", "validate", projectPath, "-o", "json"})
+	assert.Equal(t, exitError, exitCode(err))
+	assert.Contains(t, c.stderr.(*bytes.Buffer).String(), `"code": "config_invalid"`)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/export"
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+)
+
+// exportDirName はタスクのディレクトリ内でエクスポートを保存するディレクトリ名
+const exportDirName = "exports"
+
+// exportCommand は export サブコマンドを定義する
+func exportCommand(fs *flag.FlagSet) runFunc {
+	var formatName, sessionID, out string
+	var save, noRedact bool
+	fs.StringVar(&formatName, "f", string(export.Markdown), "形式 (md, html, jsonl)")
+	fs.StringVar(&formatName, "format", string(export.Markdown), "形式 (md, html, jsonl)")
+	fs.StringVar(&sessionID, "s", "", "書き出すセッションのID (省略時は最新のセッション)")
+	fs.StringVar(&sessionID, "session", "", "書き出すセッションのID (省略時は最新のセッション)")
+	fs.StringVar(&out, "out", "", "書き出すファイル (省略時は標準出力)")
+	fs.BoolVar(&save, "save", false, "タスクのディレクトリの exports/ に成果物として保存する")
+	fs.BoolVar(&noRedact, "no-redact", false, "機密情報らしき文字列を伏せずに書き出す")
+
+	return func(c *cli, args []string) (result, error) {
+		if err := requireArgs("export", args, "<タスク名>"); err != nil {
+			return nil, err
+		}
+		format, err := export.ParseFormat(formatName)
+		if err != nil {
+			return nil, newUsageError("export", "%v", err)
+		}
+		if save && out != "" {
+			return nil, newUsageError("export", "--save と --out は同時に指定できません")
+		}
+
+		ctx := context.Background()
+		st, err := c.openSearchStore(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer st.Close()
+
+		transcript, err := loadExportTranscript(ctx, st, args[0], sessionID)
+		if err != nil {
+			return nil, err
+		}
+		opts := export.Options{Redact: !noRedact}
+
+		if save {
+			task, err := c.manager().Get(args[0])
+			if err != nil {
+				return nil, err
+			}
+			out = filepath.Join(task.Dir, exportDirName, export.FileName(transcript, format, time.Now()))
+		}
+		if out == "" {
+			_, err := export.Write(c.stdout, transcript, format, opts)
+			return nil, err
+		}
+
+		redacted, err := writeExportFile(out, transcript, format, opts)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(c.stdout, "セッション %s のトランスクリプトを保存しました: %s\n", transcript.SessionID, out)
+		if redacted > 0 {
+			fmt.Fprintf(c.stdout, "機密情報らしき文字列を%d件伏せました\n", redacted)
+		}
+		return nil, nil
+	}
+}
+
+// exportFunc はTUIからアクティブなタスクのトランスクリプトを保存するための関数を返す
+// セッションIDが空の場合は最新のセッションを、保存先はタスクのディレクトリの exports/ を使う
+func (c *cli) exportFunc() func(taskName, sessionID string, format export.Format) (string, error) {
+	return func(taskName, sessionID string, format export.Format) (string, error) {
+		ctx := context.Background()
+		st, err := c.openSearchStore(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer st.Close()
+
+		transcript, err := loadExportTranscript(ctx, st, taskName, sessionID)
+		if err != nil {
+			return "", err
+		}
+		task, err := c.manager().Get(taskName)
+		if err != nil {
+			return "", err
+		}
+
+		path := filepath.Join(task.Dir, exportDirName, export.FileName(transcript, format, time.Now()))
+		if _, err := writeExportFile(path, transcript, format, export.Options{Redact: true}); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+}
+
+// loadExportTranscript はタスクのセッションのトランスクリプトを読み込む
+// セッションIDが空の場合はやり取りのある最新のセッションを使う
+func loadExportTranscript(ctx context.Context, st *store.Store, taskName, sessionID string) (export.Transcript, error) {
+	task, err := st.TaskByName(ctx, taskName)
+	if errors.Is(err, store.ErrNotFound) {
+		return export.Transcript{}, fmt.Errorf("%w: %s", tasks.ErrTaskNotFound, taskName)
+	}
+	if err != nil {
+		return export.Transcript{}, err
+	}
+
+	if sessionID != "" {
+		session, err := st.Session(ctx, sessionID)
+		if err != nil {
+			return export.Transcript{}, err
+		}
+		if session.TaskID != task.ID {
+			return export.Transcript{}, fmt.Errorf("セッション %s はタスク %s のセッションではありません", sessionID, taskName)
+		}
+		prompts, err := st.SessionTranscript(ctx, sessionID)
+		if err != nil {
+			return export.Transcript{}, err
+		}
+		return export.Transcript{Task: task.Name, SessionID: sessionID, Prompts: prompts}, nil
+	}
+
+	sessions, err := st.Sessions(ctx, task.ID)
+	if err != nil {
+		return export.Transcript{}, err
+	}
+	for i := len(sessions) - 1; i >= 0; i-- {
+		prompts, err := st.SessionTranscript(ctx, sessions[i].ID)
+		if err != nil {
+			return export.Transcript{}, err
+		}
+		if len(prompts) > 0 {
+			return export.Transcript{Task: task.Name, SessionID: sessions[i].ID, Prompts: prompts}, nil
+		}
+	}
+	return export.Transcript{}, fmt.Errorf("タスク %s に書き出せるトランスクリプトがありません (run --save-history でやり取りを残してください)", taskName)
+}
+
+// writeExportFile はトランスクリプトをファイルに書き出し、伏せた文字列の数を返す
+func writeExportFile(path string, t export.Transcript, format export.Format, opts export.Options) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("エクスポート先のディレクトリの作成に失敗しました: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("エクスポート先のファイルの作成に失敗しました: %w", err)
+	}
+
+	redacted, err := export.Write(f, t, format, opts)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("エクスポート先のファイルの保存に失敗しました: %w", closeErr)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	slog.Info("トランスクリプトを書き出しました", "component", "cli", "task", t.Task, "session", t.SessionID, "format", format, "path", path, "redacted", redacted)
+	return redacted, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/export"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appendSecretHistory はauthタスクに機密情報を含む新しいセッションのやり取りを追記する
+func appendSecretHistory(t *testing.T, c *cli) {
+	t.Helper()
+	require.NoError(t, tasks.NewManager(c.workDir).AppendHistory("auth", tasks.HistoryEntry{
+		Time:      time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		SessionID: "s2",
+		Prompt:    "DB_PASSWORD=hunter2hunter で接続して",
+		Output:    "\x1b[32m接続しました\x1b[0m\n",
+	}))
+}
+
+func TestCLI_Export(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		want   []string
+		unwant []string
+	}{
+		{
+			name:   "最新のセッションをMarkdownで書き出す",
+			args:   []string{"export", "auth"},
+			want:   []string{"# auth セッション s2", "> DB_PASSWORD=[REDACTED] で接続して", "```text\n接続しました\n```"},
+			unwant: []string{"hunter2hunter", "\x1b["},
+		},
+		{
+			name: "セッションを指定する",
+			args: []string{"export", "auth", "--session", "s1"},
+			want: []string{"# auth セッション s1", "> JWTの検証を実装して", "> テストも追加して"},
+		},
+		{
+			name: "HTMLはANSIカラーを保つ",
+			args: []string{"export", "auth", "-f", "html"},
+			want: []string{"<!DOCTYPE html>", `<span style="color:#0dbc79">接続しました</span>`},
+		},
+		{
+			name: "JSONL",
+			args: []string{"export", "auth", "--format", "jsonl"},
+			want: []string{`"role":"user"`, `"role":"assistant"`, `"session_id":"s2"`, `"time":"2026-03-02T09:00:00Z"`},
+		},
+		{
+			name: "伏せずに書き出す",
+			args: []string{"export", "auth", "--no-redact"},
+			want: []string{"DB_PASSWORD=hunter2hunter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, out := newSearchTestCLI(t)
+			appendSecretHistory(t, c)
+
+			require.NoError(t, c.run(tt.args))
+			for _, want := range tt.want {
+				assert.Contains(t, out.String(), want)
+			}
+			for _, unwant := range tt.unwant {
+				assert.NotContains(t, out.String(), unwant)
+			}
+		})
+	}
+}
+
+func TestCLI_ExportSave(t *testing.T) {
+	c, out := newSearchTestCLI(t)
+	appendSecretHistory(t, c)
+
+	require.NoError(t, c.run([]string{"export", "auth", "--save", "-f", "html"}))
+	assert.Contains(t, out.String(), "機密情報らしき文字列を1件伏せました")
+
+	// タスクのディレクトリの exports/ に保存する
+	paths, err := filepath.Glob(filepath.Join(c.workDir, "ccforge", "auth", exportDirName, "*-s2.html"))
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	assert.Contains(t, out.String(), "セッション s2 のトランスクリプトを保存しました: "+paths[0])
+
+	data, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "DB_PASSWORD=[REDACTED]")
+}
+
+func TestCLI_ExportOut(t *testing.T) {
+	c, out := newSearchTestCLI(t)
+	path := filepath.Join(t.TempDir(), "review", "transcript.md")
+
+	require.NoError(t, c.run([]string{"export", "billing", "--out", path}))
+	assert.Contains(t, out.String(), "のトランスクリプトを保存しました: "+path)
+	assert.NotContains(t, out.String(), "伏せました")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "# billing セッション "))
+	assert.Contains(t, string(data), "> 請求書のPDFを出力して")
+}
+
+func TestCLI_ExportErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+		usage   bool
+	}{
+		{name: "タスク名なし", args: []string{"export"}, wantErr: "<タスク名> を指定してください", usage: true},
+		{name: "不明な形式", args: []string{"export", "auth", "-f", "pdf"}, wantErr: "不明なエクスポート形式です: pdf", usage: true},
+		{name: "保存先の重複", args: []string{"export", "auth", "--save", "--out", "x.md"}, wantErr: "--save と --out は同時に指定できません", usage: true},
+		{name: "存在しないタスク", args: []string{"export", "unknown"}, wantErr: "unknown"},
+		{name: "存在しないセッション", args: []string{"export", "auth", "--session", "missing"}, wantErr: "missing"},
+		{name: "別のタスクのセッション", args: []string{"export", "billing", "--session", "s1"}, wantErr: "セッション s1 はタスク billing のセッションではありません"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newSearchTestCLI(t)
+			err := c.run(tt.args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+
+			var usageErr *usageError
+			assert.Equal(t, tt.usage, errors.As(err, &usageErr))
+		})
+	}
+
+	t.Run("やり取りのないタスク", func(t *testing.T) {
+		c, _ := newSearchTestCLI(t)
+		_, err := tasks.NewManager(c.workDir).Create("empty", "")
+		require.NoError(t, err)
+		err = c.run([]string{"export", "empty"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "タスク empty に書き出せるトランスクリプトがありません")
+	})
+}
+
+func TestCLI_ExportFunc(t *testing.T) {
+	c, _ := newSearchTestCLI(t)
+	require.NoError(t, c.run([]string{"list"}))
+
+	path, err := c.exportFunc()("auth", "", export.JSONL)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(c.workDir, "ccforge", "auth", exportDirName), filepath.Dir(path))
+	assert.True(t, strings.HasSuffix(path, "-s1.jsonl"))
+	assert.FileExists(t, path)
+}
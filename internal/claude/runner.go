@@ -0,0 +1,120 @@
+package claude
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBin はClaude Code CLIの既定の実行ファイル名
+	DefaultBin = "claude"
+	// BinEnv は実行ファイルを上書きする環境変数名
+	BinEnv = "CCFORGE_CLAUDE_BIN"
+)
+
+// ErrNotInstalled はClaude Code CLIが見つからない場合のエラー
+var ErrNotInstalled = errors.New("Claude Code CLIが見つかりません")
+
+// BinFromEnv は環境変数を考慮したClaude Code CLIの実行ファイルを返す
+func BinFromEnv() string {
+	if bin := os.Getenv(BinEnv); bin != "" {
+		return bin
+	}
+	return DefaultBin
+}
+
+// Runner はClaude Code CLIを非対話モード (-p) で実行する構造体
+type Runner struct {
+	Bin  string   // 実行ファイル
+	Args []string // 実行ファイルに常に渡す引数
+	Dir  string   // 作業ディレクトリ
+}
+
+// Request は1回の実行で送信する内容
+type Request struct {
+	Prompt    string   // 送信するプロンプト
+	SessionID string   // セッションID (空の場合はセッションを指定しない)
+	Resume    bool     // 既存のセッションを再開するか
+	ExtraArgs []string // Claude Code CLIへそのまま渡す追加の引数
+}
+
+// args はClaude Code CLIへ渡す引数を組み立てる
+func (r *Runner) args(req Request) []string {
+	args := append([]string{}, r.Args...)
+	args = append(args, "-p")
+
+	switch {
+	case req.SessionID == "":
+	case req.Resume:
+		args = append(args, "--resume", req.SessionID)
+	default:
+		args = append(args, "--session-id", req.SessionID)
+	}
+
+	return append(args, req.ExtraArgs...)
+}
+
+// Run はプロンプトを送信し、出力をstdout・stderrへ逐次書き出す
+// プロセスが0以外で終了した場合は *ExitError を返す
+func (r *Runner) Run(ctx context.Context, req Request, stdout, stderr io.Writer) error {
+	bin := r.Bin
+	if bin == "" {
+		bin = DefaultBin
+	}
+
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return fmt.Errorf("%w: %s (%s で実行ファイルを指定できます)", ErrNotInstalled, bin, BinEnv)
+	}
+
+	cmd := exec.CommandContext(ctx, path, r.args(req)...)
+	cmd.Dir = r.Dir
+	// プロンプトは標準入力から渡してコマンドライン長の制限を避ける
+	cmd.Stdin = strings.NewReader(req.Prompt)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	slog.Info("Claude Code CLIを実行します", "component", "claude", "bin", path, "session_id", req.SessionID, "resume", req.Resume)
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			slog.Warn("Claude Code CLIが異常終了しました", "component", "claude", "code", exitErr.ExitCode(), "duration", time.Since(start))
+			return &ExitError{Code: exitErr.ExitCode()}
+		}
+		slog.Error("Claude Code CLIの実行に失敗しました", "component", "claude", "err", err)
+		return fmt.Errorf("Claude Code CLIの実行に失敗しました: %w", err)
+	}
+	slog.Info("Claude Code CLIが終了しました", "component", "claude", "duration", time.Since(start))
+	return nil
+}
+
+// ExitError はClaude Code CLIが0以外で終了したことを表すエラー
+type ExitError struct {
+	Code int // 終了コード (シグナルで終了した場合は-1)
+}
+
+// Error はエラーメッセージを返す
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("Claude Code CLIが終了コード %d で終了しました", e.Code)
+}
+
+// NewSessionID はセッションIDとして使うUUID (v4) を生成する
+func NewSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("セッションIDの生成に失敗しました: %w", err)
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,109 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess はClaude Code CLIの代わりに起動される偽のプロセス
+// 受け取った引数と標準入力を出力し、FAKE_CLAUDE_EXITの終了コードで終了する
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	stdin, _ := io.ReadAll(os.Stdin)
+	fmt.Printf("args=%s\n", strings.Join(args, " "))
+	fmt.Printf("stdin=%s\n", stdin)
+	fmt.Fprintln(os.Stderr, "warning")
+
+	code, _ := strconv.Atoi(os.Getenv("FAKE_CLAUDE_EXIT"))
+	os.Exit(code)
+}
+
+// fakeRunner は偽のプロセスを起動するRunnerを返す
+func fakeRunner(t *testing.T, exitCode int) *Runner {
+	t.Helper()
+	t.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	t.Setenv("FAKE_CLAUDE_EXIT", strconv.Itoa(exitCode))
+
+	return &Runner{Bin: os.Args[0], Args: []string{"-test.run=TestHelperProcess", "--"}, Dir: t.TempDir()}
+}
+
+func TestRunner_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      Request
+		wantArgs string
+	}{
+		{name: "セッション指定なし", req: Request{Prompt: "hello"}, wantArgs: "-p"},
+		{name: "新規セッション", req: Request{Prompt: "hello", SessionID: "abc"}, wantArgs: "-p --session-id abc"},
+		{
+			name:     "セッション再開と追加引数",
+			req:      Request{Prompt: "hello", SessionID: "abc", Resume: true, ExtraArgs: []string{"--model", "sonnet"}},
+			wantArgs: "-p --resume abc --model sonnet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := fakeRunner(t, 0)
+
+			var stdout, stderr bytes.Buffer
+			require.NoError(t, r.Run(context.Background(), tt.req, &stdout, &stderr))
+			assert.Equal(t, "args="+tt.wantArgs+"\nstdin=hello\n", stdout.String())
+			assert.Equal(t, "warning\n", stderr.String())
+		})
+	}
+}
+
+func TestRunner_Run_ExitCode(t *testing.T) {
+	r := fakeRunner(t, 3)
+
+	err := r.Run(context.Background(), Request{Prompt: "x"}, io.Discard, io.Discard)
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.Code)
+}
+
+func TestRunner_Run_NotInstalled(t *testing.T) {
+	r := &Runner{Bin: "ccforge-no-such-claude"}
+
+	err := r.Run(context.Background(), Request{Prompt: "x"}, io.Discard, io.Discard)
+	assert.ErrorIs(t, err, ErrNotInstalled)
+}
+
+func TestBinFromEnv(t *testing.T) {
+	t.Setenv(BinEnv, "")
+	assert.Equal(t, DefaultBin, BinFromEnv())
+
+	t.Setenv(BinEnv, "/opt/claude")
+	assert.Equal(t, "/opt/claude", BinFromEnv())
+}
+
+func TestNewSessionID(t *testing.T) {
+	id, err := NewSessionID()
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+
+	other, err := NewSessionID()
+	require.NoError(t, err)
+	assert.NotEqual(t, id, other)
+}
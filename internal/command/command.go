@@ -0,0 +1,311 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	// ErrNotCommand は入力がスラッシュコマンドでない場合のエラー
+	ErrNotCommand = errors.New("スラッシュコマンドではありません")
+	// ErrUnknownCommand はccforgeに登録されていないコマンドの場合のエラー
+	// 呼び出し側はClaude Codeへそのまま転送する
+	ErrUnknownCommand = errors.New("登録されていないコマンドです")
+)
+
+// Handler はコマンドの処理を行い、非同期に実行するtea.Cmdを返す
+type Handler func(args Args) tea.Cmd
+
+// ArgSpec はコマンド引数の定義
+type ArgSpec struct {
+	Name     string   // 引数名
+	Help     string   // 説明
+	Required bool     // 必須かどうか
+	Variadic bool     // 残りの引数をすべて受け取るか (最後の引数のみ)
+	Choices  []string // 指定可能な値 (空の場合は任意)
+}
+
+// Command はスラッシュコマンドの定義
+type Command struct {
+	Name    string    // コマンド名 (空白区切りでサブコマンドを表す 例: "task new")
+	Aliases []string  // 別名
+	Args    []ArgSpec // 引数の定義
+	Help    string    // 説明
+	Handler Handler   // 処理
+}
+
+// Usage は使用法を表す文字列を返す
+func (c *Command) Usage() string {
+	parts := []string{"/" + c.Name}
+	for _, a := range c.Args {
+		name := a.Name
+		if a.Variadic {
+			name += "..."
+		}
+		if a.Required {
+			parts = append(parts, "<"+name+">")
+		} else {
+			parts = append(parts, "["+name+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Args はコマンドに渡された引数
+type Args struct {
+	values map[string][]string // 引数名ごとの値
+	raw    []string            // コマンド名を除いた全トークン
+}
+
+// Get は引数の値を返す (可変長引数は空白で連結する)
+func (a Args) Get(name string) string {
+	return strings.Join(a.values[name], " ")
+}
+
+// Values は引数の値を一覧で返す
+func (a Args) Values(name string) []string {
+	return a.values[name]
+}
+
+// Raw はコマンド名を除いた全トークンを返す
+func (a Args) Raw() []string {
+	return a.raw
+}
+
+// NewArgs は引数名と値の対応からArgsを作成する
+func NewArgs(values map[string][]string) Args {
+	return Args{values: values}
+}
+
+// ArgError は引数の誤りを表すエラー
+type ArgError struct {
+	Command *Command // 対象コマンド (不明な場合はnil)
+	Message string   // エラー内容
+}
+
+// Error はエラーメッセージを返す
+func (e *ArgError) Error() string {
+	if e.Command == nil {
+		return e.Message
+	}
+	return fmt.Sprintf("/%s: %s", e.Command.Name, e.Message)
+}
+
+// Invocation は解析済みのコマンド呼び出し
+type Invocation struct {
+	Command *Command // 呼び出されたコマンド
+	Args    Args     // 引数
+}
+
+// Run はコマンドのハンドラーを実行する
+func (i *Invocation) Run() tea.Cmd {
+	return i.Command.Handler(i.Args)
+}
+
+// Registry はスラッシュコマンドを管理する
+type Registry struct {
+	commands []*Command          // 登録順のコマンド
+	names    map[string]*Command // 名前と別名からの索引
+}
+
+// NewRegistry は新しいRegistryを作成する
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string]*Command),
+	}
+}
+
+// Register はコマンドを登録する
+func (r *Registry) Register(cmd Command) error {
+	cmd.Name = normalizeName(cmd.Name)
+	if cmd.Name == "" {
+		return fmt.Errorf("コマンド名が空です")
+	}
+	if cmd.Handler == nil {
+		return fmt.Errorf("コマンド %q にハンドラーがありません", cmd.Name)
+	}
+	if err := validateArgSpecs(cmd.Args); err != nil {
+		return fmt.Errorf("コマンド %q: %w", cmd.Name, err)
+	}
+
+	names := []string{cmd.Name}
+	for _, alias := range cmd.Aliases {
+		names = append(names, normalizeName(alias))
+	}
+	for _, name := range names {
+		if _, exists := r.names[name]; exists {
+			return fmt.Errorf("コマンド名 %q は既に登録されています", name)
+		}
+	}
+
+	registered := &cmd
+	for _, name := range names {
+		r.names[name] = registered
+	}
+	r.commands = append(r.commands, registered)
+	return nil
+}
+
+// Commands は名前順に登録済みコマンドを返す
+func (r *Registry) Commands() []*Command {
+	commands := append([]*Command(nil), r.commands...)
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+// Lookup は名前または別名からコマンドを取得する
+func (r *Registry) Lookup(name string) (*Command, bool) {
+	cmd, ok := r.names[normalizeName(name)]
+	return cmd, ok
+}
+
+// Parse は入力行を解析してコマンド呼び出しを返す
+// "/"で始まらない場合はErrNotCommand、登録されていない場合はErrUnknownCommandを返す
+func (r *Registry) Parse(line string) (*Invocation, error) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "/") {
+		return nil, ErrNotCommand
+	}
+
+	// 未登録のコマンドはClaudeへそのまま渡すため、コマンド名が決まるまで引数は解析しない
+	fields := strings.Fields(trimmed[1:])
+	if len(fields) == 0 {
+		return nil, ErrUnknownCommand
+	}
+
+	// 最も長く一致するコマンド名を探す ("task new" は "task" より優先)
+	for n := len(fields); n > 0; n-- {
+		cmd, ok := r.names[strings.Join(fields[:n], " ")]
+		if !ok {
+			continue
+		}
+		tokens, err := Tokenize(cutFields(trimmed[1:], n))
+		if err != nil {
+			return nil, &ArgError{Message: err.Error()}
+		}
+		args, err := bindArgs(cmd, tokens)
+		if err != nil {
+			return nil, err
+		}
+		return &Invocation{Command: cmd, Args: args}, nil
+	}
+
+	// サブコマンドのみが登録されたグループ名の場合は候補を示す
+	if subs := r.subcommands(fields[0]); len(subs) > 0 {
+		return nil, &ArgError{
+			Message: fmt.Sprintf("/%s: サブコマンドを指定してください (%s)", fields[0], strings.Join(subs, ", ")),
+		}
+	}
+
+	return nil, ErrUnknownCommand
+}
+
+// cutFields は先頭から空白区切りのn個のフィールドを取り除いた残りを返す
+func cutFields(s string, n int) string {
+	for i := 0; i < n; i++ {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if end := strings.IndexFunc(s, unicode.IsSpace); end >= 0 {
+			s = s[end:]
+		} else {
+			s = ""
+		}
+	}
+	return s
+}
+
+// subcommands はグループ名に属するサブコマンド名を返す
+func (r *Registry) subcommands(group string) []string {
+	var subs []string
+	prefix := group + " "
+	for _, cmd := range r.commands {
+		if strings.HasPrefix(cmd.Name, prefix) {
+			subs = append(subs, strings.TrimPrefix(cmd.Name, prefix))
+		}
+	}
+	sort.Strings(subs)
+	return subs
+}
+
+// bindArgs はトークンを引数定義に割り当てる
+func bindArgs(cmd *Command, tokens []string) (Args, error) {
+	args := Args{values: make(map[string][]string), raw: tokens}
+
+	i := 0
+	for _, spec := range cmd.Args {
+		if i >= len(tokens) {
+			if spec.Required {
+				return args, &ArgError{Command: cmd, Message: fmt.Sprintf("引数 %s が必要です", spec.Name)}
+			}
+			continue
+		}
+
+		values := tokens[i : i+1]
+		if spec.Variadic {
+			values = tokens[i:]
+		}
+		for _, v := range values {
+			if len(spec.Choices) > 0 && !contains(spec.Choices, v) {
+				return args, &ArgError{
+					Command: cmd,
+					Message: fmt.Sprintf("引数 %s の値 %q は不正です (%s のいずれか)", spec.Name, v, strings.Join(spec.Choices, ", ")),
+				}
+			}
+		}
+		args.values[spec.Name] = values
+		i += len(values)
+	}
+
+	if i < len(tokens) {
+		return args, &ArgError{Command: cmd, Message: fmt.Sprintf("余分な引数があります: %s", strings.Join(tokens[i:], " "))}
+	}
+
+	return args, nil
+}
+
+// validateArgSpecs は引数定義の整合性を検証する
+func validateArgSpecs(specs []ArgSpec) error {
+	seen := make(map[string]bool, len(specs))
+	optional := false
+	for i, spec := range specs {
+		if spec.Name == "" {
+			return fmt.Errorf("%d番目の引数名が空です", i+1)
+		}
+		if seen[spec.Name] {
+			return fmt.Errorf("引数名 %q が重複しています", spec.Name)
+		}
+		seen[spec.Name] = true
+
+		if spec.Variadic && i != len(specs)-1 {
+			return fmt.Errorf("可変長引数 %q は最後に定義してください", spec.Name)
+		}
+		if spec.Required && optional {
+			return fmt.Errorf("必須引数 %q は省略可能な引数より前に定義してください", spec.Name)
+		}
+		if !spec.Required {
+			optional = true
+		}
+	}
+	return nil
+}
+
+// normalizeName はコマンド名の余分な空白と先頭のスラッシュを取り除く
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(name), "/")), " ")
+}
+
+// contains はスライスに値が含まれるかを判定する
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
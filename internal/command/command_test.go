@@ -0,0 +1,184 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noop はテスト用の何もしないハンドラー
+func noop(Args) tea.Cmd { return nil }
+
+// newTestRegistry はテスト用のコマンドを登録したRegistryを作成する
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+
+	r := NewRegistry()
+	require.NoError(t, r.Register(Command{
+		Name:    "task new",
+		Aliases: []string{"tn"},
+		Args: []ArgSpec{
+			{Name: "name", Required: true},
+			{Name: "description", Variadic: true},
+		},
+		Help:    "新しいタスクを作成する",
+		Handler: noop,
+	}))
+	require.NoError(t, r.Register(Command{Name: "task list", Handler: noop}))
+	require.NoError(t, r.Register(Command{
+		Name:    "diff",
+		Args:    []ArgSpec{{Name: "mode", Choices: []string{"unified", "split"}}},
+		Handler: noop,
+	}))
+	return r
+}
+
+func TestRegistry_Register(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     Command
+		wantErr bool
+	}{
+		{name: "正常系_登録", cmd: Command{Name: "specs", Handler: noop}},
+		{name: "正常系_スラッシュ付きの名前", cmd: Command{Name: "/specs", Handler: noop}},
+		{name: "異常系_名前が重複", cmd: Command{Name: "diff", Handler: noop}, wantErr: true},
+		{name: "異常系_別名が重複", cmd: Command{Name: "x", Aliases: []string{"tn"}, Handler: noop}, wantErr: true},
+		{name: "異常系_名前が空", cmd: Command{Name: " ", Handler: noop}, wantErr: true},
+		{name: "異常系_ハンドラーなし", cmd: Command{Name: "y"}, wantErr: true},
+		{
+			name: "異常系_可変長引数が最後でない",
+			cmd: Command{Name: "z", Handler: noop, Args: []ArgSpec{
+				{Name: "a", Variadic: true}, {Name: "b"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "異常系_必須引数が省略可能な引数の後",
+			cmd: Command{Name: "z", Handler: noop, Args: []ArgSpec{
+				{Name: "a"}, {Name: "b", Required: true},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "異常系_引数名の重複",
+			cmd: Command{Name: "z", Handler: noop, Args: []ArgSpec{
+				{Name: "a"}, {Name: "a"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t)
+			err := r.Register(tt.cmd)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			_, ok := r.Lookup("specs")
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestRegistry_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantCmd    string
+		wantArgs   map[string]string
+		wantErr    error
+		wantArgErr string
+	}{
+		{
+			name:     "正常系_サブコマンドと引数",
+			line:     "/task new login-page ログイン 画面",
+			wantCmd:  "task new",
+			wantArgs: map[string]string{"name": "login-page", "description": "ログイン 画面"},
+		},
+		{
+			name:     "正常系_別名とクォート",
+			line:     `/tn "my task"`,
+			wantCmd:  "task new",
+			wantArgs: map[string]string{"name": "my task", "description": ""},
+		},
+		{
+			name:     "正常系_選択肢",
+			line:     "/diff split",
+			wantCmd:  "diff",
+			wantArgs: map[string]string{"mode": "split"},
+		},
+		{name: "パススルー_通常の入力", line: "hello", wantErr: ErrNotCommand},
+		{name: "パススルー_未登録コマンド", line: "/model opus", wantErr: ErrUnknownCommand},
+		{name: "パススルー_スラッシュのみ", line: "/", wantErr: ErrUnknownCommand},
+		{name: "パススルー_未登録コマンドのアポストロフィ", line: "/review what's wrong", wantErr: ErrUnknownCommand},
+		{name: "パススルー_未登録コマンドの閉じていない引用符", line: `/explain "oops`, wantErr: ErrUnknownCommand},
+		{name: "異常系_必須引数なし", line: "/task new", wantArgErr: "/task new: 引数 name が必要です"},
+		{name: "異常系_余分な引数", line: "/task list extra", wantArgErr: "/task list: 余分な引数があります: extra"},
+		{name: "異常系_選択肢外", line: "/diff wide", wantArgErr: `/diff: 引数 mode の値 "wide" は不正です (unified, split のいずれか)`},
+		{name: "異常系_サブコマンドなし", line: "/task", wantArgErr: "/task: サブコマンドを指定してください (list, new)"},
+		{name: "異常系_引用符が閉じていない", line: `/task new "oops`, wantArgErr: "引用符 \" が閉じられていません"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t)
+			inv, err := r.Parse(tt.line)
+
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantArgErr != "":
+				var argErr *ArgError
+				require.True(t, errors.As(err, &argErr), "err = %v", err)
+				assert.Equal(t, tt.wantArgErr, argErr.Error())
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantCmd, inv.Command.Name)
+				for name, want := range tt.wantArgs {
+					assert.Equal(t, want, inv.Args.Get(name), name)
+				}
+			}
+		})
+	}
+}
+
+func TestCommand_Usage(t *testing.T) {
+	r := newTestRegistry(t)
+	cmd, ok := r.Lookup("task new")
+	require.True(t, ok)
+	assert.Equal(t, "/task new <name> [description...]", cmd.Usage())
+}
+
+func TestRegistry_Commands(t *testing.T) {
+	r := newTestRegistry(t)
+	var names []string
+	for _, c := range r.Commands() {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"diff", "task list", "task new"}, names)
+}
+
+func TestInvocation_Run(t *testing.T) {
+	r := NewRegistry()
+	var got string
+	require.NoError(t, r.Register(Command{
+		Name: "echo",
+		Args: []ArgSpec{{Name: "text", Variadic: true}},
+		Handler: func(args Args) tea.Cmd {
+			got = args.Get("text")
+			return nil
+		},
+	}))
+
+	inv, err := r.Parse("/echo a  b")
+	require.NoError(t, err)
+	inv.Run()
+	assert.Equal(t, "a b", got)
+	assert.Equal(t, []string{"a", "b"}, inv.Args.Raw())
+}
@@ -0,0 +1,59 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Tokenize は入力を空白区切りのトークンに分割する
+// シングルクォート・ダブルクォートで囲んだ部分は1つのトークンとして扱い、
+// ダブルクォート内とクォート外ではバックスラッシュによるエスケープが使える
+func Tokenize(input string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inToken := false
+	var quote rune
+	escaped := false
+
+	for _, r := range input {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inToken = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("引用符 %c が閉じられていません", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("末尾のバックスラッシュが不正です")
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,38 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "空文字列", input: "", want: nil},
+		{name: "空白区切り", input: "task  new\tfoo", want: []string{"task", "new", "foo"}},
+		{name: "ダブルクォート", input: `say "hello world"`, want: []string{"say", "hello world"}},
+		{name: "シングルクォート内のバックスラッシュ", input: `'a\b'`, want: []string{`a\b`}},
+		{name: "エスケープ", input: `a\ b "c\"d"`, want: []string{"a b", `c"d`}},
+		{name: "空のクォート", input: `x ""`, want: []string{"x", ""}},
+		{name: "マルチバイト", input: "タスク 作成", want: []string{"タスク", "作成"}},
+		{name: "閉じていない引用符", input: `"abc`, wantErr: true},
+		{name: "末尾のバックスラッシュ", input: `abc\`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Tokenize(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mzkmnk/ccforge/internal/keymap"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+const (
+	// FileName は設定ファイル名
+	FileName = "config.toml"
+	// ProjectDirName はプロジェクト内のccforgeディレクトリ名
+	ProjectDirName = "ccforge"
+)
+
+// macroNamePattern はマクロ名として使える文字列のパターン
+var macroNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// reservedVars はマクロの引数名に使えない予約済みのプレースホルダー名
+var reservedVars = map[string]bool{"task": true, "file": true, "branch": true, "args": true}
+
+// Config はccforgeの設定
+// 既定値、グローバル設定、プロジェクト設定、環境変数、フラグの順に重ねて作る
+type Config struct {
+	UI          UIConfig                 `toml:"ui"`          // 画面の設定
+	Diff        DiffConfig               `toml:"diff"`        // 差分パネルの設定
+	Watch       WatchConfig              `toml:"watch"`       // プロジェクトの変更の監視の設定
+	Git         GitConfig                `toml:"git"`         // gitリポジトリの状態の表示の設定
+	Checkpoint  CheckpointConfig         `toml:"checkpoint"`  // プロンプトごとのチェックポイントの設定
+	Claude      ClaudeConfig             `toml:"claude"`      // Claude Code CLIの設定
+	Log         LogConfig                `toml:"log"`         // ログの設定
+	Keybindings keymap.Bindings          `toml:"keybindings"` // キーバインド (既定の割り当てを操作単位で置き換える)
+	Themes      map[string]theme.Palette `toml:"themes"`      // ユーザー定義のテーマ
+	Commands    []MacroCommand           `toml:"commands"`    // ユーザー定義のマクロコマンド
+}
+
+// UIConfig は画面の設定
+type UIConfig struct {
+	MaxOutputLines int    // 出力を保持する最大行数 (0 = 無制限)
+	Theme          string // テーマ名 (auto = 端末の背景色で選ぶ)
+	AutoResume     bool   // タスクの開始時に前回の画面を復元するか
+}
+
+// DiffConfig は差分パネルの設定
+type DiffConfig struct {
+	RefreshInterval    time.Duration // 自動更新の間隔
+	SideBySideMinWidth int           // side-by-side表示に必要な最小幅
+	TabWidth           int           // タブを展開する幅
+}
+
+// WatchConfig はプロジェクトの変更の監視の設定
+type WatchConfig struct {
+	Ignore       []string      // .gitignore に加えて除外するパターン
+	MaxWatches   int           // fsnotifyで監視する最大ディレクトリ数 (超える場合はポーリング)
+	PollInterval time.Duration // ポーリングの間隔
+}
+
+// GitConfig はgitリポジトリの状態の表示の設定
+type GitConfig struct {
+	StatusInterval time.Duration // ステータスバーのgitの状態の更新間隔
+	WorktreeDir    string        // タスクのworktreeを作成するディレクトリ (空の場合は <プロジェクト>-worktrees)
+}
+
+// CheckpointConfig はプロンプトごとのチェックポイントの設定
+type CheckpointConfig struct {
+	Enabled bool // プロンプトの送信前に作業ツリーのチェックポイントを保存するか
+	Keep    int  // タスクごとに残すチェックポイントの数 (0で無制限)
+}
+
+// ClaudeConfig はClaude Code CLIの設定
+type ClaudeConfig struct {
+	Bin string // 実行ファイル (空の場合は claude)
+}
+
+// LogConfig はログの設定
+type LogConfig struct {
+	File    string // ログファイル (空の場合はキャッシュディレクトリ)
+	Verbose bool   // デバッグログも出力するか
+}
+
+// Default は既定値の設定を返す
+func Default() *Config {
+	return &Config{
+		UI:         UIConfig{MaxOutputLines: 1000, Theme: theme.Auto},
+		Diff:       DiffConfig{RefreshInterval: 2 * time.Second, SideBySideMinWidth: 120, TabWidth: 4},
+		Watch:      WatchConfig{MaxWatches: 8192, PollInterval: 2 * time.Second},
+		Git:        GitConfig{StatusInterval: 5 * time.Second},
+		Checkpoint: CheckpointConfig{Enabled: true, Keep: 50},
+	}
+}
+
+// MacroCommand はプロンプトや組み込み操作に展開されるユーザー定義コマンド
+type MacroCommand struct {
+	Name        string      `toml:"name"`        // コマンド名 (/name で呼び出す)
+	Description string      `toml:"description"` // 説明
+	Args        []string    `toml:"args"`        // 必須の位置引数の名前
+	Steps       []MacroStep `toml:"steps"`       // 順に実行する手順
+}
+
+// MacroStep はマクロの1手順
+// PromptとActionのどちらか一方を指定する
+type MacroStep struct {
+	Prompt string `toml:"prompt"` // Claude Codeへ送信するプロンプト
+	Action string `toml:"action"` // 実行する組み込み操作のID
+	Wait   bool   `toml:"wait"`   // Claudeの応答を待ってから次の手順へ進む
+}
+
+// GlobalPath はグローバル設定ファイルのパスを返す
+// XDG_CONFIG_HOMEが設定されていればそれを優先する
+func GlobalPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ccforge", FileName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+	}
+	return filepath.Join(home, ".config", "ccforge", FileName), nil
+}
+
+// ProjectPath はプロジェクト設定ファイルのパスを返す
+func ProjectPath(projectRoot string) string {
+	return filepath.Join(projectRoot, ProjectDirName, FileName)
+}
+
+// Load は既定値に設定ファイルを順に重ねて読み込む
+// 後のファイルほど優先され、同名のマクロは上書きされる
+// 存在しないファイルは無視する
+// 内容に誤りがある場合は行番号付きの ValidationErrors を返す
+func Load(paths ...string) (*Config, error) {
+	cfg := Default()
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			slog.Debug("設定ファイルがありません", "component", "config", "path", path)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("設定ファイル %s の読み込みに失敗しました: %w", path, err)
+		}
+
+		if errs := cfg.apply(path, data); len(errs) > 0 {
+			return nil, errs
+		}
+		slog.Debug("設定ファイルを読み込みました", "component", "config", "path", path)
+	}
+
+	return cfg, nil
+}
+
+// apply は設定ファイルの内容を検証して重ねる
+// 誤りがある場合は何も変更しない
+func (c *Config) apply(source string, data []byte) ValidationErrors {
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return ValidationErrors{parseError(source, err)}
+	}
+
+	var file struct {
+		Commands []MacroCommand `toml:"commands"`
+	}
+	md, err := toml.Decode(string(data), &file)
+	if err != nil {
+		return ValidationErrors{parseError(source, err)}
+	}
+
+	lines := keyLines(data)
+	next := *c
+	var errs ValidationErrors
+
+	bindings, bindingErrs := parseKeybindings(raw["keybindings"], lines)
+	for _, e := range bindingErrs {
+		e.Source = source
+		errs = append(errs, e)
+	}
+
+	themes, themeErrs := parseThemes(raw["themes"], lines)
+	for _, e := range themeErrs {
+		e.Source = source
+		errs = append(errs, e)
+	}
+
+	for _, name := range sortedKeys(raw) {
+		if name == "commands" || name == "keybindings" || name == "themes" {
+			continue
+		}
+		table, ok := raw[name].(map[string]any)
+		switch {
+		case !isSection(name):
+			errs = append(errs, &ValidationError{Source: source, Line: lines.find(name), Key: name, Message: "不明なキーです"})
+			continue
+		case !ok:
+			errs = append(errs, &ValidationError{Source: source, Line: lines.find(name), Key: name, Message: "テーブルで指定してください"})
+			continue
+		}
+		for _, key := range sortedKeys(table) {
+			full := name + "." + key
+			f, ok := LookupField(full)
+			if !ok {
+				errs = append(errs, &ValidationError{Source: source, Line: lines.find(full), Key: full, Message: "不明なキーです"})
+				continue
+			}
+			if err := f.assign(&next, table[key]); err != nil {
+				errs = append(errs, &ValidationError{Source: source, Line: lines.find(full), Key: full, Message: err.Error()})
+			}
+		}
+	}
+
+	for _, key := range md.Undecoded() {
+		if key[0] == "commands" {
+			errs = append(errs, &ValidationError{Source: source, Line: lines.find(key.String()), Key: key.String(), Message: "不明なキーです"})
+		}
+	}
+	for _, e := range validateCommands(file.Commands) {
+		e.Source = source
+		e.Line = lines.find(e.Key)
+		errs = append(errs, e)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	next.Commands = append([]MacroCommand(nil), c.Commands...)
+	next.merge(file.Commands)
+	next.Keybindings = mergeKeybindings(c.Keybindings, bindings)
+	next.Themes = mergeThemes(c.Themes, themes)
+	*c = next
+	return nil
+}
+
+// parseError はTOMLの構文エラーを行番号付きのエラーに変換する
+func parseError(source string, err error) *ValidationError {
+	var pe toml.ParseError
+	if errors.As(err, &pe) {
+		return &ValidationError{Source: source, Line: pe.Position.Line, Key: pe.LastKey, Message: "読み込みに失敗しました: " + pe.Message}
+	}
+	return &ValidationError{Source: source, Message: "読み込みに失敗しました: " + err.Error()}
+}
+
+// isSection はスキーマにあるセクション名か判定する
+func isSection(name string) bool {
+	for _, f := range Fields {
+		if strings.HasPrefix(f.Key, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// merge はマクロを優先してマージする
+func (c *Config) merge(commands []MacroCommand) {
+	for _, cmd := range commands {
+		replaced := false
+		for i := range c.Commands {
+			if c.Commands[i].Name == cmd.Name {
+				c.Commands[i] = cmd
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			c.Commands = append(c.Commands, cmd)
+		}
+	}
+}
+
+// validateCommands はマクロ定義の一覧を検証する
+// エラーのKeyには commands[i] を設定する
+func validateCommands(commands []MacroCommand) []*ValidationError {
+	var errs []*ValidationError
+	seen := make(map[string]bool, len(commands))
+	for i, cmd := range commands {
+		key := fmt.Sprintf("commands[%d]", i)
+		if err := cmd.validate(); err != nil {
+			errs = append(errs, &ValidationError{Key: key, Message: err.Error()})
+			continue
+		}
+		if seen[cmd.Name] {
+			errs = append(errs, &ValidationError{Key: key, Message: fmt.Sprintf("コマンド名 %q が重複しています", cmd.Name)})
+		}
+		seen[cmd.Name] = true
+	}
+	return errs
+}
+
+// validate はマクロ定義を検証する
+func (m *MacroCommand) validate() error {
+	if !macroNamePattern.MatchString(m.Name) {
+		return fmt.Errorf("コマンド名 %q は英小文字・数字・-・_のみ使用できます", m.Name)
+	}
+	if len(m.Steps) == 0 {
+		return fmt.Errorf("%s: stepsが空です", m.Name)
+	}
+
+	argNames := make(map[string]bool, len(m.Args))
+	for _, arg := range m.Args {
+		if reservedVars[arg] {
+			return fmt.Errorf("%s: 引数名 %q は予約されています", m.Name, arg)
+		}
+		if argNames[arg] {
+			return fmt.Errorf("%s: 引数名 %q が重複しています", m.Name, arg)
+		}
+		argNames[arg] = true
+	}
+
+	for i, step := range m.Steps {
+		if (step.Prompt == "") == (step.Action == "") {
+			return fmt.Errorf("%s: steps[%d]: promptとactionのどちらか一方を指定してください", m.Name, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig はテスト用の設定ファイルを書き込む
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, FileName)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	global := writeConfig(t, t.TempDir(), `
+[[commands]]
+name = "fix"
+description = "グローバル版"
+steps = [{ prompt = "global" }]
+
+[[commands]]
+name = "review"
+steps = [{ prompt = "レビューして" }]
+`)
+	project := writeConfig(t, t.TempDir(), `
+[[commands]]
+name = "fix"
+description = "テストを実行して修正"
+args = ["target"]
+
+  [[commands.steps]]
+  prompt = "{{target}} のテストを実行して"
+  wait = true
+
+  [[commands.steps]]
+  action = "diff.toggle"
+`)
+	missing := filepath.Join(t.TempDir(), "none.toml")
+
+	cfg, err := Load(global, missing, project)
+	require.NoError(t, err)
+	require.Len(t, cfg.Commands, 2)
+
+	// 後から読み込んだプロジェクト設定が優先される
+	assert.Equal(t, MacroCommand{
+		Name:        "fix",
+		Description: "テストを実行して修正",
+		Args:        []string{"target"},
+		Steps: []MacroStep{
+			{Prompt: "{{target}} のテストを実行して", Wait: true},
+			{Action: "diff.toggle"},
+		},
+	}, cfg.Commands[0])
+	assert.Equal(t, "review", cfg.Commands[1].Name)
+}
+
+func TestLoad_Layers(t *testing.T) {
+	global := writeConfig(t, t.TempDir(), `
+[ui]
+max_output_lines = 500
+
+[diff]
+refresh_interval = "5s"
+`)
+	project := writeConfig(t, t.TempDir(), `
+[diff]
+refresh_interval = "1s"
+`)
+
+	cfg, err := Load(global, project)
+	require.NoError(t, err)
+
+	want := Default()
+	want.UI.MaxOutputLines = 500
+	want.Diff.RefreshInterval = time.Second
+	assert.Equal(t, want, cfg)
+
+	// ファイルがなければ既定値になる
+	cfg, err = Load(filepath.Join(t.TempDir(), "none.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "構文エラー", content: "[ui]\nmax_output_lines = \n", wantErr: ":2: ui.max_output_lines: 読み込みに失敗しました"},
+		{name: "不明なキー", content: "[ui]\nmax_output_lines = 1\ncolour = \"red\"\n", wantErr: ":3: ui.colour: 不明なキーです"},
+		{name: "不明なセクション", content: "\n[editor]\nname = \"vim\"\n", wantErr: ":2: editor: 不明なキーです"},
+		{name: "型の誤り", content: "[ui]\nmax_output_lines = \"many\"\n", wantErr: ":2: ui.max_output_lines: 整数で指定してください"},
+		{name: "範囲外", content: "[diff]\nside_by_side_min_width = 10\n", wantErr: ":2: diff.side_by_side_min_width: 40 以上で指定してください"},
+		{name: "不正な時間", content: "[diff]\nrefresh_interval = \"soon\"\n", wantErr: "時間 (例: 2s, 500ms)で指定してください"},
+		{name: "テーブル以外", content: "ui = 1\n", wantErr: ":1: ui: テーブルで指定してください"},
+		{name: "リスト以外", content: "[watch]\nignore = \"*.log\"\n", wantErr: ":2: watch.ignore: 文字列のリスト (カンマ区切り)で指定してください"},
+		{name: "リストの要素の型", content: "[watch]\nignore = [1]\n", wantErr: ":2: watch.ignore: 文字列のリスト"},
+		{name: "マクロの不明なキー", content: "[[commands]]\nname = \"x\"\npromt = \"a\"\nsteps = [{ prompt = \"a\" }]\n", wantErr: ":3: commands.promt: 不明なキーです"},
+		{name: "不正な名前", content: "[[commands]]\nname = \"Fix Me\"\nsteps = [{ prompt = \"x\" }]\n", wantErr: ":1: commands[0]: コマンド名 \"Fix Me\" は英小文字"},
+		{name: "手順なし", content: "[[commands]]\nname = \"x\"\n", wantErr: ":1: commands[0]: x: stepsが空です"},
+		{name: "promptとactionの両方", content: "[[commands]]\nname = \"x\"\nsteps = [{ prompt = \"a\", action = \"b\" }]\n", wantErr: "どちらか一方"},
+		{name: "予約された引数名", content: "[[commands]]\nname = \"x\"\nargs = [\"task\"]\nsteps = [{ prompt = \"a\" }]\n", wantErr: "予約されています"},
+		{name: "名前の重複", content: "[[commands]]\nname = \"x\"\nsteps = [{ prompt = \"a\" }]\n[[commands]]\nname = \"x\"\nsteps = [{ prompt = \"b\" }]\n", wantErr: ":4: commands[1]: コマンド名 \"x\" が重複しています"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, t.TempDir(), tt.content)
+			_, err := Load(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), path)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("すべての誤りを報告", func(t *testing.T) {
+		path := writeConfig(t, t.TempDir(), "[ui]\nmax_output_lines = -1\n\n[log]\nverbose = \"yes\"\n")
+		_, err := Load(path)
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 2)
+		assert.Equal(t, &ValidationError{Source: path, Line: 5, Key: "log.verbose", Message: "真偽値で指定してください"}, errs[0])
+		assert.Equal(t, 2, errs[1].Line)
+	})
+}
+
+func TestGlobalPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	path, err := GlobalPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/xdg/ccforge/config.toml", path)
+}
+
+func TestProjectPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("/repo", "ccforge", "config.toml"), ProjectPath("/repo"))
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetInFile は設定ファイルの1項目を書き換える
+// コメントや他の項目はそのまま残し、キーやテーブルがなければ追加する
+// 書き換え後の内容が不正な場合はファイルを変更しない
+func SetInFile(path, key, value string) error {
+	f, ok := LookupField(key)
+	if !ok {
+		return fmt.Errorf("不明なキーです: %s", key)
+	}
+	cfg := Default()
+	if err := cfg.Set(key, value); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("設定ファイル %s の読み込みに失敗しました: %w", path, err)
+	}
+
+	updated := setLine(string(data), f.Key, f.literal(cfg))
+	if errs := Default().apply(path, []byte(updated)); len(errs) > 0 {
+		return errs
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("設定ディレクトリの作成に失敗しました: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		return fmt.Errorf("設定ファイル %s の書き込みに失敗しました: %w", path, err)
+	}
+	return nil
+}
+
+// setLine はTOMLのテキストの key = literal の行を書き換えるか追加する
+func setLine(content, key, literal string) string {
+	section, name := key[:strings.LastIndex(key, ".")], key[strings.LastIndex(key, ".")+1:]
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	current := ""
+	header, last := -1, -1 // セクションの見出しと最後の項目の行
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			current, _, _ = strings.Cut(strings.Trim(trimmed, "[ "), "]")
+			if current == section {
+				header, last = i, i
+			}
+			continue
+		}
+		if current != section || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		last = i
+		if k, _, ok := strings.Cut(trimmed, "="); ok && strings.TrimSpace(k) == name {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			lines[i] = indent + name + " = " + literal
+			return strings.Join(lines, "\n") + "\n"
+		}
+	}
+
+	entry := name + " = " + literal
+	if header < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+section+"]", entry)
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	lines = append(lines[:last+1], append([]string{entry}, lines[last+1:]...)...)
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetInFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		value   string
+		want    string
+	}{
+		{
+			name:  "新しいファイル",
+			key:   "ui.max_output_lines",
+			value: "200",
+			want:  "[ui]\nmax_output_lines = 200\n",
+		},
+		{
+			name:    "既存の値を書き換え",
+			content: "# 設定\n[ui]\n  max_output_lines = 10\n\n[log]\nverbose = true\n",
+			key:     "ui.max_output_lines",
+			value:   "20",
+			want:    "# 設定\n[ui]\n  max_output_lines = 20\n\n[log]\nverbose = true\n",
+		},
+		{
+			name:    "セクションの末尾に追加",
+			content: "[log]\nverbose = true\n\n[[commands]]\nname = \"x\"\nsteps = [{ prompt = \"a\" }]\n",
+			key:     "log.file",
+			value:   "/tmp/ccforge.log",
+			want:    "[log]\nverbose = true\nfile = \"/tmp/ccforge.log\"\n\n[[commands]]\nname = \"x\"\nsteps = [{ prompt = \"a\" }]\n",
+		},
+		{
+			name:    "セクションを追加",
+			content: "[ui]\nmax_output_lines = 10\n",
+			key:     "diff.refresh_interval",
+			value:   "5s",
+			want:    "[ui]\nmax_output_lines = 10\n\n[diff]\nrefresh_interval = \"5s\"\n",
+		},
+		{
+			name:  "文字列のリスト",
+			key:   "watch.ignore",
+			value: "*.log, tmp/",
+			want:  "[watch]\nignore = [\"*.log\", \"tmp/\"]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ccforge", FileName)
+			if tt.content != "" {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+			}
+
+			require.NoError(t, SetInFile(path, tt.key, tt.value))
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+
+			_, err = Load(path)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSetInFile_Errors(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "[ui]\nmax_output_lines = 10\n")
+
+	assert.ErrorContains(t, SetInFile(path, "ui.colour", "red"), "不明なキーです")
+	assert.ErrorContains(t, SetInFile(path, "ui.max_output_lines", "many"), "整数で指定してください")
+
+	// 既存の内容が不正な場合は書き換えない
+	broken := writeConfig(t, t.TempDir(), "[ui]\ncolour = \"red\"\n")
+	assert.ErrorContains(t, SetInFile(broken, "ui.max_output_lines", "5"), "ui.colour: 不明なキーです")
+
+	content, err := os.ReadFile(broken)
+	require.NoError(t, err)
+	assert.Equal(t, "[ui]\ncolour = \"red\"\n", string(content))
+}
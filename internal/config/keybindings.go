@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/mzkmnk/ccforge/internal/keymap"
+)
+
+// parseKeybindings は [keybindings.<コンテキスト>] の内容を検証して読み込む
+// 操作IDにはドットが含まれるため、クォートしない書き方 (app.quit = "ctrl+q") のテーブルも展開する
+// 操作IDが存在するかは操作を登録するTUIで検証する
+func parseKeybindings(v any, lines lineIndex) (keymap.Bindings, []*ValidationError) {
+	if v == nil {
+		return nil, nil
+	}
+
+	table, ok := v.(map[string]any)
+	if !ok {
+		return nil, []*ValidationError{{Line: lines.find("keybindings"), Key: "keybindings", Message: "テーブルで指定してください"}}
+	}
+
+	bindings := keymap.Bindings{}
+	var errs []*ValidationError
+	for _, name := range sortedKeys(table) {
+		key := "keybindings." + name
+		ctx, ok := keymap.ParseContext(name)
+		if !ok {
+			errs = append(errs, &ValidationError{Line: lines.find(key), Key: key, Message: fmt.Sprintf("不明なコンテキストです (%v のいずれか)", keymap.Contexts)})
+			continue
+		}
+		actions, ok := table[name].(map[string]any)
+		if !ok {
+			errs = append(errs, &ValidationError{Line: lines.find(key), Key: key, Message: "テーブルで指定してください"})
+			continue
+		}
+
+		bindings[ctx] = map[string][]string{}
+		errs = append(errs, parseActionKeys(bindings[ctx], key, "", actions, lines)...)
+	}
+	return bindings, errs
+}
+
+// parseActionKeys は 操作ID = キー または [キー...] を読み込む
+func parseActionKeys(dst map[string][]string, prefix, action string, table map[string]any, lines lineIndex) []*ValidationError {
+	var errs []*ValidationError
+	for _, name := range sortedKeys(table) {
+		id := joinKey(action, name)
+		key := prefix + "." + id
+
+		var keys []string
+		switch v := table[name].(type) {
+		case map[string]any:
+			errs = append(errs, parseActionKeys(dst, prefix, id, v, lines)...)
+			continue
+		case string:
+			keys = []string{v}
+		case []any:
+			keys = make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					keys = nil
+					break
+				}
+				keys = append(keys, s)
+			}
+			if keys == nil && len(v) > 0 {
+				errs = append(errs, &ValidationError{Line: lines.find(key), Key: key, Message: "キーは文字列で指定してください"})
+				continue
+			}
+		default:
+			errs = append(errs, &ValidationError{Line: lines.find(key), Key: key, Message: "キーは文字列または文字列の配列で指定してください"})
+			continue
+		}
+
+		normalized := make([]string, 0, len(keys))
+		valid := true
+		for _, k := range keys {
+			n, err := keymap.Normalize(k)
+			if err != nil {
+				errs = append(errs, &ValidationError{Line: lines.find(key), Key: key, Message: err.Error()})
+				valid = false
+				continue
+			}
+			normalized = append(normalized, n)
+		}
+		if valid {
+			dst[id] = normalized
+		}
+	}
+	return errs
+}
+
+// mergeKeybindings は後の割り当てを操作単位で優先してマージする
+func mergeKeybindings(base, over keymap.Bindings) keymap.Bindings {
+	if base == nil && over == nil {
+		return nil
+	}
+
+	merged := keymap.Bindings{}
+	for _, layer := range []keymap.Bindings{base, over} {
+		for ctx, actions := range layer {
+			if merged[ctx] == nil {
+				merged[ctx] = map[string][]string{}
+			}
+			for action, keys := range actions {
+				merged[ctx][action] = keys
+			}
+		}
+	}
+	return merged
+}
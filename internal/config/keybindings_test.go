@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/keymap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Keybindings(t *testing.T) {
+	global := writeConfig(t, t.TempDir(), `
+[keybindings.global]
+app.quit = "Ctrl+Q"
+"palette.open" = ["ctrl+p", "f2"]
+`)
+	project := writeConfig(t, t.TempDir(), `
+[keybindings.global]
+palette.open = []
+
+[keybindings.sidebar]
+diff.hide = "space"
+`)
+
+	cfg, err := Load(global, project)
+	require.NoError(t, err)
+
+	// 後の設定が操作単位で優先され、キーの表記は揃えられる
+	assert.Equal(t, keymap.Bindings{
+		keymap.Global:  {"app.quit": {"ctrl+q"}, "palette.open": {}},
+		keymap.Sidebar: {"diff.hide": {" "}},
+	}, cfg.Keybindings)
+}
+
+func TestLoad_KeybindingsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "不明なコンテキスト", content: "[keybindings.editor]\nsave = \"ctrl+s\"\n", wantErr: ":1: keybindings.editor: 不明なコンテキストです"},
+		{name: "不正なキー", content: "[keybindings.global]\n\napp.quit = \"hyper+q\"\n", wantErr: `:3: keybindings.global.app.quit: 不明な修飾キーです: "hyper+q"`},
+		{name: "型の誤り", content: "[keybindings.input]\nscreen.clear = 1\n", wantErr: ":2: keybindings.input.screen.clear: キーは文字列または文字列の配列で指定してください"},
+		{name: "配列の要素の型", content: "[keybindings.input]\nscreen.clear = [\"ctrl+l\", 2]\n", wantErr: ":2: keybindings.input.screen.clear: キーは文字列で指定してください"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, t.TempDir(), tt.content)
+			_, err := Load(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), path+tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,441 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// EnvPrefix は設定を上書きする環境変数の接頭辞
+const EnvPrefix = "CCFORGE_"
+
+// Kind は設定値の型
+type Kind int
+
+const (
+	// KindInt は整数
+	KindInt Kind = iota
+	// KindBool は真偽値
+	KindBool
+	// KindString は文字列
+	KindString
+	// KindDuration は時間 (例: 2s, 500ms)
+	KindDuration
+	// KindStringList は文字列のリスト (環境変数とフラグではカンマ区切り)
+	KindStringList
+)
+
+// String は型の説明を返す
+func (k Kind) String() string {
+	switch k {
+	case KindInt:
+		return "整数"
+	case KindBool:
+		return "真偽値"
+	case KindDuration:
+		return "時間 (例: 2s, 500ms)"
+	case KindStringList:
+		return "文字列のリスト (カンマ区切り)"
+	default:
+		return "文字列"
+	}
+}
+
+// Field は設定のスキーマの1項目
+type Field struct {
+	Key         string // セクション付きのキー (例: ui.max_output_lines)
+	Kind        Kind   // 値の型
+	Description string // 説明
+	min         int64  // 整数と時間の最小値
+	ptr         func(c *Config) any
+}
+
+// Fields は設定できる項目の一覧
+var Fields = []Field{
+	{
+		Key: "ui.max_output_lines", Kind: KindInt, Description: "出力を保持する最大行数 (0で無制限)",
+		ptr: func(c *Config) any { return &c.UI.MaxOutputLines },
+	},
+	{
+		Key: "ui.theme", Kind: KindString, Description: "テーマ (auto, dark, light または [themes.<名前>] で定義した名前)",
+		ptr: func(c *Config) any { return &c.UI.Theme },
+	},
+	{
+		Key: "ui.auto_resume", Kind: KindBool, Description: "タスクの開始時に前回の画面を自動で復元する",
+		ptr: func(c *Config) any { return &c.UI.AutoResume },
+	},
+	{
+		Key: "diff.refresh_interval", Kind: KindDuration, Description: "差分パネルの自動更新間隔",
+		min: int64(100 * time.Millisecond),
+		ptr: func(c *Config) any { return &c.Diff.RefreshInterval },
+	},
+	{
+		Key: "diff.side_by_side_min_width", Kind: KindInt, Description: "side-by-side表示に必要な最小幅",
+		min: 40,
+		ptr: func(c *Config) any { return &c.Diff.SideBySideMinWidth },
+	},
+	{
+		Key: "diff.tab_width", Kind: KindInt, Description: "差分のタブを展開する幅",
+		min: 1,
+		ptr: func(c *Config) any { return &c.Diff.TabWidth },
+	},
+	{
+		Key: "watch.ignore", Kind: KindStringList, Description: "変更の記録から除外するパターン (.gitignoreの書式、.gitignoreに加えて適用)",
+		ptr: func(c *Config) any { return &c.Watch.Ignore },
+	},
+	{
+		Key: "watch.max_watches", Kind: KindInt, Description: "fsnotifyで監視する最大ディレクトリ数 (超える場合はポーリング)",
+		min: 1,
+		ptr: func(c *Config) any { return &c.Watch.MaxWatches },
+	},
+	{
+		Key: "watch.poll_interval", Kind: KindDuration, Description: "ポーリングで監視する場合の走査の間隔",
+		min: int64(100 * time.Millisecond),
+		ptr: func(c *Config) any { return &c.Watch.PollInterval },
+	},
+	{
+		Key: "git.status_interval", Kind: KindDuration, Description: "ステータスバーのgitの状態の更新間隔",
+		min: int64(time.Second),
+		ptr: func(c *Config) any { return &c.Git.StatusInterval },
+	},
+	{
+		Key: "git.worktree_dir", Kind: KindString, Description: "タスクのworktreeを作成するディレクトリ (空の場合は <プロジェクト>-worktrees、相対パスはプロジェクトからの位置)",
+		ptr: func(c *Config) any { return &c.Git.WorktreeDir },
+	},
+	{
+		Key: "checkpoint.enabled", Kind: KindBool, Description: "ccforge run でプロンプトの送信前に作業ツリーのチェックポイントを保存する",
+		ptr: func(c *Config) any { return &c.Checkpoint.Enabled },
+	},
+	{
+		Key: "checkpoint.keep", Kind: KindInt, Description: "タスクごとに残すチェックポイントの数 (0で無制限)",
+		ptr: func(c *Config) any { return &c.Checkpoint.Keep },
+	},
+	{
+		Key: "claude.bin", Kind: KindString, Description: "Claude Code CLIの実行ファイル (空の場合は claude)",
+		ptr: func(c *Config) any { return &c.Claude.Bin },
+	},
+	{
+		Key: "log.file", Kind: KindString, Description: "ログファイル (空の場合はキャッシュディレクトリ)",
+		ptr: func(c *Config) any { return &c.Log.File },
+	},
+	{
+		Key: "log.verbose", Kind: KindBool, Description: "デバッグログも出力する",
+		ptr: func(c *Config) any { return &c.Log.Verbose },
+	},
+}
+
+// LookupField はキーに対応する項目を返す
+func LookupField(key string) (Field, bool) {
+	for _, f := range Fields {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// EnvName は項目を上書きする環境変数名を返す
+// 例: ui.max_output_lines → CCFORGE_UI_MAX_OUTPUT_LINES
+func (f Field) EnvName() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(f.Key, ".", "_"))
+}
+
+// Get は項目の現在の値を文字列で返す
+func (c *Config) Get(key string) (string, error) {
+	f, ok := LookupField(key)
+	if !ok {
+		return "", fmt.Errorf("不明なキーです: %s", key)
+	}
+	return f.format(c), nil
+}
+
+// Set は文字列の値を解釈して項目に設定する
+func (c *Config) Set(key, value string) error {
+	f, ok := LookupField(key)
+	if !ok {
+		return fmt.Errorf("不明なキーです: %s", key)
+	}
+	v, err := f.parse(value)
+	if err == nil {
+		err = f.assign(c, v)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// ApplyEnv は CCFORGE_ で始まる環境変数の値を重ねる
+// lookupには通常 os.LookupEnv を渡す
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	next := *c
+	var errs ValidationErrors
+	for _, f := range Fields {
+		value, ok := lookup(f.EnvName())
+		if !ok {
+			continue
+		}
+		v, err := f.parse(value)
+		if err == nil {
+			err = f.assign(&next, v)
+		}
+		if err != nil {
+			errs = append(errs, &ValidationError{Source: "環境変数 " + f.EnvName(), Key: f.Key, Message: err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	*c = next
+	return nil
+}
+
+// parse は文字列をTOMLで解釈される値と同じ型に変換する
+func (f Field) parse(value string) (any, error) {
+	switch f.Kind {
+	case KindInt:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%sで指定してください: %q", f.Kind, value)
+		}
+		return n, nil
+	case KindBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%sで指定してください: %q", f.Kind, value)
+		}
+		return b, nil
+	case KindStringList:
+		var items []any
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	default:
+		return value, nil
+	}
+}
+
+// assign は型と範囲を検証して値を設定する
+func (f Field) assign(c *Config, v any) error {
+	typeErr := fmt.Errorf("%sで指定してください", f.Kind)
+
+	switch p := f.ptr(c).(type) {
+	case *int:
+		n, ok := v.(int64)
+		if !ok {
+			return typeErr
+		}
+		if n < f.min {
+			return fmt.Errorf("%d 以上で指定してください", f.min)
+		}
+		*p = int(n)
+	case *bool:
+		b, ok := v.(bool)
+		if !ok {
+			return typeErr
+		}
+		*p = b
+	case *time.Duration:
+		s, ok := v.(string)
+		if !ok {
+			return typeErr
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%sで指定してください: %q", f.Kind, s)
+		}
+		if int64(d) < f.min {
+			return fmt.Errorf("%s 以上で指定してください", time.Duration(f.min))
+		}
+		*p = d
+	case *string:
+		s, ok := v.(string)
+		if !ok {
+			return typeErr
+		}
+		*p = s
+	case *[]string:
+		items, ok := v.([]any)
+		if !ok {
+			return typeErr
+		}
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return typeErr
+			}
+			list = append(list, s)
+		}
+		*p = list
+	}
+	return nil
+}
+
+// format は項目の値を文字列で返す
+func (f Field) format(c *Config) string {
+	switch p := f.ptr(c).(type) {
+	case *int:
+		return strconv.Itoa(*p)
+	case *bool:
+		return strconv.FormatBool(*p)
+	case *time.Duration:
+		return p.String()
+	case *string:
+		return *p
+	case *[]string:
+		return strings.Join(*p, ",")
+	}
+	return ""
+}
+
+// literal は項目の値をTOMLの値として書ける形式で返す
+func (f Field) literal(c *Config) string {
+	var value any = f.format(c)
+	switch f.Kind {
+	case KindInt, KindBool:
+		return f.format(c)
+	case KindStringList:
+		value = append([]string{}, *f.ptr(c).(*[]string)...)
+	}
+
+	var buf bytes.Buffer
+	_ = toml.NewEncoder(&buf).Encode(map[string]any{"v": value})
+	return strings.TrimSpace(strings.TrimPrefix(buf.String(), "v = "))
+}
+
+// ValidationError は設定の誤り
+type ValidationError struct {
+	Source  string // 設定の出どころ (ファイルのパスや環境変数名)
+	Line    int    // 行番号 (不明な場合は0)
+	Key     string // 誤りのあるキー
+	Message string // 内容
+}
+
+// Error はエラーメッセージを返す
+func (e *ValidationError) Error() string {
+	loc := e.Source
+	if e.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, e.Line)
+	}
+	if e.Key == "" {
+		return fmt.Sprintf("%s: %s", loc, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, e.Key, e.Message)
+}
+
+// ValidationErrors は複数の設定の誤り
+type ValidationErrors []*ValidationError
+
+// Error はすべての誤りを1行ずつ並べたメッセージを返す
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// indexPattern はキーの配列インデックスのパターン
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// lineIndex はキーから設定ファイルの行番号を引く索引
+type lineIndex map[string]int
+
+// keyLines は設定ファイル中のテーブルとキーの行番号を集める
+// 配列テーブルの要素は commands[0].steps[1] のようにインデックス付きで、
+// インデックスを除いたキーは最初に現れた行で登録する
+func keyLines(data []byte) lineIndex {
+	lines := lineIndex{}
+	arrays := map[string]int{} // インデックス付きのパス → 要素数
+
+	// resolve はテーブル名の途中にある配列テーブルを直近の要素に解決する
+	resolve := func(name string) string {
+		path := ""
+		for _, part := range strings.Split(name, ".") {
+			path = joinKey(path, strings.Trim(strings.TrimSpace(part), `"'`))
+			if n, ok := arrays[path]; ok {
+				path = fmt.Sprintf("%s[%d]", path, n-1)
+			}
+		}
+		return path
+	}
+	record := func(path string, line int) {
+		for _, key := range []string{path, indexPattern.ReplaceAllString(path, "")} {
+			if _, ok := lines[key]; !ok {
+				lines[key] = line
+			}
+		}
+	}
+
+	prefix := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "[["):
+			name, _, _ := strings.Cut(strings.TrimPrefix(line, "[["), "]]")
+			parent, last := "", name
+			if idx := strings.LastIndex(name, "."); idx >= 0 {
+				parent, last = name[:idx], name[idx+1:]
+			}
+			base := joinKey(resolve(parent), strings.Trim(strings.TrimSpace(last), `"'`))
+			arrays[base]++
+			prefix = fmt.Sprintf("%s[%d]", base, arrays[base]-1)
+			record(prefix, i+1)
+		case strings.HasPrefix(line, "["):
+			name, _, _ := strings.Cut(strings.TrimPrefix(line, "["), "]")
+			prefix = resolve(name)
+			record(prefix, i+1)
+		default:
+			if key, _, ok := strings.Cut(line, "="); ok {
+				record(joinKey(prefix, strings.Trim(strings.TrimSpace(key), `"'`)), i+1)
+			}
+		}
+	}
+	return lines
+}
+
+// find はキーの行番号を返す
+// キーが見つからない場合は親のキーをたどり、それでもなければ0を返す
+func (l lineIndex) find(key string) int {
+	for key != "" {
+		if line, ok := l[key]; ok {
+			return line
+		}
+		idx := strings.LastIndexAny(key, ".[")
+		if idx < 0 {
+			break
+		}
+		key = key[:idx]
+	}
+	return 0
+}
+
+// joinKey はキーをドットでつなぐ
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// sortedKeys はマップのキーを昇順で返す
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_GetSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{name: "整数", key: "ui.max_output_lines", value: "0", want: "0"},
+		{name: "時間", key: "diff.refresh_interval", value: "1500ms", want: "1.5s"},
+		{name: "真偽値", key: "log.verbose", value: "true", want: "true"},
+		{name: "文字列", key: "claude.bin", value: "/opt/claude", want: "/opt/claude"},
+		{name: "文字列のリスト", key: "watch.ignore", value: " *.log, ,tmp/ ", want: "*.log,tmp/"},
+		{name: "不明なキー", key: "ui.colour", value: "red", wantErr: "不明なキーです: ui.colour"},
+		{name: "型の誤り", key: "log.verbose", value: "maybe", wantErr: "log.verbose: 真偽値で指定してください"},
+		{name: "最小値未満", key: "diff.refresh_interval", value: "10ms", wantErr: "100ms 以上で指定してください"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			err := cfg.Set(tt.key, tt.value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Equal(t, Default(), cfg)
+				return
+			}
+			require.NoError(t, err)
+
+			got, err := cfg.Get(tt.key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfig_ApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"CCFORGE_UI_MAX_OUTPUT_LINES":   "200",
+		"CCFORGE_DIFF_REFRESH_INTERVAL": "3s",
+		"CCFORGE_UNKNOWN":               "ignored",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	cfg := Default()
+	require.NoError(t, cfg.ApplyEnv(lookup))
+	assert.Equal(t, 200, cfg.UI.MaxOutputLines)
+	assert.Equal(t, 3*time.Second, cfg.Diff.RefreshInterval)
+
+	// 誤りがあれば何も変更しない
+	env["CCFORGE_LOG_VERBOSE"] = "yes"
+	cfg = Default()
+	err := cfg.ApplyEnv(lookup)
+	assert.EqualError(t, err, "環境変数 CCFORGE_LOG_VERBOSE: log.verbose: 真偽値で指定してください: \"yes\"")
+	assert.Equal(t, Default(), cfg)
+}
+
+func TestField_EnvName(t *testing.T) {
+	f, ok := LookupField("claude.bin")
+	require.True(t, ok)
+	assert.Equal(t, "CCFORGE_CLAUDE_BIN", f.EnvName())
+}
+
+func TestKeyLines(t *testing.T) {
+	lines := keyLines([]byte(`# コメント
+[ui]
+max_output_lines = 10
+
+[[commands]]
+name = "a"
+
+  [[commands.steps]]
+  prompt = "x"
+
+[[commands]]
+name = "b"
+
+  [[commands.steps]]
+  prompt = "y"
+  [[commands.steps]]
+  action = "diff.toggle"
+`))
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{key: "ui", want: 2},
+		{key: "ui.max_output_lines", want: 3},
+		{key: "commands[1]", want: 11},
+		{key: "commands[1].name", want: 12},
+		{key: "commands[1].steps[1].action", want: 17},
+		{key: "commands.steps.prompt", want: 9},
+		{key: "commands[1].description", want: 11},
+		{key: "log.file", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, lines.find(tt.key))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+// parseThemes は [themes.<名前>] の内容を検証して読み込む
+// 各テーブルには base と 役割 = "色" を指定する
+func parseThemes(v any, lines lineIndex) (map[string]theme.Palette, []*ValidationError) {
+	if v == nil {
+		return nil, nil
+	}
+
+	table, ok := v.(map[string]any)
+	if !ok {
+		return nil, []*ValidationError{{Line: lines.find("themes"), Key: "themes", Message: "テーブルで指定してください"}}
+	}
+
+	palettes := map[string]theme.Palette{}
+	var errs []*ValidationError
+	for _, name := range sortedKeys(table) {
+		key := "themes." + name
+		if theme.IsBuiltin(name) {
+			errs = append(errs, &ValidationError{Line: lines.find(key), Key: key, Message: "組み込みテーマの名前は使えません"})
+			continue
+		}
+		values, ok := table[name].(map[string]any)
+		if !ok {
+			errs = append(errs, &ValidationError{Line: lines.find(key), Key: key, Message: "テーブルで指定してください"})
+			continue
+		}
+
+		palette := theme.Palette{Colors: map[theme.Role]theme.Color{}}
+		valid := true
+		for _, field := range sortedKeys(values) {
+			full := key + "." + field
+			s, ok := values[field].(string)
+			switch {
+			case !ok:
+				errs = append(errs, &ValidationError{Line: lines.find(full), Key: full, Message: "文字列で指定してください"})
+				valid = false
+			case field == "base":
+				if s != theme.DarkName && s != theme.LightName {
+					errs = append(errs, &ValidationError{Line: lines.find(full), Key: full, Message: fmt.Sprintf("%s または %s で指定してください", theme.DarkName, theme.LightName)})
+					valid = false
+				}
+				palette.Base = s
+			case theme.IsRole(field):
+				c, err := theme.ParseColor(s)
+				if err != nil {
+					errs = append(errs, &ValidationError{Line: lines.find(full), Key: full, Message: err.Error()})
+					valid = false
+				}
+				palette.Colors[theme.Role(field)] = c
+			default:
+				errs = append(errs, &ValidationError{Line: lines.find(full), Key: full, Message: fmt.Sprintf("不明な役割です (base, %v のいずれか)", theme.Roles)})
+				valid = false
+			}
+		}
+		if valid {
+			palettes[name] = palette
+		}
+	}
+	return palettes, errs
+}
+
+// mergeThemes は後の定義をテーマ単位で優先してマージする
+func mergeThemes(base, over map[string]theme.Palette) map[string]theme.Palette {
+	if base == nil && over == nil {
+		return nil
+	}
+
+	merged := make(map[string]theme.Palette, len(base)+len(over))
+	for _, layer := range []map[string]theme.Palette{base, over} {
+		for name, palette := range layer {
+			merged[name] = palette
+		}
+	}
+	return merged
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/theme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Themes(t *testing.T) {
+	global := writeConfig(t, t.TempDir(), `
+[ui]
+theme = "solar"
+
+[themes.solar]
+base = "light"
+accent = "#268bd2"
+
+[themes.mono]
+error = "15"
+`)
+	project := writeConfig(t, t.TempDir(), `
+[themes.solar]
+accent = "33"
+`)
+
+	cfg, err := Load(global, project)
+	require.NoError(t, err)
+
+	assert.Equal(t, "solar", cfg.UI.Theme)
+	// 同名のテーマは後の定義で置き換える
+	assert.Equal(t, map[string]theme.Palette{
+		"solar": {Colors: map[theme.Role]theme.Color{theme.Accent: {TrueColor: "#0087ff", ANSI256: "33", ANSI: "12"}}},
+		"mono":  {Colors: map[theme.Role]theme.Color{theme.Error: {TrueColor: "#ffffff", ANSI256: "15", ANSI: "15"}}},
+	}, cfg.Themes)
+}
+
+func TestLoad_ThemesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "組み込みテーマ名", content: "[themes.dark]\naccent = \"1\"\n", wantErr: ":1: themes.dark: 組み込みテーマの名前は使えません"},
+		{name: "不明な役割", content: "[themes.mine]\n\nborder = \"1\"\n", wantErr: ":3: themes.mine.border: 不明な役割です"},
+		{name: "不正な色", content: "[themes.mine]\naccent = \"blue\"\n", wantErr: `:2: themes.mine.accent: 色 "blue" は #rrggbb または 0-255 で指定してください`},
+		{name: "不正なbase", content: "[themes.mine]\nbase = \"sepia\"\n", wantErr: ":2: themes.mine.base: dark または light で指定してください"},
+		{name: "型の誤り", content: "[themes.mine]\naccent = 39\n", wantErr: ":2: themes.mine.accent: 文字列で指定してください"},
+		{name: "テーブル以外", content: "themes = 1\n", wantErr: ":1: themes: テーブルで指定してください"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, t.TempDir(), tt.content)
+			_, err := Load(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), path+tt.wantErr)
+		})
+	}
+}
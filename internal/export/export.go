@@ -0,0 +1,151 @@
+// Package export はセッションのトランスクリプトをMarkdown、HTML、JSONLに書き出す
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/store"
+)
+
+// Format はエクスポートの形式
+type Format string
+
+const (
+	// Markdown はプロンプトと応答を見出しで区切ったMarkdown
+	Markdown Format = "md"
+	// HTML は応答のANSIカラーを保ったHTML
+	HTML Format = "html"
+	// JSONL は1行に1件のやり取りを日時と役割付きで書くJSON Lines
+	JSONL Format = "jsonl"
+)
+
+// Formats は対応する形式の一覧
+var Formats = []Format{Markdown, HTML, JSONL}
+
+// ParseFormat は文字列からエクスポートの形式を取得する
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "md", "markdown":
+		return Markdown, nil
+	case "html":
+		return HTML, nil
+	case "jsonl":
+		return JSONL, nil
+	default:
+		return "", fmt.Errorf("不明なエクスポート形式です: %s (md, html, jsonl のいずれか)", s)
+	}
+}
+
+// Ext はファイルの拡張子を返す
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// Transcript は書き出すセッションのトランスクリプト
+type Transcript struct {
+	Task      string                   // タスク名
+	SessionID string                   // セッションID
+	Prompts   []store.PromptTranscript // プロンプトと応答 (送信順)
+}
+
+// Options は書き出しのオプション
+type Options struct {
+	Redact bool // 機密情報らしき文字列を伏せる
+}
+
+// Write はトランスクリプトを指定した形式で書き出し、伏せた文字列の数を返す
+func Write(w io.Writer, t Transcript, f Format, opts Options) (int, error) {
+	redacted := 0
+	if opts.Redact {
+		t, redacted = redactTranscript(t)
+	}
+
+	var err error
+	switch f {
+	case Markdown:
+		err = writeMarkdown(w, t)
+	case HTML:
+		err = writeHTML(w, t)
+	case JSONL:
+		err = writeJSONL(w, t)
+	default:
+		return 0, fmt.Errorf("不明なエクスポート形式です: %s", f)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("トランスクリプトの書き出しに失敗しました: %w", err)
+	}
+	return redacted, nil
+}
+
+// unsafeFileChars はファイル名に使わない文字
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// FileName は保存するファイル名を返す (例: 20260301-100000-s1.md)
+func FileName(t Transcript, f Format, at time.Time) string {
+	session := unsafeFileChars.ReplaceAllString(t.SessionID, "-")
+	if len(session) > 16 {
+		session = session[:16]
+	}
+	return fmt.Sprintf("%s-%s%s", at.Format("20060102-150405"), strings.Trim(session, "-"), f.Ext())
+}
+
+// redactTranscript はプロンプトと応答の機密情報らしき文字列を伏せた写しを返す
+func redactTranscript(t Transcript) (Transcript, int) {
+	total := 0
+	prompts := make([]store.PromptTranscript, 0, len(t.Prompts))
+	for _, pt := range t.Prompts {
+		var n int
+		pt.Prompt.Text, n = Redact(pt.Prompt.Text)
+		total += n
+
+		chunks := make([]store.Chunk, 0, len(pt.Chunks))
+		for _, chunk := range pt.Chunks {
+			chunk.Content, n = Redact(chunk.Content)
+			total += n
+			chunks = append(chunks, chunk)
+		}
+		pt.Chunks = chunks
+		prompts = append(prompts, pt)
+	}
+	t.Prompts = prompts
+	return t, total
+}
+
+// output は応答の断片を出力先ごとにつなげる
+func output(chunks []store.Chunk) (stdout, stderr string) {
+	var out, errOut strings.Builder
+	for _, chunk := range chunks {
+		if chunk.Stream == store.Stderr {
+			errOut.WriteString(chunk.Content)
+			continue
+		}
+		out.WriteString(chunk.Content)
+	}
+	return out.String(), errOut.String()
+}
+
+// period はセッションの最初と最後のやり取りの日時を返す
+func period(t Transcript) (start, end time.Time) {
+	for _, pt := range t.Prompts {
+		if start.IsZero() || pt.Prompt.SentAt.Before(start) {
+			start = pt.Prompt.SentAt
+		}
+		last := pt.Prompt.SentAt
+		if pt.Prompt.CompletedAt.After(last) {
+			last = pt.Prompt.CompletedAt
+		}
+		if last.After(end) {
+			end = last
+		}
+	}
+	return start, end
+}
+
+// formatTime は表示用の日時を返す
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,126 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTranscript はテスト用のトランスクリプト
+func testTranscript() Transcript {
+	sent := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	return Transcript{
+		Task:      "auth",
+		SessionID: "s1",
+		Prompts: []store.PromptTranscript{
+			{
+				Prompt: store.Prompt{ID: 1, SessionID: "s1", Text: "JWTの検証を実装して\nAPI_KEY=abcdef123456 を使う", SentAt: sent, CompletedAt: sent.Add(time.Minute)},
+				Chunks: []store.Chunk{
+					{ID: 1, PromptID: 1, Stream: store.Stdout, Content: "\x1b[32m✓\x1b[0m middleware/auth.go を作成しました\n", CreatedAt: sent.Add(10 * time.Second)},
+					{ID: 2, PromptID: 1, Stream: store.Stdout, Content: "```go\nfunc Verify() {}\n```\n", CreatedAt: sent.Add(20 * time.Second)},
+				},
+			},
+			{
+				Prompt: store.Prompt{ID: 2, SessionID: "s1", Text: "テストを実行して", SentAt: sent.Add(2 * time.Minute), CompletedAt: sent.Add(3 * time.Minute), ExitCode: 1},
+				Chunks: []store.Chunk{
+					{ID: 3, PromptID: 2, Stream: store.Stderr, Content: "FAIL <auth>\n", CreatedAt: sent.Add(150 * time.Second)},
+				},
+			},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{input: "md", want: Markdown},
+		{input: "markdown", want: Markdown},
+		{input: "HTML", want: HTML},
+		{input: "jsonl", want: JSONL},
+		{input: "pdf", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 5, 0, time.UTC)
+	tests := []struct {
+		name      string
+		sessionID string
+		format    Format
+		want      string
+	}{
+		{name: "Markdown", sessionID: "s1", format: Markdown, want: "20260301-100005-s1.md"},
+		{name: "長いセッションIDは短くする", sessionID: "0f8e7d6c-5b4a-3928-1706-f5e4d3c2b1a0", format: HTML, want: "20260301-100005-0f8e7d6c-5b4a-39.html"},
+		{name: "ファイル名に使えない文字を置き換える", sessionID: "../x/y", format: JSONL, want: "20260301-100005-x-y.jsonl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FileName(Transcript{SessionID: tt.sessionID}, tt.format, at))
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       Format
+		redact       bool
+		wantRedacted int
+		want         []string
+		unwant       []string
+	}{
+		{name: "Markdown", format: Markdown, want: []string{"## 1. プロンプト", "API_KEY=abcdef123456"}},
+		{name: "HTML", format: HTML, want: []string{"<!DOCTYPE html>", "API_KEY=abcdef123456"}},
+		{name: "JSONL", format: JSONL, want: []string{`"role":"user"`, "API_KEY=abcdef123456"}},
+		{name: "伏せる", format: Markdown, redact: true, wantRedacted: 1, want: []string{"API_KEY=[REDACTED]"}, unwant: []string{"abcdef123456"}},
+		{name: "HTMLでも伏せる", format: HTML, redact: true, wantRedacted: 1, want: []string{"API_KEY=[REDACTED]"}, unwant: []string{"abcdef123456"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			redacted, err := Write(&buf, testTranscript(), tt.format, Options{Redact: tt.redact})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRedacted, redacted)
+			for _, want := range tt.want {
+				assert.Contains(t, buf.String(), want)
+			}
+			for _, unwant := range tt.unwant {
+				assert.NotContains(t, buf.String(), unwant)
+			}
+		})
+	}
+
+	t.Run("元のトランスクリプトは変更しない", func(t *testing.T) {
+		transcript := testTranscript()
+		_, err := Write(&bytes.Buffer{}, transcript, JSONL, Options{Redact: true})
+		require.NoError(t, err)
+		assert.Contains(t, transcript.Prompts[0].Prompt.Text, "abcdef123456")
+	})
+
+	t.Run("不明な形式", func(t *testing.T) {
+		_, err := Write(&bytes.Buffer{}, testTranscript(), Format("pdf"), Options{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,287 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// htmlStyle はHTMLに埋め込むスタイルシート
+const htmlStyle = `body { font-family: -apple-system, "Segoe UI", "Hiragino Sans", sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #1f2328; }
+header dl { display: grid; grid-template-columns: max-content 1fr; gap: .2em 1em; color: #59636e; }
+header dd { margin: 0; }
+section { border-top: 1px solid #d1d9e0; padding-top: 1em; margin-top: 1.5em; }
+.prompt { white-space: pre-wrap; background: #f6f8fa; border-left: 4px solid #0969da; padding: .6em 1em; margin: 0; }
+.response { white-space: pre-wrap; background: #1e1e1e; color: #d4d4d4; padding: .8em 1em; border-radius: 6px; overflow-x: auto; font-family: ui-monospace, "SFMono-Regular", Menlo, monospace; font-size: 13px; }
+.stderr { border: 2px solid #cf222e; }
+.meta { color: #59636e; font-size: .9em; }
+`
+
+// writeHTML はスタイル付きの単一のHTML文書を書き出す
+// 応答のANSIカラーはインラインのスタイルに変換する
+func writeHTML(w io.Writer, t Transcript) error {
+	bw := bufio.NewWriter(w)
+	title := html.EscapeString(fmt.Sprintf("%s セッション %s", t.Task, t.SessionID))
+	start, end := period(t)
+
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html lang=\"ja\">\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", title, htmlStyle)
+	fmt.Fprintf(bw, "<header>\n<h1>%s</h1>\n<dl>\n", title)
+	fmt.Fprintf(bw, "<dt>タスク</dt><dd>%s</dd>\n", html.EscapeString(t.Task))
+	fmt.Fprintf(bw, "<dt>セッション</dt><dd><code>%s</code></dd>\n", html.EscapeString(t.SessionID))
+	if !start.IsZero() {
+		fmt.Fprintf(bw, "<dt>期間</dt><dd>%s 〜 %s</dd>\n", formatTime(start), formatTime(end))
+	}
+	fmt.Fprintf(bw, "<dt>やり取り</dt><dd>%d件</dd>\n</dl>\n</header>\n", len(t.Prompts))
+
+	for i, pt := range t.Prompts {
+		fmt.Fprintf(bw, "<section id=\"prompt-%d\">\n<h2>%d. プロンプト <span class=\"meta\">%s</span></h2>\n", pt.Prompt.ID, i+1, formatTime(pt.Prompt.SentAt))
+		fmt.Fprintf(bw, "<pre class=\"prompt\">%s</pre>\n", html.EscapeString(strings.TrimRight(pt.Prompt.Text, "\n")))
+
+		stdout, stderr := output(pt.Chunks)
+		bw.WriteString("<h3>応答</h3>\n")
+		if stdout == "" && stderr == "" {
+			bw.WriteString("<p class=\"meta\">(応答なし)</p>\n")
+		}
+		if stdout != "" {
+			fmt.Fprintf(bw, "<pre class=\"response\">%s</pre>\n", ansiToHTML(strings.TrimRight(stdout, "\n")))
+		}
+		if stderr != "" {
+			fmt.Fprintf(bw, "<p class=\"meta\">標準エラー出力</p>\n<pre class=\"response stderr\">%s</pre>\n", ansiToHTML(strings.TrimRight(stderr, "\n")))
+		}
+		if pt.Prompt.ExitCode != 0 {
+			fmt.Fprintf(bw, "<p class=\"meta\">終了コード: %d</p>\n", pt.Prompt.ExitCode)
+		}
+		bw.WriteString("</section>\n")
+	}
+
+	bw.WriteString("</body>\n</html>\n")
+	return bw.Flush()
+}
+
+// ansiPalette は基本の16色 (xtermの既定値)
+var ansiPalette = [16]string{
+	"#000000", "#cd3131", "#0dbc79", "#e5e510", "#2472c8", "#bc3fbc", "#11a8cd", "#e5e5e5",
+	"#666666", "#f14c4c", "#23d18b", "#f5f543", "#3b8eea", "#d670d6", "#29b8db", "#ffffff",
+}
+
+// sgrState はSGR (Select Graphic Rendition) で設定された文字の装飾
+type sgrState struct {
+	bold, faint, italic, underline, strike, reverse bool
+	fg, bg                                          string // CSSの色 (空の場合は既定の色)
+}
+
+// css は装飾をインラインのスタイルに変換する
+func (s sgrState) css() string {
+	fg, bg := s.fg, s.bg
+	if s.reverse {
+		fg, bg = bg, fg
+		if fg == "" {
+			fg = "#1e1e1e"
+		}
+		if bg == "" {
+			bg = "#d4d4d4"
+		}
+	}
+
+	var props []string
+	if fg != "" {
+		props = append(props, "color:"+fg)
+	}
+	if bg != "" {
+		props = append(props, "background-color:"+bg)
+	}
+	if s.bold {
+		props = append(props, "font-weight:bold")
+	}
+	if s.faint {
+		props = append(props, "opacity:.7")
+	}
+	if s.italic {
+		props = append(props, "font-style:italic")
+	}
+	switch {
+	case s.underline && s.strike:
+		props = append(props, "text-decoration:underline line-through")
+	case s.underline:
+		props = append(props, "text-decoration:underline")
+	case s.strike:
+		props = append(props, "text-decoration:line-through")
+	}
+	return strings.Join(props, ";")
+}
+
+// apply はSGRのパラメーターを装飾に反映する
+func (s *sgrState) apply(params []int) {
+	if len(params) == 0 {
+		params = []int{0}
+	}
+	for i := 0; i < len(params); i++ {
+		switch p := params[i]; {
+		case p == 0:
+			*s = sgrState{}
+		case p == 1:
+			s.bold = true
+		case p == 2:
+			s.faint = true
+		case p == 3:
+			s.italic = true
+		case p == 4:
+			s.underline = true
+		case p == 7:
+			s.reverse = true
+		case p == 9:
+			s.strike = true
+		case p == 22:
+			s.bold, s.faint = false, false
+		case p == 23:
+			s.italic = false
+		case p == 24:
+			s.underline = false
+		case p == 27:
+			s.reverse = false
+		case p == 29:
+			s.strike = false
+		case p >= 30 && p <= 37:
+			s.fg = ansiPalette[p-30]
+		case p == 38, p == 48:
+			color, n := extendedColor(params[i+1:])
+			if p == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+			i += n
+		case p == 39:
+			s.fg = ""
+		case p >= 40 && p <= 47:
+			s.bg = ansiPalette[p-40]
+		case p == 49:
+			s.bg = ""
+		case p >= 90 && p <= 97:
+			s.fg = ansiPalette[p-90+8]
+		case p >= 100 && p <= 107:
+			s.bg = ansiPalette[p-100+8]
+		}
+	}
+}
+
+// extendedColor は 38/48 に続く256色 (5;n) とTrue Color (2;r;g;b) を解釈する
+// 色と消費したパラメーターの数を返す
+func extendedColor(params []int) (string, int) {
+	if len(params) >= 2 && params[0] == 5 {
+		return color256(params[1]), 2
+	}
+	if len(params) >= 4 && params[0] == 2 {
+		return fmt.Sprintf("#%02x%02x%02x", clampByte(params[1]), clampByte(params[2]), clampByte(params[3])), 4
+	}
+	return "", len(params)
+}
+
+// color256 は256色のパレット番号をCSSの色に変換する
+func color256(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return ansiPalette[n]
+	case n < 232:
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[n/6%6], levels[n%6])
+	default:
+		gray := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+	}
+}
+
+// clampByte は値を0から255に収める
+func clampByte(n int) int {
+	return max(0, min(n, 255))
+}
+
+// ansiToHTML はANSIエスケープシーケンスを含む文字列をHTMLに変換する
+// SGRは装飾付きのspanに変換し、それ以外の制御シーケンスは取り除く
+func ansiToHTML(s string) string {
+	var b strings.Builder
+	var state sgrState
+	open := false
+
+	closeSpan := func() {
+		if open {
+			b.WriteString("</span>")
+			open = false
+		}
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] != '\x1b' {
+			j := strings.IndexByte(s[i:], '\x1b')
+			if j < 0 {
+				j = len(s) - i
+			}
+			text := strings.ReplaceAll(s[i:i+j], "\r\n", "\n")
+			if text != "" && !open {
+				if css := state.css(); css != "" {
+					fmt.Fprintf(&b, "<span style=\"%s\">", css)
+					open = true
+				}
+			}
+			b.WriteString(html.EscapeString(text))
+			i += j
+			continue
+		}
+
+		n, params, final := parseEscape(s[i:])
+		i += n
+		if final == 'm' {
+			closeSpan()
+			state.apply(params)
+		}
+	}
+
+	closeSpan()
+	return b.String()
+}
+
+// parseEscape は先頭のエスケープシーケンスの長さを返す
+// CSIの場合は数値のパラメーターと終端文字も返す
+func parseEscape(s string) (n int, params []int, final byte) {
+	if len(s) < 2 {
+		return len(s), nil, 0
+	}
+
+	switch s[1] {
+	case '[':
+		end := 2
+		for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+			end++
+		}
+		if end == len(s) {
+			return len(s), nil, 0
+		}
+		for _, field := range strings.FieldsFunc(s[2:end], func(r rune) bool { return r == ';' || r == ':' }) {
+			v, err := strconv.Atoi(field)
+			if err != nil {
+				return end + 1, nil, 0
+			}
+			params = append(params, v)
+		}
+		return end + 1, params, s[end]
+	case ']':
+		// OSC (ハイパーリンクやタイトル) はBELまたはSTまでを取り除く
+		for end := 2; end < len(s); end++ {
+			if s[end] == '\a' {
+				return end + 1, nil, 0
+			}
+			if s[end] == '\x1b' && end+1 < len(s) && s[end+1] == '\\' {
+				return end + 2, nil, 0
+			}
+		}
+		return len(s), nil, 0
+	default:
+		return 2, nil, 0
+	}
+}
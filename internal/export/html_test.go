@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeHTML(&buf, testTranscript()))
+	got := buf.String()
+
+	assert.Contains(t, got, "<title>auth セッション s1</title>")
+	assert.Contains(t, got, "<pre class=\"prompt\">JWTの検証を実装して\nAPI_KEY=abcdef123456 を使う</pre>")
+	// ANSIカラーを保ち、HTMLはエスケープする
+	assert.Contains(t, got, `<span style="color:#0dbc79">✓</span> middleware/auth.go を作成しました`)
+	assert.Contains(t, got, `<pre class="response stderr">FAIL &lt;auth&gt;</pre>`)
+	assert.Contains(t, got, "終了コード: 1")
+}
+
+func TestAnsiToHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "装飾なし", input: "a < b", want: "a &lt; b"},
+		{name: "前景色", input: "\x1b[31merror\x1b[0m ok", want: `<span style="color:#cd3131">error</span> ok`},
+		{name: "太字と背景色", input: "\x1b[1;44mhi\x1b[m", want: `<span style="background-color:#2472c8;font-weight:bold">hi</span>`},
+		{name: "明るい色", input: "\x1b[92mok", want: `<span style="color:#23d18b">ok</span>`},
+		{name: "256色", input: "\x1b[38;5;196mred\x1b[39m", want: `<span style="color:#ff0000">red</span>`},
+		{name: "256色のグレー", input: "\x1b[48;5;240mg", want: `<span style="background-color:#585858">g</span>`},
+		{name: "True Color", input: "\x1b[38;2;255;128;0mo", want: `<span style="color:#ff8000">o</span>`},
+		{name: "装飾を重ねる", input: "\x1b[3ma\x1b[4mb\x1b[24mc", want: `<span style="font-style:italic">a</span><span style="font-style:italic;text-decoration:underline">b</span><span style="font-style:italic">c</span>`},
+		{name: "反転", input: "\x1b[7mx", want: `<span style="color:#1e1e1e;background-color:#d4d4d4">x</span>`},
+		{name: "SGR以外の制御シーケンスは取り除く", input: "\x1b[2K\x1b[1Gdone", want: "done"},
+		{name: "OSCのハイパーリンクは取り除く", input: "\x1b]8;;https://example.com\x07link\x1b]8;;\x1b\\", want: "link"},
+		{name: "途中で切れたシーケンス", input: "a\x1b[3", want: "a"},
+		{name: "CRLFはLFにする", input: "a\r\nb", want: "a\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ansiToHTML(tt.input))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/store"
+)
+
+const (
+	// RoleUser はプロンプトの役割
+	RoleUser = "user"
+	// RoleAssistant は応答の役割
+	RoleAssistant = "assistant"
+)
+
+// record はJSONLの1行
+// プロンプトは1行、応答は受信した断片ごとに1行にする
+type record struct {
+	Time      time.Time `json:"time"`                // 送信または受信の日時
+	Role      string    `json:"role"`                // user または assistant
+	Task      string    `json:"task"`                // タスク名
+	SessionID string    `json:"session_id"`          // セッションID
+	PromptID  int64     `json:"prompt_id"`           // プロンプトID
+	Stream    string    `json:"stream,omitempty"`    // 応答の出力先 (stdout または stderr)
+	ExitCode  *int      `json:"exit_code,omitempty"` // 応答の完了した終了コード (プロンプトのみ)
+	Content   string    `json:"content"`             // 内容 (ANSIエスケープシーケンスは取り除く)
+}
+
+// writeJSONL はやり取りを1行に1件のJSONで書き出す
+func writeJSONL(w io.Writer, t Transcript) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	for _, pt := range t.Prompts {
+		prompt := record{
+			Time:      pt.Prompt.SentAt,
+			Role:      RoleUser,
+			Task:      t.Task,
+			SessionID: t.SessionID,
+			PromptID:  pt.Prompt.ID,
+			Content:   pt.Prompt.Text,
+		}
+		if !pt.Prompt.CompletedAt.IsZero() {
+			code := pt.Prompt.ExitCode
+			prompt.ExitCode = &code
+		}
+		if err := enc.Encode(prompt); err != nil {
+			return err
+		}
+
+		for _, chunk := range pt.Chunks {
+			stream := chunk.Stream
+			if stream == "" {
+				stream = store.Stdout
+			}
+			err := enc.Encode(record{
+				Time:      chunk.CreatedAt,
+				Role:      RoleAssistant,
+				Task:      t.Task,
+				SessionID: t.SessionID,
+				PromptID:  pt.Prompt.ID,
+				Stream:    string(stream),
+				Content:   ansi.Strip(chunk.Content),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
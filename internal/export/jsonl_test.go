@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeJSONL(&buf, testTranscript()))
+
+	var records []record
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.Len(t, records, 5)
+
+	// プロンプトと応答の断片を送受信の順に役割付きで書き出す
+	roles := make([]string, 0, len(records))
+	for _, r := range records {
+		roles = append(roles, r.Role)
+		assert.Equal(t, "auth", r.Task)
+		assert.Equal(t, "s1", r.SessionID)
+	}
+	assert.Equal(t, []string{RoleUser, RoleAssistant, RoleAssistant, RoleUser, RoleAssistant}, roles)
+
+	sent := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	assert.True(t, records[0].Time.Equal(sent))
+	require.NotNil(t, records[0].ExitCode)
+	assert.Equal(t, 0, *records[0].ExitCode)
+	assert.Empty(t, records[0].Stream)
+
+	assert.True(t, records[1].Time.Equal(sent.Add(10*time.Second)))
+	assert.Equal(t, "stdout", records[1].Stream)
+	assert.Equal(t, "✓ middleware/auth.go を作成しました\n", records[1].Content)
+	assert.Nil(t, records[1].ExitCode)
+
+	assert.Equal(t, 1, *records[3].ExitCode)
+	assert.Equal(t, "stderr", records[4].Stream)
+	assert.Equal(t, "FAIL <auth>\n", records[4].Content)
+}
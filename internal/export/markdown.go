@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// writeMarkdown はプロンプトを引用、応答をコードブロックにしたMarkdownを書き出す
+// 応答のANSIエスケープシーケンスは取り除く
+func writeMarkdown(w io.Writer, t Transcript) error {
+	bw := bufio.NewWriter(w)
+	start, end := period(t)
+
+	fmt.Fprintf(bw, "# %s セッション %s\n\n", t.Task, t.SessionID)
+	fmt.Fprintf(bw, "- タスク: %s\n", t.Task)
+	fmt.Fprintf(bw, "- セッション: `%s`\n", t.SessionID)
+	if !start.IsZero() {
+		fmt.Fprintf(bw, "- 期間: %s 〜 %s\n", formatTime(start), formatTime(end))
+	}
+	fmt.Fprintf(bw, "- やり取り: %d件\n", len(t.Prompts))
+
+	for i, pt := range t.Prompts {
+		fmt.Fprintf(bw, "\n---\n\n## %d. プロンプト (%s)\n\n", i+1, formatTime(pt.Prompt.SentAt))
+		for _, line := range strings.Split(strings.TrimRight(pt.Prompt.Text, "\n"), "\n") {
+			if line == "" {
+				bw.WriteString(">\n")
+				continue
+			}
+			fmt.Fprintf(bw, "> %s\n", line)
+		}
+
+		stdout, stderr := output(pt.Chunks)
+		bw.WriteString("\n### 応答\n\n")
+		if stdout == "" && stderr == "" {
+			bw.WriteString("_(応答なし)_\n")
+		}
+		if stdout != "" {
+			writeFence(bw, ansi.Strip(stdout))
+		}
+		if stderr != "" {
+			bw.WriteString("\n標準エラー出力:\n\n")
+			writeFence(bw, ansi.Strip(stderr))
+		}
+		if pt.Prompt.ExitCode != 0 {
+			fmt.Fprintf(bw, "\n終了コード: %d\n", pt.Prompt.ExitCode)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeFence は内容のバッククォートより長いフェンスでコードブロックを書き出す
+func writeFence(w *bufio.Writer, content string) {
+	fence := strings.Repeat("`", max(3, longestRun(content, '`')+1))
+	fmt.Fprintf(w, "%stext\n%s\n%s\n", fence, strings.TrimRight(content, "\n"), fence)
+}
+
+// longestRun は文字cが連続する最大の長さを返す
+func longestRun(s string, c rune) int {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
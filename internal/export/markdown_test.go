@@ -0,0 +1,46 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeMarkdown(&buf, testTranscript()))
+	got := buf.String()
+
+	assert.True(t, strings.HasPrefix(got, "# auth セッション s1\n"))
+	assert.Contains(t, got, "- やり取り: 2件\n")
+	assert.Contains(t, got, "- 期間: 2026-03-01 10:00:00 〜 2026-03-01 10:03:00\n")
+
+	// プロンプトは引用、応答はコードブロックで区切る
+	assert.Contains(t, got, "## 1. プロンプト (2026-03-01 10:00:00)\n\n> JWTの検証を実装して\n> API_KEY=abcdef123456 を使う\n")
+	assert.Contains(t, got, "### 応答\n\n````text\n✓ middleware/auth.go を作成しました\n```go\nfunc Verify() {}\n```\n````\n")
+	assert.NotContains(t, got, "\x1b[")
+
+	// 標準エラー出力と終了コードも残す
+	assert.Contains(t, got, "標準エラー出力:\n\n```text\nFAIL <auth>\n```\n")
+	assert.Contains(t, got, "終了コード: 1\n")
+}
+
+func TestLongestRun(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{input: "", want: 0},
+		{input: "a`b", want: 1},
+		{input: "```go\n``", want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, longestRun(tt.input, '`'))
+		})
+	}
+}
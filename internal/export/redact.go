@@ -0,0 +1,52 @@
+package export
+
+import "regexp"
+
+// Redacted は伏せた文字列の代わりに書き出す文字列
+const Redacted = "[REDACTED]"
+
+// secretPattern は機密情報らしき文字列のパターン
+type secretPattern struct {
+	re   *regexp.Regexp
+	repl string // 置き換え後の文字列 (キー名や "Bearer " などの前置きはグループで残す)
+}
+
+// secretPatterns は伏せる文字列のパターン
+// 具体的なトークンの形式を先に、キーと値の組のような一般的な形式を後に並べる
+var secretPatterns = []secretPattern{
+	// 秘密鍵
+	{re: regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----.*?-----END [A-Z0-9 ]*PRIVATE KEY-----`), repl: Redacted},
+	// Anthropic・OpenAIのAPIキー
+	{re: regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`), repl: Redacted},
+	// GitHubのトークン
+	{re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})`), repl: Redacted},
+	// AWSのアクセスキーID
+	{re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), repl: Redacted},
+	// Slackのトークン
+	{re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), repl: Redacted},
+	// GoogleのAPIキー
+	{re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`), repl: Redacted},
+	// JWT
+	{re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`), repl: Redacted},
+	// Authorizationヘッダーのトークン
+	{re: regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/=-]{16,}`), repl: "${1}" + Redacted},
+	// URLに含まれるパスワード
+	{re: regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`), repl: "${1}" + Redacted + "@"},
+	// password=... や API_KEY: ... のようなキーと値の組
+	{re: regexp.MustCompile(`(?i)(\b[A-Z0-9_.-]*(?:password|passwd|secret|token|api_?key|access_?key|private_?key)[A-Z0-9_]*["']?\s*[:=]\s*["']?)[^\s"'\[][^\s"',;]{5,}`), repl: "${1}" + Redacted},
+}
+
+// Redact は機密情報らしき文字列を伏せ、伏せた数を返す
+// キーと値の組はキー名を残して値だけを伏せる
+func Redact(s string) (string, int) {
+	count := 0
+	for _, p := range secretPatterns {
+		n := len(p.re.FindAllStringIndex(s, -1))
+		if n == 0 {
+			continue
+		}
+		count += n
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s, count
+}
@@ -0,0 +1,101 @@
+package fuzzy
+
+import (
+	"sort"
+	"unicode"
+)
+
+const (
+	// matchScore は1文字一致ごとの基本スコア
+	matchScore = 1
+	// consecutiveBonus は連続一致のボーナス
+	consecutiveBonus = 5
+	// boundaryBonus は単語の先頭での一致のボーナス
+	boundaryBonus = 8
+	// gapPenalty は一致間の読み飛ばし1文字ごとのペナルティ
+	gapPenalty = 1
+	// maxGapPenalty は一致間の読み飛ばしペナルティの上限
+	maxGapPenalty = 5
+)
+
+// Result はフィルタリング結果の1件
+type Result struct {
+	Index     int   // 対象スライス内のインデックス
+	Score     int   // 一致スコア (高いほど良い)
+	Positions []int // 一致したrune位置
+}
+
+// Match はpatternがtargetにあいまい一致するかを判定してスコアを返す
+// patternの各文字がtarget内に順番通りに現れれば一致とみなす (大文字小文字は区別しない)
+func Match(pattern, target string) (score int, positions []int, ok bool) {
+	p := []rune(pattern)
+	t := []rune(target)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	positions = make([]int, 0, len(p))
+	pi := 0
+	last := -1
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if unicode.ToLower(t[ti]) != unicode.ToLower(p[pi]) {
+			continue
+		}
+
+		score += matchScore
+		if last >= 0 && ti == last+1 {
+			score += consecutiveBonus
+		}
+		if isBoundary(t, ti) {
+			score += boundaryBonus
+		}
+		if last >= 0 {
+			gap := ti - last - 1
+			if gap > maxGapPenalty {
+				gap = maxGapPenalty
+			}
+			score -= gap * gapPenalty
+		}
+
+		positions = append(positions, ti)
+		last = ti
+		pi++
+	}
+
+	if pi < len(p) {
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+// isBoundary はtarget[i]が単語の先頭かを判定する
+func isBoundary(target []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := target[i-1]
+	switch prev {
+	case ' ', '/', '-', '_', '.', ':':
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(target[i])
+}
+
+// Filter はtargetsのうちpatternに一致するものをスコア順に返す
+// スコアが同じ場合は元の順序を維持する
+func Filter(pattern string, targets []string) []Result {
+	results := make([]Result, 0, len(targets))
+	for i, target := range targets {
+		score, positions, ok := Match(pattern, target)
+		if !ok {
+			continue
+		}
+		results = append(results, Result{Index: i, Score: score, Positions: positions})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
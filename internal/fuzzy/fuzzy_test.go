@@ -0,0 +1,66 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		target        string
+		wantOK        bool
+		wantPositions []int
+	}{
+		{name: "正常系_空パターン", pattern: "", target: "anything", wantOK: true},
+		{name: "正常系_部分列一致", pattern: "dfv", target: "diff view", wantOK: true, wantPositions: []int{0, 2, 5}},
+		{name: "正常系_大文字小文字を無視", pattern: "DIFF", target: "diff", wantOK: true, wantPositions: []int{0, 1, 2, 3}},
+		{name: "正常系_マルチバイト", pattern: "タ切", target: "タスク切り替え", wantOK: true, wantPositions: []int{0, 3}},
+		{name: "異常系_順序が違う", pattern: "fd", target: "diff", wantOK: false},
+		{name: "異常系_文字が足りない", pattern: "diffs", target: "diff", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, positions, ok := Match(tt.pattern, tt.target)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantPositions, positions)
+		})
+	}
+}
+
+func TestMatch_Score(t *testing.T) {
+	// 連続一致や単語先頭での一致は高いスコアになる
+	consecutive, _, _ := Match("diff", "diff panel")
+	scattered, _, _ := Match("diff", "dxixfxf")
+	assert.Greater(t, consecutive, scattered)
+
+	boundary, _, _ := Match("p", "diff panel")
+	middle, _, _ := Match("p", "help")
+	assert.Greater(t, boundary, middle)
+}
+
+func TestFilter(t *testing.T) {
+	targets := []string{"quit", "toggle help", "open diff panel", "clear screen"}
+
+	t.Run("一致したものをスコア順に返す", func(t *testing.T) {
+		results := Filter("dp", targets)
+		assert.Len(t, results, 1)
+		assert.Equal(t, 2, results[0].Index)
+	})
+
+	t.Run("空パターンは元の順序", func(t *testing.T) {
+		results := Filter("", targets)
+		indexes := make([]int, len(results))
+		for i, r := range results {
+			indexes[i] = r.Index
+		}
+		assert.Equal(t, []int{0, 1, 2, 3}, indexes)
+	})
+
+	t.Run("一致なし", func(t *testing.T) {
+		assert.Empty(t, Filter("zzz", targets))
+	})
+}
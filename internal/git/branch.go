@@ -0,0 +1,182 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ErrDirtyTree はコミットしていない変更があるためブランチを切り替えられない場合のエラー
+var ErrDirtyTree = errors.New("コミットしていない変更があります")
+
+// ErrBranchNotFound はブランチが存在しない場合のエラー
+var ErrBranchNotFound = errors.New("ブランチが見つかりません")
+
+// Branch はローカルブランチの情報
+type Branch struct {
+	Name      string    // ブランチ名
+	Head      string    // 先頭のコミットの短縮ハッシュ
+	Subject   string    // 先頭のコミットの件名
+	UpdatedAt time.Time // 先頭のコミットの日時
+	Current   bool      // このworktreeでチェックアウト中か
+	Worktree  string    // チェックアウトしているworktreeのパス (このworktreeを含む。ない場合は空)
+	Merged    bool      // 基準のブランチにマージ済みか (作成してからコミットしていない場合は含めない)
+}
+
+// BranchExists はローカルブランチが存在するかを返す
+func (r *Repo) BranchExists(name string) (bool, error) {
+	_, err := r.run("rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) && cmdErr.ExitCode() == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateBranch は現在のHEADからブランチを作成してチェックアウトする
+// コミットしていない変更は新しいブランチに持ち越す
+func (r *Repo) CreateBranch(name string) error {
+	if _, err := r.run("checkout", "-q", "-b", name); err != nil {
+		return fmt.Errorf("ブランチ %s の作成に失敗しました: %w", name, err)
+	}
+	slog.Info("ブランチを作成しました", "component", "git", "branch", name)
+	return nil
+}
+
+// Switch はブランチをチェックアウトする
+// 追跡しているファイルにコミットしていない変更がある場合、stashがfalseならErrDirtyTreeを返し、
+// trueなら変更をstashに退避してから切り替える。退避した場合はstashedがtrueになる
+func (r *Repo) Switch(name string, stash bool) (stashed bool, err error) {
+	exists, err := r.BranchExists(name)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, fmt.Errorf("%w: %s", ErrBranchNotFound, name)
+	}
+
+	s, err := r.Summary()
+	if err != nil {
+		return false, err
+	}
+	// 未追跡のファイルは切り替え後もそのまま残るため対象にしない
+	if s.Staged+s.Unstaged+s.Conflicted > 0 {
+		if !stash {
+			return false, fmt.Errorf("%w (ステージ済み %d, 未ステージ %d)", ErrDirtyTree, s.Staged, s.Unstaged+s.Conflicted)
+		}
+		message := fmt.Sprintf("ccforge: %s への切り替え前の変更", name)
+		if _, err := r.run("stash", "push", "-q", "-m", message); err != nil {
+			return false, fmt.Errorf("変更の退避に失敗しました: %w", err)
+		}
+		stashed = true
+		slog.Info("変更をstashに退避しました", "component", "git", "branch", s.Branch, "message", message)
+	}
+
+	if _, err := r.run("checkout", "-q", name); err != nil {
+		return stashed, fmt.Errorf("ブランチ %s への切り替えに失敗しました: %w", name, err)
+	}
+	slog.Info("ブランチを切り替えました", "component", "git", "from", s.Branch, "to", name)
+	return stashed, nil
+}
+
+// Branches は名前がprefixで始まるローカルブランチを名前順に取得する
+// baseを指定した場合はbaseにマージ済みかも判定する
+// 作成してから1つもコミットしていないブランチはマージ済みとしない
+func (r *Repo) Branches(prefix, base string) ([]Branch, error) {
+	out, err := r.run("for-each-ref", "--format=%(refname:short)%00%(objectname)%00%(objectname:short)%00%(committerdate:iso-strict)%00%(HEAD)%00%(worktreepath)%00%(subject)", "refs/heads/"+prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseHead string
+	if base != "" {
+		head, err := r.run("rev-parse", "--verify", "--quiet", base+"^{commit}")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrBranchNotFound, base)
+		}
+		baseHead = strings.TrimSpace(head)
+	}
+
+	var branches []Branch
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\x00", 7)
+		if len(fields) != 7 || !strings.HasPrefix(fields[0], prefix) {
+			continue
+		}
+		updated, _ := time.Parse(time.RFC3339, fields[3])
+		b := Branch{
+			Name:      fields[0],
+			Head:      fields[2],
+			Subject:   fields[6],
+			UpdatedAt: updated,
+			Current:   fields[4] == "*",
+			Worktree:  fields[5],
+		}
+		if baseHead != "" {
+			if b.Merged, err = r.merged(b.Name, fields[1], baseHead); err != nil {
+				return nil, err
+			}
+		}
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
+
+// merged はブランチ (先頭のコミットがhead) がbaseHeadにマージ済みかを判定する
+func (r *Repo) merged(name, head, baseHead string) (bool, error) {
+	_, err := r.run("merge-base", "--is-ancestor", head, baseHead)
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) && cmdErr.ExitCode() == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("ブランチ %s がマージ済みかの判定に失敗しました: %w", name, err)
+	}
+	// baseが作成時のコミットより先に進むと、コミットのないブランチもbaseに含まれる
+	return r.hasOwnCommits(name, head, baseHead), nil
+}
+
+// hasOwnCommits はブランチを作成してからコミットしたかを判定する
+// 作成時のコミットはreflogの最も古い記録から調べ、reflogがない場合はbaseと同じコミットを指すかで判定する
+func (r *Repo) hasOwnCommits(name, head, baseHead string) bool {
+	out, err := r.run("reflog", "show", "--format=%H", "refs/heads/"+name, "--")
+	if lines := strings.Fields(out); err == nil && len(lines) > 0 {
+		return lines[len(lines)-1] != head
+	}
+	return head != baseHead
+}
+
+// DeleteBranch はローカルブランチを削除する
+// gitは現在のHEADを基準にマージ済みかを判定するため、マージ済みかは呼び出し側で Branches を使って判定する
+func (r *Repo) DeleteBranch(name string) error {
+	if _, err := r.run("branch", "-q", "-D", name); err != nil {
+		return fmt.Errorf("ブランチ %s の削除に失敗しました: %w", name, err)
+	}
+	slog.Info("ブランチを削除しました", "component", "git", "branch", name)
+	return nil
+}
+
+// DefaultBranch はリモートのHEADが指すブランチ、なければ main か master を返す
+func (r *Repo) DefaultBranch() (string, error) {
+	if out, err := r.run("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(out), "origin/"); ok {
+			return name, nil
+		}
+	}
+
+	for _, name := range []string{"main", "master"} {
+		exists, err := r.BranchExists(name)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("%w: 既定のブランチ (main, master)", ErrBranchNotFound)
+}
@@ -0,0 +1,194 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestRepo はコミットが1つあるリポジトリを開く
+func openTestRepo(t *testing.T) (*Repo, string) {
+	t.Helper()
+	dir := initTestRepo(t)
+	commitFile(t, dir, "a.txt", "a\n")
+	repo, err := Open(dir)
+	require.NoError(t, err)
+	return repo, dir
+}
+
+func TestRepo_CreateBranch(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	writeFile(t, dir, "a.txt", "carried\n")
+
+	require.NoError(t, repo.CreateBranch("task/auth"))
+
+	branch, err := repo.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "task/auth", branch)
+	// コミットしていない変更は持ち越す
+	content, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "carried\n", string(content))
+
+	exists, err := repo.BranchExists("task/auth")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = repo.BranchExists("task/missing")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.Error(t, repo.CreateBranch("task/auth"))
+}
+
+func TestRepo_Switch(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, dir string)
+		branch      string
+		stash       bool
+		wantErr     error
+		wantStashed bool
+		wantBranch  string
+	}{
+		{
+			name:       "変更なし",
+			branch:     "task/auth",
+			wantBranch: "task/auth",
+		},
+		{
+			name:       "未追跡のファイルは切り替えを妨げない",
+			setup:      func(t *testing.T, dir string) { writeFile(t, dir, "new.txt", "x\n") },
+			branch:     "task/auth",
+			wantBranch: "task/auth",
+		},
+		{
+			name:       "未ステージの変更",
+			setup:      func(t *testing.T, dir string) { writeFile(t, dir, "a.txt", "dirty\n") },
+			branch:     "task/auth",
+			wantErr:    ErrDirtyTree,
+			wantBranch: "main",
+		},
+		{
+			name: "ステージ済みの変更をstashに退避",
+			setup: func(t *testing.T, dir string) {
+				writeFile(t, dir, "a.txt", "dirty\n")
+				gitCmd(t, dir, "add", "a.txt")
+			},
+			branch:      "task/auth",
+			stash:       true,
+			wantStashed: true,
+			wantBranch:  "task/auth",
+		},
+		{
+			name:       "存在しないブランチ",
+			branch:     "task/missing",
+			wantErr:    ErrBranchNotFound,
+			wantBranch: "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, dir := openTestRepo(t)
+			gitCmd(t, dir, "branch", "task/auth")
+			if tt.setup != nil {
+				tt.setup(t, dir)
+			}
+
+			stashed, err := repo.Switch(tt.branch, tt.stash)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantStashed, stashed)
+
+			branch, err := repo.CurrentBranch()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBranch, branch)
+
+			if tt.wantStashed {
+				assert.Contains(t, gitCmd(t, dir, "stash", "list"), "ccforge: task/auth への切り替え前の変更")
+				s, err := repo.Summary()
+				require.NoError(t, err)
+				assert.False(t, s.Dirty())
+			}
+		})
+	}
+}
+
+func TestRepo_Branches(t *testing.T) {
+	repo, dir := openTestRepo(t)
+
+	// まだコミットのないブランチ (mainが先に進んでもマージ済みとしない)
+	gitCmd(t, dir, "branch", "task/idle")
+	// マージ済み
+	gitCmd(t, dir, "checkout", "-q", "-b", "task/done")
+	commitFile(t, dir, "done.txt", "done\n")
+	// マージ前に作成したブランチ
+	gitCmd(t, dir, "checkout", "-q", "-b", "task/wip", "main")
+	commitFile(t, dir, "wip.txt", "wip\n")
+	gitCmd(t, dir, "checkout", "-q", "main")
+	gitCmd(t, dir, "merge", "-q", "--no-ff", "-m", "merge done", "task/done")
+	// マージ済みで別のworktreeでチェックアウト中
+	gitCmd(t, dir, "checkout", "-q", "-b", "task/other")
+	commitFile(t, dir, "other.txt", "other\n")
+	gitCmd(t, dir, "checkout", "-q", "main")
+	gitCmd(t, dir, "merge", "-q", "--no-ff", "-m", "merge other", "task/other")
+	gitCmd(t, dir, "worktree", "add", "-q", filepath.Join(t.TempDir(), "other"), "task/other")
+	// まだコミットのないブランチ
+	gitCmd(t, dir, "branch", "task/new")
+	// 対象外
+	gitCmd(t, dir, "branch", "feature/other")
+	gitCmd(t, dir, "checkout", "-q", "task/wip")
+
+	branches, err := repo.Branches("task/", "main")
+	require.NoError(t, err)
+
+	var names []string
+	merged := make(map[string]bool)
+	for _, b := range branches {
+		names = append(names, b.Name)
+		merged[b.Name] = b.Merged
+		assert.NotEmpty(t, b.Head)
+		assert.False(t, b.UpdatedAt.IsZero())
+	}
+	assert.Equal(t, []string{"task/done", "task/idle", "task/new", "task/other", "task/wip"}, names)
+	assert.Equal(t, map[string]bool{"task/done": true, "task/idle": false, "task/new": false, "task/other": true, "task/wip": false}, merged)
+	assert.Equal(t, "update done.txt", branches[0].Subject)
+	assert.Empty(t, branches[0].Worktree)
+	assert.NotEmpty(t, branches[3].Worktree)
+	assert.False(t, branches[3].Current)
+	assert.True(t, branches[4].Current)
+	assert.NotEmpty(t, branches[4].Worktree)
+
+	// HEADにマージしていなくても削除できる
+	require.NoError(t, repo.DeleteBranch("task/done"))
+	err = repo.DeleteBranch("task/wip")
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "task/wip"))
+
+	_, err = repo.Branches("task/", "no-such-branch")
+	assert.ErrorIs(t, err, ErrBranchNotFound)
+}
+
+func TestRepo_DefaultBranch(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	name, err := repo.DefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "main", name)
+
+	// リモートのHEADを優先する
+	clone := filepath.Join(t.TempDir(), "clone")
+	gitCmd(t, dir, "branch", "-m", "main", "trunk")
+	gitCmd(t, dir, "clone", "-q", dir, clone)
+	repo, err = Open(clone)
+	require.NoError(t, err)
+	name, err = repo.DefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "trunk", name)
+}
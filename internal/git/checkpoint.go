@@ -0,0 +1,333 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckpointRefPrefix はチェックポイントを保存するrefの接頭辞
+// refs/ccforge/checkpoints/<タスク名>/<番号> に作業ツリーのスナップショットのコミットを保存する
+const CheckpointRefPrefix = "refs/ccforge/checkpoints/"
+
+// ErrCheckpointNotFound はチェックポイントが存在しない場合のエラー
+var ErrCheckpointNotFound = errors.New("チェックポイントが見つかりません")
+
+// maxCheckpointRetries は他の保存と番号が衝突した場合に次の番号を試す回数
+const maxCheckpointRetries = 10
+
+// checkpointIdentity はスナップショットのコミットの作成者
+var checkpointIdentity = []string{
+	"GIT_AUTHOR_NAME=ccforge", "GIT_AUTHOR_EMAIL=ccforge@localhost",
+	"GIT_COMMITTER_NAME=ccforge", "GIT_COMMITTER_EMAIL=ccforge@localhost",
+}
+
+// Checkpoint はプロンプトの送信前に保存した作業ツリーのスナップショット
+type Checkpoint struct {
+	ID        int       // タスクごとの通し番号 (1から)
+	Commit    string    // スナップショットのコミット
+	Message   string    // 送信したプロンプト
+	CreatedAt time.Time // 保存した日時
+}
+
+// Subject はメッセージの1行目を返す
+func (c Checkpoint) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// DiffStat は差分の規模
+type DiffStat struct {
+	Files      int // 変更されたファイル数
+	Insertions int // 追加された行数
+	Deletions  int // 削除された行数
+}
+
+// CreateCheckpoint は作業ツリー (未追跡のファイルを含み、無視するファイルを除く) のスナップショットを
+// タスクのチェックポイントとして保存する
+// 一時的なインデックスを使うため、インデックスとブランチは変更しない
+// excludeはスナップショットに含めないパス (リポジトリのルートからの相対パス)
+func (r *Repo) CreateCheckpoint(task, message string, exclude []string) (Checkpoint, error) {
+	tree, err := r.snapshotTree(exclude)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	args := []string{"commit-tree", tree, "-m", message}
+	if head, ok := r.head(); ok {
+		args = append(args, "-p", head)
+	}
+	out, err := runGit(r.root, append(os.Environ(), checkpointIdentity...), args...)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("チェックポイントの作成に失敗しました: %w", err)
+	}
+	commit := strings.TrimSpace(out)
+
+	list, err := r.Checkpoints(task)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	id := 1
+	if len(list) > 0 {
+		id = list[len(list)-1].ID + 1
+	}
+	if id, err = r.saveCheckpointRef(task, id, commit); err != nil {
+		return Checkpoint{}, err
+	}
+	slog.Info("チェックポイントを保存しました", "component", "git", "task", task, "id", id, "commit", commit)
+	return Checkpoint{ID: id, Commit: commit, Message: message, CreatedAt: time.Now()}, nil
+}
+
+// saveCheckpointRef はコミットをid番のチェックポイントのrefに保存し、保存した番号を返す
+// 同時に保存した他のチェックポイントを上書きしないよう、refが既にある場合は次の番号を使う
+func (r *Repo) saveCheckpointRef(task string, id int, commit string) (int, error) {
+	for i := 0; ; i++ {
+		ref := checkpointRef(task, id)
+		// 古い値に空を指定すると、refが既にある場合にgitが更新を拒否する
+		_, err := r.run("update-ref", ref, commit, "")
+		if err == nil {
+			return id, nil
+		}
+		// refがない場合は衝突以外の理由で失敗している
+		if _, verifyErr := r.run("rev-parse", "--verify", "--quiet", ref); verifyErr != nil || i >= maxCheckpointRetries {
+			return 0, fmt.Errorf("チェックポイントの保存に失敗しました: %w", err)
+		}
+		slog.Debug("チェックポイントの番号が衝突したため次の番号を使います", "component", "git", "task", task, "id", id)
+		id++
+	}
+}
+
+// Checkpoints はタスクのチェックポイントを番号順に取得する
+func (r *Repo) Checkpoints(task string) ([]Checkpoint, error) {
+	prefix := CheckpointRefPrefix + task + "/"
+	out, err := r.run("for-each-ref", "--format=%(refname)%00%(objectname)%00%(creatordate:iso-strict)%00%(contents)%00", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("チェックポイントの取得に失敗しました: %w", err)
+	}
+
+	var list []Checkpoint
+	for _, record := range strings.Split(out, "\x00\n") {
+		fields := strings.SplitN(record, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(fields[0], prefix))
+		if err != nil {
+			continue
+		}
+		created, _ := time.Parse(time.RFC3339, fields[2])
+		list = append(list, Checkpoint{
+			ID:        id,
+			Commit:    fields[1],
+			Message:   strings.TrimRight(fields[3], "\n"),
+			CreatedAt: created,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// Checkpoint はタスクの番号のチェックポイントを取得する
+func (r *Repo) Checkpoint(task string, id int) (Checkpoint, error) {
+	list, err := r.Checkpoints(task)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	for _, c := range list {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return Checkpoint{}, fmt.Errorf("%w: %s #%d", ErrCheckpointNotFound, task, id)
+}
+
+// DeleteCheckpoints はタスクのチェックポイントを新しいものからkeep個残して削除し、削除した数を返す
+func (r *Repo) DeleteCheckpoints(task string, keep int) (int, error) {
+	list, err := r.Checkpoints(task)
+	if err != nil {
+		return 0, err
+	}
+	if len(list) <= keep {
+		return 0, nil
+	}
+	old := list[:len(list)-keep]
+	for _, c := range old {
+		if _, err := r.run("update-ref", "-d", checkpointRef(task, c.ID)); err != nil {
+			return 0, fmt.Errorf("チェックポイントの削除に失敗しました: %w", err)
+		}
+	}
+	slog.Info("チェックポイントを削除しました", "component", "git", "task", task, "deleted", len(old))
+	return len(old), nil
+}
+
+// CheckpointStat はfromからtoまでの差分の規模を返す
+// toが空の場合は現在の作業ツリーと比較する
+func (r *Repo) CheckpointStat(from, to string, exclude []string) (DiffStat, error) {
+	to, err := r.resolveTarget(to, exclude)
+	if err != nil {
+		return DiffStat{}, err
+	}
+	out, err := r.run("diff", "--numstat", "--no-renames", from, to)
+	if err != nil {
+		return DiffStat{}, fmt.Errorf("差分の集計に失敗しました: %w", err)
+	}
+	return parseNumstatTotal(out), nil
+}
+
+// CheckpointDiff はfromからtoまでの差分をunified形式で返す
+// toが空の場合は現在の作業ツリーと比較する
+func (r *Repo) CheckpointDiff(from, to string, exclude []string) (string, error) {
+	to, err := r.resolveTarget(to, exclude)
+	if err != nil {
+		return "", err
+	}
+	out, err := r.run("diff", "--no-renames", from, to)
+	if err != nil {
+		return "", fmt.Errorf("差分の取得に失敗しました: %w", err)
+	}
+	return out, nil
+}
+
+// RestoreCheckpoint は作業ツリーをチェックポイントの状態に戻す
+// チェックポイントとの差分があるファイルだけを書き換え、チェックポイントの後に追加されたファイルは削除する
+// インデックス、ブランチとexcludeのパスは変更しない
+func (r *Repo) RestoreCheckpoint(c Checkpoint, exclude []string) error {
+	current, err := r.snapshotTree(exclude)
+	if err != nil {
+		return err
+	}
+	out, err := r.run("diff", "--name-status", "--no-renames", "-z", c.Commit, current)
+	if err != nil {
+		return fmt.Errorf("差分の取得に失敗しました: %w", err)
+	}
+
+	var restore, remove []string
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == "A" {
+			remove = append(remove, fields[i+1])
+		} else {
+			restore = append(restore, fields[i+1])
+		}
+	}
+
+	for _, path := range remove {
+		if err := os.Remove(filepath.Join(r.root, filepath.FromSlash(path))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%s の削除に失敗しました: %w", path, err)
+		}
+	}
+	if len(restore) > 0 {
+		err := r.withTempIndex(func(env []string) error {
+			if _, err := runGit(r.root, env, "read-tree", c.Commit); err != nil {
+				return err
+			}
+			_, err := runGit(r.root, env, append([]string{"checkout-index", "-f", "--"}, restore...)...)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("チェックポイントの復元に失敗しました: %w", err)
+		}
+	}
+	slog.Info("チェックポイントを復元しました", "component", "git", "commit", c.Commit, "restored", len(restore), "removed", len(remove))
+	return nil
+}
+
+// snapshotTree は現在の作業ツリーのtreeオブジェクトを作成する
+func (r *Repo) snapshotTree(exclude []string) (string, error) {
+	var tree string
+	err := r.withTempIndex(func(env []string) error {
+		readArgs := []string{"read-tree", "--empty"}
+		if head, ok := r.head(); ok {
+			readArgs = []string{"read-tree", head}
+		}
+		if _, err := runGit(r.root, env, readArgs...); err != nil {
+			return err
+		}
+		addArgs := []string{"add", "-A", "--", "."}
+		for _, path := range exclude {
+			// 無視するパスを指定するとgit addが失敗する (無視するパスはもともと追加されない)
+			if !r.ignored(path) {
+				addArgs = append(addArgs, ":(exclude)"+path)
+			}
+		}
+		if _, err := runGit(r.root, env, addArgs...); err != nil {
+			return err
+		}
+		// HEADで追跡しているファイルも除外する
+		if len(exclude) > 0 {
+			rmArgs := append([]string{"rm", "-r", "-q", "--cached", "--ignore-unmatch", "--"}, exclude...)
+			if _, err := runGit(r.root, env, rmArgs...); err != nil {
+				return err
+			}
+		}
+		out, err := runGit(r.root, env, "write-tree")
+		tree = strings.TrimSpace(out)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("作業ツリーのスナップショットの作成に失敗しました: %w", err)
+	}
+	return tree, nil
+}
+
+// withTempIndex は一時的なインデックスファイルを使う環境変数でfnを実行する
+func (r *Repo) withTempIndex(fn func(env []string) error) error {
+	dir, err := os.MkdirTemp("", "ccforge-index-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	return fn(append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(dir, "index")))
+}
+
+// resolveTarget は比較先が空の場合に現在の作業ツリーのtreeオブジェクトを返す
+func (r *Repo) resolveTarget(to string, exclude []string) (string, error) {
+	if to != "" {
+		return to, nil
+	}
+	return r.snapshotTree(exclude)
+}
+
+// ignored はパスが .gitignore などで無視されるかを判定する
+func (r *Repo) ignored(path string) bool {
+	_, err := r.run("check-ignore", "-q", "--", path)
+	return err == nil
+}
+
+// head はHEADのコミットを返す (まだコミットがない場合はfalse)
+func (r *Repo) head() (string, bool) {
+	out, err := r.run("rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(out), true
+}
+
+// checkpointRef はタスクの番号のチェックポイントのref名を返す
+func checkpointRef(task string, id int) string {
+	return fmt.Sprintf("%s%s/%d", CheckpointRefPrefix, task, id)
+}
+
+// parseNumstatTotal はgit diff --numstatの出力を合計する
+// バイナリファイルは行数に含めない
+func parseNumstatTotal(out string) DiffStat {
+	var stat DiffStat
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		stat.Files++
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		stat.Insertions += added
+		stat.Deletions += deleted
+	}
+	return stat
+}
@@ -0,0 +1,189 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepo_CreateCheckpoint(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	writeFile(t, dir, "a.txt", "staged\n")
+	gitCmd(t, dir, "add", "a.txt")
+	writeFile(t, dir, "new.txt", "untracked\n")
+	writeFile(t, dir, ".gitignore", "ignored.txt\n")
+	writeFile(t, dir, "ignored.txt", "ignored\n")
+	writeFile(t, dir, "ccforge/auth/tasks.md", "- [ ] spec\n")
+	indexBefore := gitCmd(t, dir, "diff", "--cached", "--name-only")
+	statusBefore := gitCmd(t, dir, "status", "--porcelain")
+
+	cp, err := repo.CreateCheckpoint("auth", "ログインを実装して\n詳細", []string{"ccforge"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, cp.ID)
+	assert.Equal(t, "ログインを実装して", cp.Subject())
+
+	// スナップショットは未追跡のファイルを含み、無視するファイルと除外したパスを含まない
+	files := gitCmd(t, dir, "ls-tree", "-r", "--name-only", cp.Commit)
+	assert.Equal(t, ".gitignore\na.txt\nnew.txt\n", files)
+	assert.Equal(t, "staged\n", gitCmd(t, dir, "show", cp.Commit+":a.txt"))
+	// インデックスとブランチは変わらない
+	assert.Equal(t, indexBefore, gitCmd(t, dir, "diff", "--cached", "--name-only"))
+	assert.Equal(t, statusBefore, gitCmd(t, dir, "status", "--porcelain"))
+	branch, err := repo.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+
+	second, err := repo.CreateCheckpoint("auth", "テストを追加して", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.ID)
+	_, err = repo.CreateCheckpoint("api", "別のタスク", nil)
+	require.NoError(t, err)
+
+	list, err := repo.Checkpoints("auth")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "ログインを実装して\n詳細", list[0].Message)
+	assert.Equal(t, cp.Commit, list[0].Commit)
+	assert.False(t, list[0].CreatedAt.IsZero())
+	assert.Equal(t, 2, list[1].ID)
+
+	got, err := repo.Checkpoint("auth", 2)
+	require.NoError(t, err)
+	assert.Equal(t, second.Commit, got.Commit)
+	_, err = repo.Checkpoint("auth", 3)
+	assert.ErrorIs(t, err, ErrCheckpointNotFound)
+}
+
+func TestRepo_CreateCheckpoint_NoCommits(t *testing.T) {
+	dir := initTestRepo(t)
+	repo, err := Open(dir)
+	require.NoError(t, err)
+	writeFile(t, dir, "a.txt", "a\n")
+
+	cp, err := repo.CreateCheckpoint("auth", "最初のプロンプト", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt\n", gitCmd(t, dir, "ls-tree", "-r", "--name-only", cp.Commit))
+}
+
+func TestRepo_CreateCheckpoint_IgnoredExclude(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	writeFile(t, dir, ".gitignore", "ccforge/\n")
+	writeFile(t, dir, "ccforge/auth/tasks.md", "- [ ] spec\n")
+
+	cp, err := repo.CreateCheckpoint("auth", "無視するディレクトリを除外", []string{"ccforge"})
+	require.NoError(t, err)
+	assert.Equal(t, ".gitignore\na.txt\n", gitCmd(t, dir, "ls-tree", "-r", "--name-only", cp.Commit))
+}
+
+func TestRepo_SaveCheckpointRef(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	head := strings.TrimSpace(gitCmd(t, dir, "rev-parse", "HEAD"))
+	first, err := repo.CreateCheckpoint("auth", "first", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, first.ID)
+
+	// 同時に保存した他のチェックポイントと番号が衝突した場合は上書きせずに次の番号を使う
+	id, err := repo.saveCheckpointRef("auth", 1, head)
+	require.NoError(t, err)
+	assert.Equal(t, 2, id)
+	cp, err := repo.Checkpoint("auth", 1)
+	require.NoError(t, err)
+	assert.Equal(t, first.Commit, cp.Commit)
+	cp, err = repo.Checkpoint("auth", 2)
+	require.NoError(t, err)
+	assert.Equal(t, head, cp.Commit)
+}
+
+func TestRepo_CheckpointDiff(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	first, err := repo.CreateCheckpoint("auth", "1", nil)
+	require.NoError(t, err)
+
+	writeFile(t, dir, "a.txt", "a\nb\nc\n")
+	writeFile(t, dir, "b.txt", "b\n")
+	second, err := repo.CreateCheckpoint("auth", "2", nil)
+	require.NoError(t, err)
+
+	stat, err := repo.CheckpointStat(first.Commit, second.Commit, nil)
+	require.NoError(t, err)
+	assert.Equal(t, DiffStat{Files: 2, Insertions: 3, Deletions: 0}, stat)
+
+	diff, err := repo.CheckpointDiff(first.Commit, second.Commit, nil)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+++ b/b.txt")
+
+	// 比較先を省略した場合は現在の作業ツリーと比較する
+	require.NoError(t, os.Remove(filepath.Join(dir, "b.txt")))
+	stat, err = repo.CheckpointStat(second.Commit, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, DiffStat{Files: 1, Insertions: 0, Deletions: 1}, stat)
+}
+
+func TestParseNumstatTotal(t *testing.T) {
+	assert.Equal(t, DiffStat{Files: 3, Insertions: 5, Deletions: 1}, parseNumstatTotal("3\t1\ta.txt\n2\t0\tb.txt\n-\t-\timage.png\n"))
+	assert.Equal(t, DiffStat{}, parseNumstatTotal(""))
+}
+
+func TestRepo_RestoreCheckpoint(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	writeFile(t, dir, "a.txt", "before\n")
+	writeFile(t, dir, "keep.txt", "keep\n")
+	writeFile(t, dir, "ccforge/auth/tasks.md", "- [ ] spec\n")
+	gitCmd(t, dir, "add", "keep.txt")
+	cp, err := repo.CreateCheckpoint("auth", "変更して", []string{"ccforge"})
+	require.NoError(t, err)
+
+	// プロンプトによる変更
+	writeFile(t, dir, "a.txt", "after\n")
+	writeFile(t, dir, "sub/added.txt", "added\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "keep.txt")))
+	writeFile(t, dir, "ccforge/auth/tasks.md", "- [x] spec\n")
+	indexBefore := gitCmd(t, dir, "diff", "--cached", "--name-only")
+
+	require.NoError(t, repo.RestoreCheckpoint(cp, []string{"ccforge"}))
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", string(content))
+	assert.FileExists(t, filepath.Join(dir, "keep.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "sub/added.txt"))
+	// 除外したパスとインデックスは変わらない
+	content, err = os.ReadFile(filepath.Join(dir, "ccforge/auth/tasks.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "- [x] spec\n", string(content))
+	assert.Equal(t, indexBefore, gitCmd(t, dir, "diff", "--cached", "--name-only"))
+
+	stat, err := repo.CheckpointStat(cp.Commit, "", []string{"ccforge"})
+	require.NoError(t, err)
+	assert.Equal(t, DiffStat{}, stat)
+}
+
+func TestRepo_DeleteCheckpoints(t *testing.T) {
+	repo, _ := openTestRepo(t)
+	for _, msg := range []string{"1", "2", "3"} {
+		_, err := repo.CreateCheckpoint("auth", msg, nil)
+		require.NoError(t, err)
+	}
+
+	deleted, err := repo.DeleteCheckpoints("auth", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	list, err := repo.Checkpoints("auth")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, 2, list[0].ID)
+
+	// 削除後も番号は続きから振る
+	cp, err := repo.CreateCheckpoint("auth", "4", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, cp.ID)
+
+	deleted, err = repo.DeleteCheckpoints("auth", 5)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+}
@@ -0,0 +1,195 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TaskTrailer はコミットとccforgeのタスクを結びつけるトレーラーのキー
+const TaskTrailer = "Ccforge-Task"
+
+// ErrNothingStaged はコミットするステージ済みの変更がない場合のエラー
+var ErrNothingStaged = errors.New("ステージされた変更がありません")
+
+// Stage はファイルの変更 (削除と未追跡のファイルを含む) をステージする
+func (r *Repo) Stage(paths ...string) error {
+	if _, err := r.run(append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return fmt.Errorf("変更のステージに失敗しました: %w", err)
+	}
+	slog.Debug("変更をステージしました", "component", "git", "paths", paths)
+	return nil
+}
+
+// Unstage はファイルのステージを取り消す (作業ツリーは変更しない)
+func (r *Repo) Unstage(paths ...string) error {
+	args := []string{"reset", "-q", "--"}
+	if _, ok := r.head(); !ok {
+		// まだコミットがない場合はインデックスから取り除く
+		args = []string{"rm", "-r", "-q", "--cached", "--"}
+	}
+	if _, err := r.run(append(args, paths...)...); err != nil {
+		return fmt.Errorf("ステージの取り消しに失敗しました: %w", err)
+	}
+	slog.Debug("ステージを取り消しました", "component", "git", "paths", paths)
+	return nil
+}
+
+// StageHunk は作業ツリーの差分のindex番目のハンクだけをステージする
+func (r *Repo) StageHunk(diff *FileDiff, index int) error {
+	if err := r.applyHunk(diff, index, "--cached"); err != nil {
+		return fmt.Errorf("ハンクのステージに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// UnstageHunk はステージ済みの差分のindex番目のハンクだけステージを取り消す
+func (r *Repo) UnstageHunk(diff *FileDiff, index int) error {
+	if err := r.applyHunk(diff, index, "--cached", "-R"); err != nil {
+		return fmt.Errorf("ハンクのステージの取り消しに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// DiscardHunk は作業ツリーの差分のindex番目のハンクだけ作業ツリーの変更を取り消す
+func (r *Repo) DiscardHunk(diff *FileDiff, index int) error {
+	if err := r.applyHunk(diff, index, "-R"); err != nil {
+		return fmt.Errorf("ハンクの取り消しに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Discard はファイルの作業ツリーの変更を取り消してインデックスの状態に戻す
+// 未追跡のファイルは削除する
+func (r *Repo) Discard(change FileChange) error {
+	var err error
+	switch change.Area {
+	case Unstaged:
+		_, err = r.run("checkout", "-q", "--", change.Path)
+	case Untracked:
+		err = os.Remove(filepath.Join(r.root, filepath.FromSlash(change.Path)))
+	default:
+		return fmt.Errorf("作業ツリーの変更ではありません: %s", change.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s の変更の取り消しに失敗しました: %w", change.Path, err)
+	}
+	slog.Debug("変更を取り消しました", "component", "git", "path", change.Path)
+	return nil
+}
+
+// HasStaged はステージ済みの変更があるかを判定する
+func (r *Repo) HasStaged() (bool, error) {
+	// --quiet は差分がある場合に終了コード1を返す
+	_, err := r.run("diff", "--cached", "--quiet")
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) && cmdErr.ExitCode() == 1 {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("ステージ済みの変更の確認に失敗しました: %w", err)
+	}
+	return false, nil
+}
+
+// Commit はステージ済みの変更をmessageでコミットし、コミットの短いハッシュを返す
+// trailersは "キー: 値" の形式でメッセージの末尾に追加する
+// フックが失敗した場合、フックの出力は *CommandError のStderrに含まれる
+func (r *Repo) Commit(message string, trailers ...string) (string, error) {
+	staged, err := r.HasStaged()
+	if err != nil {
+		return "", err
+	}
+	if !staged {
+		return "", ErrNothingStaged
+	}
+
+	file, err := os.CreateTemp("", "ccforge-commit-")
+	if err != nil {
+		return "", fmt.Errorf("コミットメッセージの書き込みに失敗しました: %w", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(message)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("コミットメッセージの書き込みに失敗しました: %w", err)
+	}
+
+	args := []string{"commit", "-q", "-F", file.Name()}
+	for _, trailer := range trailers {
+		args = append(args, "--trailer", trailer)
+	}
+	if _, err := r.run(args...); err != nil {
+		return "", fmt.Errorf("コミットに失敗しました: %w", err)
+	}
+
+	out, err := r.run("rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("コミットの取得に失敗しました: %w", err)
+	}
+	hash := strings.TrimSpace(out)
+	slog.Info("コミットしました", "component", "git", "commit", hash)
+	return hash, nil
+}
+
+// applyHunk はハンクだけのパッチを git apply で適用する
+func (r *Repo) applyHunk(diff *FileDiff, index int, args ...string) error {
+	patch, err := diff.HunkPatch(index)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", "ccforge-hunk-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(patch)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	args = append([]string{"apply", "--whitespace=nowarn"}, args...)
+	if _, err := r.run(append(args, file.Name())...); err != nil {
+		return err
+	}
+	slog.Debug("ハンクを適用しました", "component", "git", "path", diff.NewPath, "hunk", index, "args", args)
+	return nil
+}
+
+// HunkPatch はindex番目のハンクだけを含むパッチを返す
+func (d *FileDiff) HunkPatch(index int) (string, error) {
+	if index < 0 || index >= len(d.Hunks) {
+		return "", fmt.Errorf("ハンクが見つかりません: %d", index)
+	}
+
+	var b strings.Builder
+	for _, line := range d.Header {
+		b.WriteString(line + "\n")
+	}
+	h := d.Hunks[index]
+	b.WriteString(h.Header + "\n")
+	for _, l := range h.Lines {
+		switch l.Kind {
+		case AddedLine:
+			b.WriteString("+")
+		case DeletedLine:
+			b.WriteString("-")
+		default:
+			b.WriteString(" ")
+		}
+		b.WriteString(l.Text + "\n")
+		if l.NoNewline {
+			b.WriteString("\\ No newline at end of file\n")
+		}
+	}
+	return b.String(), nil
+}
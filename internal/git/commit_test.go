@@ -0,0 +1,152 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// twoHunks は2つのハンクに分かれる変更前後の内容
+const (
+	twoHunksBefore = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	twoHunksAfter  = "1\nfirst\n3\n4\n5\n6\n7\n8\n9\n10\nsecond\n12\n"
+)
+
+func TestRepo_StageAndUnstage(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	writeFile(t, dir, "a.txt", "changed\n")
+	writeFile(t, dir, "new.txt", "new\n")
+
+	require.NoError(t, repo.Stage("a.txt", "new.txt"))
+	assert.Equal(t, "a.txt\nnew.txt\n", gitCmd(t, dir, "diff", "--cached", "--name-only"))
+
+	require.NoError(t, repo.Unstage("new.txt"))
+	assert.Equal(t, "a.txt\n", gitCmd(t, dir, "diff", "--cached", "--name-only"))
+	assert.FileExists(t, filepath.Join(dir, "new.txt"))
+}
+
+func TestRepo_Unstage_NoCommits(t *testing.T) {
+	dir := initTestRepo(t)
+	repo, err := Open(dir)
+	require.NoError(t, err)
+	writeFile(t, dir, "a.txt", "a\n")
+	require.NoError(t, repo.Stage("a.txt"))
+
+	require.NoError(t, repo.Unstage("a.txt"))
+	staged, err := repo.HasStaged()
+	require.NoError(t, err)
+	assert.False(t, staged)
+}
+
+func TestRepo_StageHunk(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	commitFile(t, dir, "n.txt", twoHunksBefore)
+	writeFile(t, dir, "n.txt", twoHunksAfter)
+
+	diff, err := repo.Diff(FileChange{Path: "n.txt", Area: Unstaged})
+	require.NoError(t, err)
+	require.Len(t, diff.Hunks, 2)
+
+	// 2番目のハンクだけステージする
+	require.NoError(t, repo.StageHunk(diff, 1))
+	assert.Equal(t, "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\nsecond\n12\n", gitCmd(t, dir, "show", ":n.txt"))
+	content, err := os.ReadFile(filepath.Join(dir, "n.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, twoHunksAfter, string(content))
+
+	// ステージしたハンクを取り消す
+	staged, err := repo.Diff(FileChange{Path: "n.txt", Area: Staged})
+	require.NoError(t, err)
+	require.Len(t, staged.Hunks, 1)
+	require.NoError(t, repo.UnstageHunk(staged, 0))
+	assert.Equal(t, twoHunksBefore, gitCmd(t, dir, "show", ":n.txt"))
+
+	assert.Error(t, repo.StageHunk(diff, 2))
+}
+
+func TestRepo_DiscardHunk(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	commitFile(t, dir, "n.txt", twoHunksBefore)
+	writeFile(t, dir, "n.txt", twoHunksAfter)
+
+	diff, err := repo.Diff(FileChange{Path: "n.txt", Area: Unstaged})
+	require.NoError(t, err)
+	require.NoError(t, repo.DiscardHunk(diff, 0))
+
+	content, err := os.ReadFile(filepath.Join(dir, "n.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\nsecond\n12\n", string(content))
+	// インデックスは変わらない
+	assert.Equal(t, twoHunksBefore, gitCmd(t, dir, "show", ":n.txt"))
+
+	// 未追跡のファイルのハンクを取り消すとファイルを削除する
+	writeFile(t, dir, "new.txt", "new\n")
+	diff, err = repo.Diff(FileChange{Path: "new.txt", Area: Untracked})
+	require.NoError(t, err)
+	require.NoError(t, repo.DiscardHunk(diff, 0))
+	assert.NoFileExists(t, filepath.Join(dir, "new.txt"))
+}
+
+func TestRepo_Discard(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	writeFile(t, dir, "a.txt", "changed\n")
+	writeFile(t, dir, "new.txt", "new\n")
+
+	require.NoError(t, repo.Discard(FileChange{Path: "a.txt", Area: Unstaged}))
+	require.NoError(t, repo.Discard(FileChange{Path: "new.txt", Area: Untracked}))
+	assert.Empty(t, gitCmd(t, dir, "status", "--porcelain"))
+
+	assert.Error(t, repo.Discard(FileChange{Path: "a.txt", Area: Staged}))
+}
+
+func TestFileDiff_HunkPatch_NoNewline(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	writeFile(t, dir, "a.txt", "a\nno newline")
+
+	diff, err := repo.Diff(FileChange{Path: "a.txt", Area: Unstaged})
+	require.NoError(t, err)
+	patch, err := diff.HunkPatch(0)
+	require.NoError(t, err)
+	assert.Contains(t, patch, "+no newline\n\\ No newline at end of file\n")
+
+	require.NoError(t, repo.StageHunk(diff, 0))
+	assert.Equal(t, "a\nno newline", gitCmd(t, dir, "show", ":a.txt"))
+}
+
+func TestRepo_Commit(t *testing.T) {
+	repo, dir := openTestRepo(t)
+
+	_, err := repo.Commit("空のコミット")
+	assert.ErrorIs(t, err, ErrNothingStaged)
+
+	writeFile(t, dir, "a.txt", "changed\n")
+	require.NoError(t, repo.Stage("a.txt"))
+	hash, err := repo.Commit("auth: ログインを実装\n\n- フォームを追加\n", TaskTrailer+": 20261018-auth")
+	require.NoError(t, err)
+	assert.Equal(t, hash+"\n", gitCmd(t, dir, "rev-parse", "--short", "HEAD"))
+	assert.Equal(t, "auth: ログインを実装\n\n- フォームを追加\n\nCcforge-Task: 20261018-auth\n\n", gitCmd(t, dir, "log", "-1", "--format=%B"))
+	assert.Equal(t, "20261018-auth\n\n", gitCmd(t, dir, "log", "-1", "--format=%(trailers:key=Ccforge-Task,valueonly)"))
+}
+
+func TestRepo_Commit_HookFailure(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	hook := filepath.Join(dir, ".git", "hooks", "pre-commit")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\necho 'lint: a.txt に問題があります'\nexit 1\n"), 0o755))
+
+	writeFile(t, dir, "a.txt", "changed\n")
+	require.NoError(t, repo.Stage("a.txt"))
+	_, err := repo.Commit("変更")
+	require.Error(t, err)
+
+	var cmdErr *CommandError
+	require.True(t, errors.As(err, &cmdErr))
+	assert.Contains(t, cmdErr.Stderr, "lint: a.txt に問題があります")
+	// ステージした変更は残る
+	staged, err := repo.HasStaged()
+	require.NoError(t, err)
+	assert.True(t, staged)
+}
@@ -0,0 +1,199 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LineKind は差分行の種類を表す型
+type LineKind int
+
+const (
+	// ContextLine は変更のない文脈行
+	ContextLine LineKind = iota
+	// AddedLine は追加行
+	AddedLine
+	// DeletedLine は削除行
+	DeletedLine
+)
+
+// DiffLine は差分の1行
+type DiffLine struct {
+	Kind      LineKind // 行の種類
+	Text      string   // 先頭の記号を除いた内容
+	OldNo     int      // 変更前の行番号 (追加行は0)
+	NewNo     int      // 変更後の行番号 (削除行は0)
+	NoNewline bool     // ファイル末尾の改行がない行か
+}
+
+// Hunk は差分のハンク
+type Hunk struct {
+	Header   string     // @@ で始まるヘッダー行
+	OldStart int        // 変更前の開始行
+	OldLines int        // 変更前の行数
+	NewStart int        // 変更後の開始行
+	NewLines int        // 変更後の行数
+	Lines    []DiffLine // ハンク内の行
+}
+
+// FileDiff は1ファイル分の差分
+type FileDiff struct {
+	OldPath string   // 変更前のパス
+	NewPath string   // 変更後のパス
+	Header  []string // diff --git から最初のハンクまでのヘッダー行
+	Binary  bool     // バイナリ差分かどうか
+	Hunks   []Hunk   // ハンクの一覧
+}
+
+// Diff は変更に対応する差分を取得する
+func (r *Repo) Diff(change FileChange) (*FileDiff, error) {
+	var out string
+	var err error
+
+	switch change.Area {
+	case Staged:
+		out, err = r.runReadOnly("diff", "--cached", "--no-color", "--", change.Path)
+	case Unstaged:
+		out, err = r.runReadOnly("diff", "--no-color", "--", change.Path)
+	case Untracked:
+		// --no-indexは差分がある場合に終了コード1を返す
+		out, err = r.runReadOnly("diff", "--no-index", "--no-color", "--", "/dev/null", change.Path)
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) && cmdErr.ExitCode() == 1 {
+			err = nil
+		}
+	default:
+		return nil, fmt.Errorf("不明な変更領域です: %d", change.Area)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := ParseDiff(out)
+	if err != nil {
+		return nil, err
+	}
+	if len(diffs) == 0 {
+		return &FileDiff{OldPath: change.Path, NewPath: change.Path}, nil
+	}
+
+	return &diffs[0], nil
+}
+
+// ParseDiff はunified diff形式のテキストを解析する
+func ParseDiff(text string) ([]FileDiff, error) {
+	var diffs []FileDiff
+	var current *FileDiff
+	var hunk *Hunk
+	oldNo, newNo := 0, 0
+
+	lines := strings.Split(text, "\n")
+	// 末尾の改行による空要素を除く
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			diffs = append(diffs, FileDiff{})
+			current = &diffs[len(diffs)-1]
+			current.Header = append(current.Header, line)
+			hunk = nil
+		case current == nil:
+			// diff --git より前の行は無視する
+			continue
+		case strings.HasPrefix(line, "@@"):
+			h, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			current.Hunks = append(current.Hunks, h)
+			hunk = &current.Hunks[len(current.Hunks)-1]
+			oldNo, newNo = h.OldStart, h.NewStart
+		case hunk == nil:
+			parseFileHeader(current, line)
+		case strings.HasPrefix(line, "+"):
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: AddedLine, Text: line[1:], NewNo: newNo})
+			newNo++
+		case strings.HasPrefix(line, "-"):
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: DeletedLine, Text: line[1:], OldNo: oldNo})
+			oldNo++
+		case strings.HasPrefix(line, " ") || line == "":
+			text := ""
+			if line != "" {
+				text = line[1:]
+			}
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: ContextLine, Text: text, OldNo: oldNo, NewNo: newNo})
+			oldNo++
+			newNo++
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" は表示せず、直前の行の印にする
+			if n := len(hunk.Lines); n > 0 {
+				hunk.Lines[n-1].NoNewline = true
+			}
+		}
+	}
+
+	return diffs, nil
+}
+
+// parseFileHeader はハンクより前のヘッダー行を解析する
+func parseFileHeader(d *FileDiff, line string) {
+	d.Header = append(d.Header, line)
+
+	switch {
+	case strings.HasPrefix(line, "--- "):
+		d.OldPath = trimDiffPath(line[4:], "a/")
+	case strings.HasPrefix(line, "+++ "):
+		d.NewPath = trimDiffPath(line[4:], "b/")
+	case strings.HasPrefix(line, "Binary files "):
+		d.Binary = true
+	}
+}
+
+// trimDiffPath はdiffヘッダーのパスからプレフィックスを取り除く
+func trimDiffPath(path, prefix string) string {
+	if path == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// parseHunkHeader は "@@ -a,b +c,d @@" 形式のヘッダーを解析する
+func parseHunkHeader(line string) (Hunk, error) {
+	h := Hunk{Header: line}
+
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return h, fmt.Errorf("不正なハンクヘッダーです: %q", line)
+	}
+
+	var err error
+	h.OldStart, h.OldLines, err = parseRange(strings.TrimPrefix(fields[1], "-"))
+	if err != nil {
+		return h, fmt.Errorf("不正なハンクヘッダーです: %q", line)
+	}
+	h.NewStart, h.NewLines, err = parseRange(strings.TrimPrefix(fields[2], "+"))
+	if err != nil {
+		return h, fmt.Errorf("不正なハンクヘッダーです: %q", line)
+	}
+
+	return h, nil
+}
+
+// parseRange は "start,count" 形式の範囲を解析する (countは省略時1)
+func parseRange(s string) (start, count int, err error) {
+	startText, countText, found := strings.Cut(s, ",")
+	start, err = strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return start, 1, nil
+	}
+	count, err = strconv.Atoi(countText)
+	return start, count, err
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@ package main
+ package main
+-var a = 1
++var a = 2
++var b = 3
+
+\ No newline at end of file
+diff --git a/logo.png b/logo.png
+index 3333333..4444444 100644
+Binary files a/logo.png and b/logo.png differ
+`
+
+func TestParseDiff(t *testing.T) {
+	diffs, err := ParseDiff(sampleDiff)
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+
+	d := diffs[0]
+	assert.Equal(t, "main.go", d.OldPath)
+	assert.Equal(t, "main.go", d.NewPath)
+	assert.False(t, d.Binary)
+	require.Len(t, d.Hunks, 1)
+
+	h := d.Hunks[0]
+	assert.Equal(t, 1, h.OldStart)
+	assert.Equal(t, 3, h.OldLines)
+	assert.Equal(t, 1, h.NewStart)
+	assert.Equal(t, 4, h.NewLines)
+	assert.Equal(t, []DiffLine{
+		{Kind: ContextLine, Text: "package main", OldNo: 1, NewNo: 1},
+		{Kind: DeletedLine, Text: "var a = 1", OldNo: 2},
+		{Kind: AddedLine, Text: "var a = 2", NewNo: 2},
+		{Kind: AddedLine, Text: "var b = 3", NewNo: 3},
+		{Kind: ContextLine, Text: "", OldNo: 3, NewNo: 4, NoNewline: true},
+	}, h.Lines)
+
+	assert.True(t, diffs[1].Binary)
+	assert.Empty(t, diffs[1].Hunks)
+}
+
+func TestParseDiff_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+		wantLen int
+	}{
+		{name: "正常系_空文字列", text: "", wantLen: 0},
+		{name: "正常系_ヘッダー前の行は無視", text: "garbage\n", wantLen: 0},
+		{name: "正常系_行数省略", text: "diff --git a/x b/x\n@@ -1 +1 @@\n-a\n+b\n", wantLen: 1},
+		{name: "異常系_不正なハンクヘッダー", text: "diff --git a/x b/x\n@@ -a,b +c @@\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, err := ParseDiff(tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, diffs, tt.wantLen)
+		})
+	}
+}
+
+func TestRepo_Diff(t *testing.T) {
+	dir := initTestRepo(t)
+	writeFile(t, dir, "a.txt", "one\ntwo\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-q", "-m", "init")
+	writeFile(t, dir, "a.txt", "one\nzwei\n")
+	writeFile(t, dir, "u.txt", "new\n")
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	t.Run("未ステージの差分", func(t *testing.T) {
+		d, err := repo.Diff(FileChange{Path: "a.txt", Area: Unstaged})
+		require.NoError(t, err)
+		require.Len(t, d.Hunks, 1)
+		assert.Equal(t, "a.txt", d.NewPath)
+		assert.Len(t, d.Hunks[0].Lines, 3)
+	})
+
+	t.Run("ステージ済みの差分なし", func(t *testing.T) {
+		d, err := repo.Diff(FileChange{Path: "a.txt", Area: Staged})
+		require.NoError(t, err)
+		assert.Empty(t, d.Hunks)
+	})
+
+	t.Run("未追跡ファイルの差分", func(t *testing.T) {
+		d, err := repo.Diff(FileChange{Path: "u.txt", Area: Untracked})
+		require.NoError(t, err)
+		require.Len(t, d.Hunks, 1)
+		assert.Equal(t, "", d.OldPath)
+		assert.Equal(t, []DiffLine{{Kind: AddedLine, Text: "new", NewNo: 1}}, d.Hunks[0].Lines)
+	})
+}
@@ -0,0 +1,123 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotRepository はディレクトリがGitリポジトリでない場合のエラー
+var ErrNotRepository = errors.New("gitリポジトリではありません")
+
+// Repo はgitコマンドを介してリポジトリを操作する構造体
+type Repo struct {
+	root string // リポジトリのルートディレクトリ
+}
+
+// Open は指定ディレクトリを含むリポジトリを開く
+func Open(dir string) (*Repo, error) {
+	out, err := runGit(dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, dir)
+	}
+
+	return &Repo{root: strings.TrimSpace(out)}, nil
+}
+
+// Root はリポジトリのルートディレクトリを取得する
+func (r *Repo) Root() string {
+	return r.root
+}
+
+// RelPath はパスのリポジトリのルートからの相対パス (区切りは/) を取得する
+// パスがリポジトリの外にある場合はfalseを返す
+func (r *Repo) RelPath(path string) (string, bool) {
+	// パスがまだ存在しない場合も親ディレクトリのシンボリックリンクは解決する
+	resolved := filepath.Join(resolvePath(filepath.Dir(path)), filepath.Base(path))
+	rel, err := filepath.Rel(resolvePath(r.root), resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// run はリポジトリのルートでgitコマンドを実行する
+func (r *Repo) run(args ...string) (string, error) {
+	return runGit(r.root, nil, args...)
+}
+
+// runReadOnly はインデックスを更新せずにgitコマンドを実行する
+// 定期的な状態の取得でindex.lockを取得し、ユーザーの git add や git commit と衝突するのを避ける
+func (r *Repo) runReadOnly(args ...string) (string, error) {
+	return runGit(r.root, append(os.Environ(), "GIT_OPTIONAL_LOCKS=0"), args...)
+}
+
+// runGit はgitコマンドを実行して標準出力を返す
+// 失敗時は標準エラー出力の内容をエラーに含める
+func runGit(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	if env != nil {
+		cmd.Env = env
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	slog.Debug("gitコマンドを実行しました", "component", "git", "args", args, "dir", dir, "duration", time.Since(start), "err", err)
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), &CommandError{Args: args, Stderr: msg, err: err}
+	}
+
+	return stdout.String(), nil
+}
+
+// CommandError はgitコマンドの実行失敗を表すエラー
+type CommandError struct {
+	Args   []string // 実行した引数
+	Stderr string   // 標準エラー出力
+	err    error    // 元のエラー
+}
+
+// Error はエラーメッセージを返す
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("git %s: %s", strings.Join(e.Args, " "), e.Stderr)
+}
+
+// Unwrap は元のエラーを返す
+func (e *CommandError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode はgitコマンドの終了コードを返す (取得できない場合は-1)
+func (e *CommandError) ExitCode() int {
+	var exitErr *exec.ExitError
+	if errors.As(e.err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// CurrentBranch は現在のブランチ名を取得する
+// detached HEADの場合は "HEAD" を返す
+func (r *Repo) CurrentBranch() (string, error) {
+	out, err := r.run("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,122 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initTestRepo はテスト用の一時リポジトリを作成する
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("gitコマンドが見つかりません")
+	}
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q", "-b", "main")
+	gitCmd(t, dir, "config", "user.name", "ccforge test")
+	gitCmd(t, dir, "config", "user.email", "test@example.com")
+	gitCmd(t, dir, "config", "commit.gpgsign", "false")
+
+	return dir
+}
+
+// gitCmd はテスト用にgitコマンドを実行する
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	out, err := runGit(dir, nil, args...)
+	require.NoError(t, err)
+	return out
+}
+
+// writeFile はテスト用にファイルを書き込む
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("正常系_サブディレクトリから開く", func(t *testing.T) {
+		dir := initTestRepo(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+
+		repo, err := Open(filepath.Join(dir, "sub"))
+		require.NoError(t, err)
+
+		want, err := filepath.EvalSymlinks(dir)
+		require.NoError(t, err)
+		assert.Equal(t, want, repo.Root())
+	})
+
+	t.Run("異常系_リポジトリ外", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("gitコマンドが見つかりません")
+		}
+		t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+
+		_, err := Open(t.TempDir())
+		assert.ErrorIs(t, err, ErrNotRepository)
+	})
+}
+
+func TestRepo_RelPath(t *testing.T) {
+	dir := initTestRepo(t)
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{name: "存在しないパス", path: filepath.Join(dir, "ccforge"), want: "ccforge", wantOK: true},
+		{name: "サブディレクトリ", path: filepath.Join(dir, "sub", "ccforge"), want: "sub/ccforge", wantOK: true},
+		{name: "リポジトリの外", path: filepath.Join(filepath.Dir(dir), "other", "ccforge"), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := repo.RelPath(tt.path)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCommandError_ExitCode(t *testing.T) {
+	dir := initTestRepo(t)
+
+	_, err := runGit(dir, nil, "rev-parse", "--verify", "no-such-ref")
+	require.Error(t, err)
+
+	var cmdErr *CommandError
+	require.ErrorAs(t, err, &cmdErr)
+	assert.NotEqual(t, 0, cmdErr.ExitCode())
+	assert.Contains(t, cmdErr.Error(), "git rev-parse")
+}
+
+func TestRepo_CurrentBranch(t *testing.T) {
+	dir := initTestRepo(t)
+	writeFile(t, dir, "a.txt", "a\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-q", "-m", "init")
+	gitCmd(t, dir, "checkout", "-q", "-b", "task/login")
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	branch, err := repo.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "task/login", branch)
+}
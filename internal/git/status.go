@@ -0,0 +1,217 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Area は変更が存在する領域を表す型
+type Area int
+
+const (
+	// Staged はインデックスにステージ済みの変更
+	Staged Area = iota
+	// Unstaged はワーキングツリー上の未ステージの変更
+	Unstaged
+	// Untracked は未追跡のファイル
+	Untracked
+)
+
+const (
+	// binarySniffSize はバイナリ判定のために読み込むバイト数
+	binarySniffSize = 8000
+	// maxLineCountSize は未追跡ファイルの行数を数える最大サイズ
+	maxLineCountSize = 4 << 20
+)
+
+// String は領域の表示名を返す
+func (a Area) String() string {
+	switch a {
+	case Staged:
+		return "ステージ済み"
+	case Unstaged:
+		return "変更"
+	case Untracked:
+		return "未追跡"
+	default:
+		return "不明"
+	}
+}
+
+// FileChange は1ファイル分の変更情報
+type FileChange struct {
+	Path     string // リポジトリルートからの相対パス
+	OrigPath string // リネーム元のパス (リネーム時のみ)
+	Area     Area   // 変更の領域
+	Status   byte   // porcelain形式のステータス文字 (M, A, D, R, ?など)
+	Added    int    // 追加行数
+	Deleted  int    // 削除行数
+	Binary   bool   // バイナリファイルかどうか
+	Size     int64  // ワーキングツリー上のファイルサイズ (削除時は0)
+}
+
+// Changes はステージ済み・未ステージ・未追跡の変更一覧を取得する
+func (r *Repo) Changes() ([]FileChange, error) {
+	out, err := r.runReadOnly("status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	staged, err := r.numstat(true)
+	if err != nil {
+		return nil, err
+	}
+	unstaged, err := r.numstat(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+
+		x, y := entry[0], entry[1]
+		path := entry[3:]
+		origPath := ""
+		// リネーム・コピーの場合は次の要素が元のパス
+		if x == 'R' || x == 'C' {
+			if i+1 < len(entries) {
+				origPath = entries[i+1]
+				i++
+			}
+		}
+
+		if x == '?' {
+			changes = append(changes, r.untrackedChange(path))
+			continue
+		}
+		if x == '!' {
+			continue
+		}
+
+		if x != ' ' {
+			c := FileChange{Path: path, OrigPath: origPath, Area: Staged, Status: x}
+			applyNumstat(&c, staged[path])
+			changes = append(changes, c)
+		}
+		if y != ' ' {
+			c := FileChange{Path: path, Area: Unstaged, Status: y}
+			applyNumstat(&c, unstaged[path])
+			changes = append(changes, c)
+		}
+	}
+
+	for i := range changes {
+		if info, err := os.Stat(filepath.Join(r.root, changes[i].Path)); err == nil {
+			changes[i].Size = info.Size()
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Area != changes[j].Area {
+			return changes[i].Area < changes[j].Area
+		}
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+// numstatEntry はgit diff --numstatの1行分
+type numstatEntry struct {
+	added   int
+	deleted int
+	binary  bool
+}
+
+// applyNumstat は行数情報を変更に反映する
+func applyNumstat(c *FileChange, n numstatEntry) {
+	c.Added = n.added
+	c.Deleted = n.deleted
+	c.Binary = n.binary
+}
+
+// numstat はパスごとの追加・削除行数を取得する
+func (r *Repo) numstat(cached bool) (map[string]numstatEntry, error) {
+	args := []string{"diff", "--numstat", "-z"}
+	if cached {
+		args = append(args, "--cached")
+	}
+
+	out, err := r.runReadOnly(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]numstatEntry)
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		entry := numstatEntry{}
+		if parts[0] == "-" && parts[1] == "-" {
+			entry.binary = true
+		} else {
+			entry.added, _ = strconv.Atoi(parts[0])
+			entry.deleted, _ = strconv.Atoi(parts[1])
+		}
+
+		path := parts[2]
+		// リネームの場合はパスが空で、続く2要素が元と先のパス
+		if path == "" && i+2 < len(fields) {
+			path = fields[i+2]
+			i += 2
+		}
+		result[path] = entry
+	}
+
+	return result, nil
+}
+
+// untrackedChange は未追跡ファイルの変更情報を作成する
+func (r *Repo) untrackedChange(path string) FileChange {
+	c := FileChange{Path: path, Area: Untracked, Status: '?'}
+
+	fullPath := filepath.Join(r.root, path)
+	info, err := os.Stat(fullPath)
+	if err != nil || info.Size() > maxLineCountSize {
+		// 大きすぎるファイルは行数を数えない
+		return c
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return c
+	}
+
+	if isBinary(data) {
+		c.Binary = true
+		return c
+	}
+
+	c.Added = bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		c.Added++
+	}
+
+	return c
+}
+
+// isBinary は内容にNULバイトが含まれるかでバイナリかどうかを判定する
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffSize {
+		data = data[:binarySniffSize]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepo_Changes(t *testing.T) {
+	dir := initTestRepo(t)
+	writeFile(t, dir, "a.txt", "one\ntwo\nthree\n")
+	writeFile(t, dir, "b.txt", "keep\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-q", "-m", "init")
+
+	// 未ステージの変更
+	writeFile(t, dir, "a.txt", "one\n2\nthree\nfour\n")
+	// ステージ済みの変更
+	writeFile(t, dir, "b.txt", "keep\nstaged\n")
+	gitCmd(t, dir, "add", "b.txt")
+	// 未追跡ファイル
+	writeFile(t, dir, "new/c.txt", "x\ny")
+	// バイナリファイル
+	writeFile(t, dir, "bin.dat", "a\x00b")
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	changes, err := repo.Changes()
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+
+	assert.Equal(t, FileChange{Path: "b.txt", Area: Staged, Status: 'M', Added: 1, Size: 12}, changes[0])
+	assert.Equal(t, FileChange{Path: "a.txt", Area: Unstaged, Status: 'M', Added: 2, Deleted: 1, Size: 17}, changes[1])
+	assert.Equal(t, "bin.dat", changes[2].Path)
+	assert.True(t, changes[2].Binary)
+	assert.Equal(t, FileChange{Path: "new/c.txt", Area: Untracked, Status: '?', Added: 2, Size: 3}, changes[3])
+}
+
+func TestRepo_Changes_Clean(t *testing.T) {
+	dir := initTestRepo(t)
+	writeFile(t, dir, "a.txt", "one\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-q", "-m", "init")
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	changes, err := repo.Changes()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestArea_String(t *testing.T) {
+	tests := []struct {
+		area Area
+		want string
+	}{
+		{Staged, "ステージ済み"},
+		{Unstaged, "変更"},
+		{Untracked, "未追跡"},
+		{Area(99), "不明"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.area.String())
+	}
+}
@@ -0,0 +1,171 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// State はリポジトリで進行中の操作を表す型
+type State int
+
+const (
+	// StateNone は進行中の操作がない状態
+	StateNone State = iota
+	// StateRebase はリベースの途中
+	StateRebase
+	// StateMerge はマージの途中
+	StateMerge
+	// StateCherryPick はcherry-pickの途中
+	StateCherryPick
+	// StateRevert はrevertの途中
+	StateRevert
+	// StateBisect はbisectの途中
+	StateBisect
+)
+
+// String は操作の表示名を返す
+func (s State) String() string {
+	switch s {
+	case StateNone:
+		return ""
+	case StateRebase:
+		return "REBASE"
+	case StateMerge:
+		return "MERGE"
+	case StateCherryPick:
+		return "CHERRY-PICK"
+	case StateRevert:
+		return "REVERT"
+	case StateBisect:
+		return "BISECT"
+	default:
+		return "不明"
+	}
+}
+
+// stateMarkers はgitディレクトリ内で進行中の操作を示すファイルと操作の対応 (先にあるものを優先)
+var stateMarkers = []struct {
+	name  string
+	state State
+}{
+	{"rebase-merge", StateRebase},
+	{"rebase-apply", StateRebase},
+	{"MERGE_HEAD", StateMerge},
+	{"CHERRY_PICK_HEAD", StateCherryPick},
+	{"REVERT_HEAD", StateRevert},
+	{"BISECT_LOG", StateBisect},
+}
+
+// Summary はリポジトリの状態の概要
+type Summary struct {
+	Branch     string // 現在のブランチ名 (detached HEADの場合は空)
+	Head       string // HEADのコミットの短縮ハッシュ (コミットがない場合は空)
+	Detached   bool   // detached HEADかどうか
+	Upstream   string // 追跡しているブランチ (未設定の場合は空)
+	Ahead      int    // 追跡しているブランチより進んでいるコミット数
+	Behind     int    // 追跡しているブランチより遅れているコミット数
+	Staged     int    // ステージ済みのファイル数
+	Unstaged   int    // 未ステージの変更があるファイル数
+	Untracked  int    // 未追跡のファイル数
+	Conflicted int    // コンフリクトしているファイル数
+	State      State  // 進行中の操作
+}
+
+// Dirty はワーキングツリーかインデックスに変更があるかを返す
+func (s Summary) Dirty() bool {
+	return s.Staged+s.Unstaged+s.Untracked+s.Conflicted > 0
+}
+
+// Summary はブランチ、追跡しているブランチとの差、変更の数、進行中の操作を取得する
+func (r *Repo) Summary() (Summary, error) {
+	out, err := r.runReadOnly("status", "--porcelain=v2", "--branch", "-z", "--untracked-files=all")
+	if err != nil {
+		return Summary{}, err
+	}
+	s := parseStatusV2(out)
+
+	gitDir, err := r.run("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return Summary{}, err
+	}
+	s.State = detectState(strings.TrimSpace(gitDir))
+
+	return s, nil
+}
+
+// parseStatusV2 はgit status --porcelain=v2 --branch -z の出力を解析する
+func parseStatusV2(out string) Summary {
+	var s Summary
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 2 {
+			continue
+		}
+
+		switch entry[0] {
+		case '#':
+			parseBranchHeader(&s, entry)
+		case '1', '2':
+			if len(entry) < 4 {
+				continue
+			}
+			if entry[2] != '.' {
+				s.Staged++
+			}
+			if entry[3] != '.' {
+				s.Unstaged++
+			}
+			// リネーム・コピーの場合は次の要素が元のパス
+			if entry[0] == '2' {
+				i++
+			}
+		case 'u':
+			s.Conflicted++
+		case '?':
+			s.Untracked++
+		}
+	}
+	return s
+}
+
+// parseBranchHeader はブランチの情報の行を解析する
+func parseBranchHeader(s *Summary, line string) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return
+	}
+
+	switch fields[1] {
+	case "branch.oid":
+		if fields[2] != "(initial)" && len(fields[2]) >= 7 {
+			s.Head = fields[2][:7]
+		}
+	case "branch.head":
+		if fields[2] == "(detached)" {
+			s.Detached = true
+		} else {
+			s.Branch = fields[2]
+		}
+	case "branch.upstream":
+		s.Upstream = fields[2]
+	case "branch.ab":
+		if len(fields) < 4 {
+			return
+		}
+		s.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+		s.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+	}
+}
+
+// detectState はgitディレクトリ内のファイルから進行中の操作を判定する
+func detectState(gitDir string) State {
+	for _, m := range stateMarkers {
+		if _, err := os.Stat(filepath.Join(gitDir, m.name)); err == nil {
+			return m.state
+		}
+	}
+	return StateNone
+}
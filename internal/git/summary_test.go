@@ -0,0 +1,180 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitFile はファイルを書き込んでコミットする
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	writeFile(t, dir, name, content)
+	gitCmd(t, dir, "add", name)
+	gitCmd(t, dir, "commit", "-q", "-m", "update "+name)
+}
+
+// openSummary はリポジトリの概要を取得する
+func openSummary(t *testing.T, dir string) Summary {
+	t.Helper()
+	repo, err := Open(dir)
+	require.NoError(t, err)
+	s, err := repo.Summary()
+	require.NoError(t, err)
+	return s
+}
+
+func TestRepo_Summary(t *testing.T) {
+	t.Run("コミットのないリポジトリ", func(t *testing.T) {
+		dir := initTestRepo(t)
+		writeFile(t, dir, "a.txt", "a\n")
+
+		assert.Equal(t, Summary{Branch: "main", Untracked: 1}, openSummary(t, dir))
+	})
+
+	t.Run("変更の数", func(t *testing.T) {
+		dir := initTestRepo(t)
+		commitFile(t, dir, "a.txt", "a\n")
+		commitFile(t, dir, "b.txt", "b\n")
+		commitFile(t, dir, "c.txt", "c\n")
+
+		writeFile(t, dir, "a.txt", "staged\n")
+		gitCmd(t, dir, "add", "a.txt")
+		writeFile(t, dir, "a.txt", "staged and modified\n")
+		writeFile(t, dir, "b.txt", "modified\n")
+		gitCmd(t, dir, "mv", "c.txt", "d.txt")
+		writeFile(t, dir, "new/e.txt", "e\n")
+
+		s := openSummary(t, dir)
+		assert.Equal(t, "main", s.Branch)
+		assert.Len(t, s.Head, 7)
+		assert.Equal(t, 2, s.Staged)
+		assert.Equal(t, 2, s.Unstaged)
+		assert.Equal(t, 1, s.Untracked)
+		assert.True(t, s.Dirty())
+	})
+
+	t.Run("追跡しているブランチとの差", func(t *testing.T) {
+		remote := initTestRepo(t)
+		commitFile(t, remote, "a.txt", "a\n")
+		dir := filepath.Join(t.TempDir(), "clone")
+		gitCmd(t, remote, "clone", "-q", remote, dir)
+		gitCmd(t, dir, "config", "user.name", "ccforge test")
+		gitCmd(t, dir, "config", "user.email", "test@example.com")
+		gitCmd(t, dir, "config", "commit.gpgsign", "false")
+
+		commitFile(t, remote, "b.txt", "b\n")
+		gitCmd(t, dir, "fetch", "-q")
+		commitFile(t, dir, "c.txt", "c\n")
+		commitFile(t, dir, "d.txt", "d\n")
+
+		s := openSummary(t, dir)
+		assert.Equal(t, "origin/main", s.Upstream)
+		assert.Equal(t, 2, s.Ahead)
+		assert.Equal(t, 1, s.Behind)
+		assert.False(t, s.Dirty())
+	})
+
+	t.Run("detached HEAD", func(t *testing.T) {
+		dir := initTestRepo(t)
+		commitFile(t, dir, "a.txt", "a\n")
+		gitCmd(t, dir, "checkout", "-q", "--detach")
+
+		s := openSummary(t, dir)
+		assert.True(t, s.Detached)
+		assert.Empty(t, s.Branch)
+		assert.Len(t, s.Head, 7)
+	})
+
+	t.Run("マージのコンフリクト", func(t *testing.T) {
+		dir := initTestRepo(t)
+		commitFile(t, dir, "a.txt", "base\n")
+		gitCmd(t, dir, "checkout", "-q", "-b", "topic")
+		commitFile(t, dir, "a.txt", "topic\n")
+		gitCmd(t, dir, "checkout", "-q", "main")
+		commitFile(t, dir, "a.txt", "main\n")
+		_, err := runGit(dir, nil, "merge", "-q", "topic")
+		require.Error(t, err)
+
+		s := openSummary(t, dir)
+		assert.Equal(t, StateMerge, s.State)
+		assert.Equal(t, 1, s.Conflicted)
+	})
+
+	t.Run("リベースの途中", func(t *testing.T) {
+		dir := initTestRepo(t)
+		commitFile(t, dir, "a.txt", "base\n")
+		gitCmd(t, dir, "checkout", "-q", "-b", "topic")
+		commitFile(t, dir, "a.txt", "topic\n")
+		gitCmd(t, dir, "checkout", "-q", "main")
+		commitFile(t, dir, "a.txt", "main\n")
+		gitCmd(t, dir, "checkout", "-q", "topic")
+		_, err := runGit(dir, nil, "rebase", "main")
+		require.Error(t, err)
+
+		s := openSummary(t, dir)
+		assert.Equal(t, StateRebase, s.State)
+		assert.True(t, s.Detached)
+	})
+}
+
+func TestParseStatusV2(t *testing.T) {
+	out := "# branch.oid 0123456789abcdef\x00# branch.head main\x00# branch.upstream origin/main\x00# branch.ab +3 -1\x00" +
+		"1 M. N... 100644 100644 100644 aaa bbb a.txt\x00" +
+		"1 .M N... 100644 100644 100644 aaa bbb b.txt\x00" +
+		"2 R. N... 100644 100644 100644 aaa bbb R100 d.txt\x00c.txt\x00" +
+		"u UU N... 100644 100644 100644 100644 aaa bbb ccc e.txt\x00" +
+		"? f.txt\x00"
+
+	assert.Equal(t, Summary{
+		Branch:     "main",
+		Head:       "0123456",
+		Upstream:   "origin/main",
+		Ahead:      3,
+		Behind:     1,
+		Staged:     2,
+		Unstaged:   1,
+		Untracked:  1,
+		Conflicted: 1,
+	}, parseStatusV2(out))
+}
+
+func TestState_String(t *testing.T) {
+	tests := []struct {
+		state State
+		want  string
+	}{
+		{StateNone, ""},
+		{StateRebase, "REBASE"},
+		{StateMerge, "MERGE"},
+		{StateCherryPick, "CHERRY-PICK"},
+		{StateRevert, "REVERT"},
+		{StateBisect, "BISECT"},
+		{State(99), "不明"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.state.String())
+	}
+}
+
+func TestRepo_SummaryKeepsIndex(t *testing.T) {
+	dir := initTestRepo(t)
+	commitFile(t, dir, "a.txt", "a\n")
+	// 内容を変えずに更新日時だけを変えると、git status はインデックスの更新を試みる
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "a.txt"), later, later))
+	index := filepath.Join(dir, ".git", "index")
+	before, err := os.ReadFile(index)
+	require.NoError(t, err)
+
+	assert.False(t, openSummary(t, dir).Dirty())
+
+	after, err := os.ReadFile(index)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
@@ -0,0 +1,156 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+// ErrWorktreeDirty はコミットしていない変更があるためworktreeを削除できない場合のエラー
+var ErrWorktreeDirty = errors.New("worktreeにコミットしていない変更があります")
+
+// Worktree はリポジトリに登録されたworktree
+type Worktree struct {
+	Path     string // worktreeのディレクトリ
+	Head     string // チェックアウト中のコミット
+	Branch   string // チェックアウト中のブランチ (detached HEADの場合は空)
+	Main     bool   // メインのworktree (リポジトリ本体) か
+	Locked   bool   // ロックされているか
+	Prunable bool   // ディレクトリがなく削除できる状態か
+}
+
+// Worktrees は登録されているworktreeを取得する
+// 先頭はメインのworktree
+func (r *Repo) Worktrees() ([]Worktree, error) {
+	out, err := r.run("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("worktreeの一覧の取得に失敗しました: %w", err)
+	}
+	return parseWorktrees(out), nil
+}
+
+// parseWorktrees はgit worktree list --porcelainの出力を解析する
+func parseWorktrees(out string) []Worktree {
+	var worktrees []Worktree
+	for _, block := range strings.Split(strings.TrimSpace(out), "\n\n") {
+		var w Worktree
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				w.Path = value
+			case "HEAD":
+				w.Head = value
+			case "branch":
+				w.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "locked":
+				w.Locked = true
+			case "prunable":
+				w.Prunable = true
+			}
+		}
+		if w.Path == "" {
+			continue
+		}
+		w.Main = len(worktrees) == 0
+		worktrees = append(worktrees, w)
+	}
+	return worktrees
+}
+
+// AddWorktree はpathにworktreeを作成してbranchをチェックアウトする
+// branchが存在しない場合は現在のHEADから作成する
+// 他のworktreeでチェックアウト中のブランチはgitが拒否する
+func (r *Repo) AddWorktree(path, branch string) error {
+	exists, err := r.BranchExists(branch)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"worktree", "add", "-q"}
+	if exists {
+		args = append(args, path, branch)
+	} else {
+		args = append(args, "-b", branch, path)
+	}
+	if _, err := r.run(args...); err != nil {
+		return fmt.Errorf("worktree %s の作成に失敗しました: %w", path, err)
+	}
+	slog.Info("worktreeを作成しました", "component", "git", "path", path, "branch", branch, "new_branch", !exists)
+	return nil
+}
+
+// RemoveWorktree はworktreeを削除する
+// コミットしていない変更や未追跡のファイルがある場合はforceを指定しない限りErrWorktreeDirtyを返す
+// ブランチは削除しない
+func (r *Repo) RemoveWorktree(path string, force bool) error {
+	if !force {
+		wt, err := Open(path)
+		if err == nil {
+			s, err := wt.Summary()
+			if err != nil {
+				return err
+			}
+			if s.Dirty() {
+				return fmt.Errorf("%w: %s", ErrWorktreeDirty, path)
+			}
+		}
+	}
+
+	args := []string{"worktree", "remove", path}
+	if force {
+		args = []string{"worktree", "remove", "--force", path}
+	}
+	if _, err := r.run(args...); err != nil {
+		return fmt.Errorf("worktree %s の削除に失敗しました: %w", path, err)
+	}
+	slog.Info("worktreeを削除しました", "component", "git", "path", path, "force", force)
+	return nil
+}
+
+// PruneWorktrees はディレクトリが削除されたworktreeの登録を取り除き、取り除いたworktreeを返す
+func (r *Repo) PruneWorktrees() ([]Worktree, error) {
+	before, err := r.Worktrees()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.run("worktree", "prune"); err != nil {
+		return nil, fmt.Errorf("worktreeの整理に失敗しました: %w", err)
+	}
+	after, err := r.Worktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := make(map[string]bool, len(after))
+	for _, w := range after {
+		remaining[w.Path] = true
+	}
+	var pruned []Worktree
+	for _, w := range before {
+		if !remaining[w.Path] {
+			pruned = append(pruned, w)
+		}
+	}
+	slog.Info("worktreeを整理しました", "component", "git", "pruned", len(pruned))
+	return pruned, nil
+}
+
+// SamePath は2つのパスが同じディレクトリを指すかを返す
+// シンボリックリンクを解決して比較する (解決できない場合はパスをそのまま比較する)
+func SamePath(a, b string) bool {
+	return resolvePath(a) == resolvePath(b)
+}
+
+// resolvePath はパスを絶対パスにしてシンボリックリンクを解決する
+func resolvePath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		return resolved
+	}
+	return filepath.Clean(p)
+}
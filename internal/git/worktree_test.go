@@ -0,0 +1,141 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorktrees(t *testing.T) {
+	out := "worktree /repo\nHEAD 1111111111111111111111111111111111111111\nbranch refs/heads/main\n\n" +
+		"worktree /wt/auth\nHEAD 2222222222222222222222222222222222222222\nbranch refs/heads/task/auth\nlocked\n\n" +
+		"worktree /wt/gone\nHEAD 3333333333333333333333333333333333333333\ndetached\nprunable gitdir file points to non-existent location\n"
+
+	got := parseWorktrees(out)
+	assert.Equal(t, []Worktree{
+		{Path: "/repo", Head: "1111111111111111111111111111111111111111", Branch: "main", Main: true},
+		{Path: "/wt/auth", Head: "2222222222222222222222222222222222222222", Branch: "task/auth", Locked: true},
+		{Path: "/wt/gone", Head: "3333333333333333333333333333333333333333", Prunable: true},
+	}, got)
+	assert.Empty(t, parseWorktrees(""))
+}
+
+func TestRepo_AddWorktree(t *testing.T) {
+	repo, dir := openTestRepo(t)
+	base := t.TempDir()
+	gitCmd(t, dir, "branch", "task/existing")
+
+	// 新しいブランチ
+	newPath := filepath.Join(base, "auth")
+	require.NoError(t, repo.AddWorktree(newPath, "task/auth"))
+	assert.FileExists(t, filepath.Join(newPath, "a.txt"))
+	exists, err := repo.BranchExists("task/auth")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// 既存のブランチ
+	existingPath := filepath.Join(base, "existing")
+	require.NoError(t, repo.AddWorktree(existingPath, "task/existing"))
+
+	// チェックアウト中のブランチは拒否される
+	assert.Error(t, repo.AddWorktree(filepath.Join(base, "main"), "main"))
+
+	worktrees, err := repo.Worktrees()
+	require.NoError(t, err)
+	require.Len(t, worktrees, 3)
+	assert.True(t, worktrees[0].Main)
+	assert.True(t, SamePath(dir, worktrees[0].Path))
+	assert.Equal(t, "main", worktrees[0].Branch)
+	assert.True(t, SamePath(newPath, worktrees[1].Path))
+	assert.Equal(t, "task/auth", worktrees[1].Branch)
+	assert.Equal(t, "task/existing", worktrees[2].Branch)
+
+	// メインのworktreeのブランチは変わらない
+	branch, err := repo.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+}
+
+func TestRepo_RemoveWorktree(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, path string)
+		force   bool
+		wantErr error
+	}{
+		{name: "変更なし"},
+		{
+			name:    "未ステージの変更",
+			setup:   func(t *testing.T, path string) { writeFile(t, path, "a.txt", "dirty\n") },
+			wantErr: ErrWorktreeDirty,
+		},
+		{
+			name:    "未追跡のファイル",
+			setup:   func(t *testing.T, path string) { writeFile(t, path, "new.txt", "x\n") },
+			wantErr: ErrWorktreeDirty,
+		},
+		{
+			name:  "forceで変更ごと削除",
+			setup: func(t *testing.T, path string) { writeFile(t, path, "a.txt", "dirty\n") },
+			force: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _ := openTestRepo(t)
+			path := filepath.Join(t.TempDir(), "auth")
+			require.NoError(t, repo.AddWorktree(path, "task/auth"))
+			if tt.setup != nil {
+				tt.setup(t, path)
+			}
+
+			err := repo.RemoveWorktree(path, tt.force)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.DirExists(t, path)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoDirExists(t, path)
+
+			// ブランチは残す
+			exists, err := repo.BranchExists("task/auth")
+			require.NoError(t, err)
+			assert.True(t, exists)
+		})
+	}
+}
+
+func TestRepo_PruneWorktrees(t *testing.T) {
+	repo, _ := openTestRepo(t)
+	base := t.TempDir()
+	kept := filepath.Join(base, "kept")
+	gone := filepath.Join(base, "gone")
+	require.NoError(t, repo.AddWorktree(kept, "task/kept"))
+	require.NoError(t, repo.AddWorktree(gone, "task/gone"))
+	require.NoError(t, os.RemoveAll(gone))
+
+	worktrees, err := repo.Worktrees()
+	require.NoError(t, err)
+	require.Len(t, worktrees, 3)
+	for _, w := range worktrees {
+		assert.Equal(t, w.Branch == "task/gone", w.Prunable, w.Path)
+	}
+
+	pruned, err := repo.PruneWorktrees()
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, "task/gone", pruned[0].Branch)
+
+	worktrees, err = repo.Worktrees()
+	require.NoError(t, err)
+	assert.Len(t, worktrees, 2)
+
+	pruned, err = repo.PruneWorktrees()
+	require.NoError(t, err)
+	assert.Empty(t, pruned)
+}
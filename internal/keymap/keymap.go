@@ -0,0 +1,296 @@
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Context はキーバインドが有効になるフォーカスの範囲
+type Context string
+
+const (
+	// Global はどのフォーカスでも有効なキーバインド
+	Global Context = "global"
+	// Input はプロンプト入力中のキーバインド
+	Input Context = "input"
+	// Sidebar は差分パネルなどのサイドパネル表示中のキーバインド
+	Sidebar Context = "sidebar"
+	// Modal はダイアログ表示中のキーバインド
+	Modal Context = "modal"
+)
+
+// Contexts はすべてのコンテキストを定義順に並べたもの
+var Contexts = []Context{Global, Input, Sidebar, Modal}
+
+// ParseContext は名前からコンテキストを取得する
+func ParseContext(name string) (Context, bool) {
+	for _, ctx := range Contexts {
+		if string(ctx) == name {
+			return ctx, true
+		}
+	}
+	return "", false
+}
+
+// parent は見つからなかったキーを探す親のコンテキストを返す
+// ダイアログ表示中はグローバルのキーバインドを使わない
+func (c Context) parent() (Context, bool) {
+	switch c {
+	case Input, Sidebar:
+		return Global, true
+	default:
+		return "", false
+	}
+}
+
+// Bindings はコンテキストごとの 操作ID → キー の割り当て
+// キーが空の操作は割り当てを解除する
+type Bindings map[Context]map[string][]string
+
+// modifiers はキーの修飾子
+var modifiers = map[string]bool{"ctrl": true, "alt": true, "shift": true}
+
+// namedKeys は1文字以外のキー名
+var namedKeys = func() map[string]bool {
+	names := map[string]bool{
+		"enter": true, "tab": true, "backspace": true, "delete": true, "esc": true,
+		"up": true, "down": true, "left": true, "right": true,
+		"home": true, "end": true, "pgup": true, "pgdown": true, "insert": true,
+	}
+	for i := 1; i <= 20; i++ {
+		names[fmt.Sprintf("f%d", i)] = true
+	}
+	return names
+}()
+
+// Normalize はキーの表記をBubble Teaのキー文字列に揃える
+// 例: "Ctrl+C" → "ctrl+c", "space" → " "
+func Normalize(key string) (string, error) {
+	if key == " " {
+		return key, nil
+	}
+
+	parts := strings.Split(key, "+")
+	for i, part := range parts[:len(parts)-1] {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if !modifiers[part] {
+			return "", fmt.Errorf("不明な修飾キーです: %q", key)
+		}
+		parts[i] = part
+	}
+
+	name := parts[len(parts)-1]
+	lower := strings.ToLower(name)
+	switch {
+	case lower == "space":
+		name = " "
+	case namedKeys[lower]:
+		name = lower
+	case utf8.RuneCountInString(name) == 1:
+		if len(parts) > 1 {
+			name = lower
+		}
+	default:
+		return "", fmt.Errorf("不明なキーです: %q", key)
+	}
+	parts[len(parts)-1] = name
+	return strings.Join(parts, "+"), nil
+}
+
+// isPrintable は文字として入力されるキーか判定する
+func isPrintable(key string) bool {
+	return key == " " || (utf8.RuneCountInString(key) == 1 && !strings.Contains(key, "+")) ||
+		(strings.HasPrefix(key, "shift+") && utf8.RuneCountInString(strings.TrimPrefix(key, "shift+")) == 1)
+}
+
+// Display はキーを表示用の表記に変換する
+// 例: "ctrl+c" → "Ctrl+C", "pgdown" → "PgDown", " " → "Space"
+func Display(key string) string {
+	if key == " " {
+		return "Space"
+	}
+
+	parts := strings.Split(key, "+")
+	for i, part := range parts {
+		switch {
+		case part == "pgup":
+			parts[i] = "PgUp"
+		case part == "pgdown":
+			parts[i] = "PgDown"
+		case part == "" || len(parts) == 1 && utf8.RuneCountInString(part) == 1:
+			// 修飾子のない1文字はそのまま表示する
+		default:
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// ProblemKind はキーバインドの問題の種類
+type ProblemKind string
+
+const (
+	// Conflict は同じキーが複数の操作や文字入力と衝突している
+	Conflict ProblemKind = "conflict"
+	// Unreachable はキーバインドが実行されることがない
+	Unreachable ProblemKind = "unreachable"
+)
+
+// Problem はキーバインドの問題
+type Problem struct {
+	Kind    ProblemKind // 種類
+	Context Context     // コンテキスト
+	Key     string      // キー
+	Action  string      // 操作ID
+	Message string      // 内容
+}
+
+// String は問題の説明を返す
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s (%s): %s", p.Context, Display(p.Key), p.Action, p.Message)
+}
+
+// Keymap はコンテキストごとにキーを操作へ割り当てる
+type Keymap struct {
+	actions map[Context]map[string]string   // キー → 操作ID
+	keys    map[Context]map[string][]string // 操作ID → キー (割り当て順)
+}
+
+// New は既定の割り当てにユーザーの割り当てを重ねたKeymapを作成する
+// ユーザーの割り当ては操作単位で既定を置き換え、キーが衝突した場合はユーザーの割り当てを優先する
+// knownが指定されていれば存在しない操作への割り当てを問題として報告する
+func New(defaults, overrides Bindings, known func(action string) bool) (*Keymap, []Problem) {
+	km := &Keymap{
+		actions: make(map[Context]map[string]string),
+		keys:    make(map[Context]map[string][]string),
+	}
+	var problems []Problem
+
+	for _, ctx := range Contexts {
+		km.actions[ctx] = make(map[string]string)
+		km.keys[ctx] = make(map[string][]string)
+
+		// ユーザーの割り当てを先に登録してキーの衝突時に優先する
+		layers := []map[string][]string{overrides[ctx], defaults[ctx]}
+		for i, layer := range layers {
+			for _, action := range sortedActions(layer) {
+				if i == 1 && overrides[ctx] != nil {
+					if _, overridden := overrides[ctx][action]; overridden {
+						continue
+					}
+				}
+				for _, key := range layer[action] {
+					problems = append(problems, km.bind(ctx, key, action, known)...)
+				}
+			}
+		}
+	}
+
+	return km, append(problems, km.unreachable()...)
+}
+
+// bind はキーを操作に割り当てる
+func (km *Keymap) bind(ctx Context, key, action string, known func(string) bool) []Problem {
+	normalized, err := Normalize(key)
+	if err != nil {
+		return []Problem{{Kind: Conflict, Context: ctx, Key: key, Action: action, Message: err.Error()}}
+	}
+	key = normalized
+
+	if known != nil && !known(action) {
+		return []Problem{{Kind: Unreachable, Context: ctx, Key: key, Action: action, Message: "存在しない操作です"}}
+	}
+	if existing, ok := km.actions[ctx][key]; ok {
+		if existing == action {
+			return nil
+		}
+		return []Problem{{Kind: Conflict, Context: ctx, Key: key, Action: action, Message: fmt.Sprintf("%s に割り当て済みのため無視します", existing)}}
+	}
+
+	km.actions[ctx][key] = action
+	km.keys[ctx][action] = append(km.keys[ctx][action], key)
+
+	if ctx == Input && isPrintable(key) {
+		return []Problem{{Kind: Conflict, Context: ctx, Key: key, Action: action, Message: "文字入力と衝突します (入力欄でこの文字を入力できません)"}}
+	}
+	return nil
+}
+
+// unreachable は子のすべてのコンテキストで隠れるグローバルの割り当てを検出する
+func (km *Keymap) unreachable() []Problem {
+	var problems []Problem
+	for _, key := range sortedActions(km.actions[Global]) {
+		reachable := false
+		for _, ctx := range Contexts {
+			if parent, ok := ctx.parent(); !ok || parent != Global {
+				continue
+			}
+			if _, shadowed := km.actions[ctx][key]; !shadowed && !(ctx == Input && isPrintable(key)) {
+				reachable = true
+			}
+		}
+		if !reachable {
+			problems = append(problems, Problem{
+				Kind: Unreachable, Context: Global, Key: key, Action: km.actions[Global][key],
+				Message: "すべてのコンテキストで他の割り当てや文字入力に隠れるため実行されません",
+			})
+		}
+	}
+	return problems
+}
+
+// Lookup はコンテキストで押されたキーに割り当てられた操作を返す
+// 見つからなければ親のコンテキストを探すが、入力中の文字キーは入力を優先する
+func (km *Keymap) Lookup(ctx Context, key string) (string, bool) {
+	for {
+		if action, ok := km.actions[ctx][key]; ok {
+			return action, true
+		}
+		parent, ok := ctx.parent()
+		if !ok || (ctx == Input && isPrintable(key)) {
+			return "", false
+		}
+		ctx = parent
+	}
+}
+
+// Keys はコンテキストで操作を実行できるキーを返す
+// 親のコンテキストのキーは子で別の操作に使われていないものだけを含む
+func (km *Keymap) Keys(ctx Context, action string) []string {
+	var keys []string
+	for current := ctx; ; {
+		for _, key := range km.keys[current][action] {
+			if got, ok := km.Lookup(ctx, key); ok && got == action {
+				keys = append(keys, key)
+			}
+		}
+		parent, ok := current.parent()
+		if !ok {
+			return keys
+		}
+		current = parent
+	}
+}
+
+// AnyKeys は操作を実行できるキーを、キーのある最初のコンテキストから返す
+// コマンドパレットのようにコンテキストを問わずキーを示す場合に使う
+func (km *Keymap) AnyKeys(action string) []string {
+	for _, ctx := range Contexts {
+		if keys := km.Keys(ctx, action); len(keys) > 0 {
+			return keys
+		}
+	}
+	return nil
+}
+
+// sortedActions はマップのキーを昇順で返す
+func sortedActions[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
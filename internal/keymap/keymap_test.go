@@ -0,0 +1,163 @@
+package keymap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "Ctrl+C", want: "ctrl+c"},
+		{key: "ctrl+shift+Up", want: "ctrl+shift+up"},
+		{key: "F12", want: "f12"},
+		{key: "space", want: " "},
+		{key: "Q", want: "Q"},
+		{key: "alt+Q", want: "alt+q"},
+		{key: "super+c", wantErr: true},
+		{key: "ctrl+", wantErr: true},
+		{key: "return", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := Normalize(tt.key)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tests := map[string]string{
+		"ctrl+c":    "Ctrl+C",
+		"f1":        "F1",
+		"esc":       "Esc",
+		"pgdown":    "PgDown",
+		"shift+tab": "Shift+Tab",
+		"q":         "q",
+		" ":         "Space",
+	}
+
+	for key, want := range tests {
+		assert.Equal(t, want, Display(key), key)
+	}
+}
+
+// testDefaults はテスト用の既定の割り当て
+var testDefaults = Bindings{
+	Global:  {"app.quit": {"ctrl+c"}, "help.toggle": {"f1"}},
+	Sidebar: {"diff.hide": {"esc", "q"}},
+	Modal:   {"app.quit": {"ctrl+c"}},
+}
+
+func TestKeymap_Lookup(t *testing.T) {
+	km, problems := New(testDefaults, nil, nil)
+	require.Empty(t, problems)
+
+	tests := []struct {
+		name   string
+		ctx    Context
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{name: "入力中もグローバルの割り当てを使う", ctx: Input, key: "ctrl+c", want: "app.quit", wantOK: true},
+		{name: "コンテキストの割り当て", ctx: Sidebar, key: "q", want: "diff.hide", wantOK: true},
+		{name: "入力中の文字キーは入力を優先", ctx: Input, key: "q"},
+		{name: "ダイアログはグローバルを使わない", ctx: Modal, key: "f1"},
+		{name: "未割り当て", ctx: Global, key: "ctrl+z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := km.Lookup(tt.ctx, tt.key)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKeymap_AnyKeys(t *testing.T) {
+	km, problems := New(testDefaults, nil, nil)
+	require.Empty(t, problems)
+
+	// グローバルの割り当てを優先し、なければ他のコンテキストから探す
+	assert.Equal(t, []string{"ctrl+c"}, km.AnyKeys("app.quit"))
+	assert.Equal(t, []string{"esc", "q"}, km.AnyKeys("diff.hide"))
+	assert.Empty(t, km.AnyKeys("no.such"))
+}
+
+func TestKeymap_Overrides(t *testing.T) {
+	km, problems := New(testDefaults, Bindings{
+		Global:  {"app.quit": {"Ctrl+Q"}, "help.toggle": {}},
+		Sidebar: {"diff.refresh": {"ctrl+c"}},
+	}, nil)
+	require.Empty(t, problems)
+
+	// ユーザーの割り当ては操作単位で既定を置き換える
+	_, ok := km.Lookup(Input, "ctrl+c")
+	assert.False(t, ok)
+	action, ok := km.Lookup(Input, "ctrl+q")
+	assert.True(t, ok)
+	assert.Equal(t, "app.quit", action)
+	assert.Empty(t, km.Keys(Input, "help.toggle"))
+
+	// 子のコンテキストで別の操作に使われているキーは含めない
+	km, _ = New(testDefaults, Bindings{Sidebar: {"diff.refresh": {"ctrl+c"}}}, nil)
+	assert.Equal(t, []string{"ctrl+c"}, km.Keys(Input, "app.quit"))
+	assert.Empty(t, km.Keys(Sidebar, "app.quit"))
+}
+
+func TestKeymap_Problems(t *testing.T) {
+	known := func(action string) bool { return action != "missing.action" }
+
+	tests := []struct {
+		name      string
+		overrides Bindings
+		want      Problem
+	}{
+		{
+			name:      "同じコンテキストで衝突",
+			overrides: Bindings{Global: {"screen.clear": {"ctrl+c"}}},
+			want:      Problem{Kind: Conflict, Context: Global, Key: "ctrl+c", Action: "app.quit", Message: "screen.clear に割り当て済みのため無視します"},
+		},
+		{
+			name:      "入力中の文字キー",
+			overrides: Bindings{Input: {"app.quit": {"q"}}},
+			want:      Problem{Kind: Conflict, Context: Input, Key: "q", Action: "app.quit", Message: "文字入力と衝突します (入力欄でこの文字を入力できません)"},
+		},
+		{
+			name:      "存在しない操作",
+			overrides: Bindings{Global: {"missing.action": {"ctrl+x"}}},
+			want:      Problem{Kind: Unreachable, Context: Global, Key: "ctrl+x", Action: "missing.action", Message: "存在しない操作です"},
+		},
+		{
+			name:      "すべての子で隠れるグローバルの割り当て",
+			overrides: Bindings{Global: {"screen.clear": {"q"}}},
+			want:      Problem{Kind: Unreachable, Context: Global, Key: "q", Action: "screen.clear", Message: "すべてのコンテキストで他の割り当てや文字入力に隠れるため実行されません"},
+		},
+		{
+			name:      "不正なキー",
+			overrides: Bindings{Global: {"screen.clear": {"hyper+l"}}},
+			want:      Problem{Kind: Conflict, Context: Global, Key: "hyper+l", Action: "screen.clear", Message: `不明な修飾キーです: "hyper+l"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := New(testDefaults, tt.overrides, known)
+			require.Len(t, problems, 1)
+			assert.Equal(t, tt.want, problems[0])
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// FileName は既定のログファイル名
+	FileName = "ccforge.log"
+	// maxFileSize はローテーションする前のログファイルの最大サイズ
+	maxFileSize = 10 << 20
+)
+
+// Options はロガーの設定
+type Options struct {
+	Path    string // ログファイルのパス (空の場合は DefaultPath)
+	Verbose bool   // デバッグログも出力するか
+}
+
+// DefaultPath は既定のログファイルのパスを返す
+// 標準出力はTUIが使うため、ユーザーのキャッシュディレクトリに書き出す
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("キャッシュディレクトリの取得に失敗しました: %w", err)
+	}
+	return filepath.Join(dir, "ccforge", FileName), nil
+}
+
+// Open はログファイルに書き出すロガーを作成する
+// ファイルが最大サイズを超えている場合は .1 に退避してから開く
+func Open(opts Options) (*slog.Logger, io.Closer, error) {
+	path := opts.Path
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, nil, fmt.Errorf("ログディレクトリの作成に失敗しました: %w", err)
+	}
+	if err := rotate(path); err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ログファイルを開けません: %w", err)
+	}
+
+	return New(f, opts.Verbose), f, nil
+}
+
+// New は指定した出力先に書き出すロガーを作成する
+func New(w io.Writer, verbose bool) *slog.Logger {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Setup はログファイルに書き出すロガーを既定のロガーに設定する
+// 返り値の関数でログファイルを閉じる
+func Setup(opts Options) (func() error, error) {
+	logger, closer, err := Open(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.SetDefault(logger)
+	return closer.Close, nil
+}
+
+// Discard は何も出力しないロガーを既定のロガーに設定する
+// ログファイルを開けない場合に標準エラー出力へ漏らさないために使う
+func Discard() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// rotate はログファイルが最大サイズを超えていれば退避する
+func rotate(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ログファイルの確認に失敗しました: %w", err)
+	}
+	if info.Size() < maxFileSize {
+		return nil
+	}
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		return fmt.Errorf("ログファイルのローテーションに失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbose   bool
+		wantDebug bool
+	}{
+		{name: "通常はINFO以上", verbose: false, wantDebug: false},
+		{name: "verboseでDEBUGも出力", verbose: true, wantDebug: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := New(&buf, tt.verbose)
+
+			logger.Debug("debug message", "component", "test")
+			logger.Info("info message", "component", "test")
+
+			assert.Contains(t, buf.String(), `msg="info message" component=test`)
+			assert.Equal(t, tt.wantDebug, bytes.Contains(buf.Bytes(), []byte("debug message")))
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	path := filepath.Join(t.TempDir(), "logs", FileName)
+	closeLog, err := Setup(Options{Path: path, Verbose: true})
+	require.NoError(t, err)
+
+	slog.Debug("起動しました", "component", "cli")
+	require.NoError(t, closeLog())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "msg=起動しました component=cli")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestOpen_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("x"), maxFileSize), 0o600))
+
+	_, closer, err := Open(Options{Path: path})
+	require.NoError(t, err)
+	require.NoError(t, closer.Close())
+
+	assert.FileExists(t, path+".1")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/cache")
+	t.Setenv("HOME", "/tmp/home")
+
+	path, err := DefaultPath()
+	require.NoError(t, err)
+	assert.Equal(t, FileName, filepath.Base(path))
+	assert.Equal(t, "ccforge", filepath.Base(filepath.Dir(path)))
+}
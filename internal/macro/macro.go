@@ -0,0 +1,92 @@
+package macro
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mzkmnk/ccforge/internal/config"
+)
+
+// placeholderPattern は {{name}} 形式のプレースホルダーのパターン
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_-]+)\s*\}\}`)
+
+// Vars はプレースホルダーに埋め込む実行時の値
+type Vars struct {
+	Task   string // アクティブなタスク名 ({{task}})
+	File   string // 選択中のファイル ({{file}})
+	Branch string // 現在のGitブランチ ({{branch}})
+}
+
+// Step は展開済みのマクロの1手順
+type Step struct {
+	Prompt string // 送信するプロンプト (Actionと排他)
+	Action string // 実行する組み込み操作のID (Promptと排他)
+	Wait   bool   // Claudeの応答を待ってから次へ進むか
+}
+
+// Placeholders はマクロ内で使われているプレースホルダー名を返す
+func Placeholders(def config.MacroCommand) map[string]bool {
+	used := make(map[string]bool)
+	for _, step := range def.Steps {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(step.Prompt, -1) {
+			used[m[1]] = true
+		}
+	}
+	return used
+}
+
+// Expand はマクロ定義を引数と実行時の値で展開する
+// 位置引数は {{1}} や引数名、名前付き引数以降の残りの引数は {{args}} で参照できる
+func Expand(def config.MacroCommand, args []string, vars Vars) ([]Step, error) {
+	if len(args) < len(def.Args) {
+		return nil, fmt.Errorf("/%s: 引数 %s が必要です", def.Name, def.Args[len(args)])
+	}
+
+	values := map[string]string{
+		"task":   vars.Task,
+		"file":   vars.File,
+		"branch": vars.Branch,
+		"args":   strings.Join(args[len(def.Args):], " "),
+	}
+	for i, arg := range args {
+		values[strconv.Itoa(i+1)] = arg
+		if i < len(def.Args) {
+			values[def.Args[i]] = arg
+		}
+	}
+
+	steps := make([]Step, 0, len(def.Steps))
+	for i, step := range def.Steps {
+		prompt, err := substitute(step.Prompt, values)
+		if err != nil {
+			return nil, fmt.Errorf("/%s: steps[%d]: %w", def.Name, i, err)
+		}
+		steps = append(steps, Step{Prompt: prompt, Action: step.Action, Wait: step.Wait})
+	}
+
+	return steps, nil
+}
+
+// substitute はテキスト内のプレースホルダーを値で置き換える
+func substitute(text string, values map[string]string) (string, error) {
+	var missing []string
+	result := placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		value, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		if value == "" && (name == "task" || name == "file" || name == "branch") {
+			missing = append(missing, name)
+		}
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("プレースホルダーの値がありません: %s", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package macro
+
+import (
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	def := config.MacroCommand{
+		Name: "fix",
+		Args: []string{"target"},
+		Steps: []config.MacroStep{
+			{Prompt: "{{target}} のテストを実行して", Wait: true},
+			{Prompt: "タスク{{ task }}の失敗を要約: {{args}}", Wait: true},
+			{Action: "diff.toggle"},
+			{Prompt: "{{branch}}で{{file}}の修正案を提示して ({{1}})"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		vars      Vars
+		want      []Step
+		wantError string
+	}{
+		{
+			name: "正常系_すべての値を展開",
+			args: []string{"./internal", "-v", "-count=1"},
+			vars: Vars{Task: "login", File: "main.go", Branch: "task/login"},
+			want: []Step{
+				{Prompt: "./internal のテストを実行して", Wait: true},
+				{Prompt: "タスクloginの失敗を要約: -v -count=1", Wait: true},
+				{Action: "diff.toggle"},
+				{Prompt: "task/loginでmain.goの修正案を提示して (./internal)"},
+			},
+		},
+		{
+			name: "正常系_残りの引数がない",
+			args: []string{"./cmd"},
+			vars: Vars{Task: "login", File: "main.go", Branch: "main"},
+			want: []Step{
+				{Prompt: "./cmd のテストを実行して", Wait: true},
+				{Prompt: "タスクloginの失敗を要約: ", Wait: true},
+				{Action: "diff.toggle"},
+				{Prompt: "mainでmain.goの修正案を提示して (./cmd)"},
+			},
+		},
+		{
+			name:      "異常系_必須引数なし",
+			vars:      Vars{Task: "login", File: "main.go", Branch: "main"},
+			wantError: "/fix: 引数 target が必要です",
+		},
+		{
+			name:      "異常系_実行時の値がない",
+			args:      []string{"x"},
+			vars:      Vars{Task: "login"},
+			wantError: "/fix: steps[3]: プレースホルダーの値がありません: branch, file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(def, tt.args, tt.vars)
+			if tt.wantError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpand_UnknownPlaceholder(t *testing.T) {
+	def := config.MacroCommand{
+		Name:  "x",
+		Steps: []config.MacroStep{{Prompt: "{{2}} {{nope}}"}},
+	}
+
+	_, err := Expand(def, []string{"a"}, Vars{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2, nope")
+}
+
+func TestPlaceholders(t *testing.T) {
+	def := config.MacroCommand{
+		Steps: []config.MacroStep{
+			{Prompt: "{{task}} と {{ branch }}"},
+			{Action: "diff.toggle"},
+		},
+	}
+
+	assert.Equal(t, map[string]bool{"task": true, "branch": true}, Placeholders(def))
+}
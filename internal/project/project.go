@@ -0,0 +1,69 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// markers はプロジェクトのルートの目印となるエントリ
+// ccforgeディレクトリを.gitより優先する
+var markers = []string{"ccforge", ".git"}
+
+// ErrRootNotFound はプロジェクトのルートが見つからない場合のエラー
+var ErrRootNotFound = errors.New("プロジェクトのルートが見つかりません")
+
+// FindRoot はstartから親ディレクトリをたどり、ccforge/ または .git を含む
+// 最も近いディレクトリを返す
+func FindRoot(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", fmt.Errorf("パスの解決に失敗しました: %w", err)
+	}
+
+	for {
+		for _, marker := range markers {
+			info, err := os.Stat(filepath.Join(dir, marker))
+			if err != nil {
+				continue
+			}
+			// ccforgeはディレクトリのみ、.gitはworktreeのファイルも認める
+			if marker == ".git" || info.IsDir() {
+				return dir, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%w: %s", ErrRootNotFound, start)
+		}
+		dir = parent
+	}
+}
+
+// Resolve はプロジェクトのルートを決定する
+// explicitが指定されていればそれを使い、なければworkDirから探索する
+// 見つからない場合はworkDirをルートとする
+func Resolve(explicit, workDir string) (string, error) {
+	if explicit != "" {
+		dir, err := filepath.Abs(explicit)
+		if err != nil {
+			return "", fmt.Errorf("パスの解決に失敗しました: %w", err)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return "", fmt.Errorf("プロジェクトディレクトリを開けません: %w", err)
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf("プロジェクトのパスがディレクトリではありません: %s", dir)
+		}
+		return dir, nil
+	}
+
+	root, err := FindRoot(workDir)
+	if errors.Is(err, ErrRootNotFound) {
+		return filepath.Abs(workDir)
+	}
+	return root, err
+}
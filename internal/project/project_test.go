@@ -0,0 +1,95 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mkdirs はテスト用のディレクトリを作成する
+func mkdirs(t *testing.T, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		require.NoError(t, os.MkdirAll(path, 0o755))
+	}
+}
+
+func TestFindRoot(t *testing.T) {
+	base := t.TempDir()
+
+	repo := filepath.Join(base, "repo")
+	nested := filepath.Join(repo, "services", "api")
+	mkdirs(t, filepath.Join(repo, ".git"), filepath.Join(nested, "ccforge"), filepath.Join(nested, "internal", "x"))
+
+	worktree := filepath.Join(base, "worktree")
+	mkdirs(t, filepath.Join(worktree, "sub"))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: ../repo/.git/worktrees/w\n"), 0o644))
+
+	// ccforgeという名前のファイルは目印にしない
+	fileMarker := filepath.Join(base, "file-marker")
+	mkdirs(t, filepath.Join(fileMarker, "sub"))
+	require.NoError(t, os.WriteFile(filepath.Join(fileMarker, "ccforge"), nil, 0o755))
+
+	tests := []struct {
+		name    string
+		start   string
+		want    string
+		wantErr error
+	}{
+		{name: "ルート自身", start: repo, want: repo},
+		{name: "最も近いccforgeディレクトリ", start: filepath.Join(nested, "internal", "x"), want: nested},
+		{name: "gitのルート", start: filepath.Join(repo, "services"), want: repo},
+		{name: "worktreeの.gitファイル", start: filepath.Join(worktree, "sub"), want: worktree},
+		{name: "ccforgeファイルは無視", start: filepath.Join(fileMarker, "sub"), wantErr: ErrRootNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindRoot(tt.start)
+			if tt.wantErr != nil {
+				// 一時ディレクトリの親にリポジトリがある環境ではそのルートが見つかる
+				if err == nil {
+					assert.NotContains(t, got, base)
+					return
+				}
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	base := t.TempDir()
+	repo := filepath.Join(base, "repo")
+	mkdirs(t, filepath.Join(repo, "ccforge"), filepath.Join(repo, "src"))
+
+	t.Run("明示指定を優先", func(t *testing.T) {
+		got, err := Resolve(filepath.Join(repo, "src"), repo)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(repo, "src"), got)
+	})
+
+	t.Run("作業ディレクトリから探索", func(t *testing.T) {
+		got, err := Resolve("", filepath.Join(repo, "src"))
+		require.NoError(t, err)
+		assert.Equal(t, repo, got)
+	})
+
+	t.Run("異常系_存在しないディレクトリ", func(t *testing.T) {
+		_, err := Resolve(filepath.Join(base, "missing"), repo)
+		assert.Error(t, err)
+	})
+
+	t.Run("異常系_ファイル", func(t *testing.T) {
+		path := filepath.Join(base, "file")
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+		_, err := Resolve(path, repo)
+		assert.ErrorContains(t, err, "ディレクトリではありません")
+	})
+}
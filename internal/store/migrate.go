@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// migration はスキーマの1バージョン分の変更
+type migration struct {
+	version int    // 適用後のスキーマバージョン (PRAGMA user_version)
+	name    string // 変更の説明
+	sql     string // 実行するSQL
+}
+
+// migrations は適用順に並べたスキーマの変更
+// 一度リリースした変更は書き換えず、新しいバージョンを追加する
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "タスクとセッション",
+		sql: `
+CREATE TABLE tasks (
+	id          TEXT PRIMARY KEY,
+	name        TEXT NOT NULL UNIQUE,
+	description TEXT NOT NULL DEFAULT '',
+	created_at  INTEGER NOT NULL,
+	archived    INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE sessions (
+	id         TEXT PRIMARY KEY,
+	task_id    TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	started_at INTEGER NOT NULL,
+	ended_at   INTEGER
+);
+`,
+	},
+	{
+		version: 2,
+		name:    "プロンプトとトランスクリプト",
+		sql: `
+CREATE TABLE prompts (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id   TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	text         TEXT NOT NULL,
+	sent_at      INTEGER NOT NULL,
+	completed_at INTEGER,
+	exit_code    INTEGER
+);
+
+CREATE TABLE transcript_chunks (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	prompt_id  INTEGER NOT NULL REFERENCES prompts(id) ON DELETE CASCADE,
+	seq        INTEGER NOT NULL,
+	stream     TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	UNIQUE (prompt_id, seq)
+);
+`,
+	},
+	{
+		version: 3,
+		name:    "履歴の検索用インデックス",
+		sql: `
+CREATE INDEX sessions_task_id ON sessions(task_id, started_at);
+CREATE INDEX prompts_session_id ON prompts(session_id, sent_at);
+`,
+	},
+	{
+		version: 4,
+		name:    "画面のスナップショット",
+		sql: `
+CREATE TABLE view_snapshots (
+	task_id       TEXT PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+	session_id    TEXT NOT NULL DEFAULT '',
+	output        TEXT NOT NULL,
+	input         TEXT NOT NULL DEFAULT '',
+	cursor_pos    INTEGER NOT NULL DEFAULT 0,
+	scroll_offset INTEGER NOT NULL DEFAULT 0,
+	saved_at      INTEGER NOT NULL
+);
+`,
+	},
+	{
+		version: 5,
+		name:    "トランスクリプトの全文検索",
+		// 分かち書きのない日本語も部分一致で探せるようtrigramで索引を作る
+		// rowidは出力の断片ではそのID、プロンプトではIDの符号を反転した値にする
+		sql: `
+CREATE VIRTUAL TABLE transcript_fts USING fts5(content, tokenize = 'trigram');
+
+INSERT INTO transcript_fts (rowid, content) SELECT -id, text FROM prompts;
+INSERT INTO transcript_fts (rowid, content) SELECT id, content FROM transcript_chunks;
+
+CREATE TRIGGER prompts_fts_insert AFTER INSERT ON prompts BEGIN
+	INSERT INTO transcript_fts (rowid, content) VALUES (-new.id, new.text);
+END;
+CREATE TRIGGER prompts_fts_update AFTER UPDATE OF text ON prompts BEGIN
+	DELETE FROM transcript_fts WHERE rowid = -old.id;
+	INSERT INTO transcript_fts (rowid, content) VALUES (-new.id, new.text);
+END;
+CREATE TRIGGER prompts_fts_delete AFTER DELETE ON prompts BEGIN
+	DELETE FROM transcript_fts WHERE rowid = -old.id;
+END;
+
+CREATE TRIGGER transcript_chunks_fts_insert AFTER INSERT ON transcript_chunks BEGIN
+	INSERT INTO transcript_fts (rowid, content) VALUES (new.id, new.content);
+END;
+CREATE TRIGGER transcript_chunks_fts_update AFTER UPDATE OF content ON transcript_chunks BEGIN
+	DELETE FROM transcript_fts WHERE rowid = old.id;
+	INSERT INTO transcript_fts (rowid, content) VALUES (new.id, new.content);
+END;
+CREATE TRIGGER transcript_chunks_fts_delete AFTER DELETE ON transcript_chunks BEGIN
+	DELETE FROM transcript_fts WHERE rowid = old.id;
+END;
+
+CREATE TABLE history_imports (
+	task_id TEXT PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+	offset  INTEGER NOT NULL
+);
+`,
+	},
+}
+
+// SchemaVersion はこのバージョンのccforgeが扱うスキーマバージョン
+var SchemaVersion = migrations[len(migrations)-1].version
+
+// schemaVersion はデータベースのスキーマバージョンを返す
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("スキーマバージョンの取得に失敗しました: %w", err)
+	}
+	return version, nil
+}
+
+// migrate はスキーマを target のバージョンまで更新する
+// 各バージョンの変更はトランザクション内で適用し、失敗した場合はそのバージョンの変更を取り消す
+func migrate(ctx context.Context, db *sql.DB, target int) error {
+	current, err := schemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	if current > SchemaVersion {
+		return fmt.Errorf("%w: データベース %d, 対応 %d (ccforgeを更新してください)", ErrSchemaTooNew, current, SchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current || m.version > target {
+			continue
+		}
+		if err := applyMigration(ctx, db, m); err != nil {
+			return err
+		}
+		slog.Info("スキーマを更新しました", "component", "store", "version", m.version, "name", m.name)
+	}
+	return nil
+}
+
+// applyMigration は1バージョン分の変更を適用する
+func applyMigration(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("スキーマの更新に失敗しました (バージョン %d): %w", m.version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return fmt.Errorf("スキーマの更新に失敗しました (バージョン %d %s): %w", m.version, m.name, err)
+	}
+	// PRAGMAはプレースホルダーを使えないため数値を埋め込む
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", m.version)); err != nil {
+		return fmt.Errorf("スキーマの更新に失敗しました (バージョン %d): %w", m.version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("スキーマの更新に失敗しました (バージョン %d): %w", m.version, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations_Ordered(t *testing.T) {
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.version, m.name)
+	}
+}
+
+func TestMigrate_UpgradeFromOlderVersions(t *testing.T) {
+	ctx := context.Background()
+
+	for _, from := range []int{0, 1, 2, 3, 4} {
+		t.Run(fmt.Sprintf("バージョン%dから", from), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+
+			// 古いバージョンのスキーマでデータを作る
+			old, err := open(ctx, path, from)
+			require.NoError(t, err)
+			version, err := schemaVersion(ctx, old.db)
+			require.NoError(t, err)
+			require.Equal(t, from, version)
+			if from >= 1 {
+				require.NoError(t, old.SaveTask(ctx, Task{ID: "t1", Name: "auth", CreatedAt: testTime(0)}))
+				require.NoError(t, old.StartSession(ctx, Session{ID: "s1", TaskID: "t1", StartedAt: testTime(1)}))
+			}
+			require.NoError(t, old.Close())
+
+			// 最新のバージョンで開くと残りの変更を適用してデータを引き継ぐ
+			s, err := Open(ctx, path)
+			require.NoError(t, err)
+			defer s.Close()
+
+			version, err = schemaVersion(ctx, s.db)
+			require.NoError(t, err)
+			assert.Equal(t, SchemaVersion, version)
+
+			if from >= 1 {
+				sessions, err := s.Sessions(ctx, "t1")
+				require.NoError(t, err)
+				assert.Equal(t, []Session{{ID: "s1", TaskID: "t1", StartedAt: testTime(1)}}, sessions)
+				_, err = s.AddPrompt(ctx, "s1", "hello", testTime(2))
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMigrate_SchemaTooNew(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), FileName)
+
+	s, err := Open(ctx, path)
+	require.NoError(t, err)
+	_, err = s.db.ExecContext(ctx, "PRAGMA user_version = 999")
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	_, err = Open(ctx, path)
+	assert.True(t, errors.Is(err, ErrSchemaTooNew))
+}
+
+func TestMigrate_RollbackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), FileName)
+
+	s, err := open(ctx, path, 1)
+	require.NoError(t, err)
+	// バージョン2で作成するテーブルが既にあると失敗する
+	_, err = s.db.ExecContext(ctx, "CREATE TABLE transcript_chunks (id INTEGER)")
+	require.NoError(t, err)
+
+	err = migrate(ctx, s.db, SchemaVersion)
+	assert.ErrorContains(t, err, "バージョン 2")
+
+	// 失敗したバージョンの変更は取り消され、バージョンも変わらない
+	version, err := schemaVersion(ctx, s.db)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+	var count int
+	require.NoError(t, s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'prompts'").Scan(&count))
+	assert.Zero(t, count)
+	require.NoError(t, s.Close())
+}
@@ -0,0 +1,330 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// querier は *sql.DB と *sql.Tx に共通する問い合わせ
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Repository はタスク、セッション、プロンプト、トランスクリプトの読み書き
+// Storeに埋め込まれたものは1操作ずつ、Updateに渡されたものはトランザクション内で実行する
+type Repository struct {
+	q querier
+}
+
+// Task は保存したタスク
+type Task struct {
+	ID          string    // タスクID (tasks.Task.ID と同じ)
+	Name        string    // タスク名
+	Description string    // 概要
+	CreatedAt   time.Time // 作成日時
+	Archived    bool      // アーカイブ済みか
+}
+
+// Session はタスクに紐づくClaude Codeのセッション
+type Session struct {
+	ID        string    // Claude CodeのセッションID
+	TaskID    string    // タスクID
+	StartedAt time.Time // 開始日時
+	EndedAt   time.Time // 終了日時 (継続中の場合はゼロ値)
+}
+
+// Prompt はセッションで送信したプロンプト
+type Prompt struct {
+	ID          int64     // プロンプトID
+	SessionID   string    // セッションID
+	Text        string    // 送信した内容
+	SentAt      time.Time // 送信日時
+	CompletedAt time.Time // 応答の完了日時 (応答中の場合はゼロ値)
+	ExitCode    int       // Claude Code CLIの終了コード (完了前は0)
+}
+
+// Stream はトランスクリプトの出力先
+type Stream string
+
+const (
+	// Stdout は標準出力
+	Stdout Stream = "stdout"
+	// Stderr は標準エラー出力
+	Stderr Stream = "stderr"
+)
+
+// Chunk はプロンプトへの応答の一部
+// 応答はストリーミングで届くため、届いた順に Seq を付けて保存する
+type Chunk struct {
+	ID        int64     // チャンクID
+	PromptID  int64     // プロンプトID
+	Seq       int       // プロンプト内の順番 (0から)
+	Stream    Stream    // 出力先
+	Content   string    // 内容
+	CreatedAt time.Time // 受信日時
+}
+
+// SaveTask はタスクを保存する
+// 同じIDのタスクがあれば名前、概要、アーカイブの状態を更新する
+func (r *Repository) SaveTask(ctx context.Context, t Task) error {
+	_, err := r.q.ExecContext(ctx, `
+INSERT INTO tasks (id, name, description, created_at, archived) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET name = excluded.name, description = excluded.description, archived = excluded.archived`,
+		t.ID, t.Name, t.Description, unixNano(t.CreatedAt), t.Archived)
+	if err != nil {
+		return fmt.Errorf("タスク %s の保存に失敗しました: %w", t.Name, err)
+	}
+	return nil
+}
+
+// Task はIDでタスクを取得する
+func (r *Repository) Task(ctx context.Context, id string) (*Task, error) {
+	row := r.q.QueryRowContext(ctx, `SELECT id, name, description, created_at, archived FROM tasks WHERE id = ?`, id)
+	t, err := scanTask(row)
+	if err != nil {
+		return nil, notFound(err, "タスク", id)
+	}
+	return t, nil
+}
+
+// TaskByName は名前でタスクを取得する
+func (r *Repository) TaskByName(ctx context.Context, name string) (*Task, error) {
+	row := r.q.QueryRowContext(ctx, `SELECT id, name, description, created_at, archived FROM tasks WHERE name = ?`, name)
+	t, err := scanTask(row)
+	if err != nil {
+		return nil, notFound(err, "タスク", name)
+	}
+	return t, nil
+}
+
+// Tasks はタスクを名前順に取得する
+func (r *Repository) Tasks(ctx context.Context) ([]Task, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT id, name, description, created_at, archived FROM tasks ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("タスク一覧の取得に失敗しました: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("タスク一覧の取得に失敗しました: %w", err)
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+// DeleteTask はタスクとそのセッション、プロンプト、トランスクリプトを削除する
+func (r *Repository) DeleteTask(ctx context.Context, id string) error {
+	res, err := r.q.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("タスク %s の削除に失敗しました: %w", id, err)
+	}
+	return affected(res, "タスク", id)
+}
+
+// StartSession はタスクのセッションを記録する
+// 同じIDのセッションがあれば何もしない (--resumeで再開した場合)
+func (r *Repository) StartSession(ctx context.Context, s Session) error {
+	_, err := r.q.ExecContext(ctx, `INSERT INTO sessions (id, task_id, started_at) VALUES (?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		s.ID, s.TaskID, unixNano(s.StartedAt))
+	if err != nil {
+		return fmt.Errorf("セッション %s の保存に失敗しました: %w", s.ID, err)
+	}
+	return nil
+}
+
+// EndSession はセッションの終了日時を記録する
+func (r *Repository) EndSession(ctx context.Context, id string, at time.Time) error {
+	res, err := r.q.ExecContext(ctx, `UPDATE sessions SET ended_at = ? WHERE id = ?`, unixNano(at), id)
+	if err != nil {
+		return fmt.Errorf("セッション %s の更新に失敗しました: %w", id, err)
+	}
+	return affected(res, "セッション", id)
+}
+
+// Session はIDでセッションを取得する
+func (r *Repository) Session(ctx context.Context, id string) (*Session, error) {
+	row := r.q.QueryRowContext(ctx, `SELECT id, task_id, started_at, ended_at FROM sessions WHERE id = ?`, id)
+	s, err := scanSession(row)
+	if err != nil {
+		return nil, notFound(err, "セッション", id)
+	}
+	return s, nil
+}
+
+// Sessions はタスクのセッションを開始日時の順に取得する
+func (r *Repository) Sessions(ctx context.Context, taskID string) ([]Session, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT id, task_id, started_at, ended_at FROM sessions WHERE task_id = ? ORDER BY started_at, id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("セッション一覧の取得に失敗しました: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("セッション一覧の取得に失敗しました: %w", err)
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+// AddPrompt はセッションで送信したプロンプトを記録してIDを返す
+func (r *Repository) AddPrompt(ctx context.Context, sessionID, text string, at time.Time) (int64, error) {
+	res, err := r.q.ExecContext(ctx, `INSERT INTO prompts (session_id, text, sent_at) VALUES (?, ?, ?)`, sessionID, text, unixNano(at))
+	if err != nil {
+		return 0, fmt.Errorf("プロンプトの保存に失敗しました: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// CompletePrompt はプロンプトへの応答の完了を記録する
+func (r *Repository) CompletePrompt(ctx context.Context, id int64, exitCode int, at time.Time) error {
+	res, err := r.q.ExecContext(ctx, `UPDATE prompts SET completed_at = ?, exit_code = ? WHERE id = ?`, unixNano(at), exitCode, id)
+	if err != nil {
+		return fmt.Errorf("プロンプト %d の更新に失敗しました: %w", id, err)
+	}
+	return affected(res, "プロンプト", fmt.Sprint(id))
+}
+
+// Prompts はセッションのプロンプトを送信順に取得する
+func (r *Repository) Prompts(ctx context.Context, sessionID string) ([]Prompt, error) {
+	rows, err := r.q.QueryContext(ctx, `
+SELECT id, session_id, text, sent_at, completed_at, exit_code FROM prompts
+WHERE session_id = ? ORDER BY sent_at, id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("プロンプト一覧の取得に失敗しました: %w", err)
+	}
+	defer rows.Close()
+
+	prompts := []Prompt{}
+	for rows.Next() {
+		var p Prompt
+		var sentAt int64
+		var completedAt, exitCode sql.NullInt64
+		if err := rows.Scan(&p.ID, &p.SessionID, &p.Text, &sentAt, &completedAt, &exitCode); err != nil {
+			return nil, fmt.Errorf("プロンプト一覧の取得に失敗しました: %w", err)
+		}
+		p.SentAt = fromUnixNano(sentAt)
+		p.CompletedAt = fromNullUnixNano(completedAt)
+		p.ExitCode = int(exitCode.Int64)
+		prompts = append(prompts, p)
+	}
+	return prompts, rows.Err()
+}
+
+// AppendChunk はプロンプトへの応答の一部を末尾に追加する
+func (r *Repository) AppendChunk(ctx context.Context, promptID int64, stream Stream, content string, at time.Time) (*Chunk, error) {
+	row := r.q.QueryRowContext(ctx, `
+INSERT INTO transcript_chunks (prompt_id, seq, stream, content, created_at)
+VALUES (?, (SELECT COUNT(*) FROM transcript_chunks WHERE prompt_id = ?), ?, ?, ?)
+RETURNING id, seq`, promptID, promptID, string(stream), content, unixNano(at))
+
+	c := &Chunk{PromptID: promptID, Stream: stream, Content: content, CreatedAt: fromUnixNano(unixNano(at))}
+	if err := row.Scan(&c.ID, &c.Seq); err != nil {
+		return nil, fmt.Errorf("トランスクリプトの保存に失敗しました: %w", err)
+	}
+	return c, nil
+}
+
+// Transcript はプロンプトへの応答を受信順に取得する
+func (r *Repository) Transcript(ctx context.Context, promptID int64) ([]Chunk, error) {
+	rows, err := r.q.QueryContext(ctx, `
+SELECT id, prompt_id, seq, stream, content, created_at FROM transcript_chunks
+WHERE prompt_id = ? ORDER BY seq`, promptID)
+	if err != nil {
+		return nil, fmt.Errorf("トランスクリプトの取得に失敗しました: %w", err)
+	}
+	defer rows.Close()
+
+	chunks := []Chunk{}
+	for rows.Next() {
+		var c Chunk
+		var stream string
+		var createdAt int64
+		if err := rows.Scan(&c.ID, &c.PromptID, &c.Seq, &stream, &c.Content, &createdAt); err != nil {
+			return nil, fmt.Errorf("トランスクリプトの取得に失敗しました: %w", err)
+		}
+		c.Stream = Stream(stream)
+		c.CreatedAt = fromUnixNano(createdAt)
+		chunks = append(chunks, c)
+	}
+	return chunks, rows.Err()
+}
+
+// scanner は *sql.Row と *sql.Rows に共通する読み取り
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask はタスクの行を読み取る
+func scanTask(s scanner) (*Task, error) {
+	var t Task
+	var createdAt int64
+	if err := s.Scan(&t.ID, &t.Name, &t.Description, &createdAt, &t.Archived); err != nil {
+		return nil, err
+	}
+	t.CreatedAt = fromUnixNano(createdAt)
+	return &t, nil
+}
+
+// scanSession はセッションの行を読み取る
+func scanSession(s scanner) (*Session, error) {
+	var sess Session
+	var startedAt int64
+	var endedAt sql.NullInt64
+	if err := s.Scan(&sess.ID, &sess.TaskID, &startedAt, &endedAt); err != nil {
+		return nil, err
+	}
+	sess.StartedAt = fromUnixNano(startedAt)
+	sess.EndedAt = fromNullUnixNano(endedAt)
+	return &sess, nil
+}
+
+// notFound は行がない場合のエラーを ErrNotFound に変換する
+func notFound(err error, kind, key string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s %s が%w", kind, key, ErrNotFound)
+	}
+	return fmt.Errorf("%s %s の取得に失敗しました: %w", kind, key, err)
+}
+
+// affected は更新・削除した行がなければ ErrNotFound を返す
+func affected(res sql.Result, kind, key string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s %s の更新に失敗しました: %w", kind, key, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s %s が%w", kind, key, ErrNotFound)
+	}
+	return nil
+}
+
+// unixNano は日時をUnix時間 (ナノ秒) に変換する
+func unixNano(t time.Time) int64 {
+	return t.UnixNano()
+}
+
+// fromUnixNano はUnix時間 (ナノ秒) をUTCの日時に変換する
+func fromUnixNano(n int64) time.Time {
+	return time.Unix(0, n).UTC()
+}
+
+// fromNullUnixNano はNULLをゼロ値の日時に変換する
+func fromNullUnixNano(n sql.NullInt64) time.Time {
+	if !n.Valid {
+		return time.Time{}
+	}
+	return fromUnixNano(n.Int64)
+}
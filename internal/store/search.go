@@ -0,0 +1,247 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultSearchLimit は検索結果の既定の最大件数
+	defaultSearchLimit = 50
+	// minIndexedTerm は全文検索の索引で探せる検索語の最小文字数 (trigramのため3文字)
+	// これより短い検索語はLIKEで絞り込む
+	minIndexedTerm = 3
+	// snippetRadius はスニペットに含める一致箇所の前後の文字数
+	snippetRadius = 30
+)
+
+// ErrEmptyQuery は検索語が指定されていない場合のエラー
+var ErrEmptyQuery = errors.New("検索語を指定してください")
+
+// SearchOptions は検索の条件
+type SearchOptions struct {
+	TaskID string // 絞り込むタスクのID (空の場合はすべてのタスク)
+	Limit  int    // 最大件数 (0以下の場合は50件)
+}
+
+// SearchHit は検索語に一致したプロンプトまたは応答
+type SearchHit struct {
+	TaskID    string    // タスクID
+	TaskName  string    // タスク名
+	SessionID string    // セッションID
+	PromptID  int64     // プロンプトID
+	ChunkID   int64     // 一致した応答のチャンクID (プロンプトに一致した場合は0)
+	Time      time.Time // プロンプトの送信日時または応答の受信日時
+	Snippet   string    // 一致箇所の前後を1行にまとめた抜粋
+	Matches   []int     // Snippet内で検索語に一致した文字の位置 (rune単位)
+}
+
+// PromptTranscript はプロンプトとその応答
+type PromptTranscript struct {
+	Prompt Prompt  // プロンプト
+	Chunks []Chunk // 受信順の応答
+}
+
+// ParseQuery は検索文字列を空白で区切った検索語に分ける
+func ParseQuery(query string) []string {
+	return strings.Fields(query)
+}
+
+// Search はすべてのトランスクリプトからすべての検索語を含むものを新しい順に探す
+// 英字の大文字と小文字は区別しない
+func (r *Repository) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchHit, error) {
+	terms := ParseQuery(query)
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	var phrases, conds []string
+	var args []any
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) >= minIndexedTerm {
+			phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+			continue
+		}
+		conds = append(conds, `f.content LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(term)+"%")
+	}
+	if len(phrases) > 0 {
+		conds = append([]string{"transcript_fts MATCH ?"}, conds...)
+		args = append([]any{strings.Join(phrases, " AND ")}, args...)
+	}
+	if opts.TaskID != "" {
+		conds = append(conds, "t.id = ?")
+		args = append(args, opts.TaskID)
+	}
+	args = append(args, limit)
+
+	// rowidが正なら応答のチャンク、負ならプロンプト (migrationsのバージョン5を参照)
+	rows, err := r.q.QueryContext(ctx, `
+SELECT f.content, t.id, t.name, s.id, p.id, COALESCE(c.id, 0), COALESCE(c.created_at, p.sent_at)
+FROM transcript_fts AS f
+LEFT JOIN transcript_chunks AS c ON f.rowid > 0 AND c.id = f.rowid
+JOIN prompts AS p ON p.id = CASE WHEN f.rowid > 0 THEN c.prompt_id ELSE -f.rowid END
+JOIN sessions AS s ON s.id = p.session_id
+JOIN tasks AS t ON t.id = s.task_id
+WHERE `+strings.Join(conds, " AND ")+`
+ORDER BY COALESCE(c.created_at, p.sent_at) DESC, f.rowid DESC
+LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("トランスクリプトの検索に失敗しました: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []SearchHit{}
+	for rows.Next() {
+		var h SearchHit
+		var content string
+		var at int64
+		if err := rows.Scan(&content, &h.TaskID, &h.TaskName, &h.SessionID, &h.PromptID, &h.ChunkID, &at); err != nil {
+			return nil, fmt.Errorf("トランスクリプトの検索に失敗しました: %w", err)
+		}
+		h.Time = fromUnixNano(at)
+		h.Snippet, h.Matches = snippet(content, terms)
+		hits = append(hits, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("トランスクリプトの検索に失敗しました: %w", err)
+	}
+	return hits, nil
+}
+
+// SessionTranscript はセッションのプロンプトと応答を送信順に取得する
+func (r *Repository) SessionTranscript(ctx context.Context, sessionID string) ([]PromptTranscript, error) {
+	prompts, err := r.Prompts(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	transcript := make([]PromptTranscript, 0, len(prompts))
+	for _, p := range prompts {
+		chunks, err := r.Transcript(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		transcript = append(transcript, PromptTranscript{Prompt: p, Chunks: chunks})
+	}
+	return transcript, nil
+}
+
+// HistoryOffset はタスクの実行履歴ファイルを取り込んだ位置 (バイト数) を返す
+// 取り込んでいない場合は0を返す
+func (r *Repository) HistoryOffset(ctx context.Context, taskID string) (int64, error) {
+	var offset int64
+	err := r.q.QueryRowContext(ctx, `SELECT offset FROM history_imports WHERE task_id = ?`, taskID).Scan(&offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("履歴の取り込み位置の取得に失敗しました: %w", err)
+	}
+	return offset, nil
+}
+
+// SetHistoryOffset はタスクの実行履歴ファイルを取り込んだ位置を記録する
+func (r *Repository) SetHistoryOffset(ctx context.Context, taskID string, offset int64) error {
+	_, err := r.q.ExecContext(ctx, `
+INSERT INTO history_imports (task_id, offset) VALUES (?, ?)
+ON CONFLICT (task_id) DO UPDATE SET offset = excluded.offset`, taskID, offset)
+	if err != nil {
+		return fmt.Errorf("履歴の取り込み位置の保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// MatchPositions はtextの中で検索語に一致した文字の位置 (rune単位) を昇順に返す
+// 英字の大文字と小文字は区別しない
+func MatchPositions(text string, terms []string) []int {
+	runes := foldRunes(text)
+	marked := make([]bool, len(runes))
+	for _, term := range terms {
+		t := foldRunes(term)
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(runes); i++ {
+			if equalRunes(runes[i:i+len(t)], t) {
+				for j := i; j < i+len(t); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	positions := []int{}
+	for i, m := range marked {
+		if m {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// snippet は最初の一致箇所の前後を1行にまとめた抜粋と、抜粋内の一致位置を返す
+func snippet(content string, terms []string) (string, []int) {
+	runes := []rune(strings.Join(strings.Fields(content), " "))
+	positions := MatchPositions(string(runes), terms)
+
+	start := 0
+	if len(positions) > 0 {
+		start = max(positions[0]-snippetRadius, 0)
+	}
+	end := min(start+2*snippetRadius, len(runes))
+
+	var b strings.Builder
+	shift := -start
+	if start > 0 {
+		b.WriteString("…")
+		shift++
+	}
+	b.WriteString(string(runes[start:end]))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	matches := []int{}
+	for _, p := range positions {
+		if p >= start && p < end {
+			matches = append(matches, p+shift)
+		}
+	}
+	return b.String(), matches
+}
+
+// foldRunes は大文字と小文字を区別せずに比較するため小文字のruneに変換する
+// 文字ごとに変換するため、元の文字列と位置が対応する
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// equalRunes はruneのスライスが等しいかを判定する
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeLike はLIKEのワイルドカードをエスケープする
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
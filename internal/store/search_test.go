@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedTranscripts は検索用のタスク、セッション、プロンプト、応答を保存する
+func seedTranscripts(t *testing.T, s *Store) (authPrompt, uiPrompt int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	require.NoError(t, s.SaveTask(ctx, Task{ID: "t1", Name: "auth", CreatedAt: testTime(0)}))
+	require.NoError(t, s.SaveTask(ctx, Task{ID: "t2", Name: "dashboard", CreatedAt: testTime(0)}))
+	require.NoError(t, s.StartSession(ctx, Session{ID: "s1", TaskID: "t1", StartedAt: testTime(1)}))
+	require.NoError(t, s.StartSession(ctx, Session{ID: "s2", TaskID: "t2", StartedAt: testTime(1)}))
+
+	authPrompt, err := s.AddPrompt(ctx, "s1", "JWTの検証方法を説明して", testTime(2))
+	require.NoError(t, err)
+	_, err = s.AppendChunk(ctx, authPrompt, Stdout, "トークンの署名を公開鍵で検証します。\n有効期限 (exp) も確認してください。\n", testTime(3))
+	require.NoError(t, err)
+
+	uiPrompt, err = s.AddPrompt(ctx, "s2", "グラフを描画して", testTime(4))
+	require.NoError(t, err)
+	_, err = s.AppendChunk(ctx, uiPrompt, Stdout, "Chart.jsでグラフを描画し、APIのトークンは環境変数から読みます\n", testTime(5))
+	require.NoError(t, err)
+	return authPrompt, uiPrompt
+}
+
+func TestRepository_Search(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	authPrompt, uiPrompt := seedTranscripts(t, s)
+
+	tests := []struct {
+		name        string
+		query       string
+		opts        SearchOptions
+		wantPrompts []int64
+		wantChunks  []bool // 応答に一致したか
+	}{
+		{name: "日本語の部分一致 (新しい順)", query: "トークン", wantPrompts: []int64{uiPrompt, authPrompt}, wantChunks: []bool{true, true}},
+		{name: "プロンプトに一致", query: "検証方法", wantPrompts: []int64{authPrompt}, wantChunks: []bool{false}},
+		{name: "大文字と小文字を区別しない", query: "jwt", wantPrompts: []int64{authPrompt}, wantChunks: []bool{false}},
+		{name: "すべての検索語を含む", query: "トークン 公開鍵", wantPrompts: []int64{authPrompt}, wantChunks: []bool{true}},
+		{name: "3文字未満の検索語", query: "exp", wantPrompts: []int64{authPrompt}, wantChunks: []bool{true}},
+		{name: "2文字の検索語", query: "署名", wantPrompts: []int64{authPrompt}, wantChunks: []bool{true}},
+		{name: "タスクで絞り込む", query: "トークン", opts: SearchOptions{TaskID: "t2"}, wantPrompts: []int64{uiPrompt}, wantChunks: []bool{true}},
+		{name: "件数を制限する", query: "トークン", opts: SearchOptions{Limit: 1}, wantPrompts: []int64{uiPrompt}, wantChunks: []bool{true}},
+		{name: "ワイルドカードは文字として扱う", query: "%", wantPrompts: []int64{}, wantChunks: []bool{}},
+		{name: "引用符を含む検索語", query: `"トークン`, wantPrompts: []int64{}, wantChunks: []bool{}},
+		{name: "一致しない", query: "データベース", wantPrompts: []int64{}, wantChunks: []bool{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := s.Search(ctx, tt.query, tt.opts)
+			require.NoError(t, err)
+
+			prompts := []int64{}
+			chunks := []bool{}
+			for _, h := range hits {
+				prompts = append(prompts, h.PromptID)
+				chunks = append(chunks, h.ChunkID != 0)
+			}
+			assert.Equal(t, tt.wantPrompts, prompts)
+			assert.Equal(t, tt.wantChunks, chunks)
+		})
+	}
+
+	t.Run("結果にタスクとセッションを含む", func(t *testing.T) {
+		hits, err := s.Search(ctx, "公開鍵", SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, hits, 1)
+		assert.Equal(t, "t1", hits[0].TaskID)
+		assert.Equal(t, "auth", hits[0].TaskName)
+		assert.Equal(t, "s1", hits[0].SessionID)
+		assert.Equal(t, testTime(3), hits[0].Time)
+		assert.Equal(t, "トークンの署名を公開鍵で検証します。 有効期限 (exp) も確認してください。", hits[0].Snippet)
+		assert.Equal(t, []int{8, 9, 10}, hits[0].Matches)
+	})
+
+	t.Run("空の検索語", func(t *testing.T) {
+		_, err := s.Search(ctx, "  ", SearchOptions{})
+		assert.True(t, errors.Is(err, ErrEmptyQuery))
+	})
+
+	t.Run("削除したタスクは索引からも消える", func(t *testing.T) {
+		require.NoError(t, s.DeleteTask(ctx, "t2"))
+		hits, err := s.Search(ctx, "グラフ", SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, hits)
+
+		var count int
+		require.NoError(t, s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM transcript_fts").Scan(&count))
+		assert.Equal(t, 2, count)
+	})
+}
+
+func TestRepository_SearchIndexesExistingTranscripts(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), FileName)
+
+	// 索引を作る前のバージョンで保存したトランスクリプトも検索できる
+	old, err := open(ctx, path, 4)
+	require.NoError(t, err)
+	authPrompt, _ := seedTranscripts(t, old)
+	require.NoError(t, old.Close())
+
+	s, err := Open(ctx, path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	hits, err := s.Search(ctx, "公開鍵", SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, authPrompt, hits[0].PromptID)
+}
+
+func TestRepository_SessionTranscript(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	authPrompt, _ := seedTranscripts(t, s)
+
+	transcript, err := s.SessionTranscript(ctx, "s1")
+	require.NoError(t, err)
+	require.Len(t, transcript, 1)
+	assert.Equal(t, authPrompt, transcript[0].Prompt.ID)
+	require.Len(t, transcript[0].Chunks, 1)
+	assert.Contains(t, transcript[0].Chunks[0].Content, "公開鍵")
+
+	transcript, err = s.SessionTranscript(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, transcript)
+}
+
+func TestRepository_HistoryOffset(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	require.NoError(t, s.SaveTask(ctx, Task{ID: "t1", Name: "auth", CreatedAt: testTime(0)}))
+
+	offset, err := s.HistoryOffset(ctx, "t1")
+	require.NoError(t, err)
+	assert.Zero(t, offset)
+
+	require.NoError(t, s.SetHistoryOffset(ctx, "t1", 120))
+	require.NoError(t, s.SetHistoryOffset(ctx, "t1", 240))
+	offset, err = s.HistoryOffset(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(240), offset)
+}
+
+func TestMatchPositions(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  []int
+	}{
+		{name: "日本語", text: "認証の整理", terms: []string{"整理"}, want: []int{3, 4}},
+		{name: "大文字と小文字を区別しない", text: "Use JWT", terms: []string{"jwt"}, want: []int{4, 5, 6}},
+		{name: "複数の検索語と重なり", text: "abcabc", terms: []string{"bc", "ca"}, want: []int{1, 2, 3, 4, 5}},
+		{name: "一致なし", text: "abc", terms: []string{"x"}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchPositions(tt.text, tt.terms))
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := "前置き" + strings.Repeat("あ", 40) + "目的の語" + strings.Repeat("い", 40)
+
+	got, matches := snippet(long, []string{"目的"})
+	assert.True(t, len([]rune(got)) < len([]rune(long)))
+	assert.Equal(t, "…", string([]rune(got)[0]))
+	assert.Equal(t, "…", string([]rune(got)[len([]rune(got))-1]))
+	runes := []rune(got)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "目的", string(runes[matches[0]:matches[1]+1]))
+
+	// 改行は空白にまとめる
+	got, _ = snippet("1行目\n\n2行目", []string{"2行"})
+	assert.Equal(t, "1行目 2行目", got)
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Snapshot はタスクのセッションを再開するために保存する画面の状態
+type Snapshot struct {
+	TaskID       string    // タスクID
+	SessionID    string    // Claude CodeのセッションID (空の場合は未実行)
+	Output       []string  // メインビューの出力行
+	Input        string    // 送信前の入力
+	CursorPos    int       // 入力欄のカーソル位置 (rune単位)
+	ScrollOffset int       // 出力のスクロール位置
+	SavedAt      time.Time // 保存日時
+}
+
+// SaveSnapshot はタスクの画面の状態を保存する
+// タスクごとに最新の1件だけを保持する
+func (r *Repository) SaveSnapshot(ctx context.Context, s Snapshot) error {
+	output, err := json.Marshal(s.Output)
+	if err != nil {
+		return fmt.Errorf("画面の状態の保存に失敗しました: %w", err)
+	}
+
+	_, err = r.q.ExecContext(ctx, `
+INSERT INTO view_snapshots (task_id, session_id, output, input, cursor_pos, scroll_offset, saved_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (task_id) DO UPDATE SET
+	session_id = excluded.session_id, output = excluded.output, input = excluded.input,
+	cursor_pos = excluded.cursor_pos, scroll_offset = excluded.scroll_offset, saved_at = excluded.saved_at`,
+		s.TaskID, s.SessionID, string(output), s.Input, s.CursorPos, s.ScrollOffset, unixNano(s.SavedAt))
+	if err != nil {
+		return fmt.Errorf("画面の状態の保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Snapshot はタスクの最後に保存した画面の状態を取得する
+func (r *Repository) Snapshot(ctx context.Context, taskID string) (*Snapshot, error) {
+	row := r.q.QueryRowContext(ctx, `
+SELECT task_id, session_id, output, input, cursor_pos, scroll_offset, saved_at
+FROM view_snapshots WHERE task_id = ?`, taskID)
+
+	var s Snapshot
+	var output string
+	var savedAt int64
+	if err := row.Scan(&s.TaskID, &s.SessionID, &output, &s.Input, &s.CursorPos, &s.ScrollOffset, &savedAt); err != nil {
+		return nil, notFound(err, "タスクの画面", taskID)
+	}
+	if err := json.Unmarshal([]byte(output), &s.Output); err != nil {
+		return nil, fmt.Errorf("タスク %s の画面の状態が不正です: %w", taskID, err)
+	}
+	s.SavedAt = fromUnixNano(savedAt)
+	return &s, nil
+}
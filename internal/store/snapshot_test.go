@@ -0,0 +1,36 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	require.NoError(t, s.SaveTask(ctx, Task{ID: "t1", Name: "auth", CreatedAt: testTime(0)}))
+
+	_, err := s.Snapshot(ctx, "t1")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	first := Snapshot{TaskID: "t1", Output: []string{"> こんにちは", "\x1b[31m赤\x1b[0m"}, Input: "続き", CursorPos: 1, ScrollOffset: 1, SavedAt: testTime(1)}
+	require.NoError(t, s.SaveSnapshot(ctx, first))
+
+	got, err := s.Snapshot(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, &first, got)
+
+	// タスクごとに最新の1件だけを保持する
+	latest := Snapshot{TaskID: "t1", SessionID: "s1", Output: []string{}, SavedAt: testTime(2)}
+	require.NoError(t, s.SaveSnapshot(ctx, latest))
+	got, err = s.Snapshot(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, &latest, got)
+
+	// 存在しないタスクには保存できない
+	assert.Error(t, s.SaveSnapshot(ctx, Snapshot{TaskID: "missing", SavedAt: testTime(0)}))
+}
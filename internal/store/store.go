@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	// cgoなしでビルドできるpure GoのSQLiteドライバ
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// FileName はプロジェクト内のデータベースファイル名
+	FileName = "ccforge.db"
+	// dirName はデータベースを置くプロジェクト内のディレクトリ名
+	dirName = "ccforge"
+)
+
+var (
+	// ErrNotFound は対象のレコードが存在しない場合のエラー
+	ErrNotFound = errors.New("見つかりません")
+	// ErrSchemaTooNew はデータベースがこのバージョンより新しいccforgeで作成された場合のエラー
+	ErrSchemaTooNew = errors.New("データベースのスキーマが新しすぎます")
+)
+
+// DefaultPath はプロジェクトのデータベースファイルのパスを返す
+func DefaultPath(projectRoot string) string {
+	return filepath.Join(projectRoot, dirName, FileName)
+}
+
+// Store はSQLiteに保存したタスク、セッション、プロンプト、トランスクリプト
+// 読み書きは埋め込んだ Repository のメソッドで行い、複数の変更をまとめる場合は Update を使う
+type Store struct {
+	Repository
+	db   *sql.DB
+	path string
+}
+
+// Open はデータベースを開き、スキーマを最新のバージョンに更新する
+// ファイルが存在しない場合は作成する
+func Open(ctx context.Context, path string) (*Store, error) {
+	return open(ctx, path, SchemaVersion)
+}
+
+// open は target のスキーマバージョンまで更新してデータベースを開く
+func open(ctx context.Context, path string, target int) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("データベースのディレクトリの作成に失敗しました: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dsn(path))
+	if err != nil {
+		return nil, fmt.Errorf("データベース %s を開けません: %w", path, err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("データベース %s を開けません: %w", path, err)
+	}
+	if err := migrate(ctx, db, target); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	slog.Debug("データベースを開きました", "component", "store", "path", path)
+	return &Store{Repository: Repository{q: db}, db: db, path: path}, nil
+}
+
+// dsn は接続ごとに外部キー制約とロック待ちを有効にする接続文字列を返す
+func dsn(path string) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	return "file:" + path + "?" + params.Encode()
+}
+
+// Path はデータベースファイルのパスを返す
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Close はデータベースを閉じる
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Update はfnを1つのトランザクションで実行する
+// fnがエラーを返した場合はすべての変更を取り消す
+func (s *Store) Update(ctx context.Context, fn func(r *Repository) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("トランザクションの開始に失敗しました: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&Repository{q: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションの確定に失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestStore は一時ディレクトリのデータベースを開く
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(context.Background(), filepath.Join(t.TempDir(), FileName))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// testTime はテスト用の日時を返す
+func testTime(minute int) time.Time {
+	return time.Date(2025, 1, 2, 3, minute, 0, 0, time.UTC)
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", FileName)
+
+	s, err := Open(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, path, s.Path())
+
+	version, err := schemaVersion(ctx, s.db)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, version)
+	require.NoError(t, s.Close())
+
+	// 2回目以降はスキーマを変更しない
+	s, err = Open(ctx, path)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+}
+
+func TestRepository_Tasks(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	require.NoError(t, s.SaveTask(ctx, Task{ID: "b1", Name: "refactor", CreatedAt: testTime(1)}))
+	require.NoError(t, s.SaveTask(ctx, Task{ID: "a1", Name: "auth", Description: "認証", CreatedAt: testTime(2)}))
+
+	// 同じIDは更新する (作成日時は変えない)
+	require.NoError(t, s.SaveTask(ctx, Task{ID: "a1", Name: "auth", Description: "認証の刷新", CreatedAt: testTime(9), Archived: true}))
+
+	got, err := s.TaskByName(ctx, "auth")
+	require.NoError(t, err)
+	assert.Equal(t, &Task{ID: "a1", Name: "auth", Description: "認証の刷新", CreatedAt: testTime(2), Archived: true}, got)
+
+	tasks, err := s.Tasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, []string{"auth", "refactor"}, []string{tasks[0].Name, tasks[1].Name})
+
+	_, err = s.Task(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.EqualError(t, err, "タスク missing が見つかりません")
+
+	// 同名の別タスクは保存できない
+	assert.Error(t, s.SaveTask(ctx, Task{ID: "c1", Name: "auth", CreatedAt: testTime(3)}))
+}
+
+func TestRepository_SessionHistory(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	require.NoError(t, s.SaveTask(ctx, Task{ID: "t1", Name: "auth", CreatedAt: testTime(0)}))
+
+	require.NoError(t, s.StartSession(ctx, Session{ID: "s2", TaskID: "t1", StartedAt: testTime(5)}))
+	require.NoError(t, s.StartSession(ctx, Session{ID: "s1", TaskID: "t1", StartedAt: testTime(1)}))
+	// 再開したセッションは開始日時を変えない
+	require.NoError(t, s.StartSession(ctx, Session{ID: "s1", TaskID: "t1", StartedAt: testTime(8)}))
+	require.NoError(t, s.EndSession(ctx, "s1", testTime(4)))
+
+	sessions, err := s.Sessions(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, []Session{
+		{ID: "s1", TaskID: "t1", StartedAt: testTime(1), EndedAt: testTime(4)},
+		{ID: "s2", TaskID: "t1", StartedAt: testTime(5)},
+	}, sessions)
+
+	promptID, err := s.AddPrompt(ctx, "s1", "テストを実行して", testTime(2))
+	require.NoError(t, err)
+	for _, chunk := range []struct {
+		stream  Stream
+		content string
+	}{{Stdout, "実行します\n"}, {Stderr, "warning\n"}, {Stdout, "完了しました\n"}} {
+		_, err := s.AppendChunk(ctx, promptID, chunk.stream, chunk.content, testTime(3))
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.CompletePrompt(ctx, promptID, 1, testTime(3)))
+
+	prompts, err := s.Prompts(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, []Prompt{{ID: promptID, SessionID: "s1", Text: "テストを実行して", SentAt: testTime(2), CompletedAt: testTime(3), ExitCode: 1}}, prompts)
+
+	chunks, err := s.Transcript(ctx, promptID)
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	for i, c := range chunks {
+		assert.Equal(t, i, c.Seq)
+	}
+	assert.Equal(t, Stderr, chunks[1].Stream)
+	assert.Equal(t, "完了しました\n", chunks[2].Content)
+
+	// タスクを削除すると履歴もすべて削除される
+	require.NoError(t, s.DeleteTask(ctx, "t1"))
+	_, err = s.Session(ctx, "s1")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	chunks, err = s.Transcript(ctx, promptID)
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+}
+
+func TestRepository_ForeignKeys(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	assert.Error(t, s.StartSession(ctx, Session{ID: "s1", TaskID: "missing", StartedAt: testTime(0)}))
+	_, err := s.AddPrompt(ctx, "missing", "hello", testTime(0))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(s.EndSession(ctx, "missing", testTime(0)), ErrNotFound))
+	assert.True(t, errors.Is(s.CompletePrompt(ctx, 42, 0, testTime(0)), ErrNotFound))
+}
+
+func TestStore_Update(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	// エラーを返すとすべての変更を取り消す
+	errAbort := errors.New("中断")
+	err := s.Update(ctx, func(r *Repository) error {
+		if err := r.SaveTask(ctx, Task{ID: "t1", Name: "auth", CreatedAt: testTime(0)}); err != nil {
+			return err
+		}
+		if err := r.StartSession(ctx, Session{ID: "s1", TaskID: "t1", StartedAt: testTime(1)}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+	tasks, err := s.Tasks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	// 成功すればまとめて確定する
+	err = s.Update(ctx, func(r *Repository) error {
+		if err := r.SaveTask(ctx, Task{ID: "t1", Name: "auth", CreatedAt: testTime(0)}); err != nil {
+			return err
+		}
+		return r.StartSession(ctx, Session{ID: "s1", TaskID: "t1", StartedAt: testTime(1)})
+	})
+	require.NoError(t, err)
+	_, err = s.Session(ctx, "s1")
+	assert.NoError(t, err)
+}
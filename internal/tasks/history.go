@@ -0,0 +1,141 @@
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// historyFileName はタスクの実行履歴を保存するファイル名
+const historyFileName = ".history.jsonl"
+
+// maxHistoryLine は履歴1件あたりの最大サイズ
+const maxHistoryLine = 16 << 20
+
+// HistoryEntry はClaude Codeとのやり取り1回分の履歴
+type HistoryEntry struct {
+	Time      time.Time `json:"time"`                 // 実行日時
+	SessionID string    `json:"session_id,omitempty"` // セッションID
+	Prompt    string    `json:"prompt"`               // 送信したプロンプト
+	Output    string    `json:"output"`               // 応答の出力
+	ExitCode  int       `json:"exit_code"`            // Claude Code CLIの終了コード
+}
+
+// AppendHistory はタスクの実行履歴に1件追記する
+func (m *Manager) AppendHistory(name string, entry HistoryEntry) error {
+	task, err := m.Get(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("履歴の保存に失敗しました: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(task.Dir, historyFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("履歴の保存に失敗しました: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("履歴の保存に失敗しました: %w", err)
+	}
+	return f.Close()
+}
+
+// History はタスクの実行履歴を古い順に取得する
+func (m *Manager) History(name string) ([]HistoryEntry, error) {
+	task, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(task.Dir, historyFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []HistoryEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("履歴の読み込みに失敗しました: %w", err)
+	}
+	defer f.Close()
+
+	entries := []HistoryEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxHistoryLine)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("履歴 %s:%d が不正です: %w", f.Name(), line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("履歴の読み込みに失敗しました: %w", err)
+	}
+
+	return entries, nil
+}
+
+// HistorySince はタスクの実行履歴のうちoffsetバイト目以降を古い順に取得し、読み終えた位置を返す
+// アーカイブ済みのタスクも読めるようタスクのディレクトリから読み込む
+// 書き込み途中の最後の行は読まずに残し、不正な行は読み飛ばす
+// ファイルがoffsetより短い (作り直された) 場合は先頭から読み込む
+func HistorySince(task Task, offset int64) ([]HistoryEntry, int64, error) {
+	f, err := os.Open(filepath.Join(task.Dir, historyFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []HistoryEntry{}, 0, nil
+	}
+	if err != nil {
+		return nil, offset, fmt.Errorf("履歴の読み込みに失敗しました: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, offset, fmt.Errorf("履歴の読み込みに失敗しました: %w", err)
+	}
+	if info.Size() < offset {
+		slog.Warn("履歴が短くなったため先頭から読み込みます", "component", "tasks", "task", task.Name, "offset", offset, "size", info.Size())
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, fmt.Errorf("履歴の読み込みに失敗しました: %w", err)
+	}
+
+	entries := []HistoryEntry{}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// 改行で終わっていない行は次回に読む
+			return entries, offset, nil
+		}
+		if err != nil {
+			return nil, offset, fmt.Errorf("履歴の読み込みに失敗しました: %w", err)
+		}
+
+		var entry HistoryEntry
+		switch err := json.Unmarshal(line, &entry); {
+		case len(bytes.TrimSpace(line)) == 0:
+		case err != nil:
+			// 壊れた行で取り込みが止まらないよう読み飛ばす
+			slog.Warn("不正な履歴を読み飛ばします", "component", "tasks", "path", f.Name(), "offset", offset, "error", err)
+		default:
+			entries = append(entries, entry)
+		}
+		offset += int64(len(line))
+	}
+}
@@ -0,0 +1,127 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_History(t *testing.T) {
+	m := NewManager(t.TempDir())
+	_, err := m.Create("feature", "")
+	require.NoError(t, err)
+
+	entries, err := m.History("feature")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	first := HistoryEntry{
+		Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		SessionID: "abc",
+		Prompt:    "設計して",
+		Output:    "了解しました\n複数行の出力\n",
+	}
+	second := HistoryEntry{Time: first.Time.Add(time.Minute), Prompt: "実装して", ExitCode: 1}
+	require.NoError(t, m.AppendHistory("feature", first))
+	require.NoError(t, m.AppendHistory("feature", second))
+
+	entries, err = m.History("feature")
+	require.NoError(t, err)
+	assert.Equal(t, []HistoryEntry{first, second}, entries)
+
+	t.Run("異常系_不正な行", func(t *testing.T) {
+		path := filepath.Join(m.TaskDir("feature"), historyFileName)
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = f.WriteString("{broken\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		_, err = m.History("feature")
+		assert.ErrorContains(t, err, ":3 が不正です")
+	})
+
+	_, err = m.History("missing")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestManager_SetSessionID(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root)
+	created, err := m.Create("feature", "概要")
+	require.NoError(t, err)
+
+	require.NoError(t, m.SetSessionID("feature", "session-1"))
+	task, err := m.Get("feature")
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", task.SessionID)
+	assert.Equal(t, created.ID, task.ID)
+	assert.Equal(t, "概要", task.Description)
+
+	// メタ情報のない手動作成のタスクにはIDを割り当てる
+	require.NoError(t, os.MkdirAll(filepath.Join(root, DirName, "manual"), 0o755))
+	require.NoError(t, m.SetSessionID("manual", "session-2"))
+	task, err = m.Get("manual")
+	require.NoError(t, err)
+	assert.Equal(t, "session-2", task.SessionID)
+	assert.Len(t, task.ID, 16)
+}
+
+func TestHistorySince(t *testing.T) {
+	m := NewManager(t.TempDir())
+	task, err := m.Create("feature", "")
+	require.NoError(t, err)
+	path := filepath.Join(task.Dir, historyFileName)
+
+	entries, offset, err := HistorySince(*task, 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Zero(t, offset)
+
+	first := HistoryEntry{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Prompt: "設計して", Output: "了解しました\n"}
+	second := HistoryEntry{Time: first.Time.Add(time.Minute), Prompt: "実装して"}
+	require.NoError(t, m.AppendHistory("feature", first))
+
+	entries, offset, err = HistorySince(*task, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []HistoryEntry{first}, entries)
+
+	// 続きだけを読み込む (不正な行と書き込み途中の行は含めない)
+	require.NoError(t, m.AppendHistory("feature", second))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("{broken\n{\"prompt\": \"書き込み中")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries, next, err := HistorySince(*task, offset)
+	require.NoError(t, err)
+	assert.Equal(t, []HistoryEntry{second}, entries)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Less(t, next, info.Size())
+
+	entries, again, err := HistorySince(*task, next)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, next, again)
+
+	// アーカイブ済みのタスクも読める
+	_, err = m.Archive("feature")
+	require.NoError(t, err)
+	archived, err := m.ListArchived()
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	entries, _, err = HistorySince(archived[0], 0)
+	require.NoError(t, err)
+	assert.Equal(t, []HistoryEntry{first, second}, entries)
+
+	// ファイルが短くなった場合は先頭から読み込む
+	entries, _, err = HistorySince(archived[0], 1<<20)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,461 @@
+package tasks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DirName はプロジェクト内でタスクを格納するディレクトリ名
+	DirName = "ccforge"
+	// ArchiveDirName はアーカイブしたタスクを格納するディレクトリ名
+	ArchiveDirName = ".archive"
+	// metaFileName はタスクのメタ情報を保存するファイル名
+	metaFileName = ".meta.json"
+	// BranchPrefix はタスクごとのgitブランチ名の接頭辞
+	BranchPrefix = "task/"
+)
+
+var (
+	// ErrTaskNotFound はタスクが存在しない場合のエラー
+	ErrTaskNotFound = errors.New("タスクが見つかりません")
+	// ErrTaskExists は同名のタスクが既に存在する場合のエラー
+	ErrTaskExists = errors.New("タスクは既に存在します")
+	// ErrInvalidName はタスク名として使えない文字列の場合のエラー
+	ErrInvalidName = errors.New("タスク名が不正です")
+)
+
+// namePattern はタスク名として使える文字列のパターン
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Status はtasks.mdのチェックリストから判定したタスクの状態
+type Status int
+
+const (
+	// StatusNotStarted は完了した項目がない状態
+	StatusNotStarted Status = iota
+	// StatusInProgress は一部の項目が完了した状態
+	StatusInProgress
+	// StatusDone は全ての項目が完了した状態
+	StatusDone
+)
+
+// String は状態の表示名を返す
+func (s Status) String() string {
+	switch s {
+	case StatusNotStarted:
+		return "未着手"
+	case StatusInProgress:
+		return "進行中"
+	case StatusDone:
+		return "完了"
+	default:
+		return "不明"
+	}
+}
+
+// Key はJSON出力などで使う状態の識別子を返す
+func (s Status) Key() string {
+	switch s {
+	case StatusNotStarted:
+		return "not_started"
+	case StatusInProgress:
+		return "in_progress"
+	case StatusDone:
+		return "done"
+	default:
+		return "unknown"
+	}
+}
+
+// Progress はtasks.mdのチェックリストの進捗
+type Progress struct {
+	Done  int // 完了した項目数
+	Total int // 全項目数
+}
+
+// Percent は進捗率 (0〜100) を返す
+func (p Progress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Done * 100 / p.Total
+}
+
+// Status は進捗からタスクの状態を判定する
+func (p Progress) Status() Status {
+	switch {
+	case p.Total > 0 && p.Done == p.Total:
+		return StatusDone
+	case p.Done > 0:
+		return StatusInProgress
+	default:
+		return StatusNotStarted
+	}
+}
+
+// Task はプロジェクト内のタスク
+type Task struct {
+	ID          string    // タスクID
+	Name        string    // タスク名 (ディレクトリ名)
+	Description string    // 概要
+	Dir         string    // タスクのディレクトリ
+	CreatedAt   time.Time // 作成日時
+	UpdatedAt   time.Time // Specsの最終更新日時
+	Progress    Progress  // tasks.mdの進捗
+	Archived    bool      // アーカイブ済みか
+	SessionID   string    // Claude CodeのセッションID (未実行の場合は空)
+	Branch      string    // タスクのgitブランチ (未作成の場合は空)
+	Worktree    string    // タスクのgit worktreeのディレクトリ (未作成の場合は空)
+}
+
+// Status はタスクの状態を返す
+func (t *Task) Status() Status {
+	return t.Progress.Status()
+}
+
+// meta はタスクディレクトリに保存するメタ情報
+type meta struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	SessionID   string    `json:"session_id,omitempty"`
+	Branch      string    `json:"branch,omitempty"`
+	Worktree    string    `json:"worktree,omitempty"`
+}
+
+// Manager はプロジェクトのタスクを管理する構造体
+type Manager struct {
+	root string // プロジェクトのルートディレクトリ
+}
+
+// NewManager は指定したプロジェクトのタスクマネージャーを作成する
+func NewManager(projectRoot string) *Manager {
+	return &Manager{root: projectRoot}
+}
+
+// Dir はタスクを格納するディレクトリを返す
+func (m *Manager) Dir() string {
+	return filepath.Join(m.root, DirName)
+}
+
+// TaskDir はタスクのディレクトリを返す
+func (m *Manager) TaskDir(name string) string {
+	return filepath.Join(m.Dir(), name)
+}
+
+// ValidateName はタスク名として使えるか検証する
+// タスク名はブランチ (task/<タスク名>) とチェックポイントのrefにも使うため、gitのref名の規則にも従う
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q (英数字・'.'・'_'・'-'のみ使用できます)", ErrInvalidName, name)
+	}
+	switch {
+	case strings.Contains(name, ".."):
+		return fmt.Errorf("%w: %q ('..' は使用できません)", ErrInvalidName, name)
+	case strings.HasSuffix(name, "."):
+		return fmt.Errorf("%w: %q ('.' で終わる名前は使用できません)", ErrInvalidName, name)
+	case strings.HasSuffix(name, ".lock"):
+		return fmt.Errorf("%w: %q ('.lock' で終わる名前は使用できません)", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// Create はタスクのディレクトリとSpecsのテンプレートを作成する
+func (m *Manager) Create(name, description string) (*Task, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(m.Dir(), 0o755); err != nil {
+		return nil, fmt.Errorf("タスクディレクトリの作成に失敗しました: %w", err)
+	}
+
+	dir := m.TaskDir(name)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("%w: %s", ErrTaskExists, name)
+		}
+		return nil, fmt.Errorf("タスクディレクトリの作成に失敗しました: %w", err)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	md := meta{ID: id, Description: description, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	if err := writeMeta(dir, md); err != nil {
+		return nil, err
+	}
+
+	for _, spec := range SpecFiles {
+		path := filepath.Join(dir, spec.FileName)
+		if err := os.WriteFile(path, []byte(spec.template(name, description)), 0o644); err != nil {
+			return nil, fmt.Errorf("%s の作成に失敗しました: %w", spec.FileName, err)
+		}
+	}
+
+	slog.Info("タスクを作成しました", "component", "tasks", "task", name, "id", id)
+	return m.load(name, dir)
+}
+
+// List はタスクを名前順に取得する
+// タスクディレクトリが存在しない場合は空のスライスを返す
+func (m *Manager) List() ([]Task, error) {
+	return m.list(m.Dir())
+}
+
+// ListArchived はアーカイブ済みのタスクを名前順に取得する
+func (m *Manager) ListArchived() ([]Task, error) {
+	archived, err := m.list(filepath.Join(m.Dir(), ArchiveDirName))
+	for i := range archived {
+		archived[i].Archived = true
+	}
+	return archived, err
+}
+
+// list は指定ディレクトリ直下のタスクを取得する
+func (m *Manager) list(dir string) ([]Task, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Task{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("タスク一覧の取得に失敗しました: %w", err)
+	}
+
+	tasks := make([]Task, 0, len(entries))
+	for _, entry := range entries {
+		// 設定ファイルやアーカイブなどタスク以外のものは除外する
+		if !entry.IsDir() || ValidateName(entry.Name()) != nil {
+			continue
+		}
+
+		task, err := m.load(entry.Name(), filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks, nil
+}
+
+// Get は指定した名前のタスクを取得する
+func (m *Manager) Get(name string) (*Task, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	dir := m.TaskDir(name)
+	info, err := os.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && !info.IsDir()) {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("タスクの取得に失敗しました: %w", err)
+	}
+
+	return m.load(name, dir)
+}
+
+// Archive はタスクをアーカイブディレクトリへ移動する
+// アーカイブ先に同名のタスクがある場合は日時を付けた名前で移動する
+func (m *Manager) Archive(name string) (string, error) {
+	task, err := m.Get(name)
+	if err != nil {
+		return "", err
+	}
+
+	archiveDir := filepath.Join(m.Dir(), ArchiveDirName)
+	if err := os.MkdirAll(archiveDir, 0o755); err != nil {
+		return "", fmt.Errorf("アーカイブディレクトリの作成に失敗しました: %w", err)
+	}
+
+	dest := filepath.Join(archiveDir, name)
+	if _, err := os.Stat(dest); err == nil {
+		dest = filepath.Join(archiveDir, name+"-"+time.Now().Format("20060102-150405"))
+	}
+
+	if err := os.Rename(task.Dir, dest); err != nil {
+		return "", fmt.Errorf("タスクのアーカイブに失敗しました: %w", err)
+	}
+	slog.Info("タスクをアーカイブしました", "component", "tasks", "task", name, "dest", dest)
+	return dest, nil
+}
+
+// SetSessionID はタスクに紐づくClaude CodeのセッションIDを保存する
+func (m *Manager) SetSessionID(name, sessionID string) error {
+	task, md, err := m.ensureMeta(name)
+	if err != nil {
+		return err
+	}
+	md.SessionID = sessionID
+	slog.Debug("セッションIDを保存します", "component", "tasks", "task", name, "session_id", sessionID)
+	return writeMeta(task.Dir, md)
+}
+
+// BranchName はタスクのgitブランチ名 (task/<タスク名>) を返す
+func BranchName(name string) string {
+	return BranchPrefix + name
+}
+
+// SetBranch はタスクのgitブランチを保存する
+func (m *Manager) SetBranch(name, branch string) error {
+	task, md, err := m.ensureMeta(name)
+	if err != nil {
+		return err
+	}
+	md.Branch = branch
+	slog.Debug("ブランチを保存します", "component", "tasks", "task", name, "branch", branch)
+	return writeMeta(task.Dir, md)
+}
+
+// SetWorktree はタスクのgit worktreeのディレクトリを保存する
+// 空文字列を指定すると記録を削除する
+func (m *Manager) SetWorktree(name, dir string) error {
+	task, md, err := m.ensureMeta(name)
+	if err != nil {
+		return err
+	}
+	md.Worktree = dir
+	slog.Debug("worktreeを保存します", "component", "tasks", "task", name, "worktree", dir)
+	return writeMeta(task.Dir, md)
+}
+
+// EnsureID はタスクにIDがなければ割り当てて保存し、タスクを返す
+// 手動で作成されたタスクをデータベースに記録する前に使う
+func (m *Manager) EnsureID(name string) (*Task, error) {
+	task, md, err := m.ensureMeta(name)
+	if err != nil {
+		return nil, err
+	}
+	if task.ID != "" {
+		return task, nil
+	}
+	if err := writeMeta(task.Dir, md); err != nil {
+		return nil, err
+	}
+	task.ID = md.ID
+	task.CreatedAt = md.CreatedAt
+	return task, nil
+}
+
+// ensureMeta はタスクとIDを割り当て済みのメタ情報を返す
+// IDの割り当ては保存しないため、呼び出し側で writeMeta する
+func (m *Manager) ensureMeta(name string) (*Task, meta, error) {
+	task, err := m.Get(name)
+	if err != nil {
+		return nil, meta{}, err
+	}
+
+	md, err := readMeta(task.Dir)
+	if err != nil {
+		return nil, meta{}, err
+	}
+	if md.ID == "" {
+		// 手動で作成されたタスクにはここでIDを割り当てる
+		if md.ID, err = newID(); err != nil {
+			return nil, meta{}, err
+		}
+		md.CreatedAt = task.CreatedAt.UTC().Truncate(time.Second)
+	}
+	return task, md, nil
+}
+
+// load はタスクディレクトリからタスクの情報を読み込む
+// メタ情報がない (手動で作成された) タスクはディレクトリの情報で補う
+func (m *Manager) load(name, dir string) (*Task, error) {
+	md, err := readMeta(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &Task{
+		ID:          md.ID,
+		Name:        name,
+		Description: md.Description,
+		Dir:         dir,
+		CreatedAt:   md.CreatedAt,
+		SessionID:   md.SessionID,
+		Branch:      md.Branch,
+		Worktree:    md.Worktree,
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("タスクの取得に失敗しました: %w", err)
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = info.ModTime()
+	}
+	task.UpdatedAt = info.ModTime()
+
+	for _, spec := range SpecFiles {
+		info, err := os.Stat(filepath.Join(dir, spec.FileName))
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(task.UpdatedAt) {
+			task.UpdatedAt = info.ModTime()
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, TasksFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s の読み込みに失敗しました: %w", TasksFile, err)
+	}
+	task.Progress = ParseProgress(string(content))
+
+	return task, nil
+}
+
+// readMeta はメタ情報を読み込む (存在しない場合はゼロ値を返す)
+func readMeta(dir string) (meta, error) {
+	var md meta
+
+	data, err := os.ReadFile(filepath.Join(dir, metaFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return md, nil
+	}
+	if err != nil {
+		return md, fmt.Errorf("タスク情報の読み込みに失敗しました: %w", err)
+	}
+	if err := json.Unmarshal(data, &md); err != nil {
+		return md, fmt.Errorf("タスク情報 %s が不正です: %w", filepath.Join(dir, metaFileName), err)
+	}
+	return md, nil
+}
+
+// writeMeta はメタ情報を書き込む
+func writeMeta(dir string, md meta) error {
+	data, err := json.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return fmt.Errorf("タスク情報の保存に失敗しました: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, metaFileName), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("タスク情報の保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// newID はランダムなタスクIDを生成する
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("タスクIDの生成に失敗しました: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
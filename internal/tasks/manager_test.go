@@ -0,0 +1,204 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Create(t *testing.T) {
+	m := NewManager(t.TempDir())
+
+	task, err := m.Create("auth-refactor", "認証処理を整理する")
+	require.NoError(t, err)
+
+	assert.Equal(t, "auth-refactor", task.Name)
+	assert.Equal(t, "認証処理を整理する", task.Description)
+	assert.Len(t, task.ID, 16)
+	assert.False(t, task.CreatedAt.IsZero())
+	assert.Equal(t, Progress{Done: 0, Total: 4}, task.Progress)
+	assert.Equal(t, StatusNotStarted, task.Status())
+	assert.Equal(t, "not_started", task.Status().Key())
+
+	for _, spec := range SpecFiles {
+		assert.FileExists(t, filepath.Join(m.TaskDir("auth-refactor"), spec.FileName))
+	}
+	content, err := os.ReadFile(filepath.Join(task.Dir, RequirementsFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "認証処理を整理する")
+
+	t.Run("異常系_同名のタスク", func(t *testing.T) {
+		_, err := m.Create("auth-refactor", "")
+		assert.ErrorIs(t, err, ErrTaskExists)
+	})
+
+	t.Run("異常系_不正な名前", func(t *testing.T) {
+		for _, name := range []string{"", ".archive", "a/b", "-x", "日本語"} {
+			_, err := m.Create(name, "")
+			assert.ErrorIs(t, err, ErrInvalidName, name)
+		}
+	})
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		task    string
+		wantErr string
+	}{
+		{name: "正常系_英数字と記号", task: "auth-refactor_v1.2"},
+		{name: "正常系_途中のlock", task: "x.locked"},
+		{name: "異常系_空", task: "", wantErr: "英数字"},
+		{name: "異常系_連続するドット", task: "a..b", wantErr: "'..'"},
+		{name: "異常系_ドットで終わる", task: "release.", wantErr: "'.' で終わる"},
+		{name: "異常系_lockで終わる", task: "x.lock", wantErr: "'.lock'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.task)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidName)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestManager_List(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root)
+
+	t.Run("タスクディレクトリがない場合は空", func(t *testing.T) {
+		tasks, err := m.List()
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+
+	_, err := m.Create("b-task", "")
+	require.NoError(t, err)
+	_, err = m.Create("a-task", "")
+	require.NoError(t, err)
+
+	// 手動で作成したタスクと設定ファイル
+	manual := filepath.Join(root, DirName, "manual")
+	require.NoError(t, os.MkdirAll(manual, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(manual, TasksFile), []byte("- [x] a\n- [ ] b\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, DirName, "config.toml"), nil, 0o644))
+
+	tasks, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	assert.Equal(t, "a-task", tasks[0].Name)
+	assert.Equal(t, "b-task", tasks[1].Name)
+	assert.Equal(t, "manual", tasks[2].Name)
+	assert.Empty(t, tasks[2].ID)
+	assert.Equal(t, StatusInProgress, tasks[2].Status())
+	assert.Equal(t, 50, tasks[2].Progress.Percent())
+}
+
+func TestManager_Get(t *testing.T) {
+	m := NewManager(t.TempDir())
+	created, err := m.Create("feature", "")
+	require.NoError(t, err)
+
+	task, err := m.Get("feature")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, task.ID)
+
+	_, err = m.Get("missing")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestManager_EnsureID(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root)
+	created, err := m.Create("feature", "")
+	require.NoError(t, err)
+
+	task, err := m.EnsureID("feature")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, task.ID)
+
+	// メタ情報のない手動作成のタスクにはIDを割り当てて保存する
+	require.NoError(t, os.MkdirAll(filepath.Join(root, DirName, "manual"), 0o755))
+	task, err = m.EnsureID("manual")
+	require.NoError(t, err)
+	assert.Len(t, task.ID, 16)
+	reloaded, err := m.Get("manual")
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, reloaded.ID)
+
+	_, err = m.EnsureID("missing")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestManager_SetBranch(t *testing.T) {
+	m := NewManager(t.TempDir())
+	_, err := m.Create("auth", "")
+	require.NoError(t, err)
+	assert.Equal(t, "task/auth", BranchName("auth"))
+
+	require.NoError(t, m.SetBranch("auth", BranchName("auth")))
+	task, err := m.Get("auth")
+	require.NoError(t, err)
+	assert.Equal(t, "task/auth", task.Branch)
+
+	assert.ErrorIs(t, m.SetBranch("missing", "task/missing"), ErrTaskNotFound)
+}
+
+func TestManager_SetWorktree(t *testing.T) {
+	m := NewManager(t.TempDir())
+	_, err := m.Create("auth", "")
+	require.NoError(t, err)
+
+	require.NoError(t, m.SetWorktree("auth", "/tmp/project-worktrees/auth"))
+	task, err := m.Get("auth")
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/project-worktrees/auth", task.Worktree)
+
+	require.NoError(t, m.SetWorktree("auth", ""))
+	task, err = m.Get("auth")
+	require.NoError(t, err)
+	assert.Empty(t, task.Worktree)
+
+	assert.ErrorIs(t, m.SetWorktree("missing", "/tmp/missing"), ErrTaskNotFound)
+}
+
+func TestManager_Archive(t *testing.T) {
+	m := NewManager(t.TempDir())
+	_, err := m.Create("old", "")
+	require.NoError(t, err)
+
+	dest, err := m.Archive("old")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(m.Dir(), ArchiveDirName, "old"), dest)
+	assert.DirExists(t, dest)
+
+	// アーカイブ済みのタスクは一覧に含まれない
+	tasks, err := m.List()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	archived, err := m.ListArchived()
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	assert.Equal(t, "old", archived[0].Name)
+	assert.True(t, archived[0].Archived)
+
+	// 同名のタスクを再度アーカイブしても上書きしない
+	_, err = m.Create("old", "")
+	require.NoError(t, err)
+	dest2, err := m.Archive("old")
+	require.NoError(t, err)
+	assert.NotEqual(t, dest, dest2)
+	assert.DirExists(t, dest)
+
+	_, err = m.Archive("missing")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
@@ -0,0 +1,172 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// RequirementsFile は要件定義のファイル名
+	RequirementsFile = "requirements.md"
+	// DesignFile は設計書のファイル名
+	DesignFile = "design.md"
+	// TasksFile はタスク管理のファイル名
+	TasksFile = "tasks.md"
+)
+
+// SpecFile はタスクを構成するSpecsファイルの定義
+type SpecFile struct {
+	Kind     string // 種別 (requirements, design, tasks)
+	FileName string // ファイル名
+	Title    string // 表示名
+	template func(name, description string) string
+}
+
+// SpecFiles はタスクを構成するSpecsファイルを表示順に並べたもの
+var SpecFiles = []SpecFile{
+	{
+		Kind:     "requirements",
+		FileName: RequirementsFile,
+		Title:    "要件定義",
+		template: func(name, description string) string {
+			if description == "" {
+				description = "このタスクで実現したいことを記述する。"
+			}
+			return fmt.Sprintf("# %s 要件定義\n\n## 概要\n\n%s\n\n## 要件\n\n- \n\n## 受け入れ条件\n\n- \n", name, description)
+		},
+	},
+	{
+		Kind:     "design",
+		FileName: DesignFile,
+		Title:    "設計書",
+		template: func(name, _ string) string {
+			return fmt.Sprintf("# %s 設計書\n\n## 方針\n\n## 構成\n\n## 検討事項\n", name)
+		},
+	},
+	{
+		Kind:     "tasks",
+		FileName: TasksFile,
+		Title:    "タスク",
+		template: func(name, _ string) string {
+			return fmt.Sprintf("# %s タスク\n\n- [ ] 要件定義を書く\n- [ ] 設計書を書く\n- [ ] 実装する\n- [ ] テストする\n", name)
+		},
+	},
+}
+
+// LookupSpecFile は種別またはファイル名からSpecsファイルの定義を取得する
+func LookupSpecFile(kind string) (SpecFile, bool) {
+	for _, spec := range SpecFiles {
+		if spec.Kind == kind || spec.FileName == kind {
+			return spec, true
+		}
+	}
+	return SpecFile{}, false
+}
+
+// Spec は読み込んだSpecsファイル
+type Spec struct {
+	SpecFile
+	Path    string // ファイルのパス
+	Content string // ファイルの内容
+	Exists  bool   // ファイルが存在するか
+}
+
+// Specs はタスクのSpecsファイルを読み込む
+// 存在しないファイルはExistsがfalseのSpecとして返す
+func (m *Manager) Specs(name string) ([]Spec, error) {
+	task, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]Spec, 0, len(SpecFiles))
+	for _, file := range SpecFiles {
+		spec := Spec{SpecFile: file, Path: filepath.Join(task.Dir, file.FileName)}
+
+		content, err := os.ReadFile(spec.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("%s の読み込みに失敗しました: %w", file.FileName, err)
+		default:
+			spec.Content = string(content)
+			spec.Exists = true
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+// Checklist はタスクのtasks.mdからチェックリストの項目を取得する
+func (m *Manager) Checklist(name string) ([]ChecklistItem, error) {
+	task, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(task.Dir, TasksFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s の読み込みに失敗しました: %w", TasksFile, err)
+	}
+	return ParseChecklist(string(content)), nil
+}
+
+// checkboxPattern はMarkdownのチェックリスト項目にマッチする
+var checkboxPattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]`)
+
+// ChecklistItem はMarkdownのチェックリストの1項目
+type ChecklistItem struct {
+	Text string // 項目の本文
+	Done bool   // 完了しているか
+	Line int    // 行番号 (1始まり)
+}
+
+// ParseChecklist はMarkdownからチェックリストの項目を抽出する
+// コードブロック内の項目は含めない
+func ParseChecklist(content string) []ChecklistItem {
+	var items []ChecklistItem
+	inCode := false
+
+	for i, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		match := checkboxPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		items = append(items, ChecklistItem{
+			Text: strings.TrimSpace(line[len(match[0]):]),
+			Done: match[1] != " ",
+			Line: i + 1,
+		})
+	}
+
+	return items
+}
+
+// ParseProgress はMarkdownのチェックリストから進捗を集計する
+func ParseProgress(content string) Progress {
+	var p Progress
+	for _, item := range ParseChecklist(content) {
+		p.Total++
+		if item.Done {
+			p.Done++
+		}
+	}
+	return p
+}
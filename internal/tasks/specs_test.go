@@ -0,0 +1,99 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProgress(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Progress
+		status  Status
+	}{
+		{name: "項目なし", content: "# タスク\n\n本文\n", want: Progress{}, status: StatusNotStarted},
+		{name: "未着手", content: "- [ ] a\n- [ ] b\n", want: Progress{Done: 0, Total: 2}, status: StatusNotStarted},
+		{
+			name:    "進行中_入れ子と記号の違い",
+			content: "- [x] a\n  * [ ] b\n+ [X] c\n",
+			want:    Progress{Done: 2, Total: 3},
+			status:  StatusInProgress,
+		},
+		{name: "完了", content: "- [x] a\n", want: Progress{Done: 1, Total: 1}, status: StatusDone},
+		{
+			name:    "コードブロック内は数えない",
+			content: "- [x] a\n```\n- [ ] example\n```\n",
+			want:    Progress{Done: 1, Total: 1},
+			status:  StatusDone,
+		},
+		{name: "チェックボックスでないリスト", content: "- [リンク](x)\n- []\n", want: Progress{}, status: StatusNotStarted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseProgress(tt.content)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.status, got.Status())
+		})
+	}
+}
+
+func TestParseChecklist(t *testing.T) {
+	items := ParseChecklist("# タスク\n\n- [x] 設計する\n  - [ ]  実装する \n")
+	assert.Equal(t, []ChecklistItem{
+		{Text: "設計する", Done: true, Line: 3},
+		{Text: "実装する", Done: false, Line: 4},
+	}, items)
+}
+
+func TestManager_Checklist(t *testing.T) {
+	m := NewManager(t.TempDir())
+	_, err := m.Create("feature", "")
+	require.NoError(t, err)
+
+	items, err := m.Checklist("feature")
+	require.NoError(t, err)
+	require.Len(t, items, 4)
+	assert.Equal(t, "要件定義を書く", items[0].Text)
+
+	_, err = m.Checklist("missing")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestManager_Specs(t *testing.T) {
+	m := NewManager(t.TempDir())
+	task, err := m.Create("feature", "")
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(task.Dir, DesignFile)))
+
+	specs, err := m.Specs("feature")
+	require.NoError(t, err)
+	require.Len(t, specs, 3)
+
+	assert.Equal(t, "requirements", specs[0].Kind)
+	assert.True(t, specs[0].Exists)
+	assert.Contains(t, specs[0].Content, "# feature 要件定義")
+	assert.False(t, specs[1].Exists)
+	assert.Empty(t, specs[1].Content)
+
+	_, err = m.Specs("missing")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestLookupSpecFile(t *testing.T) {
+	spec, ok := LookupSpecFile("design")
+	require.True(t, ok)
+	assert.Equal(t, DesignFile, spec.FileName)
+
+	spec, ok = LookupSpecFile(TasksFile)
+	require.True(t, ok)
+	assert.Equal(t, "tasks", spec.Kind)
+
+	_, ok = LookupSpecFile("unknown")
+	assert.False(t, ok)
+}
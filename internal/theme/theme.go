@@ -0,0 +1,239 @@
+package theme
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Role は色の意味上の役割
+type Role string
+
+const (
+	// StatusBarBg はステータスバーの背景
+	StatusBarBg Role = "statusbar_bg"
+	// StatusBarFg はステータスバーの文字
+	StatusBarFg Role = "statusbar_fg"
+	// Success は成功や接続済みの表示
+	Success Role = "success"
+	// Warning は注意や処理中の表示
+	Warning Role = "warning"
+	// Error はエラーや切断の表示
+	Error Role = "error"
+	// Muted は補足情報や区切り線
+	Muted Role = "muted"
+	// Accent は枠線や強調
+	Accent Role = "accent"
+	// DiffAdd は差分の追加行
+	DiffAdd Role = "diff_add"
+	// DiffRemove は差分の削除行
+	DiffRemove Role = "diff_remove"
+)
+
+// Roles はすべての役割を定義順に並べたもの
+var Roles = []Role{StatusBarBg, StatusBarFg, Success, Warning, Error, Muted, Accent, DiffAdd, DiffRemove}
+
+// 組み込みテーマ名
+const (
+	// Auto は端末の背景色からDarkとLightを選ぶ
+	Auto = "auto"
+	// DarkName は暗い背景向けのテーマ名
+	DarkName = "dark"
+	// LightName は明るい背景向けのテーマ名
+	LightName = "light"
+)
+
+// IsRole は役割の名前か判定する
+func IsRole(name string) bool {
+	for _, r := range Roles {
+		if string(r) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBuiltin は組み込みテーマの名前か判定する
+func IsBuiltin(name string) bool {
+	return name == Auto || name == DarkName || name == LightName
+}
+
+// Color は端末の色数ごとの色
+// 端末が扱える最も多い色数の値が使われる
+type Color struct {
+	TrueColor string // 24bitカラー (#rrggbb)
+	ANSI256   string // 256色 (0-255)
+	ANSI      string // 16色 (0-15)
+}
+
+// ParseColor は #rrggbb, #rgb または 0-255 の色番号を読み込む
+// 256色と16色の値は近い色に変換して補う
+func ParseColor(s string) (Color, error) {
+	s = strings.TrimSpace(s)
+
+	var c termenv.Color
+	switch {
+	case strings.HasPrefix(s, "#"):
+		hex := s
+		if len(hex) == 4 {
+			hex = "#" + strings.Repeat(hex[1:2], 2) + strings.Repeat(hex[2:3], 2) + strings.Repeat(hex[3:4], 2)
+		}
+		if len(hex) != 7 {
+			return Color{}, fmt.Errorf("色 %q は #rrggbb または 0-255 で指定してください", s)
+		}
+		if _, err := strconv.ParseUint(hex[1:], 16, 32); err != nil {
+			return Color{}, fmt.Errorf("色 %q は #rrggbb または 0-255 で指定してください", s)
+		}
+		c = termenv.RGBColor(strings.ToLower(hex))
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > 255 {
+			return Color{}, fmt.Errorf("色 %q は #rrggbb または 0-255 で指定してください", s)
+		}
+		if n < 16 {
+			c = termenv.ANSIColor(n)
+		} else {
+			c = termenv.ANSI256Color(n)
+		}
+	}
+
+	return Color{
+		TrueColor: termenv.ConvertToRGB(c).Hex(),
+		ANSI256:   colorIndex(termenv.ANSI256.Convert(c)),
+		ANSI:      colorIndex(termenv.ANSI.Convert(c)),
+	}, nil
+}
+
+// colorIndex は変換後の色の番号を返す
+func colorIndex(c termenv.Color) string {
+	switch v := c.(type) {
+	case termenv.ANSIColor:
+		return strconv.Itoa(int(v))
+	case termenv.ANSI256Color:
+		return strconv.Itoa(int(v))
+	default:
+		return ""
+	}
+}
+
+// Theme は役割ごとの色の組み合わせ
+type Theme struct {
+	Name   string         // テーマ名
+	Dark   bool           // 暗い背景向けか
+	colors map[Role]Color // 役割 → 色
+}
+
+// Color は役割の色を返す
+func (t *Theme) Color(role Role) lipgloss.TerminalColor {
+	c, ok := t.colors[role]
+	if !ok {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.CompleteColor{TrueColor: c.TrueColor, ANSI256: c.ANSI256, ANSI: c.ANSI}
+}
+
+// Foreground は役割の色を文字色にしたスタイルを返す
+func (t *Theme) Foreground(role Role) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(t.Color(role))
+}
+
+// Dark は暗い背景向けの組み込みテーマを返す
+func Dark() *Theme {
+	return &Theme{
+		Name: DarkName,
+		Dark: true,
+		colors: map[Role]Color{
+			StatusBarBg: {TrueColor: "#262626", ANSI256: "235", ANSI: "0"},
+			StatusBarFg: {TrueColor: "#d0d0d0", ANSI256: "252", ANSI: "7"},
+			Success:     {TrueColor: "#00d787", ANSI256: "42", ANSI: "2"},
+			Warning:     {TrueColor: "#ffff00", ANSI256: "226", ANSI: "11"},
+			Error:       {TrueColor: "#ff0000", ANSI256: "196", ANSI: "9"},
+			Muted:       {TrueColor: "#8a8a8a", ANSI256: "245", ANSI: "8"},
+			Accent:      {TrueColor: "#00afff", ANSI256: "39", ANSI: "12"},
+			DiffAdd:     {TrueColor: "#00d787", ANSI256: "42", ANSI: "2"},
+			DiffRemove:  {TrueColor: "#ff0000", ANSI256: "196", ANSI: "9"},
+		},
+	}
+}
+
+// Light は明るい背景向けの組み込みテーマを返す
+func Light() *Theme {
+	return &Theme{
+		Name: LightName,
+		Dark: false,
+		colors: map[Role]Color{
+			StatusBarBg: {TrueColor: "#e4e4e4", ANSI256: "254", ANSI: "7"},
+			StatusBarFg: {TrueColor: "#303030", ANSI256: "236", ANSI: "0"},
+			Success:     {TrueColor: "#008700", ANSI256: "28", ANSI: "2"},
+			Warning:     {TrueColor: "#af8700", ANSI256: "136", ANSI: "3"},
+			Error:       {TrueColor: "#d70000", ANSI256: "160", ANSI: "1"},
+			Muted:       {TrueColor: "#6c6c6c", ANSI256: "242", ANSI: "8"},
+			Accent:      {TrueColor: "#005fd7", ANSI256: "26", ANSI: "4"},
+			DiffAdd:     {TrueColor: "#008700", ANSI256: "28", ANSI: "2"},
+			DiffRemove:  {TrueColor: "#d70000", ANSI256: "160", ANSI: "1"},
+		},
+	}
+}
+
+// Palette は設定ファイルで定義するユーザーテーマ
+// 指定しなかった役割はBaseのテーマの色を使う
+type Palette struct {
+	Base   string         // 元にする組み込みテーマ (dark, light, 空の場合は端末の背景色で選ぶ)
+	Colors map[Role]Color // 役割 → 色
+}
+
+// Resolve はテーマ名からテーマを選ぶ
+// auto (または空) の場合とBaseを指定しないユーザーテーマはhasDarkBackgroundで暗い背景か判定する
+func Resolve(name string, palettes map[string]Palette, hasDarkBackground func() bool) (*Theme, error) {
+	detect := func() *Theme {
+		if hasDarkBackground == nil || hasDarkBackground() {
+			return Dark()
+		}
+		return Light()
+	}
+
+	switch name {
+	case "", Auto:
+		return detect(), nil
+	case DarkName:
+		return Dark(), nil
+	case LightName:
+		return Light(), nil
+	}
+
+	p, ok := palettes[name]
+	if !ok {
+		return nil, fmt.Errorf("テーマ %q は定義されていません (%s)", name, strings.Join(Names(palettes), ", "))
+	}
+
+	var t *Theme
+	switch p.Base {
+	case "", Auto:
+		t = detect()
+	case DarkName:
+		t = Dark()
+	case LightName:
+		t = Light()
+	default:
+		return nil, fmt.Errorf("テーマ %s: base %q は dark または light で指定してください", name, p.Base)
+	}
+	t.Name = name
+	for role, c := range p.Colors {
+		t.colors[role] = c
+	}
+	return t, nil
+}
+
+// Names は組み込みテーマとユーザーテーマの名前を返す
+func Names(palettes map[string]Palette) []string {
+	names := make([]string, 0, len(palettes))
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{Auto, DarkName, LightName}, names...)
+}
@@ -0,0 +1,115 @@
+package theme
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Color
+		wantErr bool
+	}{
+		{in: "#ff0000", want: Color{TrueColor: "#ff0000", ANSI256: "196", ANSI: "9"}},
+		{in: "#F00", want: Color{TrueColor: "#ff0000", ANSI256: "196", ANSI: "9"}},
+		{in: "42", want: Color{TrueColor: "#00d787", ANSI256: "42", ANSI: "10"}},
+		{in: "4", want: Color{TrueColor: "#000080", ANSI256: "4", ANSI: "4"}},
+		{in: "#12345", wantErr: true},
+		{in: "#gggggg", wantErr: true},
+		{in: "256", wantErr: true},
+		{in: "red", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseColor(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	for _, theme := range []*Theme{Dark(), Light()} {
+		for _, role := range Roles {
+			c, ok := theme.colors[role]
+			require.True(t, ok, "%s: %s", theme.Name, role)
+			assert.NotEmpty(t, c.TrueColor, "%s: %s", theme.Name, role)
+			assert.NotEmpty(t, c.ANSI256, "%s: %s", theme.Name, role)
+			assert.NotEmpty(t, c.ANSI, "%s: %s", theme.Name, role)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	red := Color{TrueColor: "#ff0000", ANSI256: "196", ANSI: "9"}
+	palettes := map[string]Palette{
+		"solar":  {Base: LightName, Colors: map[Role]Color{Accent: red}},
+		"follow": {Colors: map[Role]Color{Accent: red}},
+		"broken": {Base: "sepia"},
+	}
+	dark := func() bool { return true }
+	light := func() bool { return false }
+
+	tests := []struct {
+		name     string
+		detect   func() bool
+		wantName string
+		wantDark bool
+		wantErr  string
+	}{
+		{name: "", detect: dark, wantName: DarkName, wantDark: true},
+		{name: Auto, detect: light, wantName: LightName},
+		{name: Auto, wantName: DarkName, wantDark: true},
+		{name: DarkName, detect: light, wantName: DarkName, wantDark: true},
+		{name: LightName, detect: dark, wantName: LightName},
+		{name: "solar", detect: dark, wantName: "solar"},
+		{name: "follow", detect: dark, wantName: "follow", wantDark: true},
+		{name: "follow", detect: light, wantName: "follow"},
+		{name: "broken", wantErr: `テーマ broken: base "sepia" は dark または light で指定してください`},
+		{name: "missing", wantErr: `テーマ "missing" は定義されていません (auto, dark, light, broken, follow, solar)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.wantName, func(t *testing.T) {
+			got, err := Resolve(tt.name, palettes, tt.detect)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, got.Name)
+			assert.Equal(t, tt.wantDark, got.Dark)
+		})
+	}
+}
+
+func TestResolve_OverridesRoles(t *testing.T) {
+	red := Color{TrueColor: "#ff0000", ANSI256: "196", ANSI: "9"}
+	got, err := Resolve("solar", map[string]Palette{
+		"solar": {Base: LightName, Colors: map[Role]Color{Accent: red}},
+	}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, lipgloss.CompleteColor{TrueColor: "#ff0000", ANSI256: "196", ANSI: "9"}, got.Color(Accent))
+	// 指定しなかった役割は元のテーマの色を使う
+	assert.Equal(t, Light().Color(Muted), got.Color(Muted))
+	// 組み込みテーマは変更しない
+	assert.NotEqual(t, got.Color(Accent), Light().Color(Accent))
+}
+
+func TestTheme_UnknownRole(t *testing.T) {
+	assert.Equal(t, lipgloss.NoColor{}, Dark().Color("unknown"))
+	assert.True(t, IsRole("diff_add"))
+	assert.False(t, IsRole("diff"))
+	assert.True(t, IsBuiltin(Auto))
+	assert.False(t, IsBuiltin("solar"))
+}
@@ -0,0 +1,352 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/export"
+	"github.com/mzkmnk/ccforge/internal/keymap"
+)
+
+const (
+	// paletteDialogID はコマンドパレットのダイアログ識別子
+	paletteDialogID = "palette"
+	// maxRecentActions は記録する最近使った操作の数
+	maxRecentActions = 10
+)
+
+// Action はccforgeに登録された操作
+type Action struct {
+	ID          string                 // 識別子 (例: "diff.toggle")
+	Title       string                 // 表示名
+	Description string                 // 説明
+	Run         func(m *Model) tea.Cmd // 実行処理
+	Enabled     func(m *Model) bool    // 実行できるか (nilの場合は常に実行できる)
+}
+
+// enabled は操作を現在の状態で実行できるかを返す
+func (a Action) enabled(m *Model) bool {
+	return a.Enabled == nil || a.Enabled(m)
+}
+
+// ActionRegistry は操作の一覧と利用履歴を管理する
+type ActionRegistry struct {
+	actions []Action       // 登録順の操作
+	index   map[string]int // IDからactionsへのインデックス
+	recent  []string       // 最近使った操作のID (新しい順)
+}
+
+// NewActionRegistry は新しいActionRegistryを作成する
+func NewActionRegistry() *ActionRegistry {
+	return &ActionRegistry{
+		index: make(map[string]int),
+	}
+}
+
+// Register は操作を登録する
+func (r *ActionRegistry) Register(a Action) error {
+	if a.ID == "" {
+		return fmt.Errorf("操作のIDが空です")
+	}
+	if a.Run == nil {
+		return fmt.Errorf("操作 %q に実行処理がありません", a.ID)
+	}
+	if _, exists := r.index[a.ID]; exists {
+		return fmt.Errorf("操作 %q は既に登録されています", a.ID)
+	}
+
+	r.index[a.ID] = len(r.actions)
+	r.actions = append(r.actions, a)
+	return nil
+}
+
+// Get はIDから操作を取得する
+func (r *ActionRegistry) Get(id string) (Action, bool) {
+	i, ok := r.index[id]
+	if !ok {
+		return Action{}, false
+	}
+	return r.actions[i], true
+}
+
+// All は登録順にすべての操作を返す
+func (r *ActionRegistry) All() []Action {
+	return append([]Action(nil), r.actions...)
+}
+
+// MarkUsed は操作を最近使ったものとして記録する
+func (r *ActionRegistry) MarkUsed(id string) {
+	recent := []string{id}
+	for _, existing := range r.recent {
+		if existing != id {
+			recent = append(recent, existing)
+		}
+	}
+	if len(recent) > maxRecentActions {
+		recent = recent[:maxRecentActions]
+	}
+	r.recent = recent
+}
+
+// Ordered は最近使った操作を先頭に、残りを登録順に並べて返す
+func (r *ActionRegistry) Ordered() []Action {
+	ordered := make([]Action, 0, len(r.actions))
+	used := make(map[string]bool, len(r.recent))
+
+	for _, id := range r.recent {
+		if a, ok := r.Get(id); ok {
+			ordered = append(ordered, a)
+			used[id] = true
+		}
+	}
+	for _, a := range r.actions {
+		if !used[a.ID] {
+			ordered = append(ordered, a)
+		}
+	}
+
+	return ordered
+}
+
+// registerBuiltinActions はccforge組み込みの操作を登録する
+func registerBuiltinActions(r *ActionRegistry) {
+	builtins := []Action{
+		{
+			ID:          "app.quit",
+			Title:       "終了",
+			Description: "ccforgeを終了する",
+			Run: func(m *Model) tea.Cmd {
+				return m.quit()
+			},
+		},
+		{
+			ID:          "help.toggle",
+			Title:       "ヘルプ表示切り替え",
+			Description: "ステータスバーのヘルプを表示/非表示にする",
+			Run: func(m *Model) tea.Cmd {
+				m.statusBar.ToggleHelp()
+				return nil
+			},
+		},
+		{
+			ID:          "screen.clear",
+			Title:       "画面をクリア",
+			Description: "メインビューの出力を消去する",
+			Run: func(m *Model) tea.Cmd {
+				m.mainView.Clear()
+				m.mainView.AddOutput("画面をクリアしました")
+				return nil
+			},
+		},
+		{
+			ID:          "palette.open",
+			Title:       "コマンドパレット",
+			Description: "登録済みの操作を検索して実行する",
+			Run: func(m *Model) tea.Cmd {
+				return m.openPalette()
+			},
+		},
+		{
+			ID:          "search.open",
+			Title:       "トランスクリプトを検索",
+			Description: "すべてのタスクの過去のやり取りを全文検索する",
+			Run: func(m *Model) tea.Cmd {
+				return m.openSearch()
+			},
+		},
+		{
+			ID:          "transcript.export",
+			Title:       "トランスクリプトをエクスポート",
+			Description: "アクティブなタスクの最新のセッションをMarkdownでタスクのディレクトリに保存する",
+			Run: func(m *Model) tea.Cmd {
+				return m.exportTranscript(exportRequestMsg{format: export.Markdown})
+			},
+		},
+		{
+			ID:          "diff.toggle",
+			Title:       "差分パネル表示切り替え",
+			Description: "Gitの変更ファイルと差分を表示/非表示にする",
+			Run: func(m *Model) tea.Cmd {
+				return m.toggleDiffView()
+			},
+		},
+		{
+			ID:          "diff.hide",
+			Title:       "サイドパネルを閉じる",
+			Description: "差分・タスク・変更・レビューパネルを閉じてメインビューに戻る (レビュー中はレビューを終了する)",
+			Run: func(m *Model) tea.Cmd {
+				// レビュー中は先にレビューを終了して結果を表示する
+				if m.reviewView.IsVisible() && !m.reviewView.IsFinished() {
+					return m.reviewView.Finish()
+				}
+				m.hidePanels()
+				return nil
+			},
+		},
+		{
+			ID:          "diff.stage",
+			Title:       "ファイルをステージ",
+			Description: "差分パネルで選択中のファイルをステージする (ステージ済みの場合は取り消す)",
+			Run: func(m *Model) tea.Cmd {
+				return m.diffView.toggleStage()
+			},
+			Enabled: diffViewVisible,
+		},
+		{
+			ID:          "diff.stage_hunk",
+			Title:       "ハンクをステージ",
+			Description: "差分パネルで選択中のハンクをステージする (ステージ済みの場合は取り消す)",
+			Run: func(m *Model) tea.Cmd {
+				return m.diffView.toggleStageHunk()
+			},
+			Enabled: diffViewVisible,
+		},
+		{
+			ID:          "diff.commit",
+			Title:       "変更をコミット",
+			Description: "ステージした変更をタスク名と完了したtasks.mdの項目から作成したメッセージでコミットする",
+			Run: func(m *Model) tea.Cmd {
+				return m.openCommitEditor()
+			},
+			Enabled: commitAvailable,
+		},
+		{
+			ID:          "review.start",
+			Title:       "変更をレビュー",
+			Description: "作業ツリーの変更をハンクごとに採用 (ステージ)・却下 (取り消し)・スキップする",
+			Run: func(m *Model) tea.Cmd {
+				return m.startReview()
+			},
+		},
+		{
+			ID:          "review.accept",
+			Title:       "ハンクを採用",
+			Description: "レビュー中のハンクを採用してステージする",
+			Run: func(m *Model) tea.Cmd {
+				return m.reviewView.Accept()
+			},
+			Enabled: reviewInProgress,
+		},
+		{
+			ID:          "review.reject",
+			Title:       "ハンクを却下",
+			Description: "レビュー中のハンクを却下して作業ツリーから取り消す",
+			Run: func(m *Model) tea.Cmd {
+				return m.rejectReviewHunk()
+			},
+			Enabled: reviewInProgress,
+		},
+		{
+			ID:          "review.skip",
+			Title:       "ハンクをスキップ",
+			Description: "レビュー中のハンクを判断せずに次のハンクへ進む",
+			Run: func(m *Model) tea.Cmd {
+				return m.reviewView.Skip()
+			},
+			Enabled: reviewInProgress,
+		},
+		{
+			ID:          "review.prev",
+			Title:       "前のハンクに戻る",
+			Description: "スキップした前のハンクに戻る",
+			Run: func(m *Model) tea.Cmd {
+				return m.reviewView.Prev()
+			},
+			Enabled: reviewInProgress,
+		},
+		{
+			ID:          "tasks.toggle",
+			Title:       "タスクパネル表示切り替え",
+			Description: "タスクの一覧とSpecsを表示/非表示にする (ファイルの変更は自動で反映される)",
+			Run: func(m *Model) tea.Cmd {
+				return m.toggleTasksView()
+			},
+		},
+		{
+			ID:          "changes.toggle",
+			Title:       "変更パネル表示切り替え",
+			Description: "セッション中にプロジェクトで作成・変更・削除されたファイルを表示/非表示にする",
+			Run: func(m *Model) tea.Cmd {
+				return m.toggleChangesView()
+			},
+		},
+		{
+			ID:          "diff.side_by_side",
+			Title:       "差分をside-by-side表示",
+			Description: "差分パネルのunified/side-by-side表示を切り替える",
+			Run: func(m *Model) tea.Cmd {
+				m.diffView.sideBySide = !m.diffView.sideBySide
+				if m.diffView.IsVisible() {
+					return nil
+				}
+				m.hidePanels()
+				return m.diffView.Show(m.repoDir())
+			},
+		},
+	}
+
+	for _, a := range builtins {
+		if err := r.Register(a); err != nil {
+			// 組み込み操作の重複はプログラムの誤り
+			panic(err)
+		}
+	}
+}
+
+// diffViewVisible は差分パネルを表示しているかを返す
+// 差分パネルの操作に割り当てたキーは、他のパネルではそのパネルが受け取る
+func diffViewVisible(m *Model) bool {
+	return m.diffView.IsVisible()
+}
+
+// commitAvailable は差分パネルを表示しているか、サイドパネルを表示していないかを返す
+// 他のパネルでは割り当てたキーをそのパネルが受け取り、パネルがなければパレットから実行できる
+func commitAvailable(m *Model) bool {
+	return m.diffView.IsVisible() || m.keyContext() != keymap.Sidebar
+}
+
+// reviewInProgress はレビューパネルでレビュー中かを返す
+func reviewInProgress(m *Model) bool {
+	return m.reviewView.IsVisible() && !m.reviewView.IsFinished()
+}
+
+// openPalette はコマンドパレットを開くコマンドを返す
+func (m *Model) openPalette() tea.Cmd {
+	actions := m.actions.Ordered()
+	items := make([]PickerItem, 0, len(actions))
+	for _, a := range actions {
+		// パレット自身を開く操作と今は実行できない操作は候補に含めない
+		if a.ID == "palette.open" || !a.enabled(m) {
+			continue
+		}
+		items = append(items, PickerItem{
+			Title:       a.Title,
+			Description: a.Description,
+			Hint:        m.anyKeyHint(a.ID),
+			Value:       a.ID,
+		})
+	}
+
+	return OpenDialog(NewPickerDialog(paletteDialogID, "コマンドパレット", items))
+}
+
+// actionEnabled はIDで指定された操作を現在の状態で実行できるかを返す
+// 登録されていない操作はrunActionでエラーを表示するため実行できるものとする
+func (m *Model) actionEnabled(id string) bool {
+	a, ok := m.actions.Get(id)
+	return !ok || a.enabled(m)
+}
+
+// runAction はIDで指定された操作を実行し、利用履歴に記録する
+func (m *Model) runAction(id string) tea.Cmd {
+	a, ok := m.actions.Get(id)
+	if !ok {
+		m.mainView.AddOutput(fmt.Sprintf("エラー: 不明な操作です: %s", id))
+		return nil
+	}
+
+	m.actions.MarkUsed(id)
+	slog.Debug("操作を実行します", "component", "tui", "action", id)
+	return a.Run(m)
+}
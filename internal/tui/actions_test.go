@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noopAction はテスト用の何もしない操作を作成する
+func noopAction(id string) Action {
+	return Action{
+		ID:    id,
+		Title: id,
+		Run:   func(m *Model) tea.Cmd { return nil },
+	}
+}
+
+// actionIDs は操作のIDを並べて返す
+func actionIDs(actions []Action) []string {
+	ids := make([]string, len(actions))
+	for i, a := range actions {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+func TestActionRegistry_Register(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  Action
+		wantErr bool
+	}{
+		{name: "正常系_登録", action: noopAction("b")},
+		{name: "異常系_重複", action: noopAction("a"), wantErr: true},
+		{name: "異常系_ID空", action: noopAction(""), wantErr: true},
+		{name: "異常系_実行処理なし", action: Action{ID: "c"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewActionRegistry()
+			require.NoError(t, r.Register(noopAction("a")))
+
+			err := r.Register(tt.action)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			_, ok := r.Get(tt.action.ID)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestActionRegistry_Ordered(t *testing.T) {
+	r := NewActionRegistry()
+	for _, id := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, r.Register(noopAction(id)))
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, actionIDs(r.Ordered()))
+
+	r.MarkUsed("c")
+	r.MarkUsed("b")
+	r.MarkUsed("c")
+	assert.Equal(t, []string{"c", "b", "a", "d"}, actionIDs(r.Ordered()))
+
+	// 未登録のIDは無視される
+	r.MarkUsed("unknown")
+	assert.Equal(t, []string{"c", "b", "a", "d"}, actionIDs(r.Ordered()))
+}
+
+func TestActionRegistry_RecentLimit(t *testing.T) {
+	r := NewActionRegistry()
+	for i := 0; i < maxRecentActions+5; i++ {
+		r.MarkUsed(string(rune('a' + i)))
+	}
+	assert.Len(t, r.recent, maxRecentActions)
+	assert.Equal(t, string(rune('a'+maxRecentActions+4)), r.recent[0])
+}
+
+func TestModel_Palette(t *testing.T) {
+	m := NewModel()
+
+	// Ctrl+Kでパレットを開く
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	require.NotNil(t, cmd)
+	openMsg, ok := cmd().(OpenDialogMsg)
+	require.True(t, ok)
+
+	picker, ok := openMsg.Dialog.(*PickerDialog)
+	require.True(t, ok)
+	// パレット自身と表示していないパネルの操作を除く登録済みの操作が候補になる
+	enabled := 0
+	for _, a := range m.actions.All() {
+		if a.enabled(&m) {
+			enabled++
+		}
+	}
+	assert.Len(t, picker.items, enabled-1)
+	assert.NotEqual(t, "palette.open", picker.items[0].Value)
+	for _, item := range picker.items {
+		assert.NotContains(t, []string{"diff.stage", "diff.stage_hunk", "review.accept", "review.reject"}, item.Value)
+	}
+
+	// 選択された操作を実行し、次回は先頭に表示される
+	updated, _ := m.Update(PickerResultMsg{ID: paletteDialogID, Item: PickerItem{Value: "screen.clear"}})
+	m = updated.(Model)
+	assert.Equal(t, []string{"画面をクリアしました"}, m.mainView.outputLines)
+	assert.Equal(t, "screen.clear", m.actions.Ordered()[0].ID)
+
+	// キャンセル時は何もしない
+	_, cmd = m.Update(PickerResultMsg{ID: paletteDialogID, Canceled: true})
+	assert.Nil(t, cmd)
+}
+
+func TestModel_RunUnknownAction(t *testing.T) {
+	m := NewModel()
+	cmd := m.runAction("no.such.action")
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.mainView.outputLines[len(m.mainView.outputLines)-1], "不明な操作です")
+}
@@ -2,9 +2,15 @@ package tui
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mzkmnk/ccforge/internal/command"
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/mzkmnk/ccforge/internal/keymap"
+	"github.com/mzkmnk/ccforge/internal/watch"
 )
 
 const (
@@ -14,18 +20,119 @@ const (
 
 // Model はTUIアプリケーションの状態を管理する構造体
 type Model struct {
-	width     int        // ターミナル幅
-	height    int        // ターミナル高さ
-	ready     bool       // 初期化完了フラグ
-	err       error      // エラー状態
-	mainView  *MainView  // メインビューコンポーネント
-	statusBar *StatusBar // ステータスバーコンポーネント
+	width          int                            // ターミナル幅
+	height         int                            // ターミナル高さ
+	ready          bool                           // 初期化完了フラグ
+	err            error                          // エラー状態
+	mainView       *MainView                      // メインビューコンポーネント
+	statusBar      *StatusBar                     // ステータスバーコンポーネント
+	diffView       *DiffView                      // 差分パネルコンポーネント
+	tasksView      *TasksView                     // タスクパネルコンポーネント
+	changesView    *ChangesView                   // 変更パネルコンポーネント
+	reviewView     *ReviewView                    // 変更のレビューパネルコンポーネント
+	overlays       *OverlayStack                  // モーダルダイアログのスタック
+	actions        *ActionRegistry                // 登録済みの操作
+	commands       *command.Registry              // スラッシュコマンド
+	macros         map[string]config.MacroCommand // ユーザー定義マクロ
+	macro          *macroRun                      // 実行中のマクロ
+	keymap         *keymap.Keymap                 // キーバインド
+	reloader       *configReloader                // 設定ファイルの再読み込み
+	snapshots      *snapshotter                   // 画面の状態の保存
+	search         *transcriptSearch              // トランスクリプトの全文検索
+	exporter       exportFunc                     // トランスクリプトのエクスポート
+	watcher        watch.Watcher                  // タスクのディレクトリの監視
+	feed           watch.Feed                     // プロジェクトのファイルの変更の監視
+	gitStatus      *gitStatus                     // ステータスバーのgitの状態の更新
+	gitInterval    time.Duration                  // gitの状態の定期更新の間隔
+	darkBackground *bool                          // 端末の背景色が暗いか (問い合わせ結果)
+	workDir        string                         // 作業ディレクトリ
+	worktree       string                         // タスクのgit worktree (空の場合は作業ディレクトリ)
+	commitDraft    commitDraft                    // 編集中のコミットメッセージの下書き
+	checkpoint     config.CheckpointConfig        // レビュー前のチェックポイントの設定
+}
+
+// Option はModel生成時のオプション
+type Option func(*Model)
+
+// WithWorkDir は作業ディレクトリを設定する
+func WithWorkDir(dir string) Option {
+	return func(m *Model) {
+		m.workDir = dir
+	}
+}
+
+// WithWorktree はタスクのgit worktreeを設定する
+// 差分パネルとgitの操作はworktreeを対象にし、タスクのSpecsは作業ディレクトリから読み込む
+func WithWorktree(dir string) Option {
+	return func(m *Model) {
+		m.worktree = dir
+		m.mainView.AddOutput(fmt.Sprintf("worktree %s で作業します", dir))
+	}
+}
+
+// repoDir は差分とgitの操作の対象のディレクトリを返す
+func (m *Model) repoDir() string {
+	if m.worktree != "" {
+		return m.worktree
+	}
+	return m.workDir
+}
+
+// setActiveTask はアクティブなタスクを設定する
+// タスクが変わった場合は前のタスクのコミットメッセージの下書きを捨てる
+func (m *Model) setActiveTask(name string) {
+	if name != m.statusBar.activeTask {
+		m.commitDraft = commitDraft{}
+	}
+	m.statusBar.SetActiveTask(name)
+}
+
+// WithActiveTask はアクティブなタスクを設定する
+func WithActiveTask(name string) Option {
+	return func(m *Model) {
+		m.setActiveTask(name)
+		m.mainView.AddOutput("")
+		m.mainView.AddOutput(fmt.Sprintf("タスク %s を開始しました", name))
+	}
+}
+
+// WithConfig は設定の値を各コンポーネントに反映し、マクロを登録する
+func WithConfig(cfg *config.Config) Option {
+	return func(m *Model) {
+		m.applyConfig(cfg)
+		WithKeybindings(cfg.Keybindings)(m)
+		WithMacros(cfg.Commands)(m)
+	}
+}
+
+// applyConfig は起動中にも変更できる設定を反映する
+func (m *Model) applyConfig(cfg *config.Config) {
+	m.mainView.SetMaxOutputLines(cfg.UI.MaxOutputLines)
+	m.diffView.Configure(cfg.Diff)
+	m.reviewView.SetTabWidth(cfg.Diff.TabWidth)
+	m.gitInterval = cfg.Git.StatusInterval
+	m.checkpoint = cfg.Checkpoint
+	WithTheme(cfg.UI.Theme, cfg.Themes)(m)
+}
+
+// WithMacros はユーザー定義マクロを登録する
+func WithMacros(defs []config.MacroCommand) Option {
+	return func(m *Model) {
+		for _, err := range m.registerMacros(defs) {
+			m.mainView.AddOutput(fmt.Sprintf("エラー: %v", err))
+		}
+	}
 }
 
 // NewModel は新しいModelを作成する
-func NewModel() Model {
+func NewModel(opts ...Option) Model {
 	mainView := NewMainView()
 	statusBar := NewStatusBar()
+	diffView := NewDiffView()
+	actions := NewActionRegistry()
+	registerBuiltinActions(actions)
+	commands := command.NewRegistry()
+	registerBuiltinCommands(commands)
 
 	// 初期メッセージを追加
 	mainView.AddOutput("ccforge - Claude Code TUIアプリケーション")
@@ -35,18 +142,67 @@ func NewModel() Model {
 	mainView.AddOutput("  - テキストを入力してEnterキーで送信")
 	mainView.AddOutput("  - ↑/↓キーでスクロール")
 	mainView.AddOutput("  - F1キーでヘルプ表示切り替え")
-	mainView.AddOutput("  - Ctrl+Cまたはqで終了")
+	mainView.AddOutput("  - Ctrl+Dで差分パネル表示切り替え (Escで閉じる)")
+	mainView.AddOutput("  - Ctrl+Tでタスクパネル表示切り替え")
+	mainView.AddOutput("  - Ctrl+Gでセッション中に変更されたファイルを表示")
+	mainView.AddOutput("  - Ctrl+Rで変更をハンクごとにレビュー")
+	mainView.AddOutput("  - Ctrl+Kでコマンドパレットを開く")
+	mainView.AddOutput("  - Ctrl+Fで過去のトランスクリプトを検索")
+	mainView.AddOutput("  - /commandsでccforgeのコマンド一覧を表示")
+	mainView.AddOutput("  - Ctrl+Cで終了")
 
-	return Model{
-		mainView:  mainView,
-		statusBar: statusBar,
+	m := Model{
+		mainView:    mainView,
+		statusBar:   statusBar,
+		diffView:    diffView,
+		tasksView:   NewTasksView(),
+		changesView: NewChangesView(),
+		reviewView:  NewReviewView(),
+		overlays:    NewOverlayStack(),
+		actions:     actions,
+		commands:    commands,
+		macros:      make(map[string]config.MacroCommand),
+		workDir:     ".",
+		gitInterval: defaultGitStatusInterval,
+		checkpoint:  config.Default().Checkpoint,
 	}
+	m.setKeymap(nil)
+
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	return m
 }
 
 // Init はBubble Teaの初期化処理
 func (m Model) Init() tea.Cmd {
-	// 初期化時に実行するコマンドはなし
-	return nil
+	var cmds []tea.Cmd
+	// 設定ファイルの監視を開始する
+	if m.reloader != nil {
+		cmds = append(cmds, m.reloader.tick())
+	}
+	// 画面の状態の定期保存を開始する
+	if m.snapshots != nil {
+		cmds = append(cmds, m.snapshots.tick())
+	}
+	// タスクのディレクトリの変更を待つ
+	if m.watcher != nil {
+		cmds = append(cmds, waitForWatch(m.watcher))
+	}
+	// プロジェクトのファイルの変更を待つ
+	if m.feed != nil {
+		cmds = append(cmds, waitForChanges(m.feed))
+	}
+	// gitの状態の取得と定期更新を開始する
+	if m.gitStatus != nil {
+		cmds = append(cmds, m.refreshGitStatus(), m.gitStatusTick())
+	}
+	// アクティブなタスクの進捗をステータスバーに表示する
+	if m.statusBar.activeTask != "" {
+		cmds = append(cmds, m.loadTaskProgress())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update はメッセージを受け取って状態を更新する
@@ -56,20 +212,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// グローバルキーバインドの処理
-		switch msg.String() {
-		case "ctrl+c", "q":
-			// 終了
-			return m, tea.Quit
-		case "f1":
-			// ヘルプ表示の切り替え
-			m.statusBar.ToggleHelp()
-			return m, nil
-		case "ctrl+l":
-			// 画面クリア
-			m.mainView.Clear()
-			m.mainView.AddOutput("画面をクリアしました")
-			return m, nil
+		// フォーカスのコンテキストに割り当てられた操作を優先する
+		// 表示中のパネルで実行できない操作のキーはパネルに渡す
+		ctx := m.keyContext()
+		if action, ok := m.keys().Lookup(ctx, msg.String()); ok && m.actionEnabled(action) {
+			return m, m.runAction(action)
+		}
+
+		switch ctx {
+		case keymap.Modal:
+			// モーダル表示中は残りのキーをダイアログが受け取る
+			return m, m.overlays.HandleKey(msg)
+		case keymap.Sidebar:
+			// サイドパネル表示中はパネルにキーイベントを渡す
+			switch {
+			case m.reviewView.IsVisible():
+				_, cmd = m.reviewView.Update(msg)
+			case m.tasksView.IsVisible():
+				_, cmd = m.tasksView.Update(msg)
+			case m.changesView.IsVisible():
+				_, cmd = m.changesView.Update(msg)
+			default:
+				_, cmd = m.diffView.Update(msg)
+			}
+			return m, cmd
 		default:
 			// メインビューにキーイベントを渡す
 			_, cmd = m.mainView.Update(msg)
@@ -88,6 +254,172 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mainView.width = msg.Width
 		m.mainView.height = msg.Height - 1 // ステータスバーの分を引く
 		m.statusBar.SetWidth(msg.Width)
+		if m.diffView != nil {
+			m.diffView.SetSize(msg.Width, msg.Height-1)
+		}
+		if m.tasksView != nil {
+			m.tasksView.SetSize(msg.Width, msg.Height-1)
+		}
+		if m.changesView != nil {
+			m.changesView.SetSize(msg.Width, msg.Height-1)
+		}
+		if m.reviewView != nil {
+			m.reviewView.SetSize(msg.Width, msg.Height-1)
+		}
+
+	case SubmitMsg:
+		// 入力の確定 (コマンドの実行またはプロンプトの送信)
+		return m, m.handleSubmit(msg.Text)
+
+	case PromptMsg:
+		return m, m.sendPrompt(msg.Text)
+
+	case ResponseCompleteMsg:
+		// 応答完了 (待機中のマクロを再開)
+		return m, m.handleResponseComplete(msg)
+
+	case macroStartMsg:
+		return m, m.startMacro(msg.name, msg.args)
+
+	case ConfirmResultMsg:
+		if msg.ID == reviewRejectDialogID && msg.Confirmed {
+			return m, m.reviewView.Reject()
+		}
+		return m, nil
+
+	case PromptResultMsg:
+		if strings.HasPrefix(msg.ID, macroDialogPrefix) {
+			return m, m.handleMacroPrompt(msg)
+		}
+		if msg.ID == commitDialogID {
+			return m, m.handleCommitMessage(msg)
+		}
+		if msg.ID == searchQueryDialogID && !msg.Canceled {
+			return m, m.runSearch(msg.Value)
+		}
+		return m, nil
+
+	case RunActionMsg:
+		return m, m.runAction(msg.ID)
+
+	case OutputMsg:
+		for _, line := range msg.Lines {
+			m.mainView.AddOutput(line)
+		}
+		return m, nil
+
+	case watchEventsMsg:
+		return m, m.handleWatchEvents(msg)
+
+	case SpecChangedMsg:
+		return m, m.handleSpecChanged(msg)
+
+	case TaskAddedMsg:
+		return m, m.handleTaskAdded(msg)
+
+	case TaskRemovedMsg:
+		return m, m.handleTaskRemoved(msg)
+
+	case fileChangesMsg:
+		return m, tea.Batch(m.handleFileChanges(msg), m.refreshGitStatus())
+
+	case gitSummaryMsg:
+		m.handleGitSummary(msg)
+		return m, nil
+
+	case gitStatusTickMsg:
+		if m.gitStatus == nil {
+			return m, nil
+		}
+		return m, tea.Batch(m.refreshGitStatus(), m.gitStatusTick())
+
+	case taskProgressMsg:
+		m.handleTaskProgress(msg)
+		return m, nil
+
+	case tasksLoadedMsg, specsLoadedMsg:
+		// タスクパネル宛てのメッセージ
+		_, cmd = m.tasksView.Update(msg)
+		return m, cmd
+
+	case diffModeMsg:
+		m.diffView.sideBySide = msg.sideBySide
+		if m.diffView.IsVisible() {
+			return m, nil
+		}
+		m.hidePanels()
+		return m, m.diffView.Show(m.repoDir())
+
+	case commitDraftMsg:
+		return m, m.handleCommitDraft(msg)
+
+	case commitDoneMsg:
+		return m, m.handleCommitDone(msg)
+
+	case PickerResultMsg:
+		// コマンドパレットで選択された操作を実行
+		if msg.Canceled {
+			return m, nil
+		}
+		switch msg.ID {
+		case paletteDialogID:
+			return m, m.runAction(msg.Item.Value)
+		case searchResultsDialogID:
+			return m, m.openSearchHit(msg.Item.Value)
+		}
+		return m, nil
+
+	case searchRequestMsg:
+		return m, m.runSearch(msg.query)
+
+	case searchDoneMsg:
+		return m, m.handleSearchDone(msg)
+
+	case transcriptLoadedMsg:
+		return m, m.handleTranscriptLoaded(msg)
+
+	case exportRequestMsg:
+		return m, m.exportTranscript(msg)
+
+	case exportDoneMsg:
+		m.handleExportDone(msg)
+		return m, nil
+
+	case OpenDialogMsg:
+		// ダイアログを最前面に表示
+		if m.overlays != nil && msg.Dialog != nil {
+			m.overlays.Push(msg.Dialog)
+		}
+		return m, nil
+
+	case configTickMsg:
+		return m, m.handleConfigTick(msg)
+
+	case snapshotTickMsg:
+		if m.snapshots == nil {
+			return m, nil
+		}
+		return m, tea.Batch(m.saveSnapshot(), m.snapshots.tick())
+
+	case snapshotSavedMsg:
+		m.handleSnapshotSaved(msg)
+		return m, nil
+
+	case configReloadedMsg:
+		m.handleConfigReloaded(msg)
+		return m, nil
+
+	case reviewStartMsg, reviewDiffMsg, reviewAppliedMsg, reviewSummaryMsg:
+		// レビューパネル宛てのメッセージ
+		_, cmd = m.reviewView.Update(msg)
+		return m, cmd
+
+	case diffChangesMsg, diffContentMsg, diffStageMsg, diffTickMsg:
+		// 差分パネル宛てのメッセージ
+		if m.diffView != nil {
+			_, cmd = m.diffView.Update(msg)
+			return m, cmd
+		}
 
 	case error:
 		// エラーメッセージの処理
@@ -99,6 +431,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// toggleDiffView は差分パネルの表示を切り替える
+func (m Model) toggleDiffView() tea.Cmd {
+	if m.diffView == nil {
+		return nil
+	}
+	if m.diffView.IsVisible() {
+		m.diffView.Hide()
+		return nil
+	}
+	m.hidePanels()
+	return m.diffView.Show(m.repoDir())
+}
+
 // View は現在の状態を文字列として描画する
 func (m Model) View() string {
 	if !m.ready {
@@ -106,7 +451,7 @@ func (m Model) View() string {
 	}
 
 	if m.err != nil && m.mainView == nil {
-		return fmt.Sprintf("エラー: %v\n\nqキーまたはCtrl+Cで終了", m.err)
+		return fmt.Sprintf("エラー: %v\n\n%s", m.err, m.quitHint())
 	}
 
 	// コンポーネントが初期化されていない場合
@@ -115,13 +460,32 @@ func (m Model) View() string {
 	}
 
 	// メインビューとステータスバーを結合
+	// サイドパネル表示中はメインビューの代わりにパネルを描画する
 	mainContent := m.mainView.View()
+	switch {
+	case m.reviewView != nil && m.reviewView.IsVisible():
+		mainContent = m.reviewView.View()
+	case m.diffView != nil && m.diffView.IsVisible():
+		mainContent = m.diffView.View()
+	case m.tasksView != nil && m.tasksView.IsVisible():
+		mainContent = m.tasksView.View()
+	case m.changesView != nil && m.changesView.IsVisible():
+		mainContent = m.changesView.View()
+	}
+	m.statusBar.SetHelpItems(m.helpText())
 	statusContent := m.statusBar.View()
 
 	// 垂直に結合
-	return lipgloss.JoinVertical(
+	view := lipgloss.JoinVertical(
 		lipgloss.Top,
 		mainContent,
 		statusContent,
 	)
+
+	// モーダルダイアログを最前面に重ねる
+	if m.overlays != nil && m.overlays.Len() > 0 {
+		view = m.overlays.Render(view, m.width, m.height)
+	}
+
+	return view
 }
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/config"
 )
 
 // TestNewModel tests Model構造体の作成
@@ -56,6 +58,60 @@ func TestNewModel(t *testing.T) {
 	}
 }
 
+// TestNewModel_Options tests Model生成時のオプション
+func TestNewModel_Options(t *testing.T) {
+	m := NewModel(WithWorkDir("/tmp/project"), WithActiveTask("auth-refactor"))
+
+	if m.workDir != "/tmp/project" {
+		t.Errorf("NewModel().workDir = %v, want %v", m.workDir, "/tmp/project")
+	}
+	if got := m.statusBar.GetActiveTask(); got != "auth-refactor" {
+		t.Errorf("NewModel().statusBar.GetActiveTask() = %v, want %v", got, "auth-refactor")
+	}
+	if !strings.Contains(strings.Join(m.mainView.outputLines, "\n"), "タスク auth-refactor を開始しました") {
+		t.Error("NewModel() should show the active task")
+	}
+}
+
+// TestNewModel_WithWorktree tests 差分とgitの操作の対象のディレクトリ
+func TestNewModel_WithWorktree(t *testing.T) {
+	m := NewModel(WithWorkDir("/tmp/project"))
+	if got := m.repoDir(); got != "/tmp/project" {
+		t.Errorf("repoDir() = %v, want %v", got, "/tmp/project")
+	}
+
+	m = NewModel(WithWorkDir("/tmp/project"), WithWorktree("/tmp/project-worktrees/auth"))
+	if m.workDir != "/tmp/project" {
+		t.Errorf("NewModel().workDir = %v, want %v", m.workDir, "/tmp/project")
+	}
+	if got := m.repoDir(); got != "/tmp/project-worktrees/auth" {
+		t.Errorf("repoDir() = %v, want %v", got, "/tmp/project-worktrees/auth")
+	}
+	if !strings.Contains(strings.Join(m.mainView.outputLines, "\n"), "worktree /tmp/project-worktrees/auth で作業します") {
+		t.Error("NewModel() should show the worktree")
+	}
+}
+
+func TestNewModel_WithConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.UI.MaxOutputLines = 5
+	cfg.Diff.RefreshInterval = 10 * time.Second
+	cfg.Diff.SideBySideMinWidth = 60
+	cfg.Commands = []config.MacroCommand{{Name: "ship", Steps: []config.MacroStep{{Prompt: "/test"}}}}
+
+	m := NewModel(WithConfig(cfg))
+
+	if got := m.mainView.GetMaxOutputLines(); got != 5 {
+		t.Errorf("mainView.GetMaxOutputLines() = %v, want %v", got, 5)
+	}
+	if m.diffView.refreshInterval != 10*time.Second || m.diffView.sideBySideMinWidth != 60 {
+		t.Errorf("diffView = %v/%v, want 10s/60", m.diffView.refreshInterval, m.diffView.sideBySideMinWidth)
+	}
+	if _, ok := m.macros["ship"]; !ok {
+		t.Error("NewModel() should register macros from config")
+	}
+}
+
 // TestModel_Init tests Initメソッド
 func TestModel_Init(t *testing.T) {
 	tests := []struct {
@@ -96,10 +152,10 @@ func TestModel_Update_KeyMessages(t *testing.T) {
 			checkQuit: true,
 		},
 		{
-			name:      "正常系_qキー終了",
+			name:      "正常系_qキーは入力として扱う",
 			model:     NewModel(),
 			msg:       tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}},
-			checkQuit: true,
+			checkQuit: false,
 		},
 		{
 			name:      "正常系_その他のキー入力",
@@ -217,7 +273,7 @@ func TestModel_View_Error(t *testing.T) {
 	got := model.View()
 	expectedContains := []string{
 		"エラー: テストエラー",
-		"qキーまたはCtrl+Cで終了",
+		"Ctrl+Cで終了",
 	}
 
 	for _, substr := range expectedContains {
@@ -257,7 +313,7 @@ func TestModel_View_Normal(t *testing.T) {
 				"ccforge - Claude Code TUIアプリケーション",
 				"準備完了",
 				"使い方:",
-				"Ctrl+Cまたはqで終了",
+				"Ctrl+Cで終了",
 			},
 		},
 	}
@@ -356,9 +412,9 @@ func TestModel_Integration(t *testing.T) {
 		}
 
 		// 7. 終了
-		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
 		if cmd == nil {
-			t.Error("Update() should return quit command for 'q' key")
+			t.Error("Update() should return quit command for Ctrl+C")
 		}
 	})
 }
@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/theme"
+	"github.com/mzkmnk/ccforge/internal/watch"
+)
+
+// fileChangesMsg はプロジェクトの監視から届いた変更のまとまり
+type fileChangesMsg struct {
+	changes []watch.Change
+}
+
+// WithChangeFeed はプロジェクトのファイルの変更の記録を有効にする
+// 変更はセッションのタイムラインに記録し、変更パネルとステータスバーに表示する
+func WithChangeFeed(feed watch.Feed, sessionID string) Option {
+	return func(m *Model) {
+		m.feed = feed
+		m.changesView.feed = feed
+		m.changesView.timeline = watch.NewTimeline(sessionID, time.Now())
+		m.statusBar.SetChangedFiles(0)
+	}
+}
+
+// waitForChanges は次の変更のまとまりを待つコマンドを返す
+// 監視が終了した場合は何も返さない
+func waitForChanges(feed watch.Feed) tea.Cmd {
+	if feed == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		changes, ok := <-feed.Changes()
+		if !ok {
+			return nil
+		}
+		return fileChangesMsg{changes: changes}
+	}
+}
+
+// handleFileChanges は変更をタイムラインに記録し、次の変更を待つ
+func (m *Model) handleFileChanges(msg fileChangesMsg) tea.Cmd {
+	timeline := m.changesView.timeline
+	if timeline == nil {
+		return nil
+	}
+	timeline.Record(msg.changes)
+	m.statusBar.SetChangedFiles(timeline.Count())
+	slog.Debug("ファイルの変更を記録しました", "component", "tui", "changes", len(msg.changes), "files", timeline.Count())
+	return waitForChanges(m.feed)
+}
+
+// toggleChangesView は変更パネルの表示を切り替える
+func (m *Model) toggleChangesView() tea.Cmd {
+	if m.changesView.IsVisible() {
+		m.changesView.Hide()
+		return nil
+	}
+	m.hidePanels()
+	m.changesView.Show()
+	return nil
+}
+
+// ChangesView はセッション中に変更されたファイルを表示するパネル
+type ChangesView struct {
+	width    int             // パネルの幅
+	height   int             // パネルの高さ
+	timeline *watch.Timeline // 変更の記録 (監視していない場合はnil)
+	feed     watch.Feed      // 監視の方式の表示に使う
+	byFile   bool            // ファイルごとの正味の変更を表示するか
+	offset   int             // スクロール位置
+	visible  bool            // 表示中フラグ
+}
+
+// NewChangesView は新しいChangesViewを作成する
+func NewChangesView() *ChangesView {
+	return &ChangesView{
+		width:  80,
+		height: 23,
+	}
+}
+
+// Show はパネルを表示する
+func (v *ChangesView) Show() {
+	v.visible = true
+	v.offset = 0
+}
+
+// Hide はパネルを非表示にする
+func (v *ChangesView) Hide() {
+	v.visible = false
+}
+
+// IsVisible はパネルが表示中かを取得する
+func (v *ChangesView) IsVisible() bool {
+	return v.visible
+}
+
+// SetSize はパネルのサイズを設定する
+func (v *ChangesView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// Init はBubble Teaの初期化処理（tea.Modelインターフェースの実装）
+func (v *ChangesView) Init() tea.Cmd {
+	return nil
+}
+
+// Update はメッセージを処理して状態を更新する
+func (v *ChangesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return v, nil
+	}
+
+	switch key.String() {
+	case "up", "k":
+		v.offset--
+	case "down", "j":
+		v.offset++
+	case "pgup":
+		v.offset -= v.height - 2
+	case "pgdown", " ":
+		v.offset += v.height - 2
+	case "tab", "f":
+		v.byFile = !v.byFile
+		v.offset = 0
+	}
+	v.clampOffset()
+	return v, nil
+}
+
+// clampOffset はスクロール位置を範囲内に収める
+func (v *ChangesView) clampOffset() {
+	maxOffset := len(v.lines()) - (v.height - 1)
+	if v.offset > maxOffset {
+		v.offset = maxOffset
+	}
+	if v.offset < 0 {
+		v.offset = 0
+	}
+}
+
+// View は現在の状態を文字列として描画する
+func (v *ChangesView) View() string {
+	style := lipgloss.NewStyle().
+		Width(v.width).
+		Height(v.height)
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	mutedStyle := themed(theme.Muted)
+
+	if v.timeline == nil {
+		return style.Render(mutedStyle.Render("ファイルの変更は記録していません"))
+	}
+
+	lines := []string{titleStyle.Render(ansi.Truncate(v.title(), v.width, "…"))}
+	body := v.lines()
+	if len(body) == 0 {
+		lines = append(lines, mutedStyle.Render("変更はありません"))
+	}
+	end := v.offset + v.height - 1
+	if end > len(body) {
+		end = len(body)
+	}
+	if v.offset < end {
+		lines = append(lines, body[v.offset:end]...)
+	}
+
+	return style.Render(strings.Join(lines, "\n"))
+}
+
+// title はパネルの見出しを返す
+func (v *ChangesView) title() string {
+	view := "タイムライン"
+	if v.byFile {
+		view = "ファイルごと"
+	}
+	title := fmt.Sprintf("変更されたファイル %d件 [%s] %sから", v.timeline.Count(), view, v.timeline.Started().Format("15:04:05"))
+	if id := v.timeline.SessionID(); id != "" {
+		title += " セッション " + id
+	}
+	if v.feed != nil {
+		title += " (監視: " + v.feed.Mode().String() + ")"
+	}
+	return title
+}
+
+// lines は変更を描画済みの行に変換する
+func (v *ChangesView) lines() []string {
+	if v.timeline == nil {
+		return nil
+	}
+
+	var lines []string
+	if v.byFile {
+		for _, f := range v.timeline.Files() {
+			lines = append(lines, v.renderChange(f.Last, f.Path, f.Op))
+		}
+		return lines
+	}
+
+	for _, c := range v.timeline.Entries() {
+		lines = append(lines, v.renderChange(c.Time, c.Path, c.Op))
+	}
+	if dropped := v.timeline.Dropped(); dropped > 0 {
+		lines = append(lines, themed(theme.Muted).Render(fmt.Sprintf("(古い変更 %d件は省略しました)", dropped)))
+	}
+	return lines
+}
+
+// renderChange は変更の1行を描画する
+func (v *ChangesView) renderChange(at time.Time, path string, op watch.Op) string {
+	mark, role := "~", theme.Warning
+	switch op {
+	case watch.Created:
+		mark, role = "+", theme.Success
+	case watch.Deleted:
+		mark, role = "-", theme.Error
+	}
+
+	prefix := themed(theme.Muted).Render(at.Format("15:04:05")) + " " + themed(role).Render(mark) + " "
+	return prefix + ansi.Truncate(path, v.width-lipgloss.Width(prefix), "…")
+}
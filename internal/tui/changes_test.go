@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_FileChanges(t *testing.T) {
+	feed := watch.NewFakeFeed(watch.ModePoll)
+	m := NewModel(WithChangeFeed(feed, "s1"))
+	assert.Contains(t, ansi.Strip(m.statusBar.View()), "変更 0")
+
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	feed.Send(
+		watch.Change{Time: at, Path: "main.go", Op: watch.Modified},
+		watch.Change{Time: at, Path: "internal/app/new.go", Op: watch.Created},
+	)
+	msgs := runBatch(t, m.Init())
+	require.Len(t, msgs, 1)
+
+	// タイムラインに記録し、次の変更を待つ
+	feed.Send(watch.Change{Time: at.Add(time.Second), Path: "old.go", Op: watch.Deleted})
+	m, msg := update(t, m, msgs[0])
+	assert.Equal(t, fileChangesMsg{changes: []watch.Change{{Time: at.Add(time.Second), Path: "old.go", Op: watch.Deleted}}}, msg)
+	updated, _ := m.Update(msg)
+	m = updated.(Model)
+	assert.Contains(t, ansi.Strip(m.statusBar.View()), "変更 3")
+
+	// Ctrl+Gで新しい順のタイムラインを表示する
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlG})
+	require.True(t, m.changesView.IsVisible())
+	view := ansi.Strip(m.changesView.View())
+	assert.Contains(t, view, "変更されたファイル 3件")
+	assert.Contains(t, view, "セッション s1")
+	assert.Contains(t, view, "(監視: polling)")
+	assert.Less(t, strings.Index(view, "09:30:01 - old.go"), strings.Index(view, "09:30:00 ~ main.go"))
+
+	// 他のパネルを開くと閉じる
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlT})
+	assert.False(t, m.changesView.IsVisible())
+	assert.True(t, m.tasksView.IsVisible())
+
+	// 監視が終了したら待つのをやめる
+	require.NoError(t, feed.Close())
+	assert.Equal(t, []tea.Msg{nil}, runBatch(t, waitForChanges(feed)))
+}
+
+func TestChangesView_View(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	timeline := watch.NewTimeline("", at)
+	timeline.Record([]watch.Change{
+		{Time: at, Path: "tmp.txt", Op: watch.Created},
+		{Time: at.Add(time.Second), Path: "main.go", Op: watch.Modified},
+		{Time: at.Add(2 * time.Second), Path: "tmp.txt", Op: watch.Deleted},
+	})
+
+	tests := []struct {
+		name     string
+		timeline *watch.Timeline
+		keys     []tea.KeyMsg
+		want     []string
+		notWant  []string
+	}{
+		{
+			name: "記録していない",
+			want: []string{"ファイルの変更は記録していません"},
+		},
+		{
+			name:     "タイムライン",
+			timeline: timeline,
+			want:     []string{"[タイムライン]", "09:30:02 - tmp.txt", "09:30:01 ~ main.go"},
+			notWant:  []string{"+ tmp.txt"},
+		},
+		{
+			name:     "ファイルごと",
+			timeline: timeline,
+			keys:     []tea.KeyMsg{{Type: tea.KeyTab}},
+			want:     []string{"変更されたファイル 1件 [ファイルごと]", "09:30:01 ~ main.go"},
+			notWant:  []string{"tmp.txt"},
+		},
+		{
+			name:     "スクロール",
+			timeline: timeline,
+			keys:     []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyDown}, {Type: tea.KeyDown}},
+			want:     []string{"09:30:00 + tmp.txt"},
+			notWant:  []string{"09:30:02"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewChangesView()
+			v.timeline = tt.timeline
+			v.SetSize(60, 3)
+			v.Show()
+			for _, k := range tt.keys {
+				v.Update(k)
+			}
+
+			view := ansi.Strip(v.View())
+			for _, w := range tt.want {
+				assert.Contains(t, view, w)
+			}
+			for _, w := range tt.notWant {
+				assert.NotContains(t, view, w)
+			}
+		})
+	}
+}
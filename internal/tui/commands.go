@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/command"
+	"github.com/mzkmnk/ccforge/internal/export"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+// SubmitMsg はメインビューで入力が確定されたことを表すメッセージ
+type SubmitMsg struct {
+	Text string // 入力された文字列
+}
+
+// PromptMsg はClaude Codeへ送信するプロンプトを表すメッセージ
+type PromptMsg struct {
+	Text string // 送信する文字列 (スラッシュコマンドを含む)
+}
+
+// RunActionMsg は登録済みの操作の実行を要求するメッセージ
+type RunActionMsg struct {
+	ID string // 操作のID
+}
+
+// OutputMsg はメインビューへの出力を要求するメッセージ
+type OutputMsg struct {
+	Lines []string // 出力する行
+}
+
+// runActionCmd は操作の実行を要求するコマンドを返す
+func runActionCmd(id string) tea.Cmd {
+	return msgCmd(RunActionMsg{ID: id})
+}
+
+// registerBuiltinCommands はccforge組み込みのスラッシュコマンドを登録する
+// Claude Code自身のコマンド (/help, /clear など) と衝突しない名前にする
+func registerBuiltinCommands(r *command.Registry) {
+	builtins := []command.Command{
+		{
+			Name: "diff",
+			Args: []command.ArgSpec{
+				{Name: "mode", Help: "表示形式", Choices: []string{"unified", "split"}},
+			},
+			Help: "差分パネルを表示/非表示にする",
+			Handler: func(args command.Args) tea.Cmd {
+				switch args.Get("mode") {
+				case "":
+					return runActionCmd("diff.toggle")
+				default:
+					return msgCmd(diffModeMsg{sideBySide: args.Get("mode") == "split"})
+				}
+			},
+		},
+		{
+			Name:    "hunks",
+			Help:    "作業ツリーの変更をハンクごとにレビューする",
+			Handler: func(command.Args) tea.Cmd { return runActionCmd("review.start") },
+		},
+		{
+			Name:    "palette",
+			Help:    "コマンドパレットを開く",
+			Handler: func(command.Args) tea.Cmd { return runActionCmd("palette.open") },
+		},
+		{
+			Name: "search",
+			Args: []command.ArgSpec{
+				{Name: "query", Help: "検索語", Variadic: true},
+			},
+			Help: "過去のトランスクリプトを全文検索する (検索語の省略時は入力欄を開く)",
+			Handler: func(args command.Args) tea.Cmd {
+				query := strings.Join(args.Values("query"), " ")
+				if query == "" {
+					return runActionCmd("search.open")
+				}
+				return msgCmd(searchRequestMsg{query: query})
+			},
+		},
+		{
+			Name: "export",
+			Args: []command.ArgSpec{
+				{Name: "format", Help: "形式", Choices: []string{"md", "html", "jsonl"}},
+			},
+			Help: "アクティブなタスクの最新のセッションをタスクのディレクトリに書き出す",
+			Handler: func(args command.Args) tea.Cmd {
+				return msgCmd(exportRequestMsg{format: export.Format(args.Get("format"))})
+			},
+		},
+		{
+			Name:    "commands",
+			Aliases: []string{"ccforge"},
+			Help:    "ccforgeのスラッシュコマンド一覧を表示する",
+			Handler: func(command.Args) tea.Cmd {
+				lines := []string{"ccforgeのコマンド (それ以外の / コマンドはClaude Codeへ送信されます):"}
+				for _, c := range r.Commands() {
+					lines = append(lines, fmt.Sprintf("  %-24s %s", c.Usage(), c.Help))
+				}
+				return msgCmd(OutputMsg{Lines: lines})
+			},
+		},
+	}
+
+	for _, c := range builtins {
+		if err := r.Register(c); err != nil {
+			// 組み込みコマンドの重複はプログラムの誤り
+			panic(err)
+		}
+	}
+}
+
+// searchRequestMsg はトランスクリプトの検索を要求するメッセージ
+type searchRequestMsg struct {
+	query string
+}
+
+// diffModeMsg は差分パネルの表示形式の変更を要求するメッセージ
+type diffModeMsg struct {
+	sideBySide bool
+}
+
+// handleSubmit は確定された入力を処理する
+// ccforgeのコマンドはローカルで実行し、それ以外はClaude Codeへ転送する
+func (m *Model) handleSubmit(text string) tea.Cmd {
+	inv, err := m.commands.Parse(text)
+
+	var argErr *command.ArgError
+	switch {
+	case errors.Is(err, command.ErrNotCommand), errors.Is(err, command.ErrUnknownCommand):
+		return msgCmd(PromptMsg{Text: text})
+	case errors.As(err, &argErr):
+		m.renderCommandError(argErr)
+		return nil
+	case err != nil:
+		m.mainView.AddOutput(fmt.Sprintf("エラー: %v", err))
+		return nil
+	}
+
+	return inv.Run()
+}
+
+// renderCommandError はコマンドの引数エラーをメインビューに表示する
+func (m *Model) renderCommandError(err *command.ArgError) {
+	errorStyle := themed(theme.Error)
+	mutedStyle := themed(theme.Muted)
+
+	m.mainView.AddOutput(errorStyle.Render("✗ " + err.Error()))
+	if err.Command != nil {
+		m.mainView.AddOutput(mutedStyle.Render("  使用法: " + err.Command.Usage()))
+	}
+}
+
+// sendPrompt はプロンプトをClaude Codeへ送信する
+func (m *Model) sendPrompt(text string) tea.Cmd {
+	slog.Debug("プロンプトを送信します", "component", "tui", "length", len(text))
+	mutedStyle := themed(theme.Muted)
+	m.mainView.AddOutput(mutedStyle.Render(fmt.Sprintf("Claude Codeに未接続のため送信されませんでした: %s", text)))
+	return msgCmd(ResponseCompleteMsg{Err: errNotConnected})
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_HandleSubmit(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantMsg tea.Msg
+	}{
+		{name: "通常の入力はプロンプトとして送信", text: "hello", wantMsg: PromptMsg{Text: "hello"}},
+		{name: "未登録のスラッシュコマンドはそのまま転送", text: "/model opus", wantMsg: PromptMsg{Text: "/model opus"}},
+		{name: "未登録のコマンドは引用符が閉じていなくても転送", text: "/explain what's wrong", wantMsg: PromptMsg{Text: "/explain what's wrong"}},
+		{name: "Claude Codeのコマンドは横取りしない", text: "/clear", wantMsg: PromptMsg{Text: "/clear"}},
+		{name: "Claude Codeのレビューコマンドは横取りしない", text: "/review", wantMsg: PromptMsg{Text: "/review"}},
+		{name: "ハンクごとのレビュー", text: "/hunks", wantMsg: RunActionMsg{ID: "review.start"}},
+		{name: "ccforgeのコマンドはローカルで実行", text: "/diff", wantMsg: RunActionMsg{ID: "diff.toggle"}},
+		{name: "引数付きのコマンド", text: "/diff split", wantMsg: diffModeMsg{sideBySide: true}},
+		{name: "パレット", text: "/palette", wantMsg: RunActionMsg{ID: "palette.open"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			_, cmd := m.Update(SubmitMsg{Text: tt.text})
+			require.NotNil(t, cmd)
+			assert.Equal(t, tt.wantMsg, cmd())
+		})
+	}
+}
+
+func TestModel_HandleSubmit_ArgError(t *testing.T) {
+	m := NewModel()
+	m.mainView.Clear()
+
+	_, cmd := m.Update(SubmitMsg{Text: "/diff wide"})
+	assert.Nil(t, cmd)
+
+	require.Len(t, m.mainView.outputLines, 2)
+	assert.Contains(t, ansi.Strip(m.mainView.outputLines[0]), "✗ /diff: 引数 mode の値 \"wide\" は不正です")
+	assert.Equal(t, "  使用法: /diff [mode]", ansi.Strip(m.mainView.outputLines[1]))
+}
+
+func TestModel_CommandsList(t *testing.T) {
+	m := NewModel()
+	_, cmd := m.Update(SubmitMsg{Text: "/commands"})
+	require.NotNil(t, cmd)
+
+	msg, ok := cmd().(OutputMsg)
+	require.True(t, ok)
+	text := strings.Join(msg.Lines, "\n")
+	assert.Contains(t, text, "/diff [mode]")
+	assert.Contains(t, text, "/palette")
+
+	updated, _ := m.Update(msg)
+	m = updated.(Model)
+	assert.Contains(t, strings.Join(m.mainView.outputLines, "\n"), "/commands")
+}
+
+func TestModel_EnterSubmitsInput(t *testing.T) {
+	m := NewModel()
+	for _, r := range "/diff" {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(Model)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	assert.Equal(t, SubmitMsg{Text: "/diff"}, cmd())
+}
+
+func TestModel_SendPromptWithoutBackend(t *testing.T) {
+	m := NewModel()
+	_, cmd := m.Update(PromptMsg{Text: "hello"})
+	require.NotNil(t, cmd)
+	assert.Equal(t, ResponseCompleteMsg{Err: errNotConnected}, cmd())
+
+	last := m.mainView.outputLines[len(m.mainView.outputLines)-1]
+	assert.Contains(t, ansi.Strip(last), "未接続")
+}
@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+// commitDialogID はコミットメッセージの入力ダイアログの識別子
+const commitDialogID = "commit.message"
+
+// commitDraft はキャンセルやコミットの失敗で残ったコミットメッセージの下書き
+type commitDraft struct {
+	task    string // 下書きを作成したときのアクティブなタスク
+	message string
+}
+
+// commitDraftMsg はコミットメッセージの下書きの作成結果
+type commitDraftMsg struct {
+	message string
+	err     error
+}
+
+// commitDoneMsg はコミットの結果
+type commitDoneMsg struct {
+	message string // コミットしたメッセージ
+	hash    string
+	err     error
+}
+
+// commitRepo はコミットの対象のリポジトリを返す
+func (m *Model) commitRepo() (*git.Repo, error) {
+	if m.diffView.repo != nil {
+		return m.diffView.repo, nil
+	}
+	return git.Open(m.repoDir())
+}
+
+// openCommitEditor はステージした変更を確認し、コミットメッセージの入力ダイアログを開くコマンドを返す
+// 前回の下書き (キャンセルやコミットの失敗で残ったもの) が同じタスクのものならそれを使う
+func (m *Model) openCommitEditor() tea.Cmd {
+	repo, err := m.commitRepo()
+	if err != nil {
+		m.reportCommitError(err)
+		return nil
+	}
+	task := m.statusBar.activeTask
+	var draft string
+	if m.commitDraft.task == task {
+		draft = m.commitDraft.message
+	}
+	manager := tasks.NewManager(m.workDir)
+
+	return func() tea.Msg {
+		staged, err := repo.HasStaged()
+		if err != nil {
+			return commitDraftMsg{err: err}
+		}
+		if !staged {
+			return commitDraftMsg{err: git.ErrNothingStaged}
+		}
+		if draft == "" {
+			draft = commitMessageDraft(manager, task)
+		}
+		return commitDraftMsg{message: draft}
+	}
+}
+
+// commitMessageDraft はタスク名と完了したtasks.mdの項目からコミットメッセージの下書きを作成する
+func commitMessageDraft(manager *tasks.Manager, task string) string {
+	if task == "" {
+		return ""
+	}
+	lines := []string{task + ": "}
+
+	items, err := manager.Checklist(task)
+	if err != nil {
+		slog.Debug("チェックリストを読み込めませんでした", "component", "tui", "task", task, "err", err)
+	}
+	var done []string
+	for _, item := range items {
+		if item.Done {
+			done = append(done, "- "+item.Text)
+		}
+	}
+	if len(done) > 0 {
+		lines = append(append(lines, ""), done...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleCommitDraft は下書きを入力ダイアログで開く
+func (m *Model) handleCommitDraft(msg commitDraftMsg) tea.Cmd {
+	if msg.err != nil {
+		m.reportCommitError(msg.err)
+		if errors.Is(msg.err, git.ErrNothingStaged) {
+			m.mainView.AddOutput(themed(theme.Muted).Render("差分パネルのaでファイルを、hでハンクをステージしてください"))
+		}
+		return nil
+	}
+	return OpenDialog(NewEditorDialog(commitDialogID, "コミットメッセージ", msg.message))
+}
+
+// handleCommitMessage は入力されたメッセージでコミットするコマンドを返す
+// キャンセルした場合は次に開くときのために下書きを残す
+func (m *Model) handleCommitMessage(msg PromptResultMsg) tea.Cmd {
+	m.commitDraft = commitDraft{task: m.statusBar.activeTask, message: msg.Value}
+	if msg.Canceled {
+		return nil
+	}
+	if strings.TrimSpace(msg.Value) == "" {
+		m.reportCommitError(errors.New("コミットメッセージが空です"))
+		return nil
+	}
+
+	repo, err := m.commitRepo()
+	if err != nil {
+		m.reportCommitError(err)
+		return nil
+	}
+	task := m.statusBar.activeTask
+	manager := tasks.NewManager(m.workDir)
+	message := msg.Value
+
+	return func() tea.Msg {
+		// コミットとccforgeのタスクをトレーラーで結びつける
+		var trailers []string
+		if task != "" {
+			if t, err := manager.Get(task); err == nil && t.ID != "" {
+				trailers = append(trailers, fmt.Sprintf("%s: %s", git.TaskTrailer, t.ID))
+			}
+		}
+		hash, err := repo.Commit(message, trailers...)
+		return commitDoneMsg{message: message, hash: hash, err: err}
+	}
+}
+
+// handleCommitDone はコミットの結果をメインビューに表示する
+// フックの失敗などで失敗した場合はパネルを閉じて出力を表示する
+func (m *Model) handleCommitDone(msg commitDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		slog.Warn("コミットに失敗しました", "component", "tui", "err", msg.err)
+		m.commitDraft = commitDraft{task: m.statusBar.activeTask, message: msg.message}
+		m.hidePanels()
+		m.reportCommitError(msg.err)
+		return nil
+	}
+
+	m.commitDraft = commitDraft{}
+	subject, _, _ := strings.Cut(msg.message, "\n")
+	notice := fmt.Sprintf("コミットしました: %s %s", msg.hash, subject)
+	m.mainView.AddOutput(themed(theme.Success).Render("✓ " + notice))
+	m.diffView.setNotice(notice, nil)
+	return tea.Batch(m.diffView.refresh(), m.refreshGitStatus())
+}
+
+// reportCommitError はコミットのエラーをメインビューと差分パネルに表示する
+// gitコマンドのエラーは出力 (フックの出力を含む) を1行ずつ表示する
+func (m *Model) reportCommitError(err error) {
+	errorStyle := themed(theme.Error)
+	m.diffView.setNotice("", err)
+
+	var cmdErr *git.CommandError
+	if !errors.As(err, &cmdErr) {
+		m.mainView.AddOutput(errorStyle.Render(fmt.Sprintf("✗ %v", err)))
+		return
+	}
+	m.mainView.AddOutput(errorStyle.Render("✗ コミットに失敗しました:"))
+	for _, line := range strings.Split(cmdErr.Stderr, "\n") {
+		m.mainView.AddOutput("  " + line)
+	}
+	m.mainView.AddOutput(themed(theme.Muted).Render("メッセージは下書きとして残しています (差分パネルのcで再度コミットできます)"))
+}
@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGit はテスト用にgitコマンドを実行する
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+// newCommitProject はタスク auth と1つのコミットがあるリポジトリを作成する
+func newCommitProject(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("gitコマンドが見つかりません")
+	}
+
+	root := newTasksProject(t)
+	runGit(t, root, "init", "-q", "-b", "main")
+	runGit(t, root, "config", "user.name", "ccforge test")
+	runGit(t, root, "config", "user.email", "test@example.com")
+	runGit(t, root, "config", "commit.gpgsign", "false")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("a\n"), 0o644))
+	runGit(t, root, "add", "a.txt")
+	runGit(t, root, "commit", "-q", "-m", "initial")
+
+	path := filepath.Join(root, "ccforge", "auth", tasks.TasksFile)
+	require.NoError(t, os.WriteFile(path, []byte("- [x] ログインフォーム\n- [ ] テスト\n- [X] API\n"), 0o644))
+	return root
+}
+
+// outputText はメインビューの出力を結合して返す
+func outputText(m Model) string {
+	return strings.Join(m.mainView.outputLines, "\n")
+}
+
+// requestCommit はコミットを要求し、開いたメッセージの入力ダイアログを返す
+func requestCommit(t *testing.T, m Model) (Model, *EditorDialog) {
+	t.Helper()
+	m, msg := update(t, m, RunActionMsg{ID: "diff.commit"})
+	m, msg = update(t, m, msg)
+	if msg == nil {
+		return m, nil
+	}
+	m, _ = update(t, m, msg)
+	dialog, ok := m.overlays.Top().(*EditorDialog)
+	require.True(t, ok)
+	return m, dialog
+}
+
+func TestCommitMessageDraft(t *testing.T) {
+	root := newTasksProject(t)
+	manager := tasks.NewManager(root)
+	path := filepath.Join(root, "ccforge", "auth", tasks.TasksFile)
+	require.NoError(t, os.WriteFile(path, []byte("- [x] ログインフォーム\n- [ ] テスト\n"), 0o644))
+
+	assert.Equal(t, "auth: \n\n- ログインフォーム", commitMessageDraft(manager, "auth"))
+	assert.Equal(t, "billing: ", commitMessageDraft(manager, "billing"))
+	assert.Empty(t, commitMessageDraft(manager, ""))
+}
+
+func TestModel_Commit(t *testing.T) {
+	root := newCommitProject(t)
+	m := NewModel(WithWorkDir(root), WithActiveTask("auth"))
+
+	// ステージした変更がない場合はダイアログを開かない
+	m, dialog := requestCommit(t, m)
+	assert.Nil(t, dialog)
+	assert.Contains(t, outputText(m), "ステージされた変更がありません")
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("changed\n"), 0o644))
+	runGit(t, root, "add", "a.txt")
+
+	// タスク名と完了した項目から下書きを作成する
+	m, dialog = requestCommit(t, m)
+	require.NotNil(t, dialog)
+	assert.Equal(t, "auth: \n\n- ログインフォーム\n- API", dialog.String())
+
+	m, msg := update(t, m, runes("ログインを実装"))
+	assert.Nil(t, msg)
+	m, msg = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	m, msg = update(t, m, msg)
+	m, _ = update(t, m, msg)
+
+	assert.Contains(t, outputText(m), "コミットしました")
+	assert.Contains(t, outputText(m), "auth: ログインを実装")
+	assert.Empty(t, m.commitDraft.message)
+
+	// タスクIDをトレーラーに記録する
+	task, err := tasks.NewManager(root).Get("auth")
+	require.NoError(t, err)
+	assert.Equal(t, "auth: ログインを実装\n\n- ログインフォーム\n- API\n\nCcforge-Task: "+task.ID+"\n\n", runGit(t, root, "log", "-1", "--format=%B"))
+}
+
+func TestModel_CommitHookFailure(t *testing.T) {
+	root := newCommitProject(t)
+	hook := filepath.Join(root, ".git", "hooks", "pre-commit")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\necho 'lint: a.txt に問題があります'\nexit 1\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("changed\n"), 0o644))
+	runGit(t, root, "add", "a.txt")
+
+	m := NewModel(WithWorkDir(root), WithActiveTask("auth"))
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlD})
+	require.True(t, m.diffView.IsVisible())
+
+	m, dialog := requestCommit(t, m)
+	require.NotNil(t, dialog)
+	m, msg := update(t, m, runes("失敗する"))
+	assert.Nil(t, msg)
+	m, msg = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	m, msg = update(t, m, msg)
+	m, _ = update(t, m, msg)
+
+	// フックの出力をメインビューに表示し、メッセージは下書きとして残す
+	assert.False(t, m.diffView.IsVisible())
+	assert.Contains(t, outputText(m), "コミットに失敗しました")
+	assert.Contains(t, outputText(m), "lint: a.txt に問題があります")
+	assert.Equal(t, "auth: 失敗する\n\n- ログインフォーム\n- API", m.commitDraft.message)
+
+	m, dialog = requestCommit(t, m)
+	require.NotNil(t, dialog)
+	assert.Equal(t, m.commitDraft.message, dialog.String())
+}
+
+func TestModel_CommitDraftPerTask(t *testing.T) {
+	root := newCommitProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("changed\n"), 0o644))
+	runGit(t, root, "add", "a.txt")
+	m := NewModel(WithWorkDir(root), WithActiveTask("auth"))
+
+	// キャンセルした下書きは同じタスクでは再び開く
+	m, dialog := requestCommit(t, m)
+	require.NotNil(t, dialog)
+	m, _ = update(t, m, runes("途中"))
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m, dialog = requestCommit(t, m)
+	require.NotNil(t, dialog)
+	assert.Equal(t, "auth: 途中\n\n- ログインフォーム\n- API", dialog.String())
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	// タスクを切り替えると前のタスクの下書きを使わない
+	m.setActiveTask("billing")
+	assert.Empty(t, m.commitDraft.message)
+	m, dialog = requestCommit(t, m)
+	require.NotNil(t, dialog)
+	assert.Equal(t, "billing: ", dialog.String())
+
+	// ステータスバーだけが変わった場合もタスクの異なる下書きは使わない
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m.statusBar.SetActiveTask("auth")
+	m, dialog = requestCommit(t, m)
+	require.NotNil(t, dialog)
+	assert.Equal(t, "auth: \n\n- ログインフォーム\n- API", dialog.String())
+}
+
+func TestModel_CommitKeyInOtherPanels(t *testing.T) {
+	root := newCommitProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("changed\n"), 0o644))
+	runGit(t, root, "add", "a.txt")
+	m := NewModel(WithWorkDir(root), WithActiveTask("auth"))
+
+	// タスクパネルではcでコミットのダイアログを開かない
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyCtrlT})
+	require.True(t, m.tasksView.IsVisible())
+	assert.NotContains(t, m.helpText(), "c: コミット")
+	m = updateAll(t, m, runes("c"))
+	assert.Zero(t, m.overlays.Len())
+
+	// 差分パネルではcでダイアログを開く
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyCtrlD})
+	require.True(t, m.diffView.IsVisible())
+	assert.Contains(t, m.helpText(), "c: コミット")
+	m = updateAll(t, m, runes("c"))
+	_, ok := m.overlays.Top().(*EditorDialog)
+	assert.True(t, ok)
+}
@@ -0,0 +1,524 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/fuzzy"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+const (
+	// maxPickerRows はピッカーに一度に表示する候補数
+	maxPickerRows = 10
+	// maxEditorRows は複数行の入力ダイアログに一度に表示する行数
+	maxEditorRows = 12
+)
+
+// ConfirmResultMsg は確認ダイアログの結果
+type ConfirmResultMsg struct {
+	ID        string // 要求元が指定した識別子
+	Confirmed bool   // 承認されたかどうか
+}
+
+// PromptResultMsg は入力ダイアログの結果
+type PromptResultMsg struct {
+	ID       string // 要求元が指定した識別子
+	Value    string // 入力された文字列
+	Canceled bool   // キャンセルされたかどうか
+}
+
+// PickerItem はピッカーの候補
+type PickerItem struct {
+	Title       string // 表示名 (あいまい検索の対象)
+	Description string // 補足説明
+	Hint        string // 右端に表示する補足 (キーバインドなど)
+	Value       string // 要求元が使う値
+}
+
+// PickerResultMsg はピッカーダイアログの結果
+type PickerResultMsg struct {
+	ID       string     // 要求元が指定した識別子
+	Item     PickerItem // 選択された候補
+	Canceled bool       // キャンセルされたかどうか
+}
+
+// dialogStyles はダイアログ共通のスタイルを返す
+func dialogStyles() (box, title, muted lipgloss.Style) {
+	box = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(activeTheme.Color(theme.Accent)).
+		Padding(0, 1)
+	title = lipgloss.NewStyle().Bold(true)
+	muted = themed(theme.Muted)
+	return box, title, muted
+}
+
+// lineEditor はダイアログ内の1行入力欄
+type lineEditor struct {
+	value  []rune // 入力中の文字列
+	cursor int    // カーソル位置 (rune単位)
+}
+
+// handleKey は入力欄の編集キーを処理する (処理した場合はtrue)
+func (e *lineEditor) handleKey(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		runes := msg.Runes
+		if msg.Type == tea.KeySpace {
+			runes = []rune{' '}
+		}
+		newValue := make([]rune, 0, len(e.value)+len(runes))
+		newValue = append(newValue, e.value[:e.cursor]...)
+		newValue = append(newValue, runes...)
+		newValue = append(newValue, e.value[e.cursor:]...)
+		e.value = newValue
+		e.cursor += len(runes)
+	case tea.KeyBackspace:
+		if e.cursor > 0 {
+			e.value = append(e.value[:e.cursor-1:e.cursor-1], e.value[e.cursor:]...)
+			e.cursor--
+		}
+	case tea.KeyDelete:
+		if e.cursor < len(e.value) {
+			e.value = append(e.value[:e.cursor:e.cursor], e.value[e.cursor+1:]...)
+		}
+	case tea.KeyLeft:
+		if e.cursor > 0 {
+			e.cursor--
+		}
+	case tea.KeyRight:
+		if e.cursor < len(e.value) {
+			e.cursor++
+		}
+	case tea.KeyHome, tea.KeyCtrlA:
+		e.cursor = 0
+	case tea.KeyEnd, tea.KeyCtrlE:
+		e.cursor = len(e.value)
+	default:
+		return false
+	}
+	return true
+}
+
+// String は入力中の文字列を返す
+func (e *lineEditor) String() string {
+	return string(e.value)
+}
+
+// View はカーソル付きで入力欄を描画する
+func (e *lineEditor) View() string {
+	return string(e.value[:e.cursor]) + "█" + string(e.value[e.cursor:])
+}
+
+// ConfirmDialog ははい/いいえを選択する確認ダイアログ
+type ConfirmDialog struct {
+	id      string // 要求元の識別子
+	title   string // タイトル
+	message string // 確認メッセージ
+	yes     bool   // 「はい」が選択されているか
+}
+
+// NewConfirmDialog は新しい確認ダイアログを作成する
+// 誤操作を防ぐため初期選択は「いいえ」
+func NewConfirmDialog(id, title, message string) *ConfirmDialog {
+	return &ConfirmDialog{id: id, title: title, message: message}
+}
+
+// HandleKey はキー入力を処理する
+func (d *ConfirmDialog) HandleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "y", "Y":
+		return d.result(true), true
+	case "n", "N":
+		return d.result(false), true
+	case "left", "right", "tab", "shift+tab", "h", "l":
+		d.yes = !d.yes
+	case "enter":
+		return d.result(d.yes), true
+	}
+	return nil, false
+}
+
+// result は結果メッセージを返すコマンドを作成する
+func (d *ConfirmDialog) result(confirmed bool) tea.Cmd {
+	return msgCmd(ConfirmResultMsg{ID: d.id, Confirmed: confirmed})
+}
+
+// Cancel はキャンセル時の結果を返す
+func (d *ConfirmDialog) Cancel() tea.Msg {
+	return ConfirmResultMsg{ID: d.id, Confirmed: false}
+}
+
+// View はダイアログを描画する
+func (d *ConfirmDialog) View(maxWidth int) string {
+	box, title, muted := dialogStyles()
+	selected := lipgloss.NewStyle().Reverse(true).Padding(0, 1)
+	normal := lipgloss.NewStyle().Padding(0, 1)
+
+	yesButton, noButton := normal.Render("はい(y)"), selected.Render("いいえ(n)")
+	if d.yes {
+		yesButton, noButton = selected.Render("はい(y)"), normal.Render("いいえ(n)")
+	}
+
+	inner := maxWidth - 4
+	body := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title.Render(ansi.Truncate(d.title, inner, "…")),
+		"",
+		lipgloss.NewStyle().Width(inner).Render(d.message),
+		"",
+		yesButton+"  "+noButton,
+		muted.Render("←/→: 選択  Enter: 決定  Esc: キャンセル"),
+	)
+
+	return box.Render(body)
+}
+
+// PromptDialog は1行のテキストを入力するダイアログ
+type PromptDialog struct {
+	id     string     // 要求元の識別子
+	title  string     // タイトル
+	label  string     // 入力欄の説明
+	editor lineEditor // 入力欄
+}
+
+// NewPromptDialog は新しい入力ダイアログを作成する
+func NewPromptDialog(id, title, label, initial string) *PromptDialog {
+	value := []rune(initial)
+	return &PromptDialog{
+		id:     id,
+		title:  title,
+		label:  label,
+		editor: lineEditor{value: value, cursor: len(value)},
+	}
+}
+
+// HandleKey はキー入力を処理する
+func (d *PromptDialog) HandleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if msg.Type == tea.KeyEnter {
+		return msgCmd(PromptResultMsg{ID: d.id, Value: d.editor.String()}), true
+	}
+	d.editor.handleKey(msg)
+	return nil, false
+}
+
+// Cancel はキャンセル時の結果を返す
+func (d *PromptDialog) Cancel() tea.Msg {
+	return PromptResultMsg{ID: d.id, Canceled: true}
+}
+
+// View はダイアログを描画する
+func (d *PromptDialog) View(maxWidth int) string {
+	box, title, muted := dialogStyles()
+	inner := maxWidth - 4
+
+	lines := []string{title.Render(ansi.Truncate(d.title, inner, "…"))}
+	if d.label != "" {
+		lines = append(lines, d.label)
+	}
+	lines = append(lines,
+		"> "+ansi.TruncateLeft(d.editor.View(), ansi.StringWidth(d.editor.View())-(inner-2), "…"),
+		muted.Render("Enter: 決定  Esc: キャンセル"),
+	)
+
+	return box.Width(maxWidth - 2).Render(strings.Join(lines, "\n"))
+}
+
+// EditorDialog は複数行のテキストを編集するダイアログ
+// 結果はPromptResultMsgで届け、キャンセル時も編集中の内容をValueに入れる
+type EditorDialog struct {
+	id    string       // 要求元の識別子
+	title string       // タイトル
+	lines []lineEditor // 各行の入力欄
+	row   int          // カーソルのある行
+}
+
+// NewEditorDialog は新しい複数行の入力ダイアログを作成する
+// カーソルは1行目の末尾に置く
+func NewEditorDialog(id, title, initial string) *EditorDialog {
+	d := &EditorDialog{id: id, title: title}
+	for _, line := range strings.Split(initial, "\n") {
+		d.lines = append(d.lines, lineEditor{value: []rune(line)})
+	}
+	d.lines[0].cursor = len(d.lines[0].value)
+	return d
+}
+
+// HandleKey はキー入力を処理する
+func (d *EditorDialog) HandleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	current := &d.lines[d.row]
+	switch msg.Type {
+	case tea.KeyCtrlS:
+		return msgCmd(PromptResultMsg{ID: d.id, Value: d.String()}), true
+	case tea.KeyEnter:
+		// カーソル位置で行を分割する
+		rest := lineEditor{value: append([]rune(nil), current.value[current.cursor:]...)}
+		current.value = current.value[:current.cursor]
+		d.lines = append(d.lines[:d.row+1], append([]lineEditor{rest}, d.lines[d.row+1:]...)...)
+		d.row++
+	case tea.KeyUp:
+		d.moveRow(d.row - 1)
+	case tea.KeyDown:
+		d.moveRow(d.row + 1)
+	case tea.KeyBackspace:
+		if current.cursor > 0 || d.row == 0 {
+			current.handleKey(msg)
+			break
+		}
+		// 行頭では前の行と結合する
+		prev := &d.lines[d.row-1]
+		prev.cursor = len(prev.value)
+		prev.value = append(prev.value, current.value...)
+		d.lines = append(d.lines[:d.row], d.lines[d.row+1:]...)
+		d.row--
+	case tea.KeyDelete:
+		if current.cursor < len(current.value) || d.row == len(d.lines)-1 {
+			current.handleKey(msg)
+			break
+		}
+		// 行末では次の行と結合する
+		current.value = append(current.value, d.lines[d.row+1].value...)
+		d.lines = append(d.lines[:d.row+1], d.lines[d.row+2:]...)
+	default:
+		current.handleKey(msg)
+	}
+	return nil, false
+}
+
+// moveRow はカーソルを指定の行に移動する (桁は行の長さに収める)
+func (d *EditorDialog) moveRow(row int) {
+	if row < 0 || row >= len(d.lines) {
+		return
+	}
+	cursor := d.lines[d.row].cursor
+	d.row = row
+	d.lines[row].cursor = min(cursor, len(d.lines[row].value))
+}
+
+// String は編集中のテキストを返す
+func (d *EditorDialog) String() string {
+	lines := make([]string, len(d.lines))
+	for i := range d.lines {
+		lines[i] = d.lines[i].String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Cancel はキャンセル時の結果を返す
+func (d *EditorDialog) Cancel() tea.Msg {
+	return PromptResultMsg{ID: d.id, Value: d.String(), Canceled: true}
+}
+
+// View はダイアログを描画する
+func (d *EditorDialog) View(maxWidth int) string {
+	box, title, muted := dialogStyles()
+	inner := maxWidth - 4
+
+	lines := []string{title.Render(ansi.Truncate(d.title, inner, "…"))}
+
+	// カーソルのある行が見える範囲を表示する
+	start := 0
+	if d.row >= maxEditorRows {
+		start = d.row - maxEditorRows + 1
+	}
+	end := min(start+maxEditorRows, len(d.lines))
+	for i := start; i < end; i++ {
+		text := d.lines[i].String()
+		if i == d.row {
+			text = d.lines[i].View()
+			text = ansi.TruncateLeft(text, ansi.StringWidth(text)-inner, "…")
+		}
+		lines = append(lines, ansi.Truncate(text, inner, "…"))
+	}
+
+	lines = append(lines, muted.Render(fmt.Sprintf("%d/%d行  Enter: 改行  Ctrl+S: 決定  Esc: キャンセル", d.row+1, len(d.lines))))
+
+	return box.Width(maxWidth - 2).Render(strings.Join(lines, "\n"))
+}
+
+// PickerDialog は候補をあいまい検索して選択するダイアログ
+type PickerDialog struct {
+	id       string         // 要求元の識別子
+	title    string         // タイトル
+	items    []PickerItem   // 全候補
+	query    lineEditor     // 検索文字列
+	matches  []fuzzy.Result // 検索に一致した候補
+	selected int            // matches内の選択位置
+}
+
+// NewPickerDialog は新しいピッカーダイアログを作成する
+// 検索文字列が空の場合はitemsの順序で表示する
+func NewPickerDialog(id, title string, items []PickerItem) *PickerDialog {
+	d := &PickerDialog{id: id, title: title, items: items}
+	d.filter()
+	return d
+}
+
+// filter は検索文字列で候補を絞り込む
+func (d *PickerDialog) filter() {
+	titles := make([]string, len(d.items))
+	for i, item := range d.items {
+		titles[i] = item.Title
+	}
+	d.matches = fuzzy.Filter(d.query.String(), titles)
+	d.selected = 0
+}
+
+// HandleKey はキー入力を処理する
+func (d *PickerDialog) HandleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "enter":
+		if len(d.matches) == 0 {
+			return nil, false
+		}
+		item := d.items[d.matches[d.selected].Index]
+		return msgCmd(PickerResultMsg{ID: d.id, Item: item}), true
+	case "up", "ctrl+p":
+		if d.selected > 0 {
+			d.selected--
+		}
+		return nil, false
+	case "down", "ctrl+n":
+		if d.selected < len(d.matches)-1 {
+			d.selected++
+		}
+		return nil, false
+	}
+
+	before := d.query.String()
+	if d.query.handleKey(msg) && d.query.String() != before {
+		d.filter()
+	}
+	return nil, false
+}
+
+// Cancel はキャンセル時の結果を返す
+func (d *PickerDialog) Cancel() tea.Msg {
+	return PickerResultMsg{ID: d.id, Canceled: true}
+}
+
+// View はダイアログを描画する
+func (d *PickerDialog) View(maxWidth int) string {
+	box, title, muted := dialogStyles()
+	matchStyle := themed(theme.Accent).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Reverse(true)
+	inner := maxWidth - 4
+
+	lines := []string{
+		title.Render(ansi.Truncate(d.title, inner, "…")),
+		"> " + d.query.View(),
+	}
+
+	if len(d.matches) == 0 {
+		lines = append(lines, muted.Render("一致する候補がありません"))
+	}
+
+	// 選択行が見える範囲を表示する
+	start := 0
+	if d.selected >= maxPickerRows {
+		start = d.selected - maxPickerRows + 1
+	}
+	end := start + maxPickerRows
+	if end > len(d.matches) {
+		end = len(d.matches)
+	}
+
+	for i := start; i < end; i++ {
+		match := d.matches[i]
+		item := d.items[match.Index]
+
+		hint := ""
+		if item.Hint != "" {
+			hint = " " + item.Hint
+		}
+		text := item.Title
+		if item.Description != "" {
+			text += " - " + item.Description
+		}
+		text = ansi.Truncate(text, inner-ansi.StringWidth(hint), "…")
+		padding := inner - ansi.StringWidth(text) - ansi.StringWidth(hint)
+		if padding < 0 {
+			padding = 0
+		}
+		row := text + strings.Repeat(" ", padding) + hint
+
+		if i == d.selected {
+			lines = append(lines, selectedStyle.Render(row))
+		} else {
+			lines = append(lines, highlightMatches(text, match.Positions, matchStyle)+strings.Repeat(" ", padding)+muted.Render(hint))
+		}
+	}
+
+	lines = append(lines, muted.Render(fmt.Sprintf("%d/%d  ↑/↓: 選択  Enter: 決定  Esc: キャンセル", len(d.matches), len(d.items))))
+
+	return box.Width(maxWidth - 2).Render(strings.Join(lines, "\n"))
+}
+
+// highlightMatches は一致位置の文字を強調して描画する
+func highlightMatches(text string, positions []int, style lipgloss.Style) string {
+	if len(positions) == 0 {
+		return text
+	}
+
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+
+	var b strings.Builder
+	for i, r := range []rune(text) {
+		if marked[i] {
+			b.WriteString(style.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// AlertDialog はエラー詳細などのメッセージを表示するダイアログ
+type AlertDialog struct {
+	title   string // タイトル
+	message string // 本文
+}
+
+// NewAlertDialog は新しいメッセージダイアログを作成する
+func NewAlertDialog(title, message string) *AlertDialog {
+	return &AlertDialog{title: title, message: message}
+}
+
+// NewErrorDialog はエラー詳細を表示するダイアログを作成する
+func NewErrorDialog(err error) *AlertDialog {
+	return NewAlertDialog("エラー", err.Error())
+}
+
+// HandleKey はキー入力を処理する
+func (d *AlertDialog) HandleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	return nil, msg.Type == tea.KeyEnter
+}
+
+// Cancel はキャンセル時の結果を返す (結果は届けない)
+func (d *AlertDialog) Cancel() tea.Msg {
+	return nil
+}
+
+// View はダイアログを描画する
+func (d *AlertDialog) View(maxWidth int) string {
+	box, title, muted := dialogStyles()
+	inner := maxWidth - 4
+
+	body := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title.Render(ansi.Truncate(d.title, inner, "…")),
+		"",
+		lipgloss.NewStyle().Width(inner).Render(d.message),
+		"",
+		muted.Render("Enter/Esc: 閉じる"),
+	)
+
+	return box.Render(body)
+}
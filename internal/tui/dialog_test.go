@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runes はテスト用に文字入力のキーメッセージを作成する
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestConfirmDialog(t *testing.T) {
+	tests := []struct {
+		name          string
+		keys          []tea.KeyMsg
+		wantConfirmed bool
+	}{
+		{name: "yキーで承認", keys: []tea.KeyMsg{runes("y")}, wantConfirmed: true},
+		{name: "nキーで拒否", keys: []tea.KeyMsg{runes("n")}, wantConfirmed: false},
+		{name: "初期選択はいいえ", keys: []tea.KeyMsg{{Type: tea.KeyEnter}}, wantConfirmed: false},
+		{name: "選択を切り替えて決定", keys: []tea.KeyMsg{{Type: tea.KeyLeft}, {Type: tea.KeyEnter}}, wantConfirmed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewConfirmDialog("id", "確認", "よろしいですか？")
+
+			var cmd tea.Cmd
+			var closed bool
+			for _, k := range tt.keys {
+				cmd, closed = d.HandleKey(k)
+			}
+
+			require.True(t, closed)
+			require.NotNil(t, cmd)
+			assert.Equal(t, ConfirmResultMsg{ID: "id", Confirmed: tt.wantConfirmed}, cmd())
+		})
+	}
+}
+
+func TestPromptDialog(t *testing.T) {
+	d := NewPromptDialog("name", "新規タスク", "タスク名", "ab")
+
+	d.HandleKey(runes("c"))
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyLeft})
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyBackspace})
+	d.HandleKey(tea.KeyMsg{Type: tea.KeySpace})
+	assert.Contains(t, ansi.Strip(d.View(40)), "> a █c")
+
+	cmd, closed := d.HandleKey(tea.KeyMsg{Type: tea.KeyEnter})
+	require.True(t, closed)
+	assert.Equal(t, PromptResultMsg{ID: "name", Value: "a c"}, cmd())
+	assert.Equal(t, PromptResultMsg{ID: "name", Canceled: true}, d.Cancel())
+}
+
+func TestPickerDialog(t *testing.T) {
+	items := []PickerItem{
+		{Title: "diff", Description: "差分を表示", Value: "diff"},
+		{Title: "clear", Description: "画面をクリア", Value: "clear"},
+		{Title: "help", Hint: "F1", Value: "help"},
+	}
+
+	t.Run("絞り込みと選択", func(t *testing.T) {
+		d := NewPickerDialog("pick", "選択", items)
+		assert.Len(t, d.matches, 3)
+
+		d.HandleKey(runes("l"))
+		assert.Len(t, d.matches, 2) // clear, help
+
+		d.HandleKey(tea.KeyMsg{Type: tea.KeyDown})
+		cmd, closed := d.HandleKey(tea.KeyMsg{Type: tea.KeyEnter})
+		require.True(t, closed)
+		assert.Equal(t, PickerResultMsg{ID: "pick", Item: items[2]}, cmd())
+	})
+
+	t.Run("一致なしではEnterで閉じない", func(t *testing.T) {
+		d := NewPickerDialog("pick", "選択", items)
+		d.HandleKey(runes("zzz"))
+
+		cmd, closed := d.HandleKey(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.False(t, closed)
+		assert.Nil(t, cmd)
+		assert.Contains(t, ansi.Strip(d.View(60)), "一致する候補がありません")
+	})
+
+	t.Run("描画", func(t *testing.T) {
+		d := NewPickerDialog("pick", "選択", items)
+		view := ansi.Strip(d.View(60))
+		assert.Contains(t, view, "diff - 差分を表示")
+		assert.Contains(t, view, "F1")
+		assert.Contains(t, view, "3/3")
+	})
+}
+
+func TestAlertDialog(t *testing.T) {
+	d := NewErrorDialog(errors.New("接続に失敗しました"))
+	assert.Contains(t, ansi.Strip(d.View(60)), "接続に失敗しました")
+
+	_, closed := d.HandleKey(runes("x"))
+	assert.False(t, closed)
+	_, closed = d.HandleKey(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, closed)
+}
+
+func TestEditorDialog(t *testing.T) {
+	d := NewEditorDialog("id", "編集", "auth: \n\n- 項目")
+
+	// カーソルは1行目の末尾から始まる
+	d.HandleKey(runes("実装"))
+	assert.Equal(t, "auth: 実装\n\n- 項目", d.String())
+
+	// Enterでカーソル位置の行を分割し、行頭のBackspaceで結合する
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyLeft})
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "auth: 実\n装\n\n- 項目", d.String())
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyBackspace})
+	assert.Equal(t, "auth: 実装\n\n- 項目", d.String())
+
+	// 上下の移動では桁を行の長さに収める
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyDown})
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyDown})
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyEnd})
+	d.HandleKey(runes("!"))
+	assert.Equal(t, "auth: 実装\n\n- 項目!", d.String())
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyUp})
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyDelete})
+	assert.Equal(t, "auth: 実装\n- 項目!", d.String())
+
+	view := ansi.Strip(d.View(60))
+	assert.Contains(t, view, "auth: 実装")
+	assert.Contains(t, view, "2/2行")
+
+	// キャンセル時も編集中の内容を返す
+	assert.Equal(t, PromptResultMsg{ID: "id", Value: "auth: 実装\n- 項目!", Canceled: true}, d.Cancel())
+
+	cmd, closed := d.HandleKey(tea.KeyMsg{Type: tea.KeyCtrlS})
+	require.True(t, closed)
+	assert.Equal(t, PromptResultMsg{ID: "id", Value: "auth: 実装\n- 項目!"}, cmd())
+}
@@ -0,0 +1,708 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+const (
+	// defaultRefreshInterval は差分パネルの既定の自動更新間隔
+	defaultRefreshInterval = 2 * time.Second
+	// defaultSideBySideMinWidth はside-by-side表示に必要な既定の最小幅
+	defaultSideBySideMinWidth = 120
+	// defaultTabWidth は差分のタブを展開する既定の幅
+	defaultTabWidth = 4
+	// maxDiffFileSize は差分を描画する最大ファイルサイズ
+	maxDiffFileSize = 1 << 20
+	// maxDiffLines は差分を描画する最大変更行数
+	maxDiffLines = 3000
+)
+
+// diffChangesMsg は変更ファイル一覧の取得結果
+type diffChangesMsg struct {
+	changes []git.FileChange
+	err     error
+}
+
+// diffContentMsg は選択ファイルの差分取得結果
+type diffContentMsg struct {
+	change  git.FileChange
+	diff    *git.FileDiff
+	summary string // 差分の代わりに表示する要約
+	err     error
+}
+
+// diffStageMsg はステージ操作の結果
+type diffStageMsg struct {
+	notice string // 成功時に表示するメッセージ
+	err    error
+}
+
+// diffTickMsg は自動更新のタイマーメッセージ
+type diffTickMsg struct {
+	id int // タイマーの世代 (古いタイマーを無視するため)
+}
+
+// DiffView はGitの変更ファイルと差分を表示するパネル
+type DiffView struct {
+	width      int              // パネルの幅
+	height     int              // パネルの高さ
+	repo       *git.Repo        // 対象リポジトリ
+	changes    []git.FileChange // 変更ファイル一覧
+	selected   int              // 選択中のファイル
+	diff       *git.FileDiff    // 選択中ファイルの差分
+	summary    string           // 差分の代わりに表示する要約
+	diffOffset int              // 差分のスクロール位置
+	hunk       int              // 選択中のハンク
+	notice     string           // 直近の操作の結果
+	noticeErr  bool             // 直近の操作が失敗したか
+	sideBySide bool             // side-by-side表示フラグ
+	visible    bool             // 表示中フラグ
+	tickID     int              // 自動更新タイマーの世代
+	err        error            // 直近のエラー
+
+	refreshInterval    time.Duration // 自動更新の間隔
+	sideBySideMinWidth int           // side-by-side表示に必要な最小幅
+	tabWidth           int           // タブを展開する幅
+}
+
+// NewDiffView は新しいDiffViewを作成する
+func NewDiffView() *DiffView {
+	return &DiffView{
+		width:              80,
+		height:             23,
+		refreshInterval:    defaultRefreshInterval,
+		sideBySideMinWidth: defaultSideBySideMinWidth,
+		tabWidth:           defaultTabWidth,
+	}
+}
+
+// Configure は設定の値を反映する
+func (d *DiffView) Configure(cfg config.DiffConfig) {
+	d.refreshInterval = cfg.RefreshInterval
+	d.sideBySideMinWidth = cfg.SideBySideMinWidth
+	d.tabWidth = cfg.TabWidth
+}
+
+// Show はパネルを表示して変更の取得を開始する
+// リポジトリが未設定の場合はdirを含むリポジトリを開く
+func (d *DiffView) Show(dir string) tea.Cmd {
+	d.visible = true
+	d.tickID++
+
+	if d.repo == nil {
+		repo, err := git.Open(dir)
+		if err != nil {
+			d.err = err
+			return nil
+		}
+		d.repo = repo
+	}
+
+	return tea.Batch(d.refresh(), d.tick())
+}
+
+// Hide はパネルを非表示にする
+func (d *DiffView) Hide() {
+	d.visible = false
+}
+
+// IsVisible はパネルが表示中かを取得する
+func (d *DiffView) IsVisible() bool {
+	return d.visible
+}
+
+// SetSize はパネルのサイズを設定する
+func (d *DiffView) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+}
+
+// SelectedChange は選択中の変更を取得する
+func (d *DiffView) SelectedChange() (git.FileChange, bool) {
+	if d.selected < 0 || d.selected >= len(d.changes) {
+		return git.FileChange{}, false
+	}
+	return d.changes[d.selected], true
+}
+
+// Init はBubble Teaの初期化処理（tea.Modelインターフェースの実装）
+func (d *DiffView) Init() tea.Cmd {
+	return nil
+}
+
+// Update はメッセージを処理して状態を更新する
+func (d *DiffView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return d, d.handleKeyMsg(msg)
+
+	case diffChangesMsg:
+		return d, d.applyChanges(msg)
+
+	case diffContentMsg:
+		current, ok := d.SelectedChange()
+		// 選択が変わった後に届いた古い結果は捨てる
+		if !ok || current.Path != msg.change.Path || current.Area != msg.change.Area {
+			return d, nil
+		}
+		d.err = msg.err
+		d.diff = msg.diff
+		d.summary = msg.summary
+		d.clampHunk()
+		d.clampDiffOffset()
+
+	case diffStageMsg:
+		d.setNotice(msg.notice, msg.err)
+		if msg.err != nil {
+			return d, nil
+		}
+		return d, d.refresh()
+
+	case diffTickMsg:
+		if !d.visible || msg.id != d.tickID {
+			return d, nil
+		}
+		return d, tea.Batch(d.refresh(), d.tick())
+	}
+
+	return d, nil
+}
+
+// handleKeyMsg はキーボード入力を処理する
+func (d *DiffView) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		return d.selectChange(d.selected - 1)
+	case "down", "j":
+		return d.selectChange(d.selected + 1)
+	case "pgup":
+		d.diffOffset -= d.bodyHeight() - 1
+		d.clampDiffOffset()
+	case "pgdown", " ":
+		d.diffOffset += d.bodyHeight() - 1
+		d.clampDiffOffset()
+	case "s":
+		d.sideBySide = !d.sideBySide
+	case "r":
+		return d.refresh()
+	case "]":
+		d.selectHunk(d.hunk + 1)
+	case "[":
+		d.selectHunk(d.hunk - 1)
+	}
+	return nil
+}
+
+// setNotice は直近の操作の結果を設定する
+func (d *DiffView) setNotice(notice string, err error) {
+	d.notice = notice
+	d.noticeErr = err != nil
+	if err != nil {
+		d.notice = fmt.Sprintf("エラー: %v", err)
+	}
+}
+
+// selectHunk は指定インデックスのハンクを選択し、ハンクの先頭までスクロールする
+func (d *DiffView) selectHunk(index int) {
+	if d.diff == nil || index < 0 || index >= len(d.diff.Hunks) {
+		return
+	}
+	d.hunk = index
+	d.diffOffset = d.hunkStarts(d.diffWidth())[index]
+	d.clampDiffOffset()
+}
+
+// clampHunk は選択中のハンクを範囲内に収める
+func (d *DiffView) clampHunk() {
+	if d.diff == nil || d.hunk >= len(d.diff.Hunks) {
+		d.hunk = 0
+		if d.diff != nil && len(d.diff.Hunks) > 0 {
+			d.hunk = len(d.diff.Hunks) - 1
+		}
+	}
+}
+
+// toggleStage は選択中のファイルをステージする (ステージ済みの場合は取り消す)
+func (d *DiffView) toggleStage() tea.Cmd {
+	change, ok := d.SelectedChange()
+	repo := d.repo
+	if !ok || repo == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		if change.Area == git.Staged {
+			return diffStageMsg{notice: "ステージを取り消しました: " + change.Path, err: repo.Unstage(change.Path)}
+		}
+		return diffStageMsg{notice: "ステージしました: " + change.Path, err: repo.Stage(change.Path)}
+	}
+}
+
+// toggleStageHunk は選択中のハンクをステージする (ステージ済みの場合は取り消す)
+// 未追跡のファイルはファイル全体をステージする
+func (d *DiffView) toggleStageHunk() tea.Cmd {
+	change, ok := d.SelectedChange()
+	repo, diff, hunk := d.repo, d.diff, d.hunk
+	if !ok || repo == nil {
+		return nil
+	}
+	if change.Area == git.Untracked {
+		return d.toggleStage()
+	}
+	if diff == nil || hunk >= len(diff.Hunks) {
+		return nil
+	}
+
+	notice := fmt.Sprintf("%s のハンク %d/%d", change.Path, hunk+1, len(diff.Hunks))
+	return func() tea.Msg {
+		if change.Area == git.Staged {
+			return diffStageMsg{notice: notice + " のステージを取り消しました", err: repo.UnstageHunk(diff, hunk)}
+		}
+		return diffStageMsg{notice: notice + " をステージしました", err: repo.StageHunk(diff, hunk)}
+	}
+}
+
+// selectChange は指定インデックスのファイルを選択して差分を読み込む
+func (d *DiffView) selectChange(index int) tea.Cmd {
+	if index < 0 || index >= len(d.changes) || index == d.selected {
+		return nil
+	}
+	d.selected = index
+	d.diff = nil
+	d.summary = ""
+	d.diffOffset = 0
+	d.hunk = 0
+	d.notice = ""
+	return d.loadDiff()
+}
+
+// applyChanges は取得した変更一覧を反映し、選択を維持する
+// 選択中のファイルが消えた場合 (ステージした場合など) は同じ位置のファイルを選択する
+func (d *DiffView) applyChanges(msg diffChangesMsg) tea.Cmd {
+	if msg.err != nil {
+		d.err = msg.err
+		return nil
+	}
+	d.err = nil
+
+	previous, hadSelection := d.SelectedChange()
+	previousIndex := d.selected
+	d.changes = msg.changes
+	d.selected = 0
+
+	if hadSelection {
+		d.selected = min(previousIndex, len(d.changes)-1)
+		for i, c := range d.changes {
+			if c.Path == previous.Path && c.Area == previous.Area {
+				d.selected = i
+				break
+			}
+		}
+		if d.selected < 0 {
+			d.selected = 0
+		}
+	}
+
+	if len(d.changes) == 0 {
+		d.diff = nil
+		d.summary = ""
+		return nil
+	}
+
+	return d.loadDiff()
+}
+
+// refresh は変更一覧を非同期に取得するコマンドを返す
+func (d *DiffView) refresh() tea.Cmd {
+	repo := d.repo
+	if repo == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		changes, err := repo.Changes()
+		return diffChangesMsg{changes: changes, err: err}
+	}
+}
+
+// tick は次の自動更新をスケジュールするコマンドを返す
+func (d *DiffView) tick() tea.Cmd {
+	id := d.tickID
+	return tea.Tick(d.refreshInterval, func(time.Time) tea.Msg {
+		return diffTickMsg{id: id}
+	})
+}
+
+// loadDiff は選択中ファイルの差分を非同期に取得するコマンドを返す
+func (d *DiffView) loadDiff() tea.Cmd {
+	change, ok := d.SelectedChange()
+	repo := d.repo
+	if !ok || repo == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		if summary := summarizeChange(change); summary != "" {
+			return diffContentMsg{change: change, summary: summary}
+		}
+		diff, err := repo.Diff(change)
+		return diffContentMsg{change: change, diff: diff, err: err}
+	}
+}
+
+// summarizeChange は描画しない変更の要約を返す (描画する場合は空文字列)
+func summarizeChange(c git.FileChange) string {
+	switch {
+	case c.Binary:
+		return "バイナリファイルのため差分を表示しません"
+	case c.Size > maxDiffFileSize:
+		return fmt.Sprintf("大きなファイルのため差分を省略しました (%d KB)", c.Size/1024)
+	case c.Added+c.Deleted > maxDiffLines:
+		return fmt.Sprintf("変更が大きいため差分を省略しました (+%d -%d)", c.Added, c.Deleted)
+	default:
+		return ""
+	}
+}
+
+// clampDiffOffset は差分のスクロール位置を範囲内に収める
+func (d *DiffView) clampDiffOffset() {
+	maxOffset := len(d.diffLines(d.diffWidth())) - d.bodyHeight()
+	if d.diffOffset > maxOffset {
+		d.diffOffset = maxOffset
+	}
+	if d.diffOffset < 0 {
+		d.diffOffset = 0
+	}
+}
+
+// bodyHeight は差分本文に使える行数を計算する (タイトルと通知の行を除く)
+func (d *DiffView) bodyHeight() int {
+	h := d.height - 1
+	if d.notice != "" {
+		h--
+	}
+	return h
+}
+
+// listWidth はファイル一覧の幅を計算する
+func (d *DiffView) listWidth() int {
+	w := d.width / 3
+	if w < 24 {
+		w = 24
+	}
+	if w > 48 {
+		w = 48
+	}
+	if w > d.width {
+		w = d.width
+	}
+	return w
+}
+
+// diffWidth は差分表示領域の幅を計算する
+func (d *DiffView) diffWidth() int {
+	w := d.width - d.listWidth() - 1 // 区切り線の分を引く
+	if w < 0 {
+		w = 0
+	}
+	return w
+}
+
+// useSideBySide はside-by-side表示を使うかを判定する
+func (d *DiffView) useSideBySide() bool {
+	return d.sideBySide && d.width >= d.sideBySideMinWidth
+}
+
+// View は現在の状態を文字列として描画する
+func (d *DiffView) View() string {
+	listStyle := lipgloss.NewStyle().
+		Width(d.listWidth()).
+		Height(d.height)
+	separatorStyle := themed(theme.Muted)
+	diffStyle := lipgloss.NewStyle().
+		Width(d.diffWidth()).
+		Height(d.height)
+
+	separator := separatorStyle.Render(strings.Repeat("│\n", d.height-1) + "│")
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		listStyle.Render(d.renderList()),
+		separator,
+		diffStyle.Render(d.renderDiff()),
+	)
+}
+
+// renderList はファイル一覧を描画する
+func (d *DiffView) renderList() string {
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	selectedStyle := lipgloss.NewStyle().Reverse(true)
+	addStyle := themed(theme.DiffAdd)
+	delStyle := themed(theme.DiffRemove)
+
+	width := d.listWidth()
+	lines := []string{headerStyle.Render("変更ファイル")}
+	selectedLine := 0
+
+	if len(d.changes) == 0 {
+		lines = append(lines, "変更はありません")
+	}
+
+	currentArea := git.Area(-1)
+	for i, c := range d.changes {
+		if c.Area != currentArea {
+			currentArea = c.Area
+			lines = append(lines, headerStyle.Render(fmt.Sprintf("%s (%d)", c.Area, d.countArea(c.Area))))
+		}
+
+		counts := addStyle.Render(fmt.Sprintf("+%d", c.Added)) + " " + delStyle.Render(fmt.Sprintf("-%d", c.Deleted))
+		if c.Binary {
+			counts = "bin"
+		}
+		countsWidth := lipgloss.Width(counts)
+
+		name := fmt.Sprintf(" %c %s", c.Status, c.Path)
+		nameWidth := width - countsWidth - 1
+		if nameWidth < 1 {
+			nameWidth = 1
+		}
+		name = ansi.Truncate(name, nameWidth, "…")
+		name += strings.Repeat(" ", nameWidth-lipgloss.Width(name))
+		if i == d.selected {
+			name = selectedStyle.Render(name)
+			selectedLine = len(lines)
+		}
+
+		lines = append(lines, name+" "+counts)
+	}
+
+	// 選択行が見えるようにスクロールする
+	start := 0
+	if selectedLine >= d.height {
+		start = selectedLine - d.height + 1
+	}
+	end := start + d.height
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	return strings.Join(lines[start:end], "\n")
+}
+
+// countArea は指定領域の変更数を数える
+func (d *DiffView) countArea(area git.Area) int {
+	count := 0
+	for _, c := range d.changes {
+		if c.Area == area {
+			count++
+		}
+	}
+	return count
+}
+
+// renderDiff は差分表示領域を描画する
+func (d *DiffView) renderDiff() string {
+	errorStyle := themed(theme.Error)
+	mutedStyle := themed(theme.Muted)
+	titleStyle := lipgloss.NewStyle().Bold(true)
+
+	if d.err != nil {
+		return errorStyle.Render(fmt.Sprintf("エラー: %v", d.err))
+	}
+
+	change, ok := d.SelectedChange()
+	if !ok {
+		return mutedStyle.Render("ファイルが選択されていません")
+	}
+
+	title := fmt.Sprintf("%s [%s]", change.Path, change.Area)
+	if d.sideBySide && !d.useSideBySide() {
+		title += " (幅が足りないためunified表示)"
+	}
+	lines := []string{titleStyle.Render(ansi.Truncate(title, d.diffWidth(), "…"))}
+	if d.notice != "" {
+		style := mutedStyle
+		if d.noticeErr {
+			style = errorStyle
+		}
+		lines = append(lines, style.Render(ansi.Truncate(d.notice, d.diffWidth(), "…")))
+	}
+	height := d.bodyHeight()
+
+	switch {
+	case d.summary != "":
+		lines = append(lines, mutedStyle.Render(d.summary))
+	case d.diff == nil:
+		lines = append(lines, mutedStyle.Render("読み込み中..."))
+	case d.diff.Binary:
+		lines = append(lines, mutedStyle.Render(summarizeChange(git.FileChange{Binary: true})))
+	default:
+		body := d.diffLines(d.diffWidth())
+		// 選択中のハンクのヘッダーを反転表示する
+		if starts := d.hunkStarts(d.diffWidth()); d.hunk < len(starts) && len(starts) > 1 {
+			header := ansi.Truncate(d.diff.Hunks[d.hunk].Header, d.diffWidth(), "…")
+			body[starts[d.hunk]] = lipgloss.NewStyle().Reverse(true).Render(header)
+		}
+		end := d.diffOffset + height
+		if end > len(body) {
+			end = len(body)
+		}
+		if d.diffOffset < end {
+			lines = append(lines, body[d.diffOffset:end]...)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// diffLines は差分本文を描画済みの行に変換する
+func (d *DiffView) diffLines(width int) []string {
+	if d.diff == nil {
+		return nil
+	}
+	if d.useSideBySide() {
+		return renderSideBySide(d.diff, width, d.tabWidth)
+	}
+	return renderUnified(d.diff, width, d.tabWidth)
+}
+
+// hunkStarts は描画済みの差分本文での各ハンクのヘッダー行の位置を返す
+func (d *DiffView) hunkStarts(width int) []int {
+	if d.diff == nil {
+		return nil
+	}
+	starts := make([]int, len(d.diff.Hunks))
+	offset := 0
+	for i, h := range d.diff.Hunks {
+		starts[i] = offset
+		single := &git.FileDiff{Hunks: []git.Hunk{h}}
+		if d.useSideBySide() {
+			offset += len(renderSideBySide(single, width, d.tabWidth))
+		} else {
+			offset += len(renderUnified(single, width, d.tabWidth))
+		}
+	}
+	return starts
+}
+
+// diffLineStyles は差分行の種類ごとのスタイルを返す
+func diffLineStyles() (hunkStyle, addStyle, delStyle lipgloss.Style) {
+	hunkStyle = themed(theme.Accent)
+	addStyle = themed(theme.DiffAdd)
+	delStyle = themed(theme.DiffRemove)
+	return hunkStyle, addStyle, delStyle
+}
+
+// expandDiffText は差分行のタブを桁位置に合わせて空白に展開し、制御文字を取り除く
+// 桁位置は行頭の記号や行番号を含めたstartの幅から数える
+func expandDiffText(text string, start, tabWidth int) string {
+	if tabWidth < 1 {
+		tabWidth = defaultTabWidth
+	}
+	var b strings.Builder
+	col := start
+	for _, r := range text {
+		switch {
+		case r == '\t':
+			n := tabWidth - col%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case unicode.IsControl(r):
+		default:
+			b.WriteRune(r)
+			col += ansi.StringWidth(string(r))
+		}
+	}
+	return b.String()
+}
+
+// renderUnified はunified形式で差分を描画する
+func renderUnified(diff *git.FileDiff, width, tabWidth int) []string {
+	hunkStyle, addStyle, delStyle := diffLineStyles()
+
+	var lines []string
+	for _, h := range diff.Hunks {
+		lines = append(lines, hunkStyle.Render(ansi.Truncate(h.Header, width, "…")))
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case git.AddedLine:
+				lines = append(lines, addStyle.Render(ansi.Truncate("+"+expandDiffText(l.Text, 1, tabWidth), width, "…")))
+			case git.DeletedLine:
+				lines = append(lines, delStyle.Render(ansi.Truncate("-"+expandDiffText(l.Text, 1, tabWidth), width, "…")))
+			default:
+				lines = append(lines, ansi.Truncate(" "+expandDiffText(l.Text, 1, tabWidth), width, "…"))
+			}
+		}
+	}
+
+	return lines
+}
+
+// renderSideBySide はside-by-side形式で差分を描画する
+// 連続する削除行と追加行を左右に並べて対応させる
+func renderSideBySide(diff *git.FileDiff, width, tabWidth int) []string {
+	hunkStyle, addStyle, delStyle := diffLineStyles()
+	columnWidth := (width - 3) / 2
+
+	cell := func(style *lipgloss.Style, no int, text string) string {
+		content := ""
+		if no > 0 {
+			prefix := fmt.Sprintf("%4d ", no)
+			content = prefix + expandDiffText(text, len(prefix), tabWidth)
+		}
+		content = ansi.Truncate(content, columnWidth, "…")
+		content += strings.Repeat(" ", columnWidth-lipgloss.Width(content))
+		if style != nil && no > 0 {
+			return style.Render(content)
+		}
+		return content
+	}
+
+	var lines []string
+	for _, h := range diff.Hunks {
+		lines = append(lines, hunkStyle.Render(ansi.Truncate(h.Header, width, "…")))
+
+		var deleted, added []git.DiffLine
+		flush := func() {
+			for i := 0; i < len(deleted) || i < len(added); i++ {
+				left, right := cell(nil, 0, ""), cell(nil, 0, "")
+				if i < len(deleted) {
+					left = cell(&delStyle, deleted[i].OldNo, deleted[i].Text)
+				}
+				if i < len(added) {
+					right = cell(&addStyle, added[i].NewNo, added[i].Text)
+				}
+				lines = append(lines, left+" │ "+right)
+			}
+			deleted, added = nil, nil
+		}
+
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case git.DeletedLine:
+				if len(added) > 0 {
+					flush()
+				}
+				deleted = append(deleted, l)
+			case git.AddedLine:
+				added = append(added, l)
+			default:
+				flush()
+				lines = append(lines, cell(nil, l.OldNo, l.Text)+" │ "+cell(nil, l.NewNo, l.Text))
+			}
+		}
+		flush()
+	}
+
+	return lines
+}
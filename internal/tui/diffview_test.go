@@ -0,0 +1,344 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFileDiff はテスト用の差分を作成する
+func testFileDiff() *git.FileDiff {
+	return &git.FileDiff{
+		OldPath: "main.go",
+		NewPath: "main.go",
+		Hunks: []git.Hunk{
+			{
+				Header: "@@ -1,3 +1,3 @@",
+				Lines: []git.DiffLine{
+					{Kind: git.ContextLine, Text: "package main", OldNo: 1, NewNo: 1},
+					{Kind: git.DeletedLine, Text: "var a = 1", OldNo: 2},
+					{Kind: git.AddedLine, Text: "var a = 2", NewNo: 2},
+					{Kind: git.ContextLine, Text: "func main() {}", OldNo: 3, NewNo: 3},
+				},
+			},
+		},
+	}
+}
+
+func TestDiffView_ApplyChanges(t *testing.T) {
+	tests := []struct {
+		name         string
+		selected     git.FileChange
+		changes      []git.FileChange
+		wantSelected int
+	}{
+		{
+			name:     "選択中のファイルを維持",
+			selected: git.FileChange{Path: "b.go", Area: git.Unstaged},
+			changes: []git.FileChange{
+				{Path: "a.go", Area: git.Unstaged},
+				{Path: "b.go", Area: git.Unstaged},
+			},
+			wantSelected: 1,
+		},
+		{
+			name:     "選択中のファイルが消えた場合は先頭を選択",
+			selected: git.FileChange{Path: "gone.go", Area: git.Unstaged},
+			changes: []git.FileChange{
+				{Path: "a.go", Area: git.Unstaged},
+			},
+			wantSelected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dv := NewDiffView()
+			dv.changes = []git.FileChange{tt.selected}
+
+			dv.Update(diffChangesMsg{changes: tt.changes})
+			assert.Equal(t, tt.wantSelected, dv.selected)
+			assert.NoError(t, dv.err)
+		})
+	}
+
+	t.Run("エラーの反映", func(t *testing.T) {
+		dv := NewDiffView()
+		_, cmd := dv.Update(diffChangesMsg{err: errors.New("失敗")})
+		assert.Nil(t, cmd)
+		assert.Contains(t, dv.View(), "エラー: 失敗")
+	})
+}
+
+func TestDiffView_ContentMsg(t *testing.T) {
+	dv := NewDiffView()
+	dv.changes = []git.FileChange{
+		{Path: "a.go", Area: git.Unstaged},
+		{Path: "b.go", Area: git.Unstaged},
+	}
+
+	// 選択中でないファイルの結果は無視する
+	dv.Update(diffContentMsg{change: dv.changes[1], diff: testFileDiff()})
+	assert.Nil(t, dv.diff)
+
+	dv.Update(diffContentMsg{change: dv.changes[0], diff: testFileDiff()})
+	assert.NotNil(t, dv.diff)
+}
+
+func TestDiffView_KeyNavigation(t *testing.T) {
+	dv := NewDiffView()
+	dv.changes = []git.FileChange{
+		{Path: "a.go", Area: git.Staged},
+		{Path: "b.go", Area: git.Unstaged},
+	}
+
+	dv.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, 1, dv.selected)
+
+	// 末尾より先には進まない
+	dv.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, 1, dv.selected)
+
+	dv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	assert.Equal(t, 0, dv.selected)
+
+	dv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	assert.True(t, dv.sideBySide)
+}
+
+func TestDiffView_TickGeneration(t *testing.T) {
+	dv := NewDiffView()
+	dv.visible = true
+	dv.tickID = 2
+
+	// 古い世代のタイマーは無視する
+	_, cmd := dv.Update(diffTickMsg{id: 1})
+	assert.Nil(t, cmd)
+
+	// 非表示の場合は更新を止める
+	dv.visible = false
+	_, cmd = dv.Update(diffTickMsg{id: 2})
+	assert.Nil(t, cmd)
+}
+
+func TestSummarizeChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		change git.FileChange
+		want   string
+	}{
+		{name: "通常ファイル", change: git.FileChange{Added: 10, Deleted: 2}, want: ""},
+		{name: "バイナリ", change: git.FileChange{Binary: true}, want: "バイナリ"},
+		{name: "大きなファイル", change: git.FileChange{Size: maxDiffFileSize + 1}, want: "大きなファイル"},
+		{name: "変更行が多い", change: git.FileChange{Added: maxDiffLines, Deleted: 1}, want: "+3000 -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizeChange(tt.change)
+			if tt.want == "" {
+				assert.Empty(t, got)
+				return
+			}
+			assert.Contains(t, got, tt.want)
+		})
+	}
+}
+
+func TestRenderUnified(t *testing.T) {
+	lines := renderUnified(testFileDiff(), 80, 4)
+	require.Len(t, lines, 5)
+
+	plain := make([]string, len(lines))
+	for i, l := range lines {
+		plain[i] = ansi.Strip(l)
+	}
+	assert.Equal(t, []string{
+		"@@ -1,3 +1,3 @@",
+		" package main",
+		"-var a = 1",
+		"+var a = 2",
+		" func main() {}",
+	}, plain)
+}
+
+func TestRenderSideBySide(t *testing.T) {
+	lines := renderSideBySide(testFileDiff(), 83, 4)
+	require.Len(t, lines, 4)
+
+	// 削除行と追加行が同じ行に並ぶ
+	paired := ansi.Strip(lines[2])
+	left, right, found := strings.Cut(paired, " │ ")
+	require.True(t, found)
+	assert.Contains(t, left, "var a = 1")
+	assert.Contains(t, right, "var a = 2")
+	assert.Equal(t, 40, ansi.StringWidth(left))
+}
+
+func TestRenderDiff_Tabs(t *testing.T) {
+	diff := &git.FileDiff{
+		Hunks: []git.Hunk{
+			{
+				Header: "@@ -1,2 +1,2 @@",
+				Lines: []git.DiffLine{
+					{Kind: git.ContextLine, Text: "func main() {", OldNo: 1, NewNo: 1},
+					{Kind: git.DeletedLine, Text: "\tfmt.Println(\"a\")\r", OldNo: 2},
+					{Kind: git.AddedLine, Text: "\t\tfmt.Println(\"b\")", NewNo: 2},
+				},
+			},
+		},
+	}
+
+	t.Run("unified", func(t *testing.T) {
+		lines := renderUnified(diff, 80, 4)
+		require.Len(t, lines, 4)
+		assert.Equal(t, "-   fmt.Println(\"a\")", ansi.Strip(lines[2]))
+		assert.Equal(t, "+       fmt.Println(\"b\")", ansi.Strip(lines[3]))
+	})
+
+	t.Run("side-by-side", func(t *testing.T) {
+		lines := renderSideBySide(diff, 83, 8)
+		for _, l := range lines[1:] {
+			plain := ansi.Strip(l)
+			assert.NotContains(t, plain, "\t")
+			assert.Equal(t, 83, ansi.StringWidth(plain))
+		}
+		left, right, found := strings.Cut(ansi.Strip(lines[2]), " │ ")
+		require.True(t, found)
+		assert.Equal(t, "   2    fmt.Println(\"a\")", strings.TrimRight(left, " "))
+		assert.Equal(t, "   2            fmt.Println(\"b\")", strings.TrimRight(right, " "))
+	})
+}
+
+func TestDiffView_ClampDiffOffset(t *testing.T) {
+	lines := make([]git.DiffLine, 20)
+	for i := range lines {
+		lines[i] = git.DiffLine{Kind: git.AddedLine, Text: fmt.Sprintf("line %d", i+1), NewNo: i + 1}
+	}
+	dv := NewDiffView()
+	dv.SetSize(80, 10)
+	dv.changes = []git.FileChange{{Path: "main.go", Area: git.Unstaged, Status: 'M', Added: 20}}
+	dv.diff = &git.FileDiff{Hunks: []git.Hunk{{Header: "@@ -0,0 +1,20 @@", Lines: lines}}}
+	dv.notice = "ステージしました"
+
+	// 通知があっても最後のページより先にはスクロールしない
+	for range 5 {
+		dv.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	}
+	assert.Equal(t, 21-8, dv.diffOffset)
+	view := strings.Split(ansi.Strip(dv.renderDiff()), "\n")
+	require.Len(t, view, 10)
+	assert.Equal(t, "+line 20", view[len(view)-1])
+}
+
+func TestDiffView_View(t *testing.T) {
+	t.Run("幅が足りない場合はunified表示", func(t *testing.T) {
+		dv := NewDiffView()
+		dv.SetSize(100, 20)
+		dv.sideBySide = true
+		dv.changes = []git.FileChange{{Path: "main.go", Area: git.Unstaged, Status: 'M', Added: 1, Deleted: 1}}
+		dv.diff = testFileDiff()
+
+		view := ansi.Strip(dv.View())
+		assert.Contains(t, view, "幅が足りないためunified表示")
+		assert.Contains(t, view, "+var a = 2")
+		assert.Contains(t, view, "変更 (1)")
+	})
+
+	t.Run("要約の表示", func(t *testing.T) {
+		dv := NewDiffView()
+		dv.changes = []git.FileChange{{Path: "logo.png", Area: git.Untracked, Status: '?', Binary: true}}
+		dv.summary = summarizeChange(dv.changes[0])
+
+		view := ansi.Strip(dv.View())
+		assert.Contains(t, view, "バイナリファイルのため差分を表示しません")
+		assert.Contains(t, view, "bin")
+	})
+
+	t.Run("変更なし", func(t *testing.T) {
+		dv := NewDiffView()
+		assert.Contains(t, ansi.Strip(dv.View()), "変更はありません")
+	})
+}
+
+func TestModel_ToggleDiffView(t *testing.T) {
+	m := NewModel()
+	m.workDir = t.TempDir()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	m = updated.(Model)
+	assert.True(t, m.diffView.IsVisible())
+
+	// 表示中は文字入力がメインビューに渡らない
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m = updated.(Model)
+	assert.Empty(t, m.mainView.input)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	assert.False(t, m.diffView.IsVisible())
+}
+
+func TestDiffView_SelectHunk(t *testing.T) {
+	dv := NewDiffView()
+	dv.SetSize(100, 20)
+	dv.changes = []git.FileChange{{Path: "main.go", Area: git.Unstaged, Status: 'M'}}
+	diff := testFileDiff()
+	second := diff.Hunks[0]
+	second.Header = "@@ -10,3 +10,3 @@"
+	diff.Hunks = append(diff.Hunks, second)
+	dv.diff = diff
+
+	assert.Equal(t, []int{0, 5}, dv.hunkStarts(dv.diffWidth()))
+
+	dv.Update(runes("]"))
+	assert.Equal(t, 1, dv.hunk)
+	// 末尾より先には進まない
+	dv.Update(runes("]"))
+	assert.Equal(t, 1, dv.hunk)
+	dv.Update(runes("["))
+	assert.Equal(t, 0, dv.hunk)
+
+	// ハンクが減った場合は範囲内に収める
+	dv.hunk = 1
+	dv.Update(diffContentMsg{change: dv.changes[0], diff: testFileDiff()})
+	assert.Equal(t, 0, dv.hunk)
+}
+
+func TestDiffView_Stage(t *testing.T) {
+	root := newCommitProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "info", "exclude"), []byte("ccforge/\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("changed\n"), 0o644))
+
+	dv := NewDiffView()
+	run := func(cmd tea.Cmd) {
+		for cmd != nil {
+			_, cmd = dv.Update(cmd())
+		}
+	}
+	dv.Show(root)
+	run(dv.refresh())
+	require.Len(t, dv.changes, 1)
+	assert.Equal(t, git.Unstaged, dv.changes[0].Area)
+	require.NotNil(t, dv.diff)
+
+	// ファイルをステージする
+	run(dv.toggleStage())
+	assert.Equal(t, "M  a.txt\n", runGit(t, root, "status", "--porcelain"))
+	assert.Equal(t, git.Staged, dv.changes[0].Area)
+	assert.Contains(t, ansi.Strip(dv.View()), "ステージしました: a.txt")
+
+	// ステージ済みのハンクは取り消す
+	run(dv.toggleStageHunk())
+	assert.Equal(t, " M a.txt\n", runGit(t, root, "status", "--porcelain"))
+	assert.Contains(t, ansi.Strip(dv.View()), "a.txt のハンク 1/1 のステージを取り消しました")
+}
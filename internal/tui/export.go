@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/export"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+// exportFunc はタスクのセッションのトランスクリプトを保存し、保存先を返す
+// セッションIDが空の場合はタスクの最新のセッションを保存する
+type exportFunc func(task, sessionID string, format export.Format) (string, error)
+
+// exportRequestMsg はトランスクリプトのエクスポートを要求するメッセージ
+type exportRequestMsg struct {
+	task      string        // タスク名 (空の場合はアクティブなタスク)
+	sessionID string        // セッションID (空の場合は最新のセッション)
+	format    export.Format // 形式
+}
+
+// exportDoneMsg はエクスポートの結果
+type exportDoneMsg struct {
+	path string
+	err  error
+}
+
+// WithTranscriptExport はトランスクリプトのエクスポートを有効にする
+func WithTranscriptExport(fn func(task, sessionID string, format export.Format) (string, error)) Option {
+	return func(m *Model) {
+		m.exporter = fn
+	}
+}
+
+// exportTranscript はトランスクリプトをタスクのディレクトリに保存するコマンドを返す
+func (m *Model) exportTranscript(req exportRequestMsg) tea.Cmd {
+	if m.exporter == nil {
+		m.mainView.AddOutput(themed(theme.Muted).Render("トランスクリプトのエクスポートは利用できません"))
+		return nil
+	}
+	if req.task == "" {
+		req.task = m.statusBar.activeTask
+	}
+	if req.task == "" {
+		m.mainView.AddOutput(themed(theme.Error).Render("✗ アクティブなタスクがありません (ccforge start <タスク名> で開始してください)"))
+		return nil
+	}
+	if req.format == "" {
+		req.format = export.Markdown
+	}
+
+	exporter := m.exporter
+	slog.Debug("トランスクリプトをエクスポートします", "component", "tui", "task", req.task, "session", req.sessionID, "format", req.format)
+	return func() tea.Msg {
+		path, err := exporter(req.task, req.sessionID, req.format)
+		return exportDoneMsg{path: path, err: err}
+	}
+}
+
+// handleExportDone はエクスポートの結果を表示する
+func (m *Model) handleExportDone(msg exportDoneMsg) {
+	if msg.err != nil {
+		m.mainView.AddOutput(themed(theme.Error).Render(fmt.Sprintf("✗ エクスポートに失敗しました: %v", msg.err)))
+		return
+	}
+	m.mainView.AddOutput(themed(theme.Success).Render(fmt.Sprintf("✓ トランスクリプトを保存しました: %s", msg.path)))
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/export"
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportCall はエクスポートの呼び出し
+type exportCall struct {
+	task, sessionID string
+	format          export.Format
+}
+
+// newExportModel はエクスポートを記録するModelを作成する
+func newExportModel(t *testing.T, err error, opts ...Option) (Model, *[]exportCall) {
+	t.Helper()
+	var calls []exportCall
+	exporter := func(task, sessionID string, format export.Format) (string, error) {
+		calls = append(calls, exportCall{task: task, sessionID: sessionID, format: format})
+		return "/p/ccforge/" + task + "/exports/x" + format.Ext(), err
+	}
+	return NewModel(append(opts, WithTranscriptExport(exporter))...), &calls
+}
+
+// lastOutput はメインビューの最後の出力を返す
+func lastOutput(m Model) string {
+	return m.mainView.outputLines[len(m.mainView.outputLines)-1]
+}
+
+func TestModel_Export(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantCall exportCall
+		wantOut  string
+	}{
+		{name: "形式を省略するとMarkdown", input: "/export", wantCall: exportCall{task: "auth", format: export.Markdown}, wantOut: "✓ トランスクリプトを保存しました: /p/ccforge/auth/exports/x.md"},
+		{name: "HTML", input: "/export html", wantCall: exportCall{task: "auth", format: export.HTML}, wantOut: "exports/x.html"},
+		{name: "JSONL", input: "/export jsonl", wantCall: exportCall{task: "auth", format: export.JSONL}, wantOut: "exports/x.jsonl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, calls := newExportModel(t, nil, WithActiveTask("auth"))
+			m, msg := update(t, m, SubmitMsg{Text: tt.input})
+			m, msg = update(t, m, msg)
+			m, _ = update(t, m, msg)
+
+			assert.Equal(t, []exportCall{tt.wantCall}, *calls)
+			assert.Contains(t, lastOutput(m), tt.wantOut)
+		})
+	}
+}
+
+func TestModel_ExportErrors(t *testing.T) {
+	t.Run("不明な形式", func(t *testing.T) {
+		m, calls := newExportModel(t, nil, WithActiveTask("auth"))
+		m, msg := update(t, m, SubmitMsg{Text: "/export pdf"})
+		assert.Nil(t, msg)
+		assert.Empty(t, *calls)
+		assert.Contains(t, m.mainView.outputLines[len(m.mainView.outputLines)-2], "pdf")
+	})
+
+	t.Run("アクティブなタスクなし", func(t *testing.T) {
+		m, calls := newExportModel(t, nil)
+		m, msg := update(t, m, RunActionMsg{ID: "transcript.export"})
+		assert.Nil(t, msg)
+		assert.Empty(t, *calls)
+		assert.Contains(t, lastOutput(m), "アクティブなタスクがありません")
+	})
+
+	t.Run("保存に失敗", func(t *testing.T) {
+		m, _ := newExportModel(t, errors.New("トランスクリプトがありません"), WithActiveTask("auth"))
+		m, msg := update(t, m, RunActionMsg{ID: "transcript.export"})
+		m, _ = update(t, m, msg)
+		assert.Contains(t, lastOutput(m), "✗ エクスポートに失敗しました: トランスクリプトがありません")
+	})
+
+	t.Run("エクスポートなし", func(t *testing.T) {
+		m := NewModel(WithActiveTask("auth"))
+		m, msg := update(t, m, RunActionMsg{ID: "transcript.export"})
+		assert.Nil(t, msg)
+		assert.Contains(t, lastOutput(m), "トランスクリプトのエクスポートは利用できません")
+	})
+}
+
+func TestTranscriptDialog_Export(t *testing.T) {
+	m, calls := newExportModel(t, nil)
+	d := NewTranscriptDialog(store.SearchHit{TaskName: "billing", SessionID: "s9", PromptID: 1}, testTranscript, nil)
+
+	// 表示中のセッションを保存し、ダイアログは閉じない
+	cmd, closed := d.HandleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	assert.False(t, closed)
+	require.NotNil(t, cmd)
+	m, msg := update(t, m, cmd())
+	_, _ = update(t, m, msg)
+	assert.Equal(t, []exportCall{{task: "billing", sessionID: "s9", format: export.Markdown}}, *calls)
+}
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/git"
+)
+
+// defaultGitStatusInterval はgitの状態を定期的に更新する既定の間隔
+const defaultGitStatusInterval = 5 * time.Second
+
+// gitStatusTickMsg はgitの状態の定期更新のタイマーメッセージ
+type gitStatusTickMsg struct{}
+
+// gitSummaryMsg はgitの状態の取得結果
+type gitSummaryMsg struct {
+	summary git.Summary
+	err     error
+}
+
+// gitStatus はステータスバーに表示するgitの状態を更新する
+type gitStatus struct {
+	load    func() (git.Summary, error) // 状態の取得
+	loading bool                        // 取得中か (取得を重ねない)
+}
+
+// WithGitStatus はloadで取得したgitの状態をステータスバーに表示する
+// 状態はファイルの変更を検出したときと一定間隔で更新する
+func WithGitStatus(load func() (git.Summary, error)) Option {
+	return func(m *Model) {
+		m.gitStatus = &gitStatus{load: load}
+	}
+}
+
+// refreshGitStatus はgitの状態を取得するコマンドを返す
+// 取得中の場合は何もしない
+func (m *Model) refreshGitStatus() tea.Cmd {
+	g := m.gitStatus
+	if g == nil || g.loading {
+		return nil
+	}
+	g.loading = true
+	load := g.load
+	return func() tea.Msg {
+		summary, err := load()
+		return gitSummaryMsg{summary: summary, err: err}
+	}
+}
+
+// handleGitSummary は取得したgitの状態をステータスバーに反映する
+func (m *Model) handleGitSummary(msg gitSummaryMsg) {
+	m.gitStatus.loading = false
+	if msg.err != nil {
+		slog.Debug("gitの状態を取得できませんでした", "component", "tui", "err", msg.err)
+		m.statusBar.ClearGit()
+		return
+	}
+	m.statusBar.SetGit(msg.summary)
+}
+
+// gitStatusTick は次の定期更新をスケジュールするコマンドを返す
+// 間隔は設定の再読み込みで変わるため、毎回その時点の設定を使う
+func (m *Model) gitStatusTick() tea.Cmd {
+	return tea.Tick(m.gitInterval, func(time.Time) tea.Msg {
+		return gitStatusTickMsg{}
+	})
+}
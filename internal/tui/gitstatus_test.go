@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_GitStatus(t *testing.T) {
+	summary := git.Summary{Branch: "main", Unstaged: 1}
+	var loadErr error
+	calls := 0
+	load := func() (git.Summary, error) {
+		calls++
+		return summary, loadErr
+	}
+	m := NewModel(WithGitStatus(load))
+	m.gitInterval = time.Millisecond
+
+	// 起動時に取得し、ステータスバーに表示する
+	msgs := runBatch(t, m.Init())
+	assert.Equal(t, []tea.Msg{gitSummaryMsg{summary: summary}, gitStatusTickMsg{}}, msgs)
+	assert.Equal(t, 1, calls)
+	m, _ = update(t, m, gitSummaryMsg{summary: summary})
+	assert.Contains(t, ansi.Strip(m.statusBar.View()), "main ~1")
+
+	// ファイルの変更を検出したら取得し直す
+	summary = git.Summary{Branch: "main", Staged: 1}
+	updated, cmd := m.Update(fileChangesMsg{changes: []watch.Change{{Path: "a.go", Op: watch.Modified}}})
+	m = updated.(Model)
+	require.NotNil(t, cmd)
+	assert.True(t, m.gitStatus.loading)
+
+	// 取得中は重ねて取得しない
+	assert.Nil(t, m.refreshGitStatus())
+
+	m, _ = update(t, m, gitSummaryMsg{summary: summary})
+	assert.False(t, m.gitStatus.loading)
+	assert.Contains(t, ansi.Strip(m.statusBar.View()), "main +1")
+
+	// 取得できない場合はgitの欄を表示しない
+	loadErr = errors.New("not a repository")
+	m, _ = update(t, m, gitSummaryMsg{err: loadErr})
+	assert.NotContains(t, ansi.Strip(m.statusBar.View()), "main")
+}
+
+func TestModel_GitStatusInterval(t *testing.T) {
+	m := NewModel()
+	assert.Equal(t, defaultGitStatusInterval, m.gitInterval)
+
+	// 監視していない場合はタイマーを止める
+	_, msg := update(t, m, gitStatusTickMsg{})
+	assert.Nil(t, msg)
+}
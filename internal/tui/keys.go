@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mzkmnk/ccforge/internal/keymap"
+)
+
+// defaultKeyBindings は既定のキーバインド
+// 文字キーはプロンプトの入力と衝突するためグローバルには割り当てない
+var defaultKeyBindings = keymap.Bindings{
+	keymap.Global: {
+		"app.quit":       {"ctrl+c"},
+		"help.toggle":    {"f1"},
+		"screen.clear":   {"ctrl+l"},
+		"diff.toggle":    {"ctrl+d"},
+		"tasks.toggle":   {"ctrl+t"},
+		"changes.toggle": {"ctrl+g"},
+		"review.start":   {"ctrl+r"},
+		"palette.open":   {"ctrl+k"},
+		"search.open":    {"ctrl+f"},
+	},
+	keymap.Sidebar: {
+		"diff.hide":       {"esc", "q"},
+		"diff.stage":      {"a"},
+		"diff.stage_hunk": {"h"},
+		"diff.commit":     {"c"},
+		"review.accept":   {"y"},
+		"review.reject":   {"n", "x"},
+		"review.skip":     {"s", "j", "down"},
+		"review.prev":     {"k", "up"},
+	},
+	keymap.Modal: {
+		"app.quit": {"ctrl+c"},
+	},
+}
+
+// helpEntry はヘルプバーに表示する操作
+type helpEntry struct {
+	action string // 操作ID
+	label  string // 表示名
+}
+
+// helpEntries はコンテキストごとにヘルプバーへ表示する操作
+var helpEntries = map[keymap.Context][]helpEntry{
+	keymap.Input: {{action: "help.toggle", label: "ヘルプ"}, {action: "app.quit", label: "終了"}},
+	keymap.Sidebar: {
+		{action: "diff.stage", label: "ステージ"},
+		{action: "diff.stage_hunk", label: "ハンク"},
+		{action: "diff.commit", label: "コミット"},
+		{action: "review.accept", label: "採用"},
+		{action: "review.reject", label: "却下"},
+		{action: "review.skip", label: "スキップ"},
+		{action: "review.prev", label: "戻る"},
+		{action: "diff.hide", label: "閉じる"},
+		{action: "app.quit", label: "終了"},
+	},
+	keymap.Modal: {{action: "app.quit", label: "終了"}},
+}
+
+// WithKeybindings は既定のキーバインドにユーザーの割り当てを重ねる
+// 衝突や実行されない割り当てはメインビューに警告として表示する
+func WithKeybindings(overrides keymap.Bindings) Option {
+	return func(m *Model) {
+		for _, problem := range m.setKeymap(overrides) {
+			m.mainView.AddOutput(fmt.Sprintf("警告: キーバインド %s", problem))
+		}
+	}
+}
+
+// setKeymap はキーマップを作り直して問題を返す
+func (m *Model) setKeymap(overrides keymap.Bindings) []keymap.Problem {
+	km, problems := keymap.New(defaultKeyBindings, overrides, func(action string) bool {
+		_, ok := m.actions.Get(action)
+		return ok
+	})
+	for _, problem := range problems {
+		slog.Warn("キーバインドに問題があります", "component", "tui", "kind", problem.Kind, "context", problem.Context, "key", problem.Key, "action", problem.Action, "message", problem.Message)
+	}
+	m.keymap = km
+	return problems
+}
+
+// keyContext は現在のフォーカスに対応するコンテキストを返す
+func (m *Model) keyContext() keymap.Context {
+	switch {
+	case m.overlays != nil && m.overlays.Len() > 0:
+		return keymap.Modal
+	case m.diffView != nil && m.diffView.IsVisible():
+		return keymap.Sidebar
+	case m.tasksView != nil && m.tasksView.IsVisible():
+		return keymap.Sidebar
+	case m.changesView != nil && m.changesView.IsVisible():
+		return keymap.Sidebar
+	case m.reviewView != nil && m.reviewView.IsVisible():
+		return keymap.Sidebar
+	default:
+		return keymap.Input
+	}
+}
+
+// keys は現在のキーマップを返す
+// NewModelを使わずに作成された場合は既定のキーマップを使う
+func (m *Model) keys() *keymap.Keymap {
+	if m.keymap == nil {
+		km, _ := keymap.New(defaultKeyBindings, nil, nil)
+		return km
+	}
+	return m.keymap
+}
+
+// keyHint は操作を実行できる最初のキーを表示用の表記で返す
+func (m *Model) keyHint(ctx keymap.Context, action string) string {
+	keys := m.keys().Keys(ctx, action)
+	if len(keys) == 0 {
+		return ""
+	}
+	return keymap.Display(keys[0])
+}
+
+// anyKeyHint は操作を実行できる最初のキーを、割り当てたコンテキストを問わず表示用の表記で返す
+func (m *Model) anyKeyHint(action string) string {
+	keys := m.keys().AnyKeys(action)
+	if len(keys) == 0 {
+		return ""
+	}
+	return keymap.Display(keys[0])
+}
+
+// helpText は現在のコンテキストのヘルプバーの項目を返す
+func (m *Model) helpText() []string {
+	ctx := m.keyContext()
+	var items []string
+	for _, entry := range helpEntries[ctx] {
+		// 表示中のパネルで実行できない操作は表示しない
+		if a, ok := m.actions.Get(entry.action); ok && !a.enabled(m) {
+			continue
+		}
+		if hint := m.keyHint(ctx, entry.action); hint != "" {
+			items = append(items, hint+": "+entry.label)
+		}
+	}
+	return items
+}
+
+// quitHint はエラー画面に表示する終了キーの案内を返す
+func (m *Model) quitHint() string {
+	keys := m.keys().Keys(keymap.Global, "app.quit")
+	hints := make([]string, len(keys))
+	for i, key := range keys {
+		hints[i] = keymap.Display(key)
+	}
+	return strings.Join(hints, "または") + "で終了"
+}
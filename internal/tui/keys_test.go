@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/keymap"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pressKey はキー入力を処理したModelを返す
+func pressKey(t *testing.T, m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	t.Helper()
+	updated, cmd := m.Update(msg)
+	return updated.(Model), cmd
+}
+
+func TestModel_KeyContext(t *testing.T) {
+	m := NewModel()
+	m.workDir = t.TempDir()
+	assert.Equal(t, keymap.Input, m.keyContext())
+
+	m, _ = pressKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlD})
+	assert.Equal(t, keymap.Sidebar, m.keyContext())
+
+	m.overlays.Push(NewConfirmDialog("test", "確認", "よろしいですか?"))
+	assert.Equal(t, keymap.Modal, m.keyContext())
+}
+
+func TestModel_TypingDoesNotQuit(t *testing.T) {
+	m := NewModel()
+
+	for _, r := range "quit" {
+		var cmd tea.Cmd
+		m, cmd = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		assert.Nil(t, cmd)
+	}
+	assert.Equal(t, "quit", m.mainView.input)
+}
+
+func TestWithKeybindings(t *testing.T) {
+	m := NewModel(WithKeybindings(keymap.Bindings{
+		keymap.Global: {"app.quit": {"ctrl+q"}, "help.toggle": {"f2"}, "no.such": {"ctrl+x"}},
+	}))
+
+	// 存在しない操作への割り当ては起動時に警告する
+	output := strings.Join(m.mainView.outputLines, "\n")
+	assert.Contains(t, output, "警告: キーバインド global: Ctrl+X (no.such): 存在しない操作です")
+
+	// 既定のCtrl+Cは置き換えられる
+	_, cmd := pressKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlC})
+	assert.Nil(t, cmd)
+	_, cmd = pressKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlQ})
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.Quit(), cmd())
+
+	// ヘルプバーはアクティブなキーマップから描画する
+	assert.Equal(t, []string{"F2: ヘルプ", "Ctrl+Q: 終了"}, m.helpText())
+	assert.Contains(t, m.quitHint(), "Ctrl+Qで終了")
+}
+
+func TestModel_HelpTextByContext(t *testing.T) {
+	m := NewModel()
+	m.workDir = t.TempDir()
+	assert.Equal(t, []string{"F1: ヘルプ", "Ctrl+C: 終了"}, m.helpText())
+
+	m, _ = pressKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlD})
+	assert.Equal(t, []string{"a: ステージ", "h: ハンク", "c: コミット", "Esc: 閉じる", "Ctrl+C: 終了"}, m.helpText())
+
+	// 差分パネルはqでも閉じる
+	m, _ = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	assert.False(t, m.diffView.IsVisible())
+}
+
+func TestModel_DiffKeysFromKeymap(t *testing.T) {
+	root := newCommitProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "info", "exclude"), []byte("ccforge/\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("changed\n"), 0o644))
+	m := NewModel(WithWorkDir(root), WithKeybindings(keymap.Bindings{
+		keymap.Sidebar: {"diff.stage": {"S"}},
+	}))
+
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyCtrlD})
+	require.True(t, m.diffView.IsVisible())
+	m = updateAll(t, m, m.diffView.refresh()())
+	require.Len(t, m.diffView.changes, 1)
+	assert.Contains(t, m.helpText(), "S: ステージ")
+
+	// 既定のaは置き換えられ、差分パネルに渡る
+	m = updateAll(t, m, runes("a"))
+	assert.Equal(t, " M a.txt\n", runGit(t, root, "status", "--porcelain"))
+	m = updateAll(t, m, runes("S"))
+	assert.Equal(t, "M  a.txt\n", runGit(t, root, "status", "--porcelain"))
+
+	// 他のパネルでは差分パネルの操作のキーをそのパネルが受け取る
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyCtrlT})
+	require.True(t, m.tasksView.IsVisible())
+	assert.NotContains(t, m.helpText(), "h: ハンク")
+	m = updateAll(t, m, runes("h"))
+	assert.Equal(t, len(tasks.SpecFiles)-1, m.tasksView.specIndex)
+}
+
+func TestModel_PaletteHintsFromKeymap(t *testing.T) {
+	m := NewModel(WithKeybindings(keymap.Bindings{keymap.Global: {"screen.clear": {"ctrl+r"}}}))
+
+	cmd := m.openPalette()
+	dialog := cmd().(OpenDialogMsg).Dialog.(*PickerDialog)
+
+	hints := map[string]string{}
+	for _, item := range dialog.items {
+		hints[item.Value] = item.Hint
+	}
+	assert.Equal(t, "Ctrl+R", hints["screen.clear"])
+	assert.Equal(t, "Ctrl+D", hints["diff.toggle"])
+	// サイドパネルだけの操作もキーを表示する
+	assert.Equal(t, "Esc", hints["diff.hide"])
+	assert.Equal(t, "c", hints["diff.commit"])
+	assert.Empty(t, hints["diff.side_by_side"])
+}
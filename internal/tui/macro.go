@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/command"
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/macro"
+)
+
+// macroDialogPrefix はマクロ引数入力ダイアログの識別子の接頭辞
+const macroDialogPrefix = "macro:"
+
+// errNotConnected はClaude Codeに未接続であることを表すエラー
+var errNotConnected = errors.New("Claude Codeに未接続です")
+
+// ResponseCompleteMsg はClaude Codeの応答が完了したことを表すメッセージ
+type ResponseCompleteMsg struct {
+	Err error // 送信・応答に失敗した場合のエラー
+}
+
+// macroStartMsg はマクロの実行開始を要求するメッセージ
+type macroStartMsg struct {
+	name string   // マクロ名
+	args []string // 位置引数
+}
+
+// macroRun は実行中のマクロの状態
+type macroRun struct {
+	name    string       // マクロ名
+	steps   []macro.Step // 展開済みの手順
+	next    int          // 次に実行する手順
+	pending int          // 応答待ちのプロンプト数
+	waiting bool         // 応答完了を待って停止中か
+}
+
+// registerMacros はユーザー定義マクロをスラッシュコマンドとパレットの操作として登録する
+// 登録できなかったマクロはエラーとして返す
+func (m *Model) registerMacros(defs []config.MacroCommand) []error {
+	var errs []error
+
+	for _, def := range defs {
+		m.macros[def.Name] = def
+
+		args := make([]command.ArgSpec, 0, len(def.Args)+1)
+		for _, name := range def.Args {
+			args = append(args, command.ArgSpec{Name: name, Required: true})
+		}
+		args = append(args, command.ArgSpec{Name: "args", Variadic: true})
+
+		err := m.commands.Register(command.Command{
+			Name: def.Name,
+			Args: args,
+			Help: macroHelp(def),
+			Handler: func(a command.Args) tea.Cmd {
+				return msgCmd(macroStartMsg{name: def.Name, args: a.Raw()})
+			},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("マクロ %s を登録できませんでした: %w", def.Name, err))
+			continue
+		}
+
+		err = m.actions.Register(Action{
+			ID:          "macro." + def.Name,
+			Title:       "/" + def.Name,
+			Description: macroHelp(def),
+			Run: func(m *Model) tea.Cmd {
+				if len(def.Args) == 0 {
+					return msgCmd(macroStartMsg{name: def.Name})
+				}
+				// 引数が必要なマクロは入力ダイアログで受け取る
+				return OpenDialog(NewPromptDialog(
+					macroDialogPrefix+def.Name,
+					"/"+def.Name,
+					"引数: "+strings.Join(def.Args, " "),
+					"",
+				))
+			},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("マクロ %s を登録できませんでした: %w", def.Name, err))
+		}
+	}
+
+	return errs
+}
+
+// macroHelp はマクロの説明文を返す
+func macroHelp(def config.MacroCommand) string {
+	if def.Description != "" {
+		return def.Description
+	}
+	return fmt.Sprintf("ユーザー定義マクロ (%d手順)", len(def.Steps))
+}
+
+// handleMacroPrompt はマクロ引数入力ダイアログの結果を処理する
+func (m *Model) handleMacroPrompt(msg PromptResultMsg) tea.Cmd {
+	if msg.Canceled {
+		return nil
+	}
+
+	name := strings.TrimPrefix(msg.ID, macroDialogPrefix)
+	args, err := command.Tokenize(msg.Value)
+	if err != nil {
+		m.mainView.AddOutput(fmt.Sprintf("エラー: /%s: %v", name, err))
+		return nil
+	}
+	return m.startMacro(name, args)
+}
+
+// startMacro はマクロを展開して実行を開始する
+func (m *Model) startMacro(name string, args []string) tea.Cmd {
+	def, ok := m.macros[name]
+	if !ok {
+		m.mainView.AddOutput(fmt.Sprintf("エラー: 不明なマクロです: %s", name))
+		return nil
+	}
+	if m.macro != nil {
+		m.mainView.AddOutput(fmt.Sprintf("エラー: マクロ /%s の実行中です", m.macro.name))
+		return nil
+	}
+
+	steps, err := macro.Expand(def, args, m.macroVars(def))
+	if err != nil {
+		m.mainView.AddOutput(fmt.Sprintf("エラー: %v", err))
+		return nil
+	}
+
+	slog.Info("マクロを開始します", "component", "tui", "macro", name, "steps", len(steps))
+	m.mainView.AddOutput(fmt.Sprintf("マクロ /%s を実行します (%d手順)", name, len(steps)))
+	m.macro = &macroRun{name: name, steps: steps}
+	return m.advanceMacro()
+}
+
+// macroVars はプレースホルダーに埋め込む実行時の値を集める
+func (m *Model) macroVars(def config.MacroCommand) macro.Vars {
+	vars := macro.Vars{Task: m.statusBar.GetActiveTask()}
+
+	if change, ok := m.diffView.SelectedChange(); ok {
+		vars.File = change.Path
+	}
+
+	// ブランチ名はgitコマンドを伴うため使われている場合のみ取得する
+	if macro.Placeholders(def)["branch"] {
+		if repo, err := git.Open(m.repoDir()); err == nil {
+			vars.Branch, _ = repo.CurrentBranch()
+		}
+	}
+
+	return vars
+}
+
+// advanceMacro は応答待ちの手順に達するまでマクロの手順を実行する
+func (m *Model) advanceMacro() tea.Cmd {
+	run := m.macro
+	if run == nil {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	for run.next < len(run.steps) {
+		step := run.steps[run.next]
+		run.next++
+
+		if step.Action != "" {
+			cmds = append(cmds, runActionCmd(step.Action))
+			continue
+		}
+
+		run.pending++
+		cmds = append(cmds, msgCmd(PromptMsg{Text: step.Prompt}))
+		if step.Wait {
+			run.waiting = true
+			break
+		}
+	}
+
+	if run.next >= len(run.steps) && !run.waiting {
+		slog.Info("マクロが完了しました", "component", "tui", "macro", run.name)
+		cmds = append(cmds, msgCmd(OutputMsg{Lines: []string{fmt.Sprintf("マクロ /%s が完了しました", run.name)}}))
+		m.macro = nil
+	}
+
+	if len(cmds) == 1 {
+		return cmds[0]
+	}
+	return tea.Sequence(cmds...)
+}
+
+// handleResponseComplete は応答完了を受けて待機中のマクロを再開する
+func (m *Model) handleResponseComplete(msg ResponseCompleteMsg) tea.Cmd {
+	run := m.macro
+	if run == nil || run.pending == 0 {
+		return nil
+	}
+
+	if msg.Err != nil {
+		slog.Warn("マクロを中断しました", "component", "tui", "macro", run.name, "err", msg.Err)
+		m.mainView.AddOutput(fmt.Sprintf("マクロ /%s を中断しました: %v", run.name, msg.Err))
+		m.macro = nil
+		return nil
+	}
+
+	run.pending--
+	if run.waiting && run.pending == 0 {
+		run.waiting = false
+		return m.advanceMacro()
+	}
+	return nil
+}
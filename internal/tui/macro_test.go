@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMacros はテスト用のマクロ定義
+func testMacros() []config.MacroCommand {
+	return []config.MacroCommand{
+		{
+			Name:        "fix",
+			Description: "テストを実行して修正",
+			Args:        []string{"target"},
+			Steps: []config.MacroStep{
+				{Prompt: "{{target}} のテストを実行して", Wait: true},
+				{Prompt: "失敗を要約して"},
+				{Action: "diff.toggle"},
+				{Prompt: "修正案を提示して", Wait: true},
+			},
+		},
+		{
+			Name:  "hello",
+			Steps: []config.MacroStep{{Prompt: "こんにちは {{args}}"}},
+		},
+	}
+}
+
+func TestModel_RegisterMacros(t *testing.T) {
+	m := NewModel(WithMacros(testMacros()))
+
+	_, ok := m.commands.Lookup("fix")
+	assert.True(t, ok)
+	_, ok = m.actions.Get("macro.fix")
+	assert.True(t, ok)
+
+	t.Run("組み込みコマンドと衝突する場合はエラーを表示", func(t *testing.T) {
+		m := NewModel(WithMacros([]config.MacroCommand{
+			{Name: "diff", Steps: []config.MacroStep{{Prompt: "x"}}},
+		}))
+		assert.Contains(t, strings.Join(m.mainView.outputLines, "\n"), "マクロ diff を登録できませんでした")
+	})
+}
+
+func TestModel_MacroSlashCommand(t *testing.T) {
+	m := NewModel(WithMacros(testMacros()))
+
+	_, cmd := m.Update(SubmitMsg{Text: "/fix ./internal"})
+	require.NotNil(t, cmd)
+	assert.Equal(t, macroStartMsg{name: "fix", args: []string{"./internal"}}, cmd())
+
+	// 必須引数がない場合は引数エラーを表示
+	m.mainView.Clear()
+	_, cmd = m.Update(SubmitMsg{Text: "/fix"})
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.mainView.outputLines[0], "引数 target が必要です")
+}
+
+func TestModel_MacroRun(t *testing.T) {
+	m := NewModel(WithMacros(testMacros()))
+
+	// 最初の手順で応答待ちになる
+	updated, cmd := m.Update(macroStartMsg{name: "fix", args: []string{"./internal"}})
+	m = updated.(Model)
+	require.NotNil(t, m.macro)
+	assert.True(t, m.macro.waiting)
+	assert.Equal(t, PromptMsg{Text: "./internal のテストを実行して"}, cmd())
+
+	// 応答完了で次の待機手順まで進む
+	updated, cmd = m.Update(ResponseCompleteMsg{})
+	m = updated.(Model)
+	require.NotNil(t, m.macro)
+	assert.Equal(t, 2, m.macro.pending)
+	require.NotNil(t, cmd)
+
+	// 2つのプロンプトの応答が揃うまで再開しない
+	updated, cmd = m.Update(ResponseCompleteMsg{})
+	m = updated.(Model)
+	assert.Nil(t, cmd)
+	require.NotNil(t, m.macro)
+
+	updated, cmd = m.Update(ResponseCompleteMsg{})
+	m = updated.(Model)
+	assert.Nil(t, m.macro)
+	require.NotNil(t, cmd)
+	assert.Equal(t, OutputMsg{Lines: []string{"マクロ /fix が完了しました"}}, cmd())
+}
+
+func TestModel_MacroAbortOnError(t *testing.T) {
+	m := NewModel(WithMacros(testMacros()))
+
+	updated, _ := m.Update(macroStartMsg{name: "fix", args: []string{"x"}})
+	m = updated.(Model)
+
+	updated, cmd := m.Update(ResponseCompleteMsg{Err: errNotConnected})
+	m = updated.(Model)
+	assert.Nil(t, cmd)
+	assert.Nil(t, m.macro)
+	assert.Contains(t, m.mainView.outputLines[len(m.mainView.outputLines)-1], "中断しました")
+}
+
+func TestModel_MacroFromPalette(t *testing.T) {
+	m := NewModel(WithMacros(testMacros()))
+
+	// 引数が必要なマクロは入力ダイアログを開く
+	cmd := m.runAction("macro.fix")
+	require.NotNil(t, cmd)
+	openMsg, ok := cmd().(OpenDialogMsg)
+	require.True(t, ok)
+	_, ok = openMsg.Dialog.(*PromptDialog)
+	assert.True(t, ok)
+
+	updated, cmd := m.Update(PromptResultMsg{ID: macroDialogPrefix + "fix", Value: `"a b"`})
+	m = updated.(Model)
+	require.NotNil(t, m.macro)
+	assert.Equal(t, PromptMsg{Text: "a b のテストを実行して"}, cmd())
+
+	// 引数のないマクロはそのまま開始する
+	cmd = m.runAction("macro.hello")
+	require.NotNil(t, cmd)
+	assert.Equal(t, macroStartMsg{name: "hello"}, cmd())
+}
+
+func TestModel_MacroAlreadyRunning(t *testing.T) {
+	m := NewModel(WithMacros(testMacros()))
+
+	updated, _ := m.Update(macroStartMsg{name: "fix", args: []string{"x"}})
+	m = updated.(Model)
+
+	_, cmd := m.Update(macroStartMsg{name: "hello"})
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.mainView.outputLines[len(m.mainView.outputLines)-1], "実行中です")
+}
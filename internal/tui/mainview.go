@@ -39,7 +39,7 @@ func NewMainView() *MainView {
 func (m *MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		m.handleKeyMsg(msg)
+		return m, m.handleKeyMsg(msg)
 	case tea.WindowSizeMsg:
 		// ウィンドウサイズ変更
 		m.width = msg.Width
@@ -50,7 +50,7 @@ func (m *MainView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 // handleKeyMsg はキーボード入力を処理する
-func (m *MainView) handleKeyMsg(msg tea.KeyMsg) {
+func (m *MainView) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
 	switch msg.Type {
 	case tea.KeyRunes:
 		m.handleTextInput(string(msg.Runes))
@@ -59,7 +59,7 @@ func (m *MainView) handleKeyMsg(msg tea.KeyMsg) {
 	case tea.KeyDelete:
 		m.handleDelete()
 	case tea.KeyEnter:
-		m.handleEnter()
+		return m.handleEnter()
 	case tea.KeyUp:
 		m.scrollUp()
 	case tea.KeyDown:
@@ -78,6 +78,7 @@ func (m *MainView) handleKeyMsg(msg tea.KeyMsg) {
 		// rune数で位置を設定
 		m.cursorPos = len([]rune(m.input))
 	}
+	return nil
 }
 
 // handleTextInput は文字入力を処理する
@@ -128,12 +129,20 @@ func (m *MainView) handleDelete() {
 }
 
 // handleEnter はエンターキーを処理する
-func (m *MainView) handleEnter() {
-	if m.input != "" {
-		m.outputLines = append(m.outputLines, "> "+m.input)
-		m.input = ""
-		m.cursorPos = 0
-		m.autoScroll()
+// 入力内容はSubmitMsgとして上位のModelへ届ける
+func (m *MainView) handleEnter() tea.Cmd {
+	if m.input == "" {
+		return nil
+	}
+
+	text := m.input
+	m.outputLines = append(m.outputLines, "> "+text)
+	m.input = ""
+	m.cursorPos = 0
+	m.autoScroll()
+
+	return func() tea.Msg {
+		return SubmitMsg{Text: text}
 	}
 }
 
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Dialog はオーバーレイとして表示されるモーダルダイアログ
+type Dialog interface {
+	// HandleKey はキー入力を処理する
+	// closeがtrueの場合、ダイアログはスタックから取り除かれる
+	HandleKey(msg tea.KeyMsg) (cmd tea.Cmd, closed bool)
+	// Cancel はEscで閉じられたときに要求元へ届けるメッセージを返す
+	Cancel() tea.Msg
+	// View は指定された最大幅でダイアログを描画する
+	View(maxWidth int) string
+}
+
+// OpenDialogMsg はダイアログを開くことを要求するメッセージ
+type OpenDialogMsg struct {
+	Dialog Dialog
+}
+
+// OpenDialog はダイアログを開くコマンドを返す
+func OpenDialog(d Dialog) tea.Cmd {
+	return func() tea.Msg {
+		return OpenDialogMsg{Dialog: d}
+	}
+}
+
+// OverlayStack は重ねて表示するダイアログのスタック
+type OverlayStack struct {
+	dialogs []Dialog
+}
+
+// NewOverlayStack は新しいOverlayStackを作成する
+func NewOverlayStack() *OverlayStack {
+	return &OverlayStack{}
+}
+
+// Push はダイアログを最前面に追加する
+func (o *OverlayStack) Push(d Dialog) {
+	o.dialogs = append(o.dialogs, d)
+}
+
+// Pop は最前面のダイアログを取り除く
+func (o *OverlayStack) Pop() {
+	if len(o.dialogs) > 0 {
+		o.dialogs = o.dialogs[:len(o.dialogs)-1]
+	}
+}
+
+// Top は最前面のダイアログを取得する
+func (o *OverlayStack) Top() Dialog {
+	if len(o.dialogs) == 0 {
+		return nil
+	}
+	return o.dialogs[len(o.dialogs)-1]
+}
+
+// Len は開いているダイアログの数を取得する
+func (o *OverlayStack) Len() int {
+	return len(o.dialogs)
+}
+
+// HandleKey は最前面のダイアログにキー入力を渡す
+// Escはダイアログを閉じ、キャンセル結果を要求元へ届ける
+func (o *OverlayStack) HandleKey(msg tea.KeyMsg) tea.Cmd {
+	top := o.Top()
+	if top == nil {
+		return nil
+	}
+
+	if msg.Type == tea.KeyEsc {
+		o.Pop()
+		return msgCmd(top.Cancel())
+	}
+
+	cmd, closed := top.HandleKey(msg)
+	if closed {
+		o.Pop()
+	}
+	return cmd
+}
+
+// Render は背景の上に最前面のダイアログを中央揃えで重ねて描画する
+func (o *OverlayStack) Render(background string, width, height int) string {
+	top := o.Top()
+	if top == nil {
+		return background
+	}
+
+	maxWidth := width - 4
+	if maxWidth > 72 {
+		maxWidth = 72
+	}
+	if maxWidth < 10 {
+		maxWidth = 10
+	}
+
+	fg := top.View(maxWidth)
+	x := (width - lipgloss.Width(fg)) / 2
+	y := (height - lipgloss.Height(fg)) / 2
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+
+	return placeOverlay(x, y, fg, background)
+}
+
+// placeOverlay はbgの(x, y)の位置にfgを重ねた文字列を返す
+// ANSIエスケープシーケンスを含む文字列でも表示幅を基準に合成する
+func placeOverlay(x, y int, fg, bg string) string {
+	bgLines := strings.Split(bg, "\n")
+	fgLines := strings.Split(fg, "\n")
+
+	for i, fgLine := range fgLines {
+		row := y + i
+		for row >= len(bgLines) {
+			bgLines = append(bgLines, "")
+		}
+
+		bgLine := bgLines[row]
+		if w := ansi.StringWidth(bgLine); w < x {
+			bgLine += strings.Repeat(" ", x-w)
+		}
+
+		left := ansi.Truncate(bgLine, x, "")
+		right := ansi.TruncateLeft(bgLine, x+ansi.StringWidth(fgLine), "")
+		bgLines[row] = left + "\x1b[0m" + fgLine + "\x1b[0m" + right
+	}
+
+	return strings.Join(bgLines, "\n")
+}
+
+// msgCmd はメッセージをそのまま返すコマンドを作成する
+func msgCmd(msg tea.Msg) tea.Cmd {
+	if msg == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return msg
+	}
+}
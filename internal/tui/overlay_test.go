@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlayStack_PushPop(t *testing.T) {
+	o := NewOverlayStack()
+	assert.Nil(t, o.Top())
+	assert.Equal(t, 0, o.Len())
+
+	first := NewAlertDialog("1", "first")
+	second := NewAlertDialog("2", "second")
+	o.Push(first)
+	o.Push(second)
+	assert.Equal(t, 2, o.Len())
+	assert.Same(t, second, o.Top())
+
+	o.Pop()
+	assert.Same(t, first, o.Top())
+	o.Pop()
+	o.Pop() // 空でもパニックしない
+	assert.Equal(t, 0, o.Len())
+}
+
+func TestOverlayStack_HandleKey(t *testing.T) {
+	t.Run("Escでキャンセル結果を届ける", func(t *testing.T) {
+		o := NewOverlayStack()
+		o.Push(NewConfirmDialog("delete", "削除", "削除しますか？"))
+
+		cmd := o.HandleKey(tea.KeyMsg{Type: tea.KeyEsc})
+		require.NotNil(t, cmd)
+		assert.Equal(t, ConfirmResultMsg{ID: "delete", Confirmed: false}, cmd())
+		assert.Equal(t, 0, o.Len())
+	})
+
+	t.Run("結果を返したダイアログは閉じる", func(t *testing.T) {
+		o := NewOverlayStack()
+		o.Push(NewConfirmDialog("delete", "削除", "削除しますか？"))
+
+		cmd := o.HandleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		require.NotNil(t, cmd)
+		assert.Equal(t, ConfirmResultMsg{ID: "delete", Confirmed: true}, cmd())
+		assert.Equal(t, 0, o.Len())
+	})
+
+	t.Run("メッセージダイアログのEscは結果なし", func(t *testing.T) {
+		o := NewOverlayStack()
+		o.Push(NewAlertDialog("情報", "本文"))
+
+		cmd := o.HandleKey(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Nil(t, cmd)
+		assert.Equal(t, 0, o.Len())
+	})
+
+	t.Run("空のスタック", func(t *testing.T) {
+		o := NewOverlayStack()
+		assert.Nil(t, o.HandleKey(tea.KeyMsg{Type: tea.KeyEnter}))
+	})
+}
+
+func TestPlaceOverlay(t *testing.T) {
+	bg := strings.Join([]string{
+		"aaaaaaaaaa",
+		"bbbbbbbbbb",
+		"cccccccccc",
+	}, "\n")
+
+	got := ansi.Strip(placeOverlay(2, 1, "XY\nZW", bg))
+	assert.Equal(t, strings.Join([]string{
+		"aaaaaaaaaa",
+		"bbXYbbbbbb",
+		"ccZWcccccc",
+	}, "\n"), got)
+
+	t.Run("背景より下にはみ出す場合", func(t *testing.T) {
+		got := ansi.Strip(placeOverlay(1, 2, "X\nY", "ab\ncd"))
+		assert.Equal(t, "ab\ncd\n X\n Y", got)
+	})
+
+	t.Run("全角文字を含む背景", func(t *testing.T) {
+		got := ansi.Strip(placeOverlay(2, 0, "XY", "あいう"))
+		assert.Equal(t, "あXYう", got)
+	})
+}
+
+func TestModel_Overlay(t *testing.T) {
+	m := NewModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = updated.(Model)
+
+	updated, _ = m.Update(OpenDialogMsg{Dialog: NewConfirmDialog("quit", "確認", "終了しますか？")})
+	m = updated.(Model)
+	assert.Equal(t, 1, m.overlays.Len())
+	assert.Contains(t, m.View(), "終了しますか？")
+
+	// モーダル表示中の文字入力はメインビューに渡らない
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = updated.(Model)
+	assert.Empty(t, m.mainView.input)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	assert.Equal(t, 0, m.overlays.Len())
+	require.NotNil(t, cmd)
+	assert.Equal(t, ConfirmResultMsg{ID: "quit"}, cmd())
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+// configCheckInterval は設定ファイルの変更を確認する間隔
+const configCheckInterval = time.Second
+
+// fileStamp は変更の検出に使うファイルの状態
+type fileStamp struct {
+	modTime time.Time // 更新日時
+	size    int64     // サイズ
+	exists  bool      // ファイルがあるか
+}
+
+// configTickMsg は設定ファイルの確認結果
+type configTickMsg struct {
+	stamps map[string]fileStamp // パス → ファイルの状態
+}
+
+// configReloadedMsg は設定の再読み込み結果
+type configReloadedMsg struct {
+	config *config.Config // 読み込んだ設定
+	err    error          // 読み込みや検証のエラー
+}
+
+// configReloader は設定ファイルの変更を監視して再読み込みする
+type configReloader struct {
+	paths    []string                       // 監視する設定ファイル
+	load     func() (*config.Config, error) // 起動時と同じ順序で設定を重ねて読み込む
+	stamps   map[string]fileStamp           // 最後に確認したファイルの状態
+	interval time.Duration                  // 確認の間隔
+}
+
+// WithConfigReload は設定ファイルを監視し、変更されたらloadで読み込み直して反映する
+// 反映するのはキーバインド、テーマ、差分パネルの設定、出力の最大行数
+// 誤りのある設定は反映せず、直前の正しい設定を使い続ける
+func WithConfigReload(paths []string, load func() (*config.Config, error)) Option {
+	return func(m *Model) {
+		m.reloader = &configReloader{
+			paths:    paths,
+			load:     load,
+			stamps:   statFiles(paths),
+			interval: configCheckInterval,
+		}
+		// 起動後に端末へ問い合わせないよう背景色を先に判定しておく
+		m.isDarkBackground()
+	}
+}
+
+// statFiles はファイルの状態を取得する
+func statFiles(paths []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			stamps[path] = fileStamp{}
+			continue
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size(), exists: true}
+	}
+	return stamps
+}
+
+// tick は次の確認をスケジュールするコマンドを返す
+func (r *configReloader) tick() tea.Cmd {
+	paths := r.paths
+	return tea.Tick(r.interval, func(time.Time) tea.Msg {
+		return configTickMsg{stamps: statFiles(paths)}
+	})
+}
+
+// changed は前回の確認から変更されたファイルを返す
+func (r *configReloader) changed(stamps map[string]fileStamp) []string {
+	var paths []string
+	for _, path := range r.paths {
+		if stamps[path] != r.stamps[path] {
+			paths = append(paths, path)
+		}
+	}
+	r.stamps = stamps
+	return paths
+}
+
+// handleConfigTick は設定ファイルが変更されていれば再読み込みを開始する
+func (m *Model) handleConfigTick(msg configTickMsg) tea.Cmd {
+	if m.reloader == nil {
+		return nil
+	}
+
+	changed := m.reloader.changed(msg.stamps)
+	if len(changed) == 0 {
+		return m.reloader.tick()
+	}
+
+	slog.Info("設定ファイルの変更を検出しました", "component", "tui", "paths", changed)
+	load := m.reloader.load
+	return tea.Batch(m.reloader.tick(), func() tea.Msg {
+		cfg, err := load()
+		return configReloadedMsg{config: cfg, err: err}
+	})
+}
+
+// handleConfigReloaded は再読み込みした設定を反映する
+func (m *Model) handleConfigReloaded(msg configReloadedMsg) {
+	if msg.err != nil {
+		slog.Warn("設定の再読み込みに失敗しました", "component", "tui", "error", msg.err)
+		errorStyle := themed(theme.Error)
+		m.mainView.AddOutput(errorStyle.Render("設定の再読み込みに失敗しました (直前の設定を使い続けます):"))
+		lines := []string{msg.err.Error()}
+		var errs config.ValidationErrors
+		if errors.As(msg.err, &errs) {
+			lines = lines[:0]
+			for _, e := range errs {
+				lines = append(lines, e.Error())
+			}
+		}
+		for _, line := range lines {
+			m.mainView.AddOutput(errorStyle.Render("  " + line))
+		}
+		return
+	}
+
+	m.applyConfig(msg.config)
+	WithKeybindings(msg.config.Keybindings)(m)
+	slog.Info("設定を再読み込みしました", "component", "tui")
+	m.mainView.AddOutput(themed(theme.Muted).Render("設定を再読み込みしました"))
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/mzkmnk/ccforge/internal/keymap"
+	"github.com/mzkmnk/ccforge/internal/theme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReloadModel は設定ファイルを監視するModelを作成する
+func newReloadModel(t *testing.T, content string) (Model, string) {
+	t.Helper()
+	stubBackground(t, true)
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	load := func() (*config.Config, error) { return config.Load(path) }
+
+	cfg, err := load()
+	require.NoError(t, err)
+	return NewModel(WithConfig(cfg), WithConfigReload([]string{path}, load)), path
+}
+
+// rewrite は更新日時を進めて設定ファイルを書き換える
+func rewrite(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+}
+
+// checkConfig は設定ファイルを確認して再読み込みの結果を反映する
+func checkConfig(t *testing.T, m Model) Model {
+	t.Helper()
+	updated, cmd := m.Update(configTickMsg{stamps: statFiles(m.reloader.paths)})
+	m = updated.(Model)
+	require.NotNil(t, cmd)
+
+	// 再読み込みのコマンドだけを実行する (タイマーは待たない)
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			done := make(chan tea.Msg, 1)
+			go func() { done <- c() }()
+			select {
+			case msg := <-done:
+				if reloaded, ok := msg.(configReloadedMsg); ok {
+					updated, _ = m.Update(reloaded)
+					m = updated.(Model)
+				}
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+	return m
+}
+
+func TestModel_InitWatchesConfig(t *testing.T) {
+	m, _ := newReloadModel(t, "")
+	assert.NotNil(t, m.Init())
+}
+
+func TestModel_ConfigReload(t *testing.T) {
+	m, path := newReloadModel(t, "[ui]\nmax_output_lines = 100\n")
+
+	// 変更がなければ読み込み直さない
+	m = checkConfig(t, m)
+	assert.NotContains(t, strings.Join(m.mainView.outputLines, "\n"), "設定を再読み込みしました")
+
+	rewrite(t, path, `
+[ui]
+max_output_lines = 5
+theme = "light"
+
+[diff]
+side_by_side_min_width = 60
+
+[keybindings.global]
+app.quit = "ctrl+q"
+`)
+	m = checkConfig(t, m)
+
+	assert.Contains(t, strings.Join(m.mainView.outputLines, "\n"), "設定を再読み込みしました")
+	assert.Equal(t, 5, m.mainView.GetMaxOutputLines())
+	assert.Equal(t, 60, m.diffView.sideBySideMinWidth)
+	assert.Equal(t, theme.LightName, activeTheme.Name)
+	action, ok := m.keys().Lookup(keymap.Input, "ctrl+q")
+	assert.True(t, ok)
+	assert.Equal(t, "app.quit", action)
+}
+
+func TestModel_ConfigReloadKeepsLastGoodConfig(t *testing.T) {
+	m, path := newReloadModel(t, "[ui]\nmax_output_lines = 100\n")
+
+	rewrite(t, path, "[ui]\nmax_output_lines = \"many\"\n")
+	m = checkConfig(t, m)
+
+	output := strings.Join(m.mainView.outputLines, "\n")
+	assert.Contains(t, output, "設定の再読み込みに失敗しました (直前の設定を使い続けます)")
+	assert.Contains(t, output, path+":2: ui.max_output_lines:")
+	assert.Equal(t, 100, m.mainView.GetMaxOutputLines())
+
+	// 誤りを直せば反映される
+	rewrite(t, path, "[ui]\nmax_output_lines = 50\n")
+	m = checkConfig(t, m)
+	assert.Equal(t, 50, m.mainView.GetMaxOutputLines())
+}
+
+func TestModel_ConfigReloadDetectsNewFile(t *testing.T) {
+	m, path := newReloadModel(t, "")
+	require.NoError(t, os.Remove(path))
+	m = checkConfig(t, m)
+
+	require.NoError(t, os.WriteFile(path, []byte("[ui]\nmax_output_lines = 7\n"), 0o644))
+	m = checkConfig(t, m)
+	assert.Equal(t, 7, m.mainView.GetMaxOutputLines())
+}
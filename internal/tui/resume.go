@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+// snapshotInterval は画面の状態を定期的に保存する間隔
+const snapshotInterval = 30 * time.Second
+
+// snapshotTickMsg は定期保存のタイマーメッセージ
+type snapshotTickMsg struct{}
+
+// snapshotSavedMsg は画面の状態の保存結果
+type snapshotSavedMsg struct {
+	err error
+}
+
+// snapshotter はタスクのセッションの画面の状態を保存する
+type snapshotter struct {
+	taskID    string                     // タスクID
+	sessionID string                     // Claude CodeのセッションID
+	save      func(store.Snapshot) error // 保存先
+	interval  time.Duration              // 定期保存の間隔
+	now       func() time.Time           // 現在日時 (テストで差し替える)
+	lastErr   error                      // 直前の保存エラー (同じエラーを繰り返し表示しない)
+}
+
+// WithSnapshots はタスクの画面の状態を終了時と定期的にsaveで保存する
+// sessionIDは再開時にClaude Codeのセッションへ再接続するために一緒に保存する
+func WithSnapshots(taskID, sessionID string, save func(store.Snapshot) error) Option {
+	return func(m *Model) {
+		m.snapshots = &snapshotter{
+			taskID:    taskID,
+			sessionID: sessionID,
+			save:      save,
+			interval:  snapshotInterval,
+			now:       time.Now,
+		}
+	}
+}
+
+// WithResume は保存した画面の状態を復元する
+// 出力、送信前の入力、カーソル位置、スクロール位置を元に戻す
+func WithResume(snap store.Snapshot) Option {
+	return func(m *Model) {
+		v := m.mainView
+		v.outputLines = append([]string(nil), snap.Output...)
+		if v.maxOutputLines > 0 && len(v.outputLines) > v.maxOutputLines {
+			v.outputLines = v.outputLines[len(v.outputLines)-v.maxOutputLines:]
+		}
+		v.input = snap.Input
+		v.cursorPos = min(max(snap.CursorPos, 0), len([]rune(snap.Input)))
+		v.scrollOffset = min(max(snap.ScrollOffset, 0), max(len(v.outputLines)-1, 0))
+
+		muted := themed(theme.Muted)
+		v.outputLines = append(v.outputLines, muted.Render(fmt.Sprintf("--- %s に保存した画面を復元しました ---", snap.SavedAt.Local().Format("2006-01-02 15:04"))))
+		if snap.SessionID != "" {
+			// Claude Code CLIの --resume で同じ会話を続ける
+			if m.snapshots != nil && m.snapshots.sessionID == "" {
+				m.snapshots.sessionID = snap.SessionID
+			}
+			v.outputLines = append(v.outputLines, muted.Render(fmt.Sprintf("Claude Codeのセッション %s を再開します", snap.SessionID)))
+		}
+		slog.Info("画面を復元しました", "component", "tui", "task", snap.TaskID, "lines", len(snap.Output), "session_id", snap.SessionID)
+	}
+}
+
+// snapshot は現在の画面の状態を返す
+func (m *Model) snapshot() store.Snapshot {
+	v := m.mainView
+	return store.Snapshot{
+		TaskID:       m.snapshots.taskID,
+		SessionID:    m.snapshots.sessionID,
+		Output:       append([]string(nil), v.outputLines...),
+		Input:        v.input,
+		CursorPos:    v.cursorPos,
+		ScrollOffset: v.scrollOffset,
+		SavedAt:      m.snapshots.now().UTC(),
+	}
+}
+
+// saveSnapshot は現在の画面の状態を保存するコマンドを返す
+// 状態はコマンドの作成時に複製するため、保存中に画面が変わっても影響しない
+func (m *Model) saveSnapshot() tea.Cmd {
+	if m.snapshots == nil {
+		return nil
+	}
+	state := m.snapshot()
+	save := m.snapshots.save
+	return func() tea.Msg {
+		err := save(state)
+		if err != nil {
+			slog.Warn("画面の状態を保存できませんでした", "component", "tui", "task", state.TaskID, "error", err)
+		}
+		return snapshotSavedMsg{err: err}
+	}
+}
+
+// tick は次の定期保存をスケジュールするコマンドを返す
+func (s *snapshotter) tick() tea.Cmd {
+	return tea.Tick(s.interval, func(time.Time) tea.Msg {
+		return snapshotTickMsg{}
+	})
+}
+
+// handleSnapshotSaved は保存に失敗した場合に一度だけ警告を表示する
+func (m *Model) handleSnapshotSaved(msg snapshotSavedMsg) {
+	if m.snapshots == nil {
+		return
+	}
+	if msg.err != nil && (m.snapshots.lastErr == nil || m.snapshots.lastErr.Error() != msg.err.Error()) {
+		m.mainView.AddOutput(themed(theme.Warning).Render(fmt.Sprintf("警告: 画面の状態を保存できませんでした: %v", msg.err)))
+	}
+	m.snapshots.lastErr = msg.err
+}
+
+// quit は画面の状態を保存してから終了するコマンドを返す
+func (m *Model) quit() tea.Cmd {
+	if m.snapshots == nil {
+		return tea.Quit
+	}
+	return tea.Sequence(m.saveSnapshot(), tea.Quit)
+}
@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSnapshotModel は画面の状態を保存するModelを作成する
+// 保存された状態は戻り値のスライスに記録する
+func newSnapshotModel(t *testing.T, opts ...Option) (Model, *[]store.Snapshot) {
+	t.Helper()
+	var saved []store.Snapshot
+	save := func(snap store.Snapshot) error {
+		saved = append(saved, snap)
+		return nil
+	}
+	m := NewModel(append([]Option{WithSnapshots("t1", "s1", save)}, opts...)...)
+	m.snapshots.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 0, 0, time.UTC) }
+	return m, &saved
+}
+
+func TestWithResume(t *testing.T) {
+	stubBackground(t, true)
+
+	tests := []struct {
+		name       string
+		snap       store.Snapshot
+		wantCursor int
+		wantScroll int
+		wantBanner []string
+	}{
+		{
+			name: "出力と入力を復元する",
+			snap: store.Snapshot{
+				TaskID:       "t1",
+				Output:       []string{"1行目", "2行目"},
+				Input:        "続きを",
+				CursorPos:    2,
+				ScrollOffset: 1,
+				SavedAt:      time.Now(),
+			},
+			wantCursor: 2,
+			wantScroll: 1,
+			wantBanner: []string{"に保存した画面を復元しました"},
+		},
+		{
+			name: "範囲外の位置は丸める",
+			snap: store.Snapshot{
+				TaskID:       "t1",
+				Output:       []string{"1行目"},
+				Input:        "abc",
+				CursorPos:    10,
+				ScrollOffset: 5,
+			},
+			wantCursor: 3,
+			wantScroll: 0,
+			wantBanner: []string{"に保存した画面を復元しました"},
+		},
+		{
+			name: "セッションIDがあれば再開を表示する",
+			snap: store.Snapshot{
+				TaskID:    "t1",
+				SessionID: "abc-123",
+				Output:    []string{"1行目"},
+			},
+			wantBanner: []string{"に保存した画面を復元しました", "Claude Codeのセッション abc-123 を再開します"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(WithResume(tt.snap))
+			v := m.mainView
+
+			// ウェルカムメッセージは復元した出力に置き換わる
+			require.GreaterOrEqual(t, len(v.outputLines), len(tt.snap.Output))
+			assert.Equal(t, tt.snap.Output, v.outputLines[:len(tt.snap.Output)])
+			assert.Equal(t, tt.snap.Input, v.input)
+			assert.Equal(t, tt.wantCursor, v.cursorPos)
+			assert.Equal(t, tt.wantScroll, v.scrollOffset)
+
+			banner := strings.Join(v.outputLines[len(tt.snap.Output):], "\n")
+			for _, want := range tt.wantBanner {
+				assert.Contains(t, banner, want)
+			}
+		})
+	}
+}
+
+func TestWithResume_TrimsToMaxOutputLines(t *testing.T) {
+	m := NewModel(func(m *Model) { m.mainView.maxOutputLines = 2 }, WithResume(store.Snapshot{Output: []string{"a", "b", "c"}}))
+	assert.Equal(t, []string{"b", "c"}, m.mainView.outputLines[:2])
+}
+
+func TestWithResume_KeepsSessionForSnapshots(t *testing.T) {
+	m := NewModel(
+		WithSnapshots("t1", "", func(store.Snapshot) error { return nil }),
+		WithResume(store.Snapshot{TaskID: "t1", SessionID: "s9"}),
+	)
+	assert.Equal(t, "s9", m.snapshots.sessionID)
+}
+
+func TestModel_SnapshotTick(t *testing.T) {
+	m, saved := newSnapshotModel(t)
+	assert.NotNil(t, m.Init())
+	m.mainView.input = "書きかけ"
+	m.mainView.cursorPos = 2
+
+	updated, cmd := m.Update(snapshotTickMsg{})
+	m = updated.(Model)
+	require.NotNil(t, cmd)
+
+	// 保存のコマンドだけを実行する (次のタイマーは待たない)
+	batch, ok := cmd().(tea.BatchMsg)
+	require.True(t, ok)
+	for _, c := range batch {
+		done := make(chan tea.Msg, 1)
+		go func() { done <- c() }()
+		select {
+		case msg := <-done:
+			if msg, ok := msg.(snapshotSavedMsg); ok {
+				assert.NoError(t, msg.err)
+			}
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	require.Len(t, *saved, 1)
+	got := (*saved)[0]
+	assert.Equal(t, "t1", got.TaskID)
+	assert.Equal(t, "s1", got.SessionID)
+	assert.Equal(t, "書きかけ", got.Input)
+	assert.Equal(t, 2, got.CursorPos)
+	assert.Equal(t, m.mainView.outputLines, got.Output)
+	assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 0, 0, time.UTC), got.SavedAt)
+}
+
+func TestModel_QuitSavesSnapshot(t *testing.T) {
+	t.Run("保存してから終了する", func(t *testing.T) {
+		m, saved := newSnapshotModel(t)
+		cmd := m.quit()
+		require.NotNil(t, cmd)
+
+		// tea.Sequenceのメッセージは非公開の型のため、リフレクションで順に実行する
+		seq := reflect.ValueOf(cmd())
+		require.Equal(t, reflect.Slice, seq.Kind())
+		var msgs []tea.Msg
+		for i := range seq.Len() {
+			msgs = append(msgs, seq.Index(i).Interface().(tea.Cmd)())
+		}
+		require.Len(t, msgs, 2)
+		assert.IsType(t, snapshotSavedMsg{}, msgs[0])
+		assert.IsType(t, tea.QuitMsg{}, msgs[1])
+		assert.NotEmpty(t, *saved)
+	})
+
+	t.Run("保存しない場合はそのまま終了する", func(t *testing.T) {
+		m := NewModel()
+		assert.IsType(t, tea.QuitMsg{}, m.quit()())
+	})
+}
+
+func TestModel_SnapshotSaveError(t *testing.T) {
+	m, _ := newSnapshotModel(t)
+	errFull := errors.New("disk full")
+
+	count := func() int {
+		return strings.Count(strings.Join(m.mainView.outputLines, "\n"), "画面の状態を保存できませんでした")
+	}
+
+	// 同じエラーは一度だけ表示する
+	for range 3 {
+		updated, _ := m.Update(snapshotSavedMsg{err: errFull})
+		m = updated.(Model)
+	}
+	assert.Equal(t, 1, count())
+
+	// 成功した後に再び失敗すれば表示する
+	updated, _ := m.Update(snapshotSavedMsg{})
+	m = updated.(Model)
+	updated, _ = m.Update(snapshotSavedMsg{err: errFull})
+	m = updated.(Model)
+	assert.Equal(t, 2, count())
+}
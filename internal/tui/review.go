@@ -0,0 +1,557 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+const (
+	// reviewCheckpointMessage はレビュー前に保存するチェックポイントのメッセージ
+	reviewCheckpointMessage = "ccforge: レビュー前の状態"
+	// reviewRejectDialogID はチェックポイントがない場合の却下の確認ダイアログの識別子
+	reviewRejectDialogID = "review.reject"
+)
+
+// reviewDecision はハンクに対する判断
+type reviewDecision int
+
+const (
+	// reviewAccept は変更を採用してステージする
+	reviewAccept reviewDecision = iota
+	// reviewReject は変更を却下して作業ツリーから取り消す
+	reviewReject
+)
+
+// reviewStartMsg はレビューする変更の取得結果
+type reviewStartMsg struct {
+	files      []git.FileChange
+	checkpoint int // レビュー前に保存したチェックポイント (0は保存なし)
+	err        error
+}
+
+// reviewDiffMsg はレビュー中のファイルの差分の取得結果
+type reviewDiffMsg struct {
+	file int // ファイルの位置
+	hunk int // 選択するハンク (負の場合は末尾のハンク)
+	diff *git.FileDiff
+	err  error
+}
+
+// reviewAppliedMsg はハンクへの判断の反映結果
+type reviewAppliedMsg struct {
+	decision reviewDecision
+	err      error
+}
+
+// reviewSummaryMsg はレビュー終了時に残っている変更の集計結果
+type reviewSummaryMsg struct {
+	remaining int
+	err       error
+}
+
+// reviewBackup はレビュー前の作業ツリーをチェックポイントとして保存する設定
+type reviewBackup struct {
+	task     string // チェックポイントを保存するタスク
+	specsDir string // スナップショットから除外するccforgeのディレクトリ
+	keep     int    // タスクごとに残すチェックポイントの数
+}
+
+// ReviewView は作業ツリーの変更をハンクごとに採用・却下・スキップするパネル
+// 採用したハンクはステージし、却下したハンクは作業ツリーから取り消す
+type ReviewView struct {
+	width         int              // パネルの幅
+	height        int              // パネルの高さ
+	repo          *git.Repo        // 対象リポジトリ
+	task          string           // チェックポイントを保存したタスク
+	files         []git.FileChange // レビューする変更 (未ステージと未追跡)
+	file          int              // レビュー中のファイル
+	diff          *git.FileDiff    // レビュー中のファイルの差分
+	hunk          int              // レビュー中のハンク
+	offset        int              // ハンクのスクロール位置
+	accepted      int              // 採用したハンクの数
+	rejected      int              // 却下したハンクの数
+	remaining     int              // 終了時に残っている未処理のハンクの数
+	checkpoint    int              // レビュー前に保存したチェックポイント (0は保存なし)
+	busy          bool             // 取得・反映中か (判断を受け付けない)
+	finishPending bool             // 取得・反映の完了後にレビューを終了するか
+	finished      bool             // レビューを終了したか
+	visible       bool             // 表示中フラグ
+	tabWidth      int              // タブを展開する幅
+	err           error            // 直近のエラー
+}
+
+// NewReviewView は新しいReviewViewを作成する
+func NewReviewView() *ReviewView {
+	return &ReviewView{width: 80, height: 23, tabWidth: defaultTabWidth}
+}
+
+// Start はdirを含むリポジトリの作業ツリーの変更のレビューを開始する
+// backupがある場合は却下を取り消せるようにレビュー前の状態をチェックポイントに保存する
+func (v *ReviewView) Start(dir string, backup *reviewBackup) tea.Cmd {
+	*v = ReviewView{width: v.width, height: v.height, tabWidth: v.tabWidth, visible: true, busy: true}
+
+	repo, err := git.Open(dir)
+	if err != nil {
+		v.busy = false
+		v.err = err
+		return nil
+	}
+	v.repo = repo
+	if backup != nil {
+		v.task = backup.task
+	}
+
+	return func() tea.Msg {
+		changes, err := repo.Changes()
+		if err != nil {
+			return reviewStartMsg{err: err}
+		}
+		var files []git.FileChange
+		for _, c := range changes {
+			if c.Area != git.Staged {
+				files = append(files, c)
+			}
+		}
+		if len(files) == 0 || backup == nil {
+			return reviewStartMsg{files: files}
+		}
+
+		id, err := saveReviewCheckpoint(repo, backup)
+		if err != nil {
+			return reviewStartMsg{err: fmt.Errorf("レビュー前の状態の保存に失敗しました: %w", err)}
+		}
+		return reviewStartMsg{files: files, checkpoint: id}
+	}
+}
+
+// startReview は作業ツリーの変更のレビューを開始する
+// アクティブなタスクがありチェックポイントが有効な場合は、レビュー前の状態を保存する
+func (m *Model) startReview() tea.Cmd {
+	var backup *reviewBackup
+	if task := m.statusBar.activeTask; task != "" && m.checkpoint.Enabled {
+		backup = &reviewBackup{task: task, specsDir: filepath.Join(m.workDir, tasks.DirName), keep: m.checkpoint.Keep}
+	}
+	m.hidePanels()
+	return m.reviewView.Start(m.repoDir(), backup)
+}
+
+// rejectReviewHunk はレビュー中のハンクを却下するコマンドを返す
+// チェックポイントを保存していない場合は取り消した変更を元に戻せないため、先に確認する
+func (m *Model) rejectReviewHunk() tea.Cmd {
+	v := m.reviewView
+	if !v.ready() {
+		return nil
+	}
+	if v.checkpoint == 0 {
+		return OpenDialog(NewConfirmDialog(reviewRejectDialogID, "変更の却下",
+			"チェックポイントを保存していないため、取り消した変更は元に戻せません。作業ツリーから取り消しますか?"))
+	}
+	return v.Reject()
+}
+
+// saveReviewCheckpoint はレビュー前の作業ツリーをチェックポイントに保存し、番号を返す
+func saveReviewCheckpoint(repo *git.Repo, backup *reviewBackup) (int, error) {
+	var exclude []string
+	if rel, ok := repo.RelPath(backup.specsDir); ok {
+		exclude = []string{rel}
+	}
+	cp, err := repo.CreateCheckpoint(backup.task, reviewCheckpointMessage, exclude)
+	if err != nil {
+		return 0, err
+	}
+	if backup.keep > 0 {
+		if _, err := repo.DeleteCheckpoints(backup.task, backup.keep); err != nil {
+			slog.Warn("古いチェックポイントを削除できませんでした", "component", "tui", "task", backup.task, "err", err)
+		}
+	}
+	return cp.ID, nil
+}
+
+// Hide はパネルを非表示にする
+func (v *ReviewView) Hide() {
+	v.visible = false
+}
+
+// IsVisible はパネルが表示中かを取得する
+func (v *ReviewView) IsVisible() bool {
+	return v.visible
+}
+
+// IsFinished はレビューを終了したかを取得する
+func (v *ReviewView) IsFinished() bool {
+	return v.finished
+}
+
+// SetSize はパネルのサイズを設定する
+func (v *ReviewView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// SetTabWidth は差分のタブを展開する幅を設定する
+func (v *ReviewView) SetTabWidth(width int) {
+	v.tabWidth = width
+}
+
+// Init はBubble Teaの初期化処理（tea.Modelインターフェースの実装）
+func (v *ReviewView) Init() tea.Cmd {
+	return nil
+}
+
+// Update はメッセージを処理して状態を更新する
+func (v *ReviewView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return v, v.handleKeyMsg(msg)
+
+	case reviewStartMsg:
+		v.busy = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.files = msg.files
+		v.checkpoint = msg.checkpoint
+		if len(v.files) == 0 || v.finishPending {
+			return v, v.Finish()
+		}
+		return v, v.load(0, 0)
+
+	case reviewDiffMsg:
+		if v.finished {
+			return v, nil
+		}
+		v.busy = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, v.finishIfPending()
+		}
+		if v.finishPending {
+			return v, v.Finish()
+		}
+		return v, v.applyDiff(msg)
+
+	case reviewAppliedMsg:
+		if v.finished {
+			return v, nil
+		}
+		v.busy = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, v.finishIfPending()
+		}
+		v.err = nil
+		if msg.decision == reviewAccept {
+			v.accepted++
+		} else {
+			v.rejected++
+		}
+		// 未追跡のファイルは反映後にインデックスとの差分で残りを確認する
+		if v.files[v.file].Area == git.Untracked {
+			v.files[v.file].Area = git.Unstaged
+		}
+		// 反映を待っていた終了の要求は、反映した結果で残りを集計する
+		if v.finishPending {
+			return v, v.Finish()
+		}
+		// 反映したハンクは差分から消えるため、同じ位置に次のハンクが来る
+		return v, v.load(v.file, v.hunk)
+
+	case reviewSummaryMsg:
+		v.busy = false
+		if msg.err != nil {
+			v.err = msg.err
+		}
+		v.remaining = msg.remaining
+		return v, msgCmd(OutputMsg{Lines: v.summaryLines()})
+	}
+
+	return v, nil
+}
+
+// handleKeyMsg はキーボード入力を処理する
+func (v *ReviewView) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	if v.finished {
+		return nil
+	}
+	switch msg.String() {
+	case "pgup":
+		v.scroll(-(v.height - 3))
+		return nil
+	case "pgdown", " ":
+		v.scroll(v.height - 3)
+		return nil
+	}
+	return nil
+}
+
+// ready は選択中のハンクへの判断や移動を受け付けるかを返す
+func (v *ReviewView) ready() bool {
+	return !v.finished && !v.busy && v.diff != nil
+}
+
+// Accept は選択中のハンクを採用してステージするコマンドを返す
+func (v *ReviewView) Accept() tea.Cmd {
+	if !v.ready() {
+		return nil
+	}
+	return v.decide(reviewAccept)
+}
+
+// Reject は選択中のハンクを却下して作業ツリーから取り消すコマンドを返す
+func (v *ReviewView) Reject() tea.Cmd {
+	if !v.ready() {
+		return nil
+	}
+	return v.decide(reviewReject)
+}
+
+// Skip は選択中のハンクをスキップして次のハンクへ進む
+func (v *ReviewView) Skip() tea.Cmd {
+	if !v.ready() {
+		return nil
+	}
+	return v.next()
+}
+
+// Prev はスキップした前のハンクへ戻る
+func (v *ReviewView) Prev() tea.Cmd {
+	if !v.ready() {
+		return nil
+	}
+	return v.prev()
+}
+
+// load はfile番目のファイルの差分を取得してhunk番目のハンクを選択するコマンドを返す
+func (v *ReviewView) load(file, hunk int) tea.Cmd {
+	repo, change := v.repo, v.files[file]
+	v.busy = true
+	return func() tea.Msg {
+		diff, err := repo.Diff(change)
+		return reviewDiffMsg{file: file, hunk: hunk, diff: diff, err: err}
+	}
+}
+
+// applyDiff は取得した差分のハンクを選択する
+// 選択するハンクがない場合 (すべて反映済みの場合など) は前後のファイルへ進む
+func (v *ReviewView) applyDiff(msg reviewDiffMsg) tea.Cmd {
+	count := reviewItems(msg.diff)
+	hunk := msg.hunk
+	if hunk < 0 {
+		hunk = count - 1
+	}
+
+	switch {
+	case hunk >= 0 && hunk < count:
+		v.file, v.diff, v.hunk, v.offset = msg.file, msg.diff, hunk, 0
+		return nil
+	case msg.hunk < 0 && msg.file > 0:
+		return v.load(msg.file-1, -1)
+	case msg.hunk < 0:
+		return v.load(0, 0)
+	case msg.file+1 < len(v.files):
+		return v.load(msg.file+1, 0)
+	default:
+		return v.Finish()
+	}
+}
+
+// reviewItems は差分のうち判断の対象になる数を返す
+// バイナリファイルなどハンクのない変更はファイル全体を1つとして扱う
+func reviewItems(diff *git.FileDiff) int {
+	if diff == nil {
+		return 0
+	}
+	if len(diff.Hunks) == 0 && len(diff.Header) > 0 {
+		return 1
+	}
+	return len(diff.Hunks)
+}
+
+// decide は選択中のハンクへの判断を反映するコマンドを返す
+func (v *ReviewView) decide(decision reviewDecision) tea.Cmd {
+	repo, change, diff, hunk := v.repo, v.files[v.file], v.diff, v.hunk
+	whole := len(diff.Hunks) == 0
+	v.busy = true
+	slog.Debug("ハンクへの判断を反映します", "component", "tui", "path", change.Path, "hunk", hunk, "decision", decision)
+
+	return func() tea.Msg {
+		var err error
+		switch {
+		case decision == reviewAccept && whole:
+			err = repo.Stage(change.Path)
+		case decision == reviewAccept:
+			err = repo.StageHunk(diff, hunk)
+		case whole:
+			err = repo.Discard(change)
+		default:
+			err = repo.DiscardHunk(diff, hunk)
+		}
+		return reviewAppliedMsg{decision: decision, err: err}
+	}
+}
+
+// next は選択中のハンクをスキップして次のハンクへ進む
+func (v *ReviewView) next() tea.Cmd {
+	v.err = nil
+	if v.hunk+1 < reviewItems(v.diff) {
+		v.hunk++
+		v.offset = 0
+		return nil
+	}
+	if v.file+1 < len(v.files) {
+		return v.load(v.file+1, 0)
+	}
+	return v.Finish()
+}
+
+// prev はスキップした前のハンクへ戻る
+func (v *ReviewView) prev() tea.Cmd {
+	v.err = nil
+	if v.hunk > 0 {
+		v.hunk--
+		v.offset = 0
+		return nil
+	}
+	if v.file > 0 {
+		return v.load(v.file-1, -1)
+	}
+	return nil
+}
+
+// Finish はレビューを終了し、残っている変更を集計するコマンドを返す
+// 取得・反映中の場合は完了してから終了する
+func (v *ReviewView) Finish() tea.Cmd {
+	if v.finished {
+		return nil
+	}
+	if v.busy {
+		v.finishPending = true
+		return nil
+	}
+	v.finishPending = false
+	v.finished = true
+	v.busy = true
+	repo, files := v.repo, v.files
+	if repo == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		remaining := 0
+		for _, change := range files {
+			diff, err := repo.Diff(change)
+			if err != nil {
+				return reviewSummaryMsg{remaining: remaining, err: err}
+			}
+			remaining += reviewItems(diff)
+		}
+		return reviewSummaryMsg{remaining: remaining}
+	}
+}
+
+// finishIfPending は取得・反映中に終了が要求されていればレビューを終了する
+func (v *ReviewView) finishIfPending() tea.Cmd {
+	if !v.finishPending {
+		return nil
+	}
+	return v.Finish()
+}
+
+// summaryLines はレビューの結果の要約を返す
+func (v *ReviewView) summaryLines() []string {
+	if len(v.files) == 0 {
+		return []string{"レビューする変更はありません"}
+	}
+	lines := []string{fmt.Sprintf("レビューを終了しました: 採用 %d / 却下 %d / 未処理 %d", v.accepted, v.rejected, v.remaining)}
+	if v.accepted > 0 {
+		lines = append(lines, "  採用した変更はステージしました (差分パネルでコミットできます)")
+	}
+	if v.rejected > 0 && v.checkpoint > 0 {
+		lines = append(lines, fmt.Sprintf("  却下した変更は 'ccforge checkpoint restore %s %d' で元に戻せます", v.task, v.checkpoint))
+	}
+	return lines
+}
+
+// scroll はハンクの表示位置を移動する
+func (v *ReviewView) scroll(delta int) {
+	v.offset += delta
+	if maxOffset := len(v.hunkLines()) - (v.height - 3); v.offset > maxOffset {
+		v.offset = maxOffset
+	}
+	if v.offset < 0 {
+		v.offset = 0
+	}
+}
+
+// hunkLines は選択中のハンクを描画済みの行に変換する
+func (v *ReviewView) hunkLines() []string {
+	if v.diff == nil {
+		return nil
+	}
+	if v.hunk >= len(v.diff.Hunks) {
+		return []string{themed(theme.Muted).Render("ファイル全体の変更です (バイナリファイルなど)")}
+	}
+	return renderUnified(&git.FileDiff{Hunks: []git.Hunk{v.diff.Hunks[v.hunk]}}, v.width, v.tabWidth)
+}
+
+// View は現在の状態を文字列として描画する
+func (v *ReviewView) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	mutedStyle := themed(theme.Muted)
+	errorStyle := themed(theme.Error)
+
+	lines := []string{titleStyle.Render(ansi.Truncate(v.title(), v.width, "…"))}
+	if v.err != nil {
+		lines = append(lines, errorStyle.Render(ansi.Truncate(fmt.Sprintf("エラー: %v", v.err), v.width, "…")))
+	}
+
+	switch {
+	case v.finished && !v.busy:
+		lines = append(lines, "")
+		lines = append(lines, v.summaryLines()...)
+	case v.diff == nil:
+		lines = append(lines, mutedStyle.Render("読み込み中..."))
+	default:
+		change := v.files[v.file]
+		lines = append(lines, ansi.Truncate(fmt.Sprintf("%s [%s]", change.Path, change.Area), v.width, "…"))
+		body := v.hunkLines()
+		end := min(v.offset+v.height-len(lines)-1, len(body))
+		if v.offset < end {
+			lines = append(lines, body[v.offset:end]...)
+		}
+	}
+
+	for len(lines) < v.height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, mutedStyle.Render(ansi.Truncate(v.help(), v.width, "…")))
+	return lipgloss.NewStyle().Width(v.width).Height(v.height).Render(strings.Join(lines, "\n"))
+}
+
+// title はレビューの進み具合を返す
+func (v *ReviewView) title() string {
+	if len(v.files) == 0 || v.diff == nil {
+		return "変更のレビュー"
+	}
+	return fmt.Sprintf("変更のレビュー  ファイル %d/%d  ハンク %d/%d  採用 %d  却下 %d",
+		v.file+1, len(v.files), v.hunk+1, reviewItems(v.diff), v.accepted, v.rejected)
+}
+
+// help はパネル内の操作の案内を返す
+// 採用・却下などのキーはキーマップに従ってヘルプバーに表示する
+func (v *ReviewView) help() string {
+	if v.finished {
+		return ""
+	}
+	return "PgUp/PgDn: スクロール"
+}
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/keymap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 2つのハンクに分かれる変更前後の内容
+const (
+	reviewBefore = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	reviewAfter  = "1\nfirst\n3\n4\n5\n6\n7\n8\n9\n10\nsecond\n12\n"
+)
+
+// newReviewProject はレビューする変更のあるリポジトリを作成する
+// a.txt (1ハンク)、n.txt (2ハンク)、未追跡の new.txt を変更する
+func newReviewProject(t *testing.T) string {
+	t.Helper()
+	root := newCommitProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "info", "exclude"), []byte("ccforge/\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "n.txt"), []byte(reviewBefore), 0o644))
+	runGit(t, root, "add", "n.txt")
+	runGit(t, root, "commit", "-q", "-m", "add n.txt")
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("changed\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "n.txt"), []byte(reviewAfter), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "new.txt"), []byte("new\n"), 0o644))
+	return root
+}
+
+// updateAll はメッセージを処理し、返されたコマンドの結果がなくなるまで処理を続ける
+func updateAll(t *testing.T, m Model, msg tea.Msg) Model {
+	t.Helper()
+	for msg != nil {
+		m, msg = update(t, m, msg)
+	}
+	return m
+}
+
+// reviewPosition はレビュー中のファイルとハンクを返す
+func reviewPosition(m Model) (string, int) {
+	v := m.reviewView
+	return v.files[v.file].Path, v.hunk
+}
+
+func TestModel_Review(t *testing.T) {
+	root := newReviewProject(t)
+	m := NewModel(WithWorkDir(root), WithActiveTask("auth"))
+	m.reviewView.SetSize(100, 30)
+
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	require.True(t, m.reviewView.IsVisible())
+	require.Len(t, m.reviewView.files, 3)
+	path, hunk := reviewPosition(m)
+	assert.Equal(t, "a.txt", path)
+	assert.Equal(t, 0, hunk)
+	view := ansi.Strip(m.reviewView.View())
+	assert.Contains(t, view, "ファイル 1/3  ハンク 1/1")
+	assert.Contains(t, view, "+changed")
+	assert.Contains(t, m.helpText(), "y: 採用")
+	assert.Contains(t, m.helpText(), "n: 却下")
+
+	// 採用するとステージして次のファイルへ進む
+	m = updateAll(t, m, runes("y"))
+	path, hunk = reviewPosition(m)
+	assert.Equal(t, "n.txt", path)
+	assert.Equal(t, 0, hunk)
+
+	// 却下すると作業ツリーから取り消し、残りのハンクが同じ位置に来る
+	m = updateAll(t, m, runes("n"))
+	assert.Contains(t, ansi.Strip(m.reviewView.View()), "+second")
+
+	// スキップした後に戻れる
+	m = updateAll(t, m, runes("s"))
+	path, _ = reviewPosition(m)
+	assert.Equal(t, "new.txt", path)
+	m = updateAll(t, m, runes("k"))
+	path, hunk = reviewPosition(m)
+	assert.Equal(t, "n.txt", path)
+	assert.Equal(t, 0, hunk)
+	m = updateAll(t, m, runes("j"))
+
+	// 未追跡のファイルを採用すると最後のハンクなのでレビューを終了する
+	m = updateAll(t, m, runes("y"))
+	assert.True(t, m.reviewView.IsFinished())
+	assert.Equal(t, "M  a.txt\n M n.txt\nA  new.txt\n", runGit(t, root, "status", "--porcelain"))
+	content, err := os.ReadFile(filepath.Join(root, "n.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\nsecond\n12\n", string(content))
+
+	// 結果の要約をパネルとメインビューに表示する
+	assert.Contains(t, ansi.Strip(m.reviewView.View()), "採用 2 / 却下 1 / 未処理 1")
+	assert.Contains(t, outputText(m), "レビューを終了しました: 採用 2 / 却下 1 / 未処理 1")
+	assert.Contains(t, outputText(m), "'ccforge checkpoint restore auth 1' で元に戻せます")
+
+	// レビュー前の状態をチェックポイントに保存している
+	repo, err := git.Open(root)
+	require.NoError(t, err)
+	cp, err := repo.Checkpoint("auth", 1)
+	require.NoError(t, err)
+	assert.Equal(t, reviewCheckpointMessage, cp.Message)
+	assert.Equal(t, reviewAfter, runGit(t, root, "show", cp.Commit+":n.txt"))
+
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.reviewView.IsVisible())
+}
+
+func TestModel_ReviewQuitEarly(t *testing.T) {
+	root := newReviewProject(t)
+	m := NewModel(WithWorkDir(root))
+
+	m = updateAll(t, m, runActionCmd("review.start")())
+	require.True(t, m.reviewView.IsVisible())
+
+	// Escでレビューを終了して要約を表示し、もう一度Escで閉じる
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.True(t, m.reviewView.IsVisible())
+	assert.Contains(t, outputText(m), "採用 0 / 却下 0 / 未処理 4")
+	// アクティブなタスクがない場合はチェックポイントを保存しない
+	assert.NotContains(t, outputText(m), "checkpoint restore")
+
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.reviewView.IsVisible())
+	assert.Equal(t, " M a.txt\n M n.txt\n?? new.txt\n", runGit(t, root, "status", "--porcelain"))
+}
+
+func TestModel_ReviewRejectWithoutCheckpoint(t *testing.T) {
+	root := newReviewProject(t)
+	m := NewModel(WithWorkDir(root))
+
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	require.True(t, m.reviewView.IsVisible())
+
+	// アクティブなタスクがなくチェックポイントがない場合は却下する前に確認する
+	m = updateAll(t, m, runes("n"))
+	_, ok := m.overlays.Top().(*ConfirmDialog)
+	require.True(t, ok)
+	m = updateAll(t, m, runes("n"))
+	assert.Zero(t, m.overlays.Len())
+	assert.Equal(t, " M a.txt\n M n.txt\n?? new.txt\n", runGit(t, root, "status", "--porcelain"))
+	path, _ := reviewPosition(m)
+	assert.Equal(t, "a.txt", path)
+
+	// 承認すると作業ツリーから取り消す
+	m = updateAll(t, m, runes("n"))
+	m = updateAll(t, m, runes("y"))
+	assert.Equal(t, " M n.txt\n?? new.txt\n", runGit(t, root, "status", "--porcelain"))
+	assert.Equal(t, 1, m.reviewView.rejected)
+}
+
+func TestModel_ReviewKeymap(t *testing.T) {
+	root := newReviewProject(t)
+	m := NewModel(WithWorkDir(root), WithActiveTask("auth"), WithKeybindings(keymap.Bindings{
+		keymap.Sidebar: {"review.accept": {"enter"}},
+	}))
+
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	assert.Contains(t, m.helpText(), "Enter: 採用")
+
+	// 既定のyは置き換えられる
+	m = updateAll(t, m, runes("y"))
+	assert.Equal(t, " M a.txt\n M n.txt\n?? new.txt\n", runGit(t, root, "status", "--porcelain"))
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "M  a.txt\n M n.txt\n?? new.txt\n", runGit(t, root, "status", "--porcelain"))
+	path, _ := reviewPosition(m)
+	assert.Equal(t, "n.txt", path)
+}
+
+func TestModel_ReviewFinishWhileApplying(t *testing.T) {
+	root := newReviewProject(t)
+	m := NewModel(WithWorkDir(root), WithActiveTask("auth"))
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyCtrlR})
+
+	// 採用の反映中にEscで終了すると、反映が終わってから集計する
+	m, apply := update(t, m, runes("y"))
+	require.IsType(t, reviewAppliedMsg{}, apply)
+	m, msg := update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Nil(t, msg)
+	assert.False(t, m.reviewView.IsFinished())
+
+	m = updateAll(t, m, apply)
+	assert.True(t, m.reviewView.IsFinished())
+	assert.Contains(t, outputText(m), "採用 1 / 却下 0 / 未処理 3")
+	assert.Equal(t, "M  a.txt\n M n.txt\n?? new.txt\n", runGit(t, root, "status", "--porcelain"))
+
+	// 終了後に届いた古い結果は無視する
+	m = updateAll(t, m, reviewAppliedMsg{decision: reviewReject})
+	m = updateAll(t, m, reviewDiffMsg{file: 1})
+	assert.Equal(t, 0, m.reviewView.rejected)
+	assert.Equal(t, 0, m.reviewView.file)
+}
+
+func TestModel_ReviewNoChanges(t *testing.T) {
+	root := newCommitProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "info", "exclude"), []byte("ccforge/\n"), 0o644))
+	m := NewModel(WithWorkDir(root), WithActiveTask("auth"))
+
+	m = updateAll(t, m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	assert.True(t, m.reviewView.IsFinished())
+	assert.Contains(t, outputText(m), "レビューする変更はありません")
+}
+
+func TestReviewItems(t *testing.T) {
+	assert.Equal(t, 0, reviewItems(nil))
+	assert.Equal(t, 0, reviewItems(&git.FileDiff{}))
+	assert.Equal(t, 1, reviewItems(&git.FileDiff{Header: []string{"diff --git a/logo.png b/logo.png"}, Binary: true}))
+	assert.Equal(t, 1, reviewItems(testFileDiff()))
+}
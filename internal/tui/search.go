@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+const (
+	// searchQueryDialogID は検索語の入力ダイアログの識別子
+	searchQueryDialogID = "search.query"
+	// searchResultsDialogID は検索結果のピッカーの識別子
+	searchResultsDialogID = "search.results"
+)
+
+// transcriptSearch はトランスクリプトの全文検索
+type transcriptSearch struct {
+	search func(query string) ([]store.SearchHit, error)            // 検索
+	open   func(sessionID string) ([]store.PromptTranscript, error) // セッションのトランスクリプトの読み込み
+	query  string                                                   // 直前の検索語
+	hits   []store.SearchHit                                        // 直前の検索結果
+}
+
+// searchDoneMsg は検索の結果
+type searchDoneMsg struct {
+	query string
+	hits  []store.SearchHit
+	err   error
+}
+
+// transcriptLoadedMsg は検索結果のトランスクリプトの読み込み結果
+type transcriptLoadedMsg struct {
+	hit        store.SearchHit
+	transcript []store.PromptTranscript
+	err        error
+}
+
+// WithTranscriptSearch は過去のトランスクリプトの全文検索を有効にする
+// searchは検索語に一致したやり取りを、openはセッションのトランスクリプトを返す
+func WithTranscriptSearch(search func(query string) ([]store.SearchHit, error), open func(sessionID string) ([]store.PromptTranscript, error)) Option {
+	return func(m *Model) {
+		m.search = &transcriptSearch{search: search, open: open}
+	}
+}
+
+// openSearch は検索語の入力ダイアログを開く
+// 前回の検索語を初期値にする
+func (m *Model) openSearch() tea.Cmd {
+	if m.search == nil {
+		m.mainView.AddOutput(themed(theme.Muted).Render("トランスクリプトの検索は利用できません"))
+		return nil
+	}
+	return OpenDialog(NewPromptDialog(searchQueryDialogID, "トランスクリプトを検索", "検索語 (空白区切りですべてを含むもの)", m.search.query))
+}
+
+// runSearch は検索を実行するコマンドを返す
+func (m *Model) runSearch(query string) tea.Cmd {
+	if m.search == nil {
+		return m.openSearch()
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	m.search.query = query
+	search := m.search.search
+	slog.Debug("トランスクリプトを検索します", "component", "tui", "query", query)
+	return func() tea.Msg {
+		hits, err := search(query)
+		return searchDoneMsg{query: query, hits: hits, err: err}
+	}
+}
+
+// handleSearchDone は検索結果をピッカーで表示する
+func (m *Model) handleSearchDone(msg searchDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		m.mainView.AddOutput(themed(theme.Error).Render(fmt.Sprintf("✗ 検索に失敗しました: %v", msg.err)))
+		return nil
+	}
+	if len(msg.hits) == 0 {
+		m.mainView.AddOutput(themed(theme.Muted).Render(fmt.Sprintf("%q に一致するトランスクリプトはありません", msg.query)))
+		return nil
+	}
+
+	m.search.hits = msg.hits
+	items := make([]PickerItem, 0, len(msg.hits))
+	for i, hit := range msg.hits {
+		items = append(items, PickerItem{
+			Title:       fmt.Sprintf("%s %s", hit.TaskName, hit.Time.Local().Format("01-02 15:04")),
+			Description: hit.Snippet,
+			Hint:        hitLabel(hit),
+			Value:       strconv.Itoa(i),
+		})
+	}
+	title := fmt.Sprintf("%q の検索結果 (%d件)", msg.query, len(msg.hits))
+	return OpenDialog(NewPickerDialog(searchResultsDialogID, title, items))
+}
+
+// openSearchHit は選択された検索結果のトランスクリプトを読み込むコマンドを返す
+func (m *Model) openSearchHit(value string) tea.Cmd {
+	i, err := strconv.Atoi(value)
+	if err != nil || m.search == nil || i < 0 || i >= len(m.search.hits) {
+		return nil
+	}
+	hit := m.search.hits[i]
+	open := m.search.open
+	return func() tea.Msg {
+		transcript, err := open(hit.SessionID)
+		return transcriptLoadedMsg{hit: hit, transcript: transcript, err: err}
+	}
+}
+
+// handleTranscriptLoaded はトランスクリプトを一致した位置から表示する
+func (m *Model) handleTranscriptLoaded(msg transcriptLoadedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.mainView.AddOutput(themed(theme.Error).Render(fmt.Sprintf("✗ トランスクリプトを読み込めませんでした: %v", msg.err)))
+		return nil
+	}
+	return OpenDialog(NewTranscriptDialog(msg.hit, msg.transcript, store.ParseQuery(m.search.query)))
+}
+
+// hitLabel は検索結果がプロンプトと応答のどちらに一致したかを返す
+func hitLabel(hit store.SearchHit) string {
+	if hit.ChunkID == 0 {
+		return "プロンプト"
+	}
+	return "応答"
+}
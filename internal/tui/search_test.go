@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testHits はテスト用の検索結果
+var testHits = []store.SearchHit{
+	{TaskName: "auth", SessionID: "s1", PromptID: 1, ChunkID: 2, Time: time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC), Snippet: "公開鍵で検証します"},
+	{TaskName: "auth", SessionID: "s1", PromptID: 1, Time: time.Date(2026, 1, 2, 3, 3, 0, 0, time.UTC), Snippet: "JWTの検証方法"},
+}
+
+// testTranscript はテスト用のトランスクリプト
+var testTranscript = []store.PromptTranscript{
+	{
+		Prompt: store.Prompt{ID: 1, SessionID: "s1", Text: "JWTの検証方法を説明して"},
+		Chunks: []store.Chunk{
+			{ID: 1, Content: "まず"},
+			{ID: 2, Content: "ヘッダーを読みます\n署名を公開鍵で検証します\n"},
+		},
+	},
+}
+
+// newSearchModel は検索を記録するModelを作成する
+func newSearchModel(t *testing.T, hits []store.SearchHit, searchErr error) (Model, *[]string) {
+	t.Helper()
+	var queries []string
+	search := func(query string) ([]store.SearchHit, error) {
+		queries = append(queries, query)
+		return hits, searchErr
+	}
+	open := func(sessionID string) ([]store.PromptTranscript, error) {
+		if sessionID != "s1" {
+			return nil, errors.New("not found")
+		}
+		return testTranscript, nil
+	}
+	return NewModel(WithTranscriptSearch(search, open)), &queries
+}
+
+// update はメッセージを処理し、返されたコマンドのメッセージを返す
+func update(t *testing.T, m Model, msg tea.Msg) (Model, tea.Msg) {
+	t.Helper()
+	updated, cmd := m.Update(msg)
+	if cmd == nil {
+		return updated.(Model), nil
+	}
+	return updated.(Model), cmd()
+}
+
+func TestModel_Search(t *testing.T) {
+	m, queries := newSearchModel(t, testHits, nil)
+
+	// Ctrl+Fで検索語の入力ダイアログを開く
+	m, msg := update(t, m, tea.KeyMsg{Type: tea.KeyCtrlF})
+	openMsg, ok := msg.(OpenDialogMsg)
+	require.True(t, ok)
+	assert.IsType(t, &PromptDialog{}, openMsg.Dialog)
+
+	// 検索語を確定すると検索し、結果をピッカーで表示する
+	m, msg = update(t, m, PromptResultMsg{ID: searchQueryDialogID, Value: " 検証 "})
+	assert.Equal(t, []string{"検証"}, *queries)
+	m, msg = update(t, m, msg)
+	openMsg, ok = msg.(OpenDialogMsg)
+	require.True(t, ok)
+	picker, ok := openMsg.Dialog.(*PickerDialog)
+	require.True(t, ok)
+	require.Len(t, picker.items, 2)
+	assert.Contains(t, picker.items[0].Title, "auth")
+	assert.Equal(t, "公開鍵で検証します", picker.items[0].Description)
+	assert.Equal(t, "応答", picker.items[0].Hint)
+	assert.Equal(t, "プロンプト", picker.items[1].Hint)
+
+	// 結果を選ぶとトランスクリプトを一致した位置から表示する
+	m, msg = update(t, m, PickerResultMsg{ID: searchResultsDialogID, Item: picker.items[0]})
+	_, msg = update(t, m, msg)
+	openMsg, ok = msg.(OpenDialogMsg)
+	require.True(t, ok)
+	transcript, ok := openMsg.Dialog.(*TranscriptDialog)
+	require.True(t, ok)
+	assert.Equal(t, []string{"検証"}, transcript.terms)
+	assert.Equal(t, "署名を公開鍵で検証します", transcript.lines[transcript.cursor].text)
+}
+
+func TestModel_SearchSlashCommand(t *testing.T) {
+	m, queries := newSearchModel(t, testHits, nil)
+
+	m, msg := update(t, m, SubmitMsg{Text: "/search JWT 検証"})
+	_, _ = update(t, m, msg)
+	assert.Equal(t, []string{"JWT 検証"}, *queries)
+
+	// 検索語を省略すると入力ダイアログを開く
+	m, msg = update(t, m, SubmitMsg{Text: "/search"})
+	_, msg = update(t, m, msg)
+	assert.IsType(t, OpenDialogMsg{}, msg)
+}
+
+func TestModel_SearchNoResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		hits    []store.SearchHit
+		err     error
+		wantOut string
+	}{
+		{name: "一致なし", hits: []store.SearchHit{}, wantOut: `"未知" に一致するトランスクリプトはありません`},
+		{name: "検索に失敗", err: errors.New("database is locked"), wantOut: "検索に失敗しました: database is locked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := newSearchModel(t, tt.hits, tt.err)
+			m, msg := update(t, m, PromptResultMsg{ID: searchQueryDialogID, Value: "未知"})
+			m, msg = update(t, m, msg)
+			assert.Nil(t, msg)
+			assert.Contains(t, strings.Join(m.mainView.outputLines, "\n"), tt.wantOut)
+		})
+	}
+}
+
+func TestModel_SearchUnavailable(t *testing.T) {
+	m := NewModel()
+	m, msg := update(t, m, tea.KeyMsg{Type: tea.KeyCtrlF})
+	assert.Nil(t, msg)
+	assert.Contains(t, m.mainView.outputLines[len(m.mainView.outputLines)-1], "トランスクリプトの検索は利用できません")
+}
+
+func TestModel_SearchTranscriptError(t *testing.T) {
+	m, _ := newSearchModel(t, []store.SearchHit{{TaskName: "auth", SessionID: "missing"}}, nil)
+	m, msg := update(t, m, PromptResultMsg{ID: searchQueryDialogID, Value: "検証"})
+	m, _ = update(t, m, msg)
+
+	m, msg = update(t, m, PickerResultMsg{ID: searchResultsDialogID, Item: PickerItem{Value: "0"}})
+	m, msg = update(t, m, msg)
+	assert.Nil(t, msg)
+	assert.Contains(t, m.mainView.outputLines[len(m.mainView.outputLines)-1], "トランスクリプトを読み込めませんでした")
+}
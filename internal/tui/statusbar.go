@@ -5,6 +5,10 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/mzkmnk/ccforge/internal/theme"
 )
 
 // ConnectionStatus は接続状態を表す型
@@ -25,8 +29,13 @@ const (
 // StatusBar はステータスバーコンポーネント
 type StatusBar struct {
 	activeTask       string           // アクティブなタスク名
+	progress         tasks.Progress   // アクティブなタスクの進捗 (未取得の場合はゼロ値)
+	changedFiles     int              // セッション中に変更されたファイル数
+	trackChanges     bool             // 変更されたファイル数を表示するか
+	git              *git.Summary     // gitリポジトリの状態 (nilの場合はgitの欄を表示しない)
 	connectionStatus ConnectionStatus // 接続状態
 	showHelp         bool             // ヘルプ表示フラグ
+	helpItems        []string         // ヘルプに表示する項目 (例: "F1: ヘルプ")
 	width            int              // ステータスバーの幅
 }
 
@@ -41,10 +50,36 @@ func NewStatusBar() *StatusBar {
 }
 
 // SetActiveTask はアクティブなタスクを設定する
+// タスクが変わった場合は進捗の表示を消す
 func (s *StatusBar) SetActiveTask(taskName string) {
+	if taskName != s.activeTask {
+		s.progress = tasks.Progress{}
+	}
 	s.activeTask = taskName
 }
 
+// SetProgress はアクティブなタスクの進捗を設定する
+// 項目のない進捗は表示しない
+func (s *StatusBar) SetProgress(progress tasks.Progress) {
+	s.progress = progress
+}
+
+// SetChangedFiles はセッション中に変更されたファイル数を設定する
+func (s *StatusBar) SetChangedFiles(n int) {
+	s.changedFiles = n
+	s.trackChanges = true
+}
+
+// SetGit はgitリポジトリの状態を設定する
+func (s *StatusBar) SetGit(summary git.Summary) {
+	s.git = &summary
+}
+
+// ClearGit はgitの欄を非表示にする
+func (s *StatusBar) ClearGit() {
+	s.git = nil
+}
+
 // SetConnectionStatus は接続状態を設定する
 func (s *StatusBar) SetConnectionStatus(status ConnectionStatus) {
 	s.connectionStatus = status
@@ -55,6 +90,12 @@ func (s *StatusBar) ToggleHelp() {
 	s.showHelp = !s.showHelp
 }
 
+// SetHelpItems はヘルプに表示する項目を設定する
+// 項目はアクティブなキーマップから作る
+func (s *StatusBar) SetHelpItems(items []string) {
+	s.helpItems = items
+}
+
 // SetWidth はステータスバーの幅を設定する
 func (s *StatusBar) SetWidth(width int) {
 	s.width = width
@@ -65,8 +106,8 @@ func (s *StatusBar) View() string {
 	// スタイルの定義
 	baseStyle := lipgloss.NewStyle().
 		Width(s.width).
-		Background(lipgloss.Color("235")).
-		Foreground(lipgloss.Color("252"))
+		Background(activeTheme.Color(theme.StatusBarBg)).
+		Foreground(activeTheme.Color(theme.StatusBarFg))
 
 	// 左側: タスク情報
 	taskText := s.getTaskText()
@@ -88,22 +129,35 @@ func (s *StatusBar) View() string {
 
 	// レイアウトの構築
 	// 各セクションの幅を計算
-	taskWidth := s.width / 3
-	connectionWidth := s.width / 3
-	helpWidth := s.width - taskWidth - connectionWidth
+	sectionWidth := s.sectionWidth()
+	helpWidth := s.width - sectionWidth*2
 
 	// スタイルを適用
-	taskSection := taskStyle.Width(taskWidth).Render(taskText)
-	connectionSection := connectionStyle.Width(connectionWidth).Render(connectionText)
-	helpSection := helpStyle.Width(helpWidth).Render(helpText)
+	sections := []string{taskStyle.Width(sectionWidth).Render(taskText)}
+	if s.git != nil {
+		// gitの欄はタスクの右に置く
+		gitStyle := baseStyle.
+			Padding(0, 1).
+			Align(lipgloss.Left)
+		sections = append(sections, gitStyle.Width(sectionWidth).Render(ansi.Truncate(s.getGitText(), sectionWidth-2, "…")))
+		helpWidth -= sectionWidth
+	}
+	sections = append(sections,
+		connectionStyle.Width(sectionWidth).Render(connectionText),
+		helpStyle.Width(helpWidth).Render(helpText),
+	)
 
 	// 横に並べる
-	return lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		taskSection,
-		connectionSection,
-		helpSection,
-	)
+	return lipgloss.JoinHorizontal(lipgloss.Top, sections...)
+}
+
+// sectionWidth はヘルプ以外の各セクションの幅を返す
+// gitの欄を表示する場合は4等分、それ以外は3等分する
+func (s *StatusBar) sectionWidth() int {
+	if s.git != nil {
+		return s.width / 4
+	}
+	return s.width / 3
 }
 
 // getTaskText はタスク表示テキストを取得する
@@ -113,9 +167,12 @@ func (s *StatusBar) getTaskText() string {
 	}
 
 	taskText := fmt.Sprintf("タスク: %s", s.activeTask)
+	if s.progress.Total > 0 {
+		taskText += fmt.Sprintf(" (%d/%d)", s.progress.Done, s.progress.Total)
+	}
 
 	// 長すぎる場合は省略
-	maxLength := s.width/3 - 4
+	maxLength := s.sectionWidth() - 4
 	if len(taskText) > maxLength && maxLength > 3 {
 		taskText = taskText[:maxLength-3] + "..."
 	}
@@ -123,31 +180,74 @@ func (s *StatusBar) getTaskText() string {
 	return taskText
 }
 
+// getGitText はgitの欄の表示テキストを取得する
+// 例: "main ↑2 ↓1 +1 ~3 ?2"、"[REBASE] (a1b2c3d) !1"
+func (s *StatusBar) getGitText() string {
+	g := s.git
+	var parts []string
+	if g.State != git.StateNone {
+		parts = append(parts, themed(theme.Warning).Render("["+g.State.String()+"]"))
+	}
+
+	switch {
+	case g.Detached && g.Head != "":
+		parts = append(parts, "("+g.Head+")")
+	case g.Detached:
+		parts = append(parts, "(detached)")
+	default:
+		parts = append(parts, g.Branch)
+	}
+
+	if g.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", g.Ahead))
+	}
+	if g.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", g.Behind))
+	}
+	if g.Conflicted > 0 {
+		parts = append(parts, themed(theme.Error).Render(fmt.Sprintf("!%d", g.Conflicted)))
+	}
+	if g.Staged > 0 {
+		parts = append(parts, themed(theme.Success).Render(fmt.Sprintf("+%d", g.Staged)))
+	}
+	if g.Unstaged > 0 {
+		parts = append(parts, themed(theme.Warning).Render(fmt.Sprintf("~%d", g.Unstaged)))
+	}
+	if g.Untracked > 0 {
+		parts = append(parts, fmt.Sprintf("?%d", g.Untracked))
+	}
+
+	return strings.Join(parts, " ")
+}
+
 // getConnectionStatusText は接続状態の表示テキストを取得する
 func (s *StatusBar) getConnectionStatusText() string {
 	var statusText string
-	var statusColor lipgloss.Color
+	var statusRole theme.Role
 
 	switch s.connectionStatus {
 	case Connected:
 		statusText = "接続済み"
-		statusColor = lipgloss.Color("42") // 緑
+		statusRole = theme.Success
 	case Connecting:
 		statusText = "接続中..."
-		statusColor = lipgloss.Color("226") // 黄色
+		statusRole = theme.Warning
 	case Disconnected:
 		statusText = "切断"
-		statusColor = lipgloss.Color("196") // 赤
+		statusRole = theme.Error
 	default:
 		statusText = "不明"
-		statusColor = lipgloss.Color("245") // グレー
+		statusRole = theme.Muted
 	}
 
 	// アイコンに色を適用
-	iconStyle := lipgloss.NewStyle().Foreground(statusColor)
-	coloredIcon := iconStyle.Render(statusIcon)
+	coloredIcon := themed(statusRole).Render(statusIcon)
 
-	return fmt.Sprintf("%s %s", coloredIcon, statusText)
+	text := fmt.Sprintf("%s %s", coloredIcon, statusText)
+	if s.trackChanges {
+		text += fmt.Sprintf(" | 変更 %d", s.changedFiles)
+	}
+	return text
 }
 
 // getHelpText はヘルプ表示テキストを取得する
@@ -156,12 +256,7 @@ func (s *StatusBar) getHelpText() string {
 		return ""
 	}
 
-	helpItems := []string{
-		"F1: ヘルプ",
-		"Ctrl+C: 終了",
-	}
-
-	return strings.Join(helpItems, " | ")
+	return strings.Join(s.helpItems, " | ")
 }
 
 // GetActiveTask はアクティブなタスク名を取得する
@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/git"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -193,6 +195,9 @@ func TestStatusBar_ViewHelp(t *testing.T) {
 		width:            80,
 	}
 
+	sbWithHelp.SetHelpItems([]string{"F1: ヘルプ", "Ctrl+C: 終了"})
+	sbWithoutHelp.SetHelpItems([]string{"F1: ヘルプ", "Ctrl+C: 終了"})
+
 	viewWithHelp := sbWithHelp.View()
 	viewWithoutHelp := sbWithoutHelp.View()
 
@@ -287,3 +292,57 @@ func TestStatusBar_LongTaskNameTruncation(t *testing.T) {
 		assert.LessOrEqual(t, len(line), 200) // スタイル含めた最大長
 	}
 }
+
+func TestStatusBar_ViewGit(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary git.Summary
+		want    string
+	}{
+		{
+			name:    "変更なし",
+			summary: git.Summary{Branch: "main", Upstream: "origin/main"},
+			want:    "main",
+		},
+		{
+			name:    "追跡しているブランチとの差と変更の数",
+			summary: git.Summary{Branch: "main", Ahead: 2, Behind: 1, Staged: 1, Unstaged: 3, Untracked: 2},
+			want:    "main ↑2 ↓1 +1 ~3 ?2",
+		},
+		{
+			name:    "detached HEAD",
+			summary: git.Summary{Head: "a1b2c3d", Detached: true},
+			want:    "(a1b2c3d)",
+		},
+		{
+			name:    "リベースの途中のコンフリクト",
+			summary: git.Summary{Head: "a1b2c3d", Detached: true, Conflicted: 1, State: git.StateRebase},
+			want:    "[REBASE] (a1b2c3d) !1",
+		},
+		{
+			name:    "マージの途中",
+			summary: git.Summary{Branch: "main", State: git.StateMerge},
+			want:    "[MERGE] main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := NewStatusBar()
+			sb.SetWidth(120)
+			sb.SetGit(tt.summary)
+
+			assert.Equal(t, tt.want, ansi.Strip(sb.getGitText()))
+			assert.Contains(t, ansi.Strip(sb.View()), tt.want)
+		})
+	}
+}
+
+func TestStatusBar_ClearGit(t *testing.T) {
+	sb := NewStatusBar()
+	sb.SetGit(git.Summary{Branch: "feature/login"})
+	assert.Contains(t, sb.View(), "feature/login")
+
+	sb.ClearGit()
+	assert.NotContains(t, sb.View(), "feature/login")
+}
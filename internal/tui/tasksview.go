@@ -0,0 +1,385 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+// tasksLoadedMsg はタスク一覧の取得結果
+type tasksLoadedMsg struct {
+	tasks []tasks.Task
+	err   error
+}
+
+// specsLoadedMsg は選択中タスクのSpecsの取得結果
+type specsLoadedMsg struct {
+	task  string
+	specs []tasks.Spec
+	err   error
+}
+
+// TasksView はタスクの一覧とSpecsを表示するパネル
+type TasksView struct {
+	width     int            // パネルの幅
+	height    int            // パネルの高さ
+	manager   *tasks.Manager // タスクの管理
+	tasks     []tasks.Task   // タスク一覧
+	selected  int            // 選択中のタスク
+	specIndex int            // 表示中のSpecsファイル
+	specs     []tasks.Spec   // 選択中タスクのSpecs
+	offset    int            // Specsのスクロール位置
+	visible   bool           // 表示中フラグ
+	err       error          // 直近のエラー
+}
+
+// NewTasksView は新しいTasksViewを作成する
+func NewTasksView() *TasksView {
+	return &TasksView{
+		width:  80,
+		height: 23,
+	}
+}
+
+// Show はパネルを表示してタスク一覧の取得を開始する
+// 管理が未設定の場合はdirをプロジェクトのルートとして使う
+func (v *TasksView) Show(dir string) tea.Cmd {
+	v.visible = true
+	if v.manager == nil {
+		v.manager = tasks.NewManager(dir)
+	}
+	return v.Reload()
+}
+
+// Hide はパネルを非表示にする
+func (v *TasksView) Hide() {
+	v.visible = false
+}
+
+// IsVisible はパネルが表示中かを取得する
+func (v *TasksView) IsVisible() bool {
+	return v.visible
+}
+
+// SetSize はパネルのサイズを設定する
+func (v *TasksView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// SelectedTask は選択中のタスクを取得する
+func (v *TasksView) SelectedTask() (tasks.Task, bool) {
+	if v.selected < 0 || v.selected >= len(v.tasks) {
+		return tasks.Task{}, false
+	}
+	return v.tasks[v.selected], true
+}
+
+// Init はBubble Teaの初期化処理（tea.Modelインターフェースの実装）
+func (v *TasksView) Init() tea.Cmd {
+	return nil
+}
+
+// Update はメッセージを処理して状態を更新する
+func (v *TasksView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return v, v.handleKeyMsg(msg)
+
+	case tasksLoadedMsg:
+		return v, v.applyTasks(msg)
+
+	case specsLoadedMsg:
+		current, ok := v.SelectedTask()
+		// 選択が変わった後に届いた古い結果は捨てる
+		if !ok || current.Name != msg.task {
+			return v, nil
+		}
+		v.err = msg.err
+		v.specs = msg.specs
+		v.clampOffset()
+	}
+
+	return v, nil
+}
+
+// handleKeyMsg はキーボード入力を処理する
+func (v *TasksView) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		return v.selectTask(v.selected - 1)
+	case "down", "j":
+		return v.selectTask(v.selected + 1)
+	case "tab", "right", "l":
+		v.selectSpec(v.specIndex + 1)
+	case "shift+tab", "left", "h":
+		v.selectSpec(v.specIndex - 1)
+	case "pgup":
+		v.offset -= v.height - 2
+		v.clampOffset()
+	case "pgdown", " ":
+		v.offset += v.height - 2
+		v.clampOffset()
+	case "r":
+		return v.Reload()
+	}
+	return nil
+}
+
+// selectTask は指定インデックスのタスクを選択してSpecsを読み込む
+func (v *TasksView) selectTask(index int) tea.Cmd {
+	if index < 0 || index >= len(v.tasks) || index == v.selected {
+		return nil
+	}
+	v.selected = index
+	v.specs = nil
+	v.offset = 0
+	return v.loadSpecs()
+}
+
+// selectSpec は表示するSpecsファイルを切り替える (端では反対側に戻る)
+func (v *TasksView) selectSpec(index int) {
+	n := len(tasks.SpecFiles)
+	v.specIndex = (index%n + n) % n
+	v.offset = 0
+}
+
+// applyTasks は取得したタスク一覧を反映し、選択を維持する
+func (v *TasksView) applyTasks(msg tasksLoadedMsg) tea.Cmd {
+	if msg.err != nil {
+		v.err = msg.err
+		return nil
+	}
+	v.err = nil
+
+	previous, hadSelection := v.SelectedTask()
+	v.tasks = msg.tasks
+	v.selected = 0
+
+	if hadSelection {
+		for i, t := range v.tasks {
+			if t.Name == previous.Name {
+				v.selected = i
+				break
+			}
+		}
+	}
+
+	if len(v.tasks) == 0 {
+		v.specs = nil
+		return nil
+	}
+
+	return v.loadSpecs()
+}
+
+// Reload はタスク一覧を読み直すコマンドを返す
+// 一覧の反映後に選択中タスクのSpecsも読み直す
+func (v *TasksView) Reload() tea.Cmd {
+	manager := v.manager
+	if manager == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		list, err := manager.List()
+		return tasksLoadedMsg{tasks: list, err: err}
+	}
+}
+
+// loadSpecs は選択中タスクのSpecsを非同期に取得するコマンドを返す
+func (v *TasksView) loadSpecs() tea.Cmd {
+	task, ok := v.SelectedTask()
+	manager := v.manager
+	if !ok || manager == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		specs, err := manager.Specs(task.Name)
+		return specsLoadedMsg{task: task.Name, specs: specs, err: err}
+	}
+}
+
+// currentSpec は表示中のSpecsファイルを取得する
+func (v *TasksView) currentSpec() (tasks.Spec, bool) {
+	if v.specIndex < 0 || v.specIndex >= len(v.specs) {
+		return tasks.Spec{}, false
+	}
+	return v.specs[v.specIndex], true
+}
+
+// clampOffset はSpecsのスクロール位置を範囲内に収める
+func (v *TasksView) clampOffset() {
+	maxOffset := len(v.specLines()) - (v.height - 1)
+	if v.offset > maxOffset {
+		v.offset = maxOffset
+	}
+	if v.offset < 0 {
+		v.offset = 0
+	}
+}
+
+// listWidth はタスク一覧の幅を計算する
+func (v *TasksView) listWidth() int {
+	w := v.width / 3
+	if w < 24 {
+		w = 24
+	}
+	if w > 48 {
+		w = 48
+	}
+	if w > v.width {
+		w = v.width
+	}
+	return w
+}
+
+// specWidth はSpecs表示領域の幅を計算する
+func (v *TasksView) specWidth() int {
+	w := v.width - v.listWidth() - 1 // 区切り線の分を引く
+	if w < 0 {
+		w = 0
+	}
+	return w
+}
+
+// View は現在の状態を文字列として描画する
+func (v *TasksView) View() string {
+	listStyle := lipgloss.NewStyle().
+		Width(v.listWidth()).
+		Height(v.height)
+	separatorStyle := themed(theme.Muted)
+	specStyle := lipgloss.NewStyle().
+		Width(v.specWidth()).
+		Height(v.height)
+
+	separator := separatorStyle.Render(strings.Repeat("│\n", v.height-1) + "│")
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		listStyle.Render(v.renderList()),
+		separator,
+		specStyle.Render(v.renderSpec()),
+	)
+}
+
+// renderList はタスクのツリーを描画する
+// 選択中のタスクの下にSpecsファイルを並べる
+func (v *TasksView) renderList() string {
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	selectedStyle := lipgloss.NewStyle().Reverse(true)
+	mutedStyle := themed(theme.Muted)
+	accentStyle := themed(theme.Accent)
+
+	width := v.listWidth()
+	lines := []string{headerStyle.Render(fmt.Sprintf("タスク (%d)", len(v.tasks)))}
+	selectedLine := 0
+
+	if len(v.tasks) == 0 {
+		lines = append(lines, "タスクはありません")
+	}
+
+	for i, t := range v.tasks {
+		progress := fmt.Sprintf("%d/%d %3d%%", t.Progress.Done, t.Progress.Total, t.Progress.Percent())
+		nameWidth := width - lipgloss.Width(progress) - 1
+		if nameWidth < 1 {
+			nameWidth = 1
+		}
+		name := ansi.Truncate(" "+t.Name, nameWidth, "…")
+		name += strings.Repeat(" ", nameWidth-lipgloss.Width(name))
+		if i == v.selected {
+			name = selectedStyle.Render(name)
+			selectedLine = len(lines)
+		}
+		lines = append(lines, name+" "+mutedStyle.Render(progress))
+
+		if i != v.selected {
+			continue
+		}
+		for j, file := range tasks.SpecFiles {
+			branch := "├─"
+			if j == len(tasks.SpecFiles)-1 {
+				branch = "└─"
+			}
+			entry := ansi.Truncate(fmt.Sprintf("   %s %s", branch, file.FileName), width, "…")
+			if j == v.specIndex {
+				entry = accentStyle.Render(entry)
+			} else {
+				entry = mutedStyle.Render(entry)
+			}
+			lines = append(lines, entry)
+		}
+	}
+
+	// 選択行とその下のSpecsが見えるようにスクロールする
+	start := 0
+	if last := selectedLine + len(tasks.SpecFiles); last >= v.height {
+		start = last - v.height + 1
+	}
+	end := start + v.height
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	return strings.Join(lines[start:end], "\n")
+}
+
+// renderSpec はSpecs表示領域を描画する
+func (v *TasksView) renderSpec() string {
+	errorStyle := themed(theme.Error)
+	mutedStyle := themed(theme.Muted)
+	titleStyle := lipgloss.NewStyle().Bold(true)
+
+	if v.err != nil {
+		return errorStyle.Render(fmt.Sprintf("エラー: %v", v.err))
+	}
+
+	task, ok := v.SelectedTask()
+	if !ok {
+		return mutedStyle.Render("タスクが選択されていません")
+	}
+
+	file := tasks.SpecFiles[v.specIndex]
+	title := fmt.Sprintf("%s / %s (%s)", task.Name, file.FileName, file.Title)
+	lines := []string{titleStyle.Render(ansi.Truncate(title, v.specWidth(), "…"))}
+
+	spec, loaded := v.currentSpec()
+	switch {
+	case !loaded:
+		lines = append(lines, mutedStyle.Render("読み込み中..."))
+	case !spec.Exists:
+		lines = append(lines, mutedStyle.Render(file.FileName+" はまだありません"))
+	default:
+		body := v.specLines()
+		end := v.offset + v.height - 1
+		if end > len(body) {
+			end = len(body)
+		}
+		if v.offset < end {
+			lines = append(lines, body[v.offset:end]...)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// specLines は表示中のSpecsファイルを表示幅に切り詰めた行に変換する
+func (v *TasksView) specLines() []string {
+	spec, ok := v.currentSpec()
+	if !ok || !spec.Exists {
+		return nil
+	}
+
+	width := v.specWidth()
+	lines := strings.Split(strings.TrimRight(spec.Content, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(strings.ReplaceAll(line, "\t", "    "), width, "…")
+	}
+	return lines
+}
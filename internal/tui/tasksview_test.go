@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTasksProject はタスクauthとbillingのあるプロジェクトを作成する
+func newTasksProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	manager := tasks.NewManager(root)
+	_, err := manager.Create("auth", "認証")
+	require.NoError(t, err)
+	_, err = manager.Create("billing", "請求")
+	require.NoError(t, err)
+	return root
+}
+
+// loadTasksView はパネルを表示して一覧とSpecsの読み込みを反映する
+func loadTasksView(t *testing.T, v *TasksView, cmd tea.Cmd) {
+	t.Helper()
+	for cmd != nil {
+		_, cmd = v.Update(cmd())
+	}
+}
+
+func TestTasksView_Show(t *testing.T) {
+	v := NewTasksView()
+	v.SetSize(100, 20)
+	loadTasksView(t, v, v.Show(newTasksProject(t)))
+
+	assert.True(t, v.IsVisible())
+	require.Len(t, v.tasks, 2)
+	require.Len(t, v.specs, len(tasks.SpecFiles))
+
+	view := ansi.Strip(v.View())
+	assert.Contains(t, view, "タスク (2)")
+	assert.Contains(t, view, "auth")
+	assert.Contains(t, view, "0/4   0%")
+	assert.Contains(t, view, "├─ requirements.md")
+	assert.Contains(t, view, "auth / requirements.md (要件定義)")
+	assert.Contains(t, view, "# auth 要件定義")
+}
+
+func TestTasksView_Keys(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []tea.KeyMsg
+		wantTask  string
+		wantSpec  int
+		wantTitle string
+	}{
+		{
+			name:      "次のタスク",
+			keys:      []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune{'j'}}},
+			wantTask:  "billing",
+			wantTitle: "# billing 要件定義",
+		},
+		{
+			name:      "先頭より前には移動しない",
+			keys:      []tea.KeyMsg{{Type: tea.KeyUp}},
+			wantTask:  "auth",
+			wantTitle: "# auth 要件定義",
+		},
+		{
+			name:      "次のSpecsファイル",
+			keys:      []tea.KeyMsg{{Type: tea.KeyTab}, {Type: tea.KeyRunes, Runes: []rune{'l'}}},
+			wantTask:  "auth",
+			wantSpec:  2,
+			wantTitle: "# auth タスク",
+		},
+		{
+			name:      "前のSpecsファイルは末尾に戻る",
+			keys:      []tea.KeyMsg{{Type: tea.KeyShiftTab}},
+			wantTask:  "auth",
+			wantSpec:  2,
+			wantTitle: "# auth タスク",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewTasksView()
+			v.SetSize(100, 20)
+			loadTasksView(t, v, v.Show(newTasksProject(t)))
+
+			for _, key := range tt.keys {
+				_, cmd := v.Update(key)
+				loadTasksView(t, v, cmd)
+			}
+
+			task, ok := v.SelectedTask()
+			require.True(t, ok)
+			assert.Equal(t, tt.wantTask, task.Name)
+			assert.Equal(t, tt.wantSpec, v.specIndex)
+			assert.Contains(t, ansi.Strip(v.View()), tt.wantTitle)
+		})
+	}
+}
+
+func TestTasksView_ApplyTasks(t *testing.T) {
+	v := NewTasksView()
+	v.tasks = []tasks.Task{{Name: "auth"}, {Name: "billing"}}
+	v.selected = 1
+
+	// 選択中のタスクを維持する
+	v.Update(tasksLoadedMsg{tasks: []tasks.Task{{Name: "api"}, {Name: "auth"}, {Name: "billing"}}})
+	assert.Equal(t, 2, v.selected)
+
+	// 選択中のタスクが消えた場合は先頭を選択する
+	v.Update(tasksLoadedMsg{tasks: []tasks.Task{{Name: "api"}}})
+	assert.Equal(t, 0, v.selected)
+
+	// 選択が変わった後に届いた古いSpecsは捨てる
+	v.Update(specsLoadedMsg{task: "billing", specs: []tasks.Spec{{}}})
+	assert.Nil(t, v.specs)
+}
+
+func TestTasksView_Empty(t *testing.T) {
+	v := NewTasksView()
+	loadTasksView(t, v, v.Show(t.TempDir()))
+
+	view := ansi.Strip(v.View())
+	assert.Contains(t, view, "タスクはありません")
+	assert.Contains(t, view, "タスクが選択されていません")
+}
+
+func TestModel_ToggleTasksView(t *testing.T) {
+	m := NewModel(WithWorkDir(newTasksProject(t)))
+
+	m, msg := update(t, m, tea.KeyMsg{Type: tea.KeyCtrlT})
+	assert.True(t, m.tasksView.IsVisible())
+	m, _ = update(t, m, msg)
+	assert.Len(t, m.tasksView.tasks, 2)
+
+	// 表示中はキーをパネルが受け取る
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	assert.Empty(t, m.mainView.input)
+	assert.Equal(t, 1, m.tasksView.selected)
+
+	// 差分パネルとは同時に表示しない
+	m.workDir = t.TempDir()
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlD})
+	assert.True(t, m.diffView.IsVisible())
+	assert.False(t, m.tasksView.IsVisible())
+
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlT})
+	assert.True(t, m.tasksView.IsVisible())
+	assert.False(t, m.diffView.IsVisible())
+
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.tasksView.IsVisible())
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+// activeTheme は描画に使うテーマ
+// ダイアログや差分の描画関数からも参照するためパッケージで共有する
+var activeTheme = theme.Dark()
+
+// hasDarkBackground は端末の背景色が暗いか判定する (テストで差し替える)
+// Bubble Teaの起動後は端末への問い合わせが入力と競合するため、起動前に呼ぶ
+var hasDarkBackground = lipgloss.HasDarkBackground
+
+// WithTheme はテーマを設定する
+// auto の場合は端末の背景色から選び、色数の少ない端末ではテーマの16色・256色の値を使う
+// テーマが見つからない場合は警告を表示して auto を使う
+func WithTheme(name string, palettes map[string]theme.Palette) Option {
+	return func(m *Model) {
+		t, err := theme.Resolve(name, palettes, m.isDarkBackground)
+		if err != nil {
+			m.mainView.AddOutput(fmt.Sprintf("警告: %v", err))
+			t, _ = theme.Resolve(theme.Auto, nil, m.isDarkBackground)
+		}
+		slog.Debug("テーマを設定しました", "component", "tui", "theme", t.Name, "dark", t.Dark)
+		activeTheme = t
+	}
+}
+
+// themed は役割の色を文字色にしたスタイルを返す
+func themed(role theme.Role) lipgloss.Style {
+	return activeTheme.Foreground(role)
+}
+
+// isDarkBackground は端末の背景色が暗いかを返す
+// 端末への問い合わせは最初の1回だけ行う
+func (m *Model) isDarkBackground() bool {
+	if m.darkBackground == nil {
+		dark := hasDarkBackground()
+		m.darkBackground = &dark
+	}
+	return *m.darkBackground
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubBackground は端末の背景色の判定を差し替える
+func stubBackground(t *testing.T, dark bool) {
+	t.Helper()
+	orig, origTheme := hasDarkBackground, activeTheme
+	hasDarkBackground = func() bool { return dark }
+	t.Cleanup(func() {
+		hasDarkBackground = orig
+		activeTheme = origTheme
+	})
+}
+
+func TestWithTheme(t *testing.T) {
+	palettes := map[string]theme.Palette{
+		"solar": {Base: theme.DarkName, Colors: map[theme.Role]theme.Color{theme.Accent: {TrueColor: "#268bd2", ANSI256: "32", ANSI: "4"}}},
+	}
+
+	tests := []struct {
+		name     string
+		theme    string
+		dark     bool
+		wantName string
+		wantWarn string
+	}{
+		{name: "明るい端末ではlight", theme: theme.Auto, dark: false, wantName: theme.LightName},
+		{name: "暗い端末ではdark", theme: theme.Auto, dark: true, wantName: theme.DarkName},
+		{name: "明示したテーマを優先", theme: theme.DarkName, dark: false, wantName: theme.DarkName},
+		{name: "ユーザーテーマ", theme: "solar", dark: false, wantName: "solar"},
+		{name: "未定義のテーマはautoを使う", theme: "missing", dark: false, wantName: theme.LightName, wantWarn: `警告: テーマ "missing" は定義されていません`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubBackground(t, tt.dark)
+
+			m := NewModel(WithTheme(tt.theme, palettes))
+
+			assert.Equal(t, tt.wantName, activeTheme.Name)
+			output := strings.Join(m.mainView.outputLines, "\n")
+			if tt.wantWarn != "" {
+				assert.Contains(t, output, tt.wantWarn)
+			} else {
+				assert.NotContains(t, output, "警告")
+			}
+		})
+	}
+}
+
+func TestThemed(t *testing.T) {
+	stubBackground(t, true)
+	NewModel(WithTheme(theme.LightName, nil))
+
+	assert.Equal(t, theme.Light().Color(theme.Error), themed(theme.Error).GetForeground())
+}
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/export"
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/mzkmnk/ccforge/internal/theme"
+)
+
+// maxTranscriptRows はトランスクリプトを一度に表示する行数
+const maxTranscriptRows = 16
+
+// transcriptLine はトランスクリプトの表示行
+type transcriptLine struct {
+	text   string // 表示する文字列
+	prompt bool   // プロンプトの行か
+	header bool   // やり取りの区切りの行か
+	match  bool   // 検索語を含むか
+}
+
+// TranscriptDialog は過去のセッションのトランスクリプトを表示するダイアログ
+type TranscriptDialog struct {
+	task      string           // タスク名
+	sessionID string           // セッションID
+	title     string           // タイトル
+	lines     []transcriptLine // 表示行
+	terms     []string         // 強調する検索語
+	offset    int              // 先頭に表示している行
+	cursor    int              // n/N で移動した一致行 (初期値は検索結果の行)
+}
+
+// NewTranscriptDialog は検索結果のトランスクリプトを一致した位置から表示するダイアログを作成する
+func NewTranscriptDialog(hit store.SearchHit, transcript []store.PromptTranscript, terms []string) *TranscriptDialog {
+	d := &TranscriptDialog{
+		task:      hit.TaskName,
+		sessionID: hit.SessionID,
+		title:     fmt.Sprintf("%s セッション %s", hit.TaskName, hit.SessionID),
+		terms:     terms,
+	}
+
+	at := 0
+	for _, pt := range transcript {
+		d.lines = append(d.lines, transcriptLine{text: fmt.Sprintf("── %s ──", pt.Prompt.SentAt.Local().Format("2006-01-02 15:04")), header: true})
+		promptStart := len(d.lines)
+		for _, text := range splitLines(pt.Prompt.Text) {
+			d.lines = append(d.lines, transcriptLine{text: "> " + text, prompt: true})
+		}
+
+		// 応答は断片をつなげてから行に分け、一致した断片の開始行を記録する
+		var output strings.Builder
+		chunkStart := -1
+		for _, chunk := range pt.Chunks {
+			if chunk.ID == hit.ChunkID && hit.ChunkID != 0 {
+				chunkStart = len(d.lines) + strings.Count(output.String(), "\n")
+			}
+			output.WriteString(chunk.Content)
+		}
+		for _, text := range splitLines(output.String()) {
+			d.lines = append(d.lines, transcriptLine{text: text})
+		}
+
+		if pt.Prompt.ID == hit.PromptID {
+			at = promptStart
+			if chunkStart >= 0 {
+				at = chunkStart
+			}
+		}
+	}
+
+	for i := range d.lines {
+		d.lines[i].match = !d.lines[i].header && len(store.MatchPositions(d.lines[i].text, terms)) > 0
+	}
+	// 一致したやり取りの中で最初に検索語を含む行を表示する
+	for i := at; i < len(d.lines) && !d.lines[i].header; i++ {
+		if d.lines[i].match {
+			at = i
+			break
+		}
+	}
+	d.cursor = at
+	d.scrollTo(at - 2)
+	return d
+}
+
+// splitLines は末尾の改行を除いて行に分ける
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// scrollTo は先頭に表示する行を範囲内に収めて設定する
+func (d *TranscriptDialog) scrollTo(offset int) {
+	d.offset = max(min(offset, len(d.lines)-maxTranscriptRows), 0)
+}
+
+// nextMatch は前後の一致した行へ移動する
+func (d *TranscriptDialog) nextMatch(forward bool) {
+	step := -1
+	if forward {
+		step = 1
+	}
+	for i := d.cursor + step; i >= 0 && i < len(d.lines); i += step {
+		if d.lines[i].match {
+			d.cursor = i
+			d.scrollTo(i - 2)
+			return
+		}
+	}
+}
+
+// HandleKey はキー入力を処理する
+func (d *TranscriptDialog) HandleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "q", "enter":
+		return nil, true
+	case "up", "k":
+		d.scrollTo(d.offset - 1)
+	case "down", "j":
+		d.scrollTo(d.offset + 1)
+	case "pgup":
+		d.scrollTo(d.offset - maxTranscriptRows)
+	case "pgdown", " ":
+		d.scrollTo(d.offset + maxTranscriptRows)
+	case "home", "g":
+		d.scrollTo(0)
+	case "end", "G":
+		d.scrollTo(len(d.lines))
+	case "n":
+		d.nextMatch(true)
+	case "N":
+		d.nextMatch(false)
+	case "e":
+		// 表示中のセッションをMarkdownで保存する (ダイアログは閉じない)
+		return msgCmd(exportRequestMsg{task: d.task, sessionID: d.sessionID, format: export.Markdown}), false
+	}
+	return nil, false
+}
+
+// Cancel はキャンセル時の結果を返す (結果は届けない)
+func (d *TranscriptDialog) Cancel() tea.Msg {
+	return nil
+}
+
+// View はダイアログを描画する
+func (d *TranscriptDialog) View(maxWidth int) string {
+	box, title, muted := dialogStyles()
+	matchStyle := themed(theme.Accent).Bold(true)
+	promptStyle := lipgloss.NewStyle().Bold(true)
+	inner := maxWidth - 4
+
+	lines := []string{title.Render(ansi.Truncate(d.title, inner, "…"))}
+	end := min(d.offset+maxTranscriptRows, len(d.lines))
+	for _, line := range d.lines[d.offset:end] {
+		text := ansi.Truncate(line.text, inner, "…")
+		switch {
+		case line.header:
+			lines = append(lines, muted.Render(text))
+		case line.match:
+			rendered := highlightMatches(text, store.MatchPositions(text, d.terms), matchStyle)
+			if line.prompt {
+				rendered = promptStyle.Render(rendered)
+			}
+			lines = append(lines, rendered)
+		case line.prompt:
+			lines = append(lines, promptStyle.Render(text))
+		default:
+			lines = append(lines, text)
+		}
+	}
+
+	lines = append(lines, muted.Render(fmt.Sprintf("%d-%d/%d行  ↑/↓: スクロール  n/N: 次/前の一致  e: 保存  q: 閉じる", min(d.offset+1, end), end, len(d.lines))))
+	return box.Width(maxWidth - 2).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// longTranscript は表示行数を超えるトランスクリプトを作成する
+// 2つ目のプロンプトの応答の途中に検索語を含む
+func longTranscript() []store.PromptTranscript {
+	var first, second strings.Builder
+	for i := range 30 {
+		fmt.Fprintf(&first, "1つ目の出力 %d\n", i)
+	}
+	for i := range 30 {
+		if i == 20 {
+			second.WriteString("ここに目的の語があります\n")
+			continue
+		}
+		fmt.Fprintf(&second, "2つ目の出力 %d\n", i)
+	}
+	return []store.PromptTranscript{
+		{Prompt: store.Prompt{ID: 1, Text: "目的の語を含むプロンプト"}, Chunks: []store.Chunk{{ID: 1, Content: first.String()}}},
+		{Prompt: store.Prompt{ID: 2, Text: "次の質問"}, Chunks: []store.Chunk{{ID: 2, Content: second.String()}}},
+	}
+}
+
+func TestNewTranscriptDialog(t *testing.T) {
+	tests := []struct {
+		name     string
+		hit      store.SearchHit
+		wantLine string
+	}{
+		{name: "応答に一致", hit: store.SearchHit{PromptID: 2, ChunkID: 2}, wantLine: "ここに目的の語があります"},
+		{name: "プロンプトに一致", hit: store.SearchHit{PromptID: 1}, wantLine: "> 目的の語を含むプロンプト"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewTranscriptDialog(tt.hit, longTranscript(), []string{"目的"})
+			assert.Equal(t, tt.wantLine, d.lines[d.cursor].text)
+
+			// 一致した行が見える位置から表示する
+			assert.LessOrEqual(t, d.offset, d.cursor)
+			assert.Less(t, d.cursor, d.offset+maxTranscriptRows)
+			assert.Contains(t, ansi.Strip(d.View(72)), tt.wantLine)
+		})
+	}
+}
+
+func TestTranscriptDialog_HandleKey(t *testing.T) {
+	d := NewTranscriptDialog(store.SearchHit{PromptID: 1}, longTranscript(), []string{"目的"})
+	assert.Equal(t, 0, d.offset)
+
+	// 前の一致がなければ移動しない
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	assert.Equal(t, "> 目的の語を含むプロンプト", d.lines[d.cursor].text)
+
+	// n で次の一致へ移動する
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	assert.Equal(t, "ここに目的の語があります", d.lines[d.cursor].text)
+	assert.Contains(t, ansi.Strip(d.View(72)), "ここに目的の語があります")
+
+	// 末尾を超えてスクロールしない
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyEnd})
+	assert.Equal(t, len(d.lines)-maxTranscriptRows, d.offset)
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, len(d.lines)-maxTranscriptRows, d.offset)
+
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyPgUp})
+	assert.Equal(t, len(d.lines)-2*maxTranscriptRows, d.offset)
+	d.HandleKey(tea.KeyMsg{Type: tea.KeyHome})
+	assert.Equal(t, 0, d.offset)
+
+	_, closed := d.HandleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	assert.True(t, closed)
+}
+
+func TestTranscriptDialog_ShortTranscript(t *testing.T) {
+	d := NewTranscriptDialog(store.SearchHit{TaskName: "auth", SessionID: "s1", PromptID: 1, ChunkID: 2}, testTranscript, []string{"公開鍵"})
+	require.Equal(t, "署名を公開鍵で検証します", d.lines[d.cursor].text)
+	assert.Equal(t, 0, d.offset)
+
+	view := ansi.Strip(d.View(60))
+	assert.Contains(t, view, "auth セッション s1")
+	// 断片の境界は行の区切りにしない
+	assert.Contains(t, view, "まずヘッダーを読みます")
+}
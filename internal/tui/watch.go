@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/mzkmnk/ccforge/internal/theme"
+	"github.com/mzkmnk/ccforge/internal/watch"
+)
+
+// SpecChangedMsg はタスクのSpecsファイルが変更されたことを通知するメッセージ
+type SpecChangedMsg struct {
+	Task string // タスク名
+	File string // Specsファイル名
+}
+
+// TaskAddedMsg はタスクが追加されたことを通知するメッセージ
+type TaskAddedMsg struct {
+	Task string // タスク名
+}
+
+// TaskRemovedMsg はタスクが削除またはアーカイブされたことを通知するメッセージ
+type TaskRemovedMsg struct {
+	Task string // タスク名
+}
+
+// watchEventsMsg は監視から届いた変更のまとまり
+type watchEventsMsg struct {
+	events []watch.Event
+}
+
+// taskProgressMsg はアクティブなタスクの進捗の取得結果
+type taskProgressMsg struct {
+	task     string
+	progress tasks.Progress
+	err      error
+}
+
+// WithWatcher はタスクのディレクトリの監視を設定する
+// 届いた変更は SpecChangedMsg などのメッセージとして処理する
+func WithWatcher(w watch.Watcher) Option {
+	return func(m *Model) {
+		m.watcher = w
+	}
+}
+
+// waitForWatch は次の変更のまとまりを待つコマンドを返す
+// 監視が終了した場合は何も返さない
+func waitForWatch(w watch.Watcher) tea.Cmd {
+	if w == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		events, ok := <-w.Events()
+		if !ok {
+			return nil
+		}
+		return watchEventsMsg{events: events}
+	}
+}
+
+// handleWatchEvents は変更のまとまりを種類ごとのメッセージに変換し、次の変更を待つ
+func (m *Model) handleWatchEvents(msg watchEventsMsg) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(msg.events)+1)
+	for _, ev := range msg.events {
+		switch ev.Kind {
+		case watch.SpecChanged:
+			cmds = append(cmds, msgCmd(SpecChangedMsg{Task: ev.Task, File: ev.File}))
+		case watch.TaskAdded:
+			cmds = append(cmds, msgCmd(TaskAddedMsg{Task: ev.Task}))
+		case watch.TaskRemoved:
+			cmds = append(cmds, msgCmd(TaskRemovedMsg{Task: ev.Task}))
+		}
+	}
+	cmds = append(cmds, waitForWatch(m.watcher))
+	return tea.Batch(cmds...)
+}
+
+// handleSpecChanged はSpecsの変更をタスクパネルとステータスバーに反映する
+func (m *Model) handleSpecChanged(msg SpecChangedMsg) tea.Cmd {
+	slog.Debug("Specsが変更されました", "component", "tui", "task", msg.Task, "file", msg.File)
+	var cmds []tea.Cmd
+	if m.tasksView.IsVisible() {
+		cmds = append(cmds, m.tasksView.Reload())
+	}
+	if msg.Task == m.statusBar.activeTask && msg.File == tasks.TasksFile {
+		cmds = append(cmds, m.loadTaskProgress())
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleTaskAdded はタスクの追加を表示する
+func (m *Model) handleTaskAdded(msg TaskAddedMsg) tea.Cmd {
+	m.mainView.AddOutput(themed(theme.Muted).Render(fmt.Sprintf("タスク %s が追加されました", msg.Task)))
+	if m.tasksView.IsVisible() {
+		return m.tasksView.Reload()
+	}
+	return nil
+}
+
+// handleTaskRemoved はタスクの削除を表示する
+func (m *Model) handleTaskRemoved(msg TaskRemovedMsg) tea.Cmd {
+	if msg.Task == m.statusBar.activeTask {
+		m.mainView.AddOutput(themed(theme.Warning).Render(fmt.Sprintf("! アクティブなタスク %s が削除またはアーカイブされました", msg.Task)))
+		m.statusBar.SetProgress(tasks.Progress{})
+	} else {
+		m.mainView.AddOutput(themed(theme.Muted).Render(fmt.Sprintf("タスク %s が削除されました", msg.Task)))
+	}
+	if m.tasksView.IsVisible() {
+		return m.tasksView.Reload()
+	}
+	return nil
+}
+
+// loadTaskProgress はアクティブなタスクの進捗を読み込むコマンドを返す
+func (m *Model) loadTaskProgress() tea.Cmd {
+	name := m.statusBar.activeTask
+	if name == "" {
+		return nil
+	}
+	manager := tasks.NewManager(m.workDir)
+	return func() tea.Msg {
+		task, err := manager.Get(name)
+		if err != nil {
+			return taskProgressMsg{task: name, err: err}
+		}
+		return taskProgressMsg{task: name, progress: task.Progress}
+	}
+}
+
+// handleTaskProgress はアクティブなタスクの進捗をステータスバーに反映する
+func (m *Model) handleTaskProgress(msg taskProgressMsg) {
+	if msg.task != m.statusBar.activeTask {
+		return
+	}
+	if msg.err != nil {
+		slog.Debug("タスクの進捗を取得できませんでした", "component", "tui", "task", msg.task, "err", msg.err)
+		m.statusBar.SetProgress(tasks.Progress{})
+		return
+	}
+	m.statusBar.SetProgress(msg.progress)
+}
+
+// toggleTasksView はタスクパネルの表示を切り替える
+func (m *Model) toggleTasksView() tea.Cmd {
+	if m.tasksView.IsVisible() {
+		m.tasksView.Hide()
+		return nil
+	}
+	m.hidePanels()
+	return m.tasksView.Show(m.workDir)
+}
+
+// hidePanels は表示中のサイドパネルを閉じる
+// サイドパネルは同時に1つだけ表示する
+func (m *Model) hidePanels() {
+	m.diffView.Hide()
+	m.tasksView.Hide()
+	m.changesView.Hide()
+	m.reviewView.Hide()
+}
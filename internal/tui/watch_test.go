@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/mzkmnk/ccforge/internal/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runBatch はコマンドを実行し、まとめられたコマンドを展開してメッセージを返す
+func runBatch(t *testing.T, cmd tea.Cmd) []tea.Msg {
+	t.Helper()
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, runBatch(t, c)...)
+	}
+	return msgs
+}
+
+func TestModel_WatchEvents(t *testing.T) {
+	fake := watch.NewFake()
+	m := NewModel(WithWatcher(fake))
+
+	fake.Send(
+		watch.Event{Kind: watch.TaskAdded, Task: "billing"},
+		watch.Event{Kind: watch.SpecChanged, Task: "auth", File: tasks.DesignFile},
+	)
+	msgs := runBatch(t, m.Init())
+	require.Equal(t, []tea.Msg{watchEventsMsg{events: []watch.Event{
+		{Kind: watch.TaskAdded, Task: "billing"},
+		{Kind: watch.SpecChanged, Task: "auth", File: tasks.DesignFile},
+	}}}, msgs)
+
+	// 種類ごとのメッセージに変換し、次の変更を待つ
+	fake.Send(watch.Event{Kind: watch.TaskRemoved, Task: "auth"})
+	updated, cmd := m.Update(msgs[0])
+	m = updated.(Model)
+	assert.Equal(t, []tea.Msg{
+		TaskAddedMsg{Task: "billing"},
+		SpecChangedMsg{Task: "auth", File: tasks.DesignFile},
+		watchEventsMsg{events: []watch.Event{{Kind: watch.TaskRemoved, Task: "auth"}}},
+	}, runBatch(t, cmd))
+
+	// 監視が終了したら待つのをやめる
+	require.NoError(t, fake.Close())
+	assert.Equal(t, []tea.Msg{nil}, runBatch(t, waitForWatch(fake)))
+}
+
+func TestModel_TaskAddedAndRemoved(t *testing.T) {
+	tests := []struct {
+		name   string
+		active string
+		msg    tea.Msg
+		want   string
+	}{
+		{name: "追加", msg: TaskAddedMsg{Task: "billing"}, want: "タスク billing が追加されました"},
+		{name: "削除", active: "auth", msg: TaskRemovedMsg{Task: "billing"}, want: "タスク billing が削除されました"},
+		{name: "アクティブなタスクの削除", active: "auth", msg: TaskRemovedMsg{Task: "auth"}, want: "! アクティブなタスク auth が削除またはアーカイブされました"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			m.statusBar.SetActiveTask(tt.active)
+			m, _ = update(t, m, tt.msg)
+			assert.Contains(t, ansi.Strip(strings.Join(m.mainView.outputLines, "\n")), tt.want)
+		})
+	}
+}
+
+func TestModel_SpecChangedReloadsPanelAndProgress(t *testing.T) {
+	root := newTasksProject(t)
+	m := NewModel(WithWorkDir(root), WithActiveTask("auth"))
+
+	// 起動時にアクティブなタスクの進捗を表示する
+	m, _ = update(t, m, runBatch(t, m.Init())[0])
+	assert.Contains(t, m.statusBar.getTaskText(), "auth (0/4)")
+
+	m, msg := update(t, m, tea.KeyMsg{Type: tea.KeyCtrlT})
+	m, msg = update(t, m, msg)
+	m, _ = update(t, m, msg)
+
+	// tasks.md の変更でパネルの進捗とステータスバーを更新する
+	path := filepath.Join(root, "ccforge", "auth", tasks.TasksFile)
+	require.NoError(t, os.WriteFile(path, []byte("- [x] 実装する\n- [ ] テストする\n"), 0o644))
+	updated, cmd := m.Update(SpecChangedMsg{Task: "auth", File: tasks.TasksFile})
+	m = updated.(Model)
+	for _, msg := range runBatch(t, cmd) {
+		m, msg = update(t, m, msg)
+		if msg != nil {
+			m, _ = update(t, m, msg)
+		}
+	}
+
+	assert.Contains(t, m.statusBar.getTaskText(), "auth (1/2)")
+	assert.Equal(t, tasks.Progress{Done: 1, Total: 2}, m.tasksView.tasks[0].Progress)
+	assert.Contains(t, ansi.Strip(m.tasksView.View()), "1/2  50%")
+}
@@ -0,0 +1,65 @@
+package watch
+
+import "sync"
+
+// Fake はテスト用の Watcher
+// Send で渡したイベントをそのまま1つのまとまりとして届ける
+type Fake struct {
+	events chan []Event
+	once   sync.Once
+}
+
+// NewFake は新しい Fake を作成する
+func NewFake() *Fake {
+	return &Fake{events: make(chan []Event, 16)}
+}
+
+// Send はイベントのまとまりを届ける
+func (f *Fake) Send(events ...Event) {
+	f.events <- events
+}
+
+// Events は変更のまとまりを届けるチャネルを返す
+func (f *Fake) Events() <-chan []Event {
+	return f.events
+}
+
+// Close はチャネルを閉じる
+func (f *Fake) Close() error {
+	f.once.Do(func() { close(f.events) })
+	return nil
+}
+
+// FakeFeed はテスト用の Feed
+// Send で渡した変更をそのまま1つのまとまりとして届ける
+type FakeFeed struct {
+	changes chan []Change
+	mode    Mode
+	once    sync.Once
+}
+
+// NewFakeFeed は指定した監視の方式の FakeFeed を作成する
+func NewFakeFeed(mode Mode) *FakeFeed {
+	return &FakeFeed{changes: make(chan []Change, 16), mode: mode}
+}
+
+// Send は変更のまとまりを届ける
+func (f *FakeFeed) Send(changes ...Change) {
+	f.changes <- changes
+}
+
+// Changes は変更のまとまりを届けるチャネルを返す
+func (f *FakeFeed) Changes() <-chan []Change {
+	return f.changes
+}
+
+// Mode は監視の方式を返す
+func (f *FakeFeed) Mode() Mode {
+	return f.mode
+}
+
+// Close はチャネルを閉じる
+func (f *FakeFeed) Close() error {
+	f.once.Do(func() { close(f.changes) })
+	return nil
+}
@@ -0,0 +1,497 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// DefaultMaxWatches はfsnotifyで監視する既定の最大ディレクトリ数
+	DefaultMaxWatches = 8192
+	// DefaultPollInterval はポーリングの既定の間隔
+	DefaultPollInterval = 2 * time.Second
+)
+
+// Op はファイルの変更の種類
+type Op int
+
+const (
+	// Created はファイルの作成
+	Created Op = iota + 1
+	// Modified はファイルの変更
+	Modified
+	// Deleted はファイルの削除
+	Deleted
+)
+
+// String は変更の種類の名前を返す
+func (o Op) String() string {
+	switch o {
+	case Created:
+		return "created"
+	case Modified:
+		return "modified"
+	case Deleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// Change はプロジェクトのファイルの変更
+type Change struct {
+	Time time.Time // 検出した日時
+	Path string    // ルートからの相対パス (区切りは /)
+	Op   Op        // 種類
+}
+
+// Mode はプロジェクトの監視の方式
+type Mode int32
+
+const (
+	// ModeNotify はfsnotifyによる監視
+	ModeNotify Mode = iota
+	// ModePoll は一定間隔でディレクトリを走査する監視
+	ModePoll
+)
+
+// String は監視の方式の名前を返す
+func (m Mode) String() string {
+	if m == ModePoll {
+		return "polling"
+	}
+	return "fsnotify"
+}
+
+// Feed はプロジェクトのファイルの変更を届ける
+// 実装は ProjectWatcher で、テストでは FakeFeed に差し替える
+type Feed interface {
+	// Changes は変更のまとまりを届けるチャネルを返す (Close で閉じられる)
+	Changes() <-chan []Change
+	// Mode は現在の監視の方式を返す
+	Mode() Mode
+	// Close は監視を終了する
+	Close() error
+}
+
+// FeedOptions はプロジェクトの監視の設定
+type FeedOptions struct {
+	Ignore       []string      // .gitignore に加えて除外するパターン
+	MaxWatches   int           // fsnotifyで監視する最大ディレクトリ数 (超える場合はポーリングする)
+	PollInterval time.Duration // ポーリングの間隔
+	Debounce     time.Duration // 最後の変更から通知するまでの待ち時間
+}
+
+// fileState はファイルの変更の判定に使う情報
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// ProjectWatcher はプロジェクト全体のファイルの変更を監視する
+// .gitignore と追加のパターンで除外したパスは監視しない
+// ディレクトリが多すぎる場合やinotifyの監視数が尽きた場合はポーリングに切り替える
+type ProjectWatcher struct {
+	root    string
+	opts    FeedOptions
+	changes chan []Change
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	mode    atomic.Int32
+
+	// 以下は監視のゴルーチンだけが使う
+	ignore  *Ignore
+	fsw     *fsnotify.Watcher    // ポーリング中はnil
+	files   map[string]fileState // 存在するファイル
+	pending map[string]bool      // 確認を待っているパス
+}
+
+// WatchProject はプロジェクトの監視を開始する
+// 設定が0以下の項目は既定値を使う
+func WatchProject(root string, opts FeedOptions) (*ProjectWatcher, error) {
+	if opts.MaxWatches <= 0 {
+		opts.MaxWatches = DefaultMaxWatches
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+
+	ignore, err := NewIgnore(root, opts.Ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ProjectWatcher{
+		root:    filepath.Clean(root),
+		opts:    opts,
+		changes: make(chan []Change),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		ignore:  ignore,
+		files:   make(map[string]fileState),
+		pending: make(map[string]bool),
+	}
+
+	dirs, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) > opts.MaxWatches {
+		slog.Info("ディレクトリが多いためポーリングで監視します", "component", "watch", "dirs", len(dirs), "max_watches", opts.MaxWatches)
+		w.mode.Store(int32(ModePoll))
+	} else if err := w.startNotify(dirs); err != nil {
+		slog.Warn("fsnotifyで監視できないためポーリングで監視します", "component", "watch", "err", err)
+		w.mode.Store(int32(ModePoll))
+	}
+
+	go w.loop()
+	slog.Info("プロジェクトの監視を開始しました", "component", "watch", "root", w.root, "mode", w.Mode(), "dirs", len(dirs), "files", len(w.files))
+	return w, nil
+}
+
+// Changes は変更のまとまりを届けるチャネルを返す
+func (w *ProjectWatcher) Changes() <-chan []Change {
+	return w.changes
+}
+
+// Mode は現在の監視の方式を返す
+func (w *ProjectWatcher) Mode() Mode {
+	return Mode(w.mode.Load())
+}
+
+// Close は監視を終了し、Changes のチャネルを閉じる
+func (w *ProjectWatcher) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+		<-w.stopped
+		close(w.changes)
+	})
+	return nil
+}
+
+// startNotify はfsnotifyでディレクトリを監視する
+func (w *ProjectWatcher) startNotify(dirs []string) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("ファイルの監視の開始に失敗しました: %w", err)
+	}
+	for _, dir := range dirs {
+		if err := fsw.Add(w.abs(dir)); err != nil {
+			fsw.Close()
+			return fmt.Errorf("%s の監視に失敗しました: %w", w.abs(dir), err)
+		}
+	}
+	w.fsw = fsw
+	return nil
+}
+
+// fallbackToPoll はfsnotifyの監視をやめてポーリングに切り替える
+func (w *ProjectWatcher) fallbackToPoll(reason error) {
+	slog.Warn("ポーリングでの監視に切り替えます", "component", "watch", "err", reason)
+	if w.fsw != nil {
+		w.fsw.Close()
+		w.fsw = nil
+	}
+	w.mode.Store(int32(ModePoll))
+}
+
+// loop は変更を受け取り、まとめて通知する
+func (w *ProjectWatcher) loop() {
+	defer close(w.stopped)
+	defer func() {
+		if w.fsw != nil {
+			w.fsw.Close()
+		}
+	}()
+
+	debounce := time.NewTimer(w.opts.Debounce)
+	debounce.Stop()
+	poll := time.NewTicker(w.opts.PollInterval)
+	defer poll.Stop()
+
+	for {
+		var events chan fsnotify.Event
+		var errs chan error
+		var tick <-chan time.Time
+		if w.fsw != nil {
+			events, errs = w.fsw.Events, w.fsw.Errors
+		} else {
+			tick = poll.C
+		}
+
+		var changes []Change
+		select {
+		case <-w.done:
+			debounce.Stop()
+			return
+		case ev, ok := <-events:
+			if !ok {
+				w.fallbackToPoll(errors.New("fsnotifyのチャネルが閉じられました"))
+				continue
+			}
+			if w.handle(ev) {
+				debounce.Reset(w.opts.Debounce)
+			}
+			continue
+		case err, ok := <-errs:
+			if !ok {
+				w.fallbackToPoll(errors.New("fsnotifyのチャネルが閉じられました"))
+				continue
+			}
+			if isWatchLimit(err) {
+				w.fallbackToPoll(err)
+				continue
+			}
+			slog.Warn("ファイルの監視でエラーが発生しました", "component", "watch", "err", err)
+			continue
+		case <-debounce.C:
+			changes = w.flush()
+		case <-tick:
+			changes = w.poll()
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+		select {
+		case w.changes <- changes:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// handle はfsnotifyのイベントを記録し、通知の対象になるかを返す
+func (w *ProjectWatcher) handle(ev fsnotify.Event) bool {
+	if ev.Op == fsnotify.Chmod {
+		return false
+	}
+	rel, err := filepath.Rel(w.root, ev.Name)
+	rel = filepath.ToSlash(rel)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+	if path.Base(rel) == gitignoreFile {
+		dir := path.Dir(rel)
+		if dir == "." {
+			dir = ""
+		}
+		if err := w.ignore.LoadDir(dir); err != nil {
+			slog.Warn(".gitignoreを読み込めませんでした", "component", "watch", "path", rel, "err", err)
+		}
+	}
+	w.pending[rel] = true
+	return true
+}
+
+// flush は確認を待っているパスの変更を判定する
+func (w *ProjectWatcher) flush() []Change {
+	paths := make([]string, 0, len(w.pending))
+	for rel := range w.pending {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	w.pending = make(map[string]bool)
+
+	var changes []Change
+	for _, rel := range paths {
+		changes = append(changes, w.check(rel)...)
+	}
+	return dedupe(changes)
+}
+
+// poll はプロジェクト全体を走査して変更を判定する
+func (w *ProjectWatcher) poll() []Change {
+	changes, err := w.diff("")
+	if err != nil {
+		slog.Warn("プロジェクトの走査に失敗しました", "component", "watch", "err", err)
+	}
+	return changes
+}
+
+// check はパスの現在の状態と記録を比べて変更を判定する
+// ディレクトリの場合は中のファイルも判定し、新しいディレクトリを監視に加える
+func (w *ProjectWatcher) check(rel string) []Change {
+	info, err := os.Lstat(w.abs(rel))
+	switch {
+	case err != nil:
+		return w.forget(rel)
+	case info.IsDir():
+		changes, err := w.diff(rel)
+		if err != nil {
+			slog.Warn("ディレクトリの走査に失敗しました", "component", "watch", "path", rel, "err", err)
+		}
+		return changes
+	case w.ignore.Match(rel, false):
+		return nil
+	default:
+		return w.compare(rel, info)
+	}
+}
+
+// diff はディレクトリ以下を走査して記録との差分を返す
+// 走査中に見つけたディレクトリはfsnotifyの監視に加える
+func (w *ProjectWatcher) diff(dir string) ([]Change, error) {
+	if dir != "" && w.ignore.Match(dir, true) {
+		return w.forget(dir), nil
+	}
+
+	seen := make(map[string]bool)
+	var changes []Change
+	dirs, err := w.walk(dir, func(rel string, info fs.FileInfo) {
+		seen[rel] = true
+		changes = append(changes, w.compare(rel, info)...)
+	})
+	for _, d := range dirs {
+		if w.fsw == nil {
+			break
+		}
+		if err := w.fsw.Add(w.abs(d)); err != nil {
+			if isWatchLimit(err) {
+				w.fallbackToPoll(err)
+				break
+			}
+			slog.Warn("ディレクトリを監視に加えられませんでした", "component", "watch", "path", d, "err", err)
+		}
+	}
+
+	now := time.Now()
+	for rel := range w.files {
+		if (dir == "" || strings.HasPrefix(rel, dir+"/")) && !seen[rel] {
+			delete(w.files, rel)
+			changes = append(changes, Change{Time: now, Path: rel, Op: Deleted})
+		}
+	}
+	sortChanges(changes)
+	return changes, err
+}
+
+// scan は起動時のファイルを変更として扱わずに記録し、監視するディレクトリを返す
+func (w *ProjectWatcher) scan() ([]string, error) {
+	return w.walk("", func(rel string, info fs.FileInfo) {
+		w.files[rel] = fileState{size: info.Size(), modTime: info.ModTime()}
+	})
+}
+
+// walk は除外されないファイルとディレクトリをたどる
+// 各ディレクトリの .gitignore は中を走査する前に読み込む
+func (w *ProjectWatcher) walk(dir string, visit func(rel string, info fs.FileInfo)) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(w.abs(dir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(w.root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			rel = ""
+		}
+
+		if d.IsDir() {
+			if rel != "" && w.ignore.Match(rel, true) {
+				return filepath.SkipDir
+			}
+			if err := w.ignore.LoadDir(rel); err != nil {
+				slog.Warn(".gitignoreを読み込めませんでした", "component", "watch", "dir", rel, "err", err)
+			}
+			dirs = append(dirs, rel)
+			return nil
+		}
+		if !d.Type().IsRegular() || w.ignore.Match(rel, false) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		visit(rel, info)
+		return nil
+	})
+	if err != nil {
+		return dirs, fmt.Errorf("%s の走査に失敗しました: %w", w.abs(dir), err)
+	}
+	return dirs, nil
+}
+
+// compare はファイルの状態を記録と比べ、変更があれば記録を更新して返す
+func (w *ProjectWatcher) compare(rel string, info fs.FileInfo) []Change {
+	state := fileState{size: info.Size(), modTime: info.ModTime()}
+	prev, known := w.files[rel]
+	w.files[rel] = state
+	switch {
+	case !known:
+		return []Change{{Time: time.Now(), Path: rel, Op: Created}}
+	case prev != state:
+		return []Change{{Time: time.Now(), Path: rel, Op: Modified}}
+	default:
+		return nil
+	}
+}
+
+// forget はファイルまたはディレクトリ以下のファイルを記録から消し、削除として返す
+func (w *ProjectWatcher) forget(rel string) []Change {
+	var changes []Change
+	now := time.Now()
+	for file := range w.files {
+		if file == rel || strings.HasPrefix(file, rel+"/") {
+			delete(w.files, file)
+			changes = append(changes, Change{Time: now, Path: file, Op: Deleted})
+		}
+	}
+	sortChanges(changes)
+	return changes
+}
+
+// abs はルートからの相対パスを絶対パスに変換する
+func (w *ProjectWatcher) abs(rel string) string {
+	return filepath.Join(w.root, filepath.FromSlash(rel))
+}
+
+// isWatchLimit はinotifyの監視数やファイルディスクリプタが尽きたエラーかを判定する
+func isWatchLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
+// sortChanges は変更をパスの順に並べる
+func sortChanges(changes []Change) {
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+}
+
+// dedupe は同じパスの変更を1つにまとめる (後の変更を優先する)
+func dedupe(changes []Change) []Change {
+	index := make(map[string]int, len(changes))
+	var out []Change
+	for _, c := range changes {
+		if i, ok := index[c.Path]; ok {
+			out[i] = c
+			continue
+		}
+		index[c.Path] = len(out)
+		out = append(out, c)
+	}
+	sortChanges(out)
+	return out
+}
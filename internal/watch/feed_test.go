@@ -0,0 +1,145 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProject は main.go と .gitignore のあるプロジェクトを作成する
+func newTestProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".gitignore"), "node_modules/\n*.log\n")
+	writeFile(t, filepath.Join(root, "main.go"), "package main\n")
+	writeFile(t, filepath.Join(root, "internal", "app", "app.go"), "package app\n")
+	return root
+}
+
+// watchTestProject はテスト用の設定でプロジェクトを監視する
+func watchTestProject(t *testing.T, root string, opts FeedOptions) *ProjectWatcher {
+	t.Helper()
+	opts.Debounce = testDebounce
+	if opts.PollInterval == 0 {
+		opts.PollInterval = 50 * time.Millisecond
+	}
+	w, err := WatchProject(root, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+// receiveChanges は次の変更のまとまりを待ち、日時を除いて返す
+func receiveChanges(t *testing.T, f Feed) []Change {
+	t.Helper()
+	select {
+	case changes := <-f.Changes():
+		for i := range changes {
+			assert.False(t, changes[i].Time.IsZero())
+			changes[i].Time = time.Time{}
+		}
+		return changes
+	case <-time.After(5 * time.Second):
+		t.Fatal("変更が届きませんでした")
+		return nil
+	}
+}
+
+// collectChanges は変更がn件届くまで待ち、パスの順に並べて返す
+// ポーリングでは書き込みの途中で走査することがあるため、複数のまとまりを合わせる
+func collectChanges(t *testing.T, f Feed, n int) []Change {
+	t.Helper()
+	var changes []Change
+	for len(changes) < n {
+		changes = append(changes, receiveChanges(t, f)...)
+	}
+	sortChanges(changes)
+	return changes
+}
+
+func TestProjectWatcher(t *testing.T) {
+	modes := []struct {
+		name string
+		opts FeedOptions
+		want Mode
+	}{
+		{name: "fsnotify", want: ModeNotify},
+		{name: "ディレクトリが多い場合はポーリング", opts: FeedOptions{MaxWatches: 1}, want: ModePoll},
+	}
+
+	for _, mode := range modes {
+		t.Run(mode.name, func(t *testing.T) {
+			root := newTestProject(t)
+			w := watchTestProject(t, root, mode.opts)
+			assert.Equal(t, mode.want, w.Mode())
+
+			// 起動時にあったファイルは変更として扱わない
+			writeFile(t, filepath.Join(root, "main.go"), "package main\n\nfunc main() {}\n")
+			writeFile(t, filepath.Join(root, "internal", "app", "new.go"), "package app\n")
+			writeFile(t, filepath.Join(root, "debug.log"), "x")
+			writeFile(t, filepath.Join(root, "node_modules", "lib", "index.js"), "x")
+			assert.Equal(t, []Change{
+				{Path: "internal/app/new.go", Op: Created},
+				{Path: "main.go", Op: Modified},
+			}, collectChanges(t, w, 2))
+
+			// 新しいディレクトリの中のファイルも作成として扱う
+			writeFile(t, filepath.Join(root, "pkg", "util", "util.go"), "package util\n")
+			assert.Equal(t, []Change{{Path: "pkg/util/util.go", Op: Created}}, collectChanges(t, w, 1))
+
+			// ディレクトリの削除は中のファイルの削除として扱う
+			require.NoError(t, os.RemoveAll(filepath.Join(root, "internal")))
+			assert.Equal(t, []Change{
+				{Path: "internal/app/app.go", Op: Deleted},
+				{Path: "internal/app/new.go", Op: Deleted},
+			}, collectChanges(t, w, 2))
+		})
+	}
+}
+
+func TestProjectWatcher_GitignoreReload(t *testing.T) {
+	root := newTestProject(t)
+	w := watchTestProject(t, root, FeedOptions{Ignore: []string{"*.tmp"}})
+
+	writeFile(t, filepath.Join(root, "a.tmp"), "x")
+	writeFile(t, filepath.Join(root, ".gitignore"), "node_modules/\n*.log\ngen/\n")
+	assert.Equal(t, []Change{{Path: ".gitignore", Op: Modified}}, receiveChanges(t, w))
+
+	writeFile(t, filepath.Join(root, "gen", "out.go"), "package gen\n")
+	writeFile(t, filepath.Join(root, "keep.go"), "package main\n")
+	assert.Equal(t, []Change{{Path: "keep.go", Op: Created}}, receiveChanges(t, w))
+}
+
+func TestProjectWatcher_Close(t *testing.T) {
+	w := watchTestProject(t, newTestProject(t), FeedOptions{})
+	require.NoError(t, w.Close())
+	_, ok := <-w.Changes()
+	assert.False(t, ok)
+	assert.NoError(t, w.Close())
+}
+
+func TestDedupe(t *testing.T) {
+	changes := dedupe([]Change{
+		{Path: "b.go", Op: Created},
+		{Path: "a.go", Op: Modified},
+		{Path: "b.go", Op: Modified},
+	})
+	assert.Equal(t, []Change{{Path: "a.go", Op: Modified}, {Path: "b.go", Op: Modified}}, changes)
+}
+
+func TestFakeFeed(t *testing.T) {
+	f := NewFakeFeed(ModePoll)
+	var feed Feed = f
+	assert.Equal(t, ModePoll, feed.Mode())
+
+	f.Send(Change{Path: "main.go", Op: Modified})
+	assert.Equal(t, []Change{{Path: "main.go", Op: Modified}}, <-feed.Changes())
+
+	require.NoError(t, feed.Close())
+	_, ok := <-feed.Changes()
+	assert.False(t, ok)
+}
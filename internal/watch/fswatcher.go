@@ -0,0 +1,272 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce は変更をまとめる既定の待ち時間
+const DefaultDebounce = 200 * time.Millisecond
+
+// FSWatcher はfsnotifyでタスクのディレクトリを再帰的に監視する
+type FSWatcher struct {
+	root     string        // 監視するディレクトリ (ccforge/)
+	debounce time.Duration // 最後の変更から通知するまでの待ち時間
+	fsw      *fsnotify.Watcher
+	events   chan []Event
+	done     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+
+	// 以下は監視のゴルーチンだけが使う
+	known   map[string]bool // 存在するタスク
+	pending pending         // 通知を待っている変更
+}
+
+// pending は通知を待っている変更
+type pending struct {
+	tasks map[string]bool    // 追加または削除された可能性のあるタスク
+	specs map[[2]string]bool // 変更されたSpecsファイル (タスク名, ファイル名)
+}
+
+// New はディレクトリの監視を開始する
+// ディレクトリがまだない場合は親ディレクトリを監視し、作成されたら監視に加える
+// debounceが0以下の場合は DefaultDebounce を使う
+func New(root string, debounce time.Duration) (*FSWatcher, error) {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("ファイルの監視の開始に失敗しました: %w", err)
+	}
+
+	w := &FSWatcher{
+		root:     filepath.Clean(root),
+		debounce: debounce,
+		fsw:      fsw,
+		events:   make(chan []Event),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		known:    listTasks(root),
+		pending:  newPending(),
+	}
+	if err := w.watchRoot(); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	go w.loop()
+	slog.Info("タスクの監視を開始しました", "component", "watch", "root", w.root, "tasks", len(w.known))
+	return w, nil
+}
+
+// newPending は空の pending を作成する
+func newPending() pending {
+	return pending{tasks: make(map[string]bool), specs: make(map[[2]string]bool)}
+}
+
+// Events は変更のまとまりを届けるチャネルを返す
+func (w *FSWatcher) Events() <-chan []Event {
+	return w.events
+}
+
+// Close は監視を終了し、Events のチャネルを閉じる
+func (w *FSWatcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.fsw.Close()
+		<-w.stopped
+		close(w.events)
+	})
+	return err
+}
+
+// watchRoot はルート以下を監視に加える
+// ルートがない場合は作成を検知するために親ディレクトリを監視する
+func (w *FSWatcher) watchRoot() error {
+	err := w.addTree(w.root)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = w.fsw.Add(filepath.Dir(w.root))
+	}
+	if err != nil {
+		return fmt.Errorf("%s の監視に失敗しました: %w", w.root, err)
+	}
+	return nil
+}
+
+// addTree はディレクトリとその下のディレクトリを監視に加える
+// "." で始まるディレクトリ (アーカイブなど) は監視しない
+func (w *FSWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != w.root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return w.fsw.Add(path)
+	})
+}
+
+// loop はファイルの変更を受け取り、debounce の間に続いた変更をまとめて通知する
+func (w *FSWatcher) loop() {
+	defer close(w.stopped)
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			timer.Stop()
+			return
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if w.handle(ev) {
+				timer.Reset(w.debounce)
+			}
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("ファイルの監視でエラーが発生しました", "component", "watch", "err", err)
+		case <-timer.C:
+			events := w.flush()
+			if len(events) == 0 {
+				continue
+			}
+			select {
+			case w.events <- events:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// handle はファイルの変更を記録し、通知の対象になるかを返す
+func (w *FSWatcher) handle(ev fsnotify.Event) bool {
+	if ev.Op == fsnotify.Chmod {
+		return false
+	}
+
+	rel, err := filepath.Rel(w.root, ev.Name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	if rel == "." {
+		// ルート自体の作成または削除
+		if ev.Has(fsnotify.Create) {
+			if err := w.addTree(w.root); err != nil {
+				slog.Warn("ディレクトリを監視に加えられませんでした", "component", "watch", "path", w.root, "err", err)
+			}
+		} else if err := w.fsw.Add(filepath.Dir(w.root)); err != nil {
+			slog.Warn("親ディレクトリを監視に加えられませんでした", "component", "watch", "path", w.root, "err", err)
+		}
+		for name := range listTasks(w.root) {
+			w.pending.tasks[name] = true
+		}
+		for name := range w.known {
+			w.pending.tasks[name] = true
+		}
+		return true
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	if strings.HasPrefix(parts[0], ".") {
+		return false
+	}
+
+	// 新しいディレクトリは中身ごと監視に加える
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			if err := w.addTree(ev.Name); err != nil {
+				slog.Warn("ディレクトリを監視に加えられませんでした", "component", "watch", "path", ev.Name, "err", err)
+			}
+		}
+	}
+
+	switch {
+	case len(parts) == 1:
+		// ルート直下のファイル (設定やデータベース) は pending の確認時に除外する
+		w.pending.tasks[parts[0]] = true
+		return true
+	case len(parts) == 2 && isSpecFile(parts[1]):
+		w.pending.specs[[2]string{parts[0], parts[1]}] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// flush は待っている変更をイベントにする
+// タスクの追加・削除は通知の時点でディレクトリがあるかで判定する
+func (w *FSWatcher) flush() []Event {
+	var events []Event
+	added := make(map[string]bool)
+
+	for _, name := range sortedKeys(w.pending.tasks) {
+		exists := isTaskDir(filepath.Join(w.root, name))
+		switch {
+		case exists && !w.known[name]:
+			w.known[name] = true
+			added[name] = true
+			events = append(events, Event{Kind: TaskAdded, Task: name})
+		case !exists && w.known[name]:
+			delete(w.known, name)
+			events = append(events, Event{Kind: TaskRemoved, Task: name})
+		}
+	}
+
+	specs := make([][2]string, 0, len(w.pending.specs))
+	for key := range w.pending.specs {
+		specs = append(specs, key)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		if specs[i][0] != specs[j][0] {
+			return specs[i][0] < specs[j][0]
+		}
+		return specs[i][1] < specs[j][1]
+	})
+	for _, key := range specs {
+		// 追加されたタスクのSpecsは追加のイベントに含める
+		if !w.known[key[0]] || added[key[0]] {
+			continue
+		}
+		events = append(events, Event{Kind: SpecChanged, Task: key[0], File: key[1]})
+	}
+
+	w.pending = newPending()
+	if len(events) > 0 {
+		slog.Debug("タスクの変更を通知します", "component", "watch", "events", len(events))
+	}
+	return events
+}
+
+// sortedKeys はマップのキーを昇順に返す
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
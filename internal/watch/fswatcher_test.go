@@ -0,0 +1,142 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDebounce はテストで使う変更をまとめる待ち時間
+const testDebounce = 30 * time.Millisecond
+
+// newTestWatcher はタスクauthのあるディレクトリを監視する
+func newTestWatcher(t *testing.T) (*FSWatcher, string) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "ccforge")
+	writeFile(t, filepath.Join(root, "auth", "tasks.md"), "- [ ] 実装する\n")
+
+	w, err := New(root, testDebounce)
+	require.NoError(t, err)
+	t.Cleanup(func() { w.Close() })
+	return w, root
+}
+
+// writeFile は親ディレクトリを作成してファイルを書き込む
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+// receive は次のまとまりを待つ
+func receive(t *testing.T, w Watcher) []Event {
+	t.Helper()
+	select {
+	case events := <-w.Events():
+		return events
+	case <-time.After(5 * time.Second):
+		t.Fatal("イベントが届きませんでした")
+		return nil
+	}
+}
+
+// assertNoEvents は一定時間イベントが届かないことを確認する
+func assertNoEvents(t *testing.T, w Watcher) {
+	t.Helper()
+	select {
+	case events := <-w.Events():
+		t.Fatalf("予期しないイベントが届きました: %+v", events)
+	case <-time.After(5 * testDebounce):
+	}
+}
+
+func TestFSWatcher_SpecChanged(t *testing.T) {
+	w, root := newTestWatcher(t)
+
+	// 連続した書き込みは1つにまとめる
+	for i := range 5 {
+		writeFile(t, filepath.Join(root, "auth", "tasks.md"), "- [x] 実装する\n"+string(rune('a'+i)))
+	}
+	writeFile(t, filepath.Join(root, "auth", "design.md"), "# 設計\n")
+
+	assert.Equal(t, []Event{
+		{Kind: SpecChanged, Task: "auth", File: "design.md"},
+		{Kind: SpecChanged, Task: "auth", File: "tasks.md"},
+	}, receive(t, w))
+}
+
+func TestFSWatcher_AtomicSave(t *testing.T) {
+	w, root := newTestWatcher(t)
+
+	// 一時ファイルに書いてから置き換えるエディタの保存
+	tmp := filepath.Join(root, "auth", ".requirements.md.swp")
+	writeFile(t, tmp, "# 要件\n")
+	require.NoError(t, os.Rename(tmp, filepath.Join(root, "auth", "requirements.md")))
+
+	assert.Equal(t, []Event{{Kind: SpecChanged, Task: "auth", File: "requirements.md"}}, receive(t, w))
+}
+
+func TestFSWatcher_TaskAddedAndRemoved(t *testing.T) {
+	w, root := newTestWatcher(t)
+
+	// 追加したタスクのSpecsの作成は追加のイベントに含める
+	writeFile(t, filepath.Join(root, "billing", "requirements.md"), "# 要件\n")
+	writeFile(t, filepath.Join(root, "billing", "tasks.md"), "- [ ] 実装する\n")
+	assert.Equal(t, []Event{{Kind: TaskAdded, Task: "billing"}}, receive(t, w))
+
+	// 追加したタスクのディレクトリも監視する
+	writeFile(t, filepath.Join(root, "billing", "design.md"), "# 設計\n")
+	assert.Equal(t, []Event{{Kind: SpecChanged, Task: "billing", File: "design.md"}}, receive(t, w))
+
+	// アーカイブ (.archive への移動) は削除として扱う
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".archive"), 0o755))
+	require.NoError(t, os.Rename(filepath.Join(root, "auth"), filepath.Join(root, ".archive", "auth")))
+	assert.Equal(t, []Event{{Kind: TaskRemoved, Task: "auth"}}, receive(t, w))
+
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "billing")))
+	assert.Equal(t, []Event{{Kind: TaskRemoved, Task: "billing"}}, receive(t, w))
+}
+
+func TestFSWatcher_IgnoresOtherFiles(t *testing.T) {
+	w, root := newTestWatcher(t)
+
+	writeFile(t, filepath.Join(root, "config.toml"), "[ui]\n")
+	writeFile(t, filepath.Join(root, "ccforge.db-wal"), "x")
+	writeFile(t, filepath.Join(root, "auth", ".history.jsonl"), "{}\n")
+	writeFile(t, filepath.Join(root, "auth", "exports", "x.md"), "# x\n")
+	writeFile(t, filepath.Join(root, ".archive", "old", "tasks.md"), "- [ ] x\n")
+	assertNoEvents(t, w)
+}
+
+func TestFSWatcher_RootCreatedLater(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ccforge")
+	w, err := New(root, testDebounce)
+	require.NoError(t, err)
+	defer w.Close()
+
+	// 最初のタスクの作成でディレクトリができる
+	writeFile(t, filepath.Join(root, "auth", "tasks.md"), "- [ ] 実装する\n")
+	assert.Equal(t, []Event{{Kind: TaskAdded, Task: "auth"}}, receive(t, w))
+
+	writeFile(t, filepath.Join(root, "auth", "tasks.md"), "- [x] 実装する\n")
+	assert.Equal(t, []Event{{Kind: SpecChanged, Task: "auth", File: "tasks.md"}}, receive(t, w))
+}
+
+func TestFSWatcher_Close(t *testing.T) {
+	w, _ := newTestWatcher(t)
+	require.NoError(t, w.Close())
+
+	_, ok := <-w.Events()
+	assert.False(t, ok)
+	// 2回目以降は何もしない
+	assert.NoError(t, w.Close())
+}
+
+func TestNew_MissingParent(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing", "ccforge"), 0)
+	assert.Error(t, err)
+}
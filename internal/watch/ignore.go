@@ -0,0 +1,203 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitignoreFile は除外パターンを書くファイル名
+const gitignoreFile = ".gitignore"
+
+// DefaultIgnore は常に除外するパターン
+// Gitの内部ファイルとccforgeが自分で書き込むファイル (データベースや履歴) は変更として扱わない
+var DefaultIgnore = []string{
+	".git/",
+	"/ccforge/ccforge.db*",
+	"/ccforge/**/.*",
+}
+
+// ignoreRule は除外パターンの1行
+type ignoreRule struct {
+	re      *regexp.Regexp // パターン
+	negate  bool           // ! で始まる (除外を取り消す)
+	dirOnly bool           // / で終わる (ディレクトリにだけ一致する)
+	name    bool           // 途中に / を含まない (どの階層の名前にも一致する)
+}
+
+// Ignore は .gitignore の書式のパターンでパスを除外する
+// パスはルートからの相対パスで、区切りは / を使う
+type Ignore struct {
+	root    string                  // プロジェクトのルート
+	exclude []ignoreRule            // .git/info/exclude のパターン
+	dirs    map[string][]ignoreRule // ディレクトリごとの .gitignore のパターン (ルートは空文字列)
+	extra   []ignoreRule            // 既定のパターンと設定で追加したパターン
+}
+
+// NewIgnore はルートの .gitignore と .git/info/exclude を読み込む
+// patternsは .gitignore より優先して適用する追加のパターン
+// 下の階層の .gitignore は LoadDir で読み込む
+func NewIgnore(root string, patterns []string) (*Ignore, error) {
+	ig := &Ignore{
+		root:  root,
+		dirs:  make(map[string][]ignoreRule),
+		extra: parseIgnore(append(append([]string(nil), DefaultIgnore...), patterns...)),
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, ".git", "info", "exclude"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf(".git/info/exclude の読み込みに失敗しました: %w", err)
+	}
+	ig.exclude = parseIgnore(strings.Split(string(data), "\n"))
+
+	if err := ig.LoadDir(""); err != nil {
+		return nil, err
+	}
+	return ig, nil
+}
+
+// LoadDir はディレクトリの .gitignore を読み込む (既に読み込んだ場合は読み直す)
+func (ig *Ignore) LoadDir(dir string) error {
+	data, err := os.ReadFile(filepath.Join(ig.root, filepath.FromSlash(dir), gitignoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		delete(ig.dirs, dir)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s の読み込みに失敗しました: %w", path.Join(dir, gitignoreFile), err)
+	}
+	ig.dirs[dir] = parseIgnore(strings.Split(string(data), "\n"))
+	return nil
+}
+
+// Match はパスが除外されるかを判定する
+// 親のディレクトリが除外される場合も除外する (Gitと同じく中のファイルは取り消せない)
+func (ig *Ignore) Match(rel string, isDir bool) bool {
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if ig.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return ig.match(rel, isDir)
+}
+
+// match は親のディレクトリを見ずにパスが除外されるかを判定する
+// 浅い階層の .gitignore から順に評価し、最後に一致したパターンに従う
+func (ig *Ignore) match(rel string, isDir bool) bool {
+	ignored := evalRules(ig.exclude, rel, isDir, false)
+
+	parts := strings.Split(rel, "/")
+	for i := 0; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		if rules, ok := ig.dirs[dir]; ok {
+			ignored = evalRules(rules, strings.Join(parts[i:], "/"), isDir, ignored)
+		}
+	}
+
+	return evalRules(ig.extra, rel, isDir, ignored)
+}
+
+// evalRules はパターンを順に評価し、最後に一致したパターンの結果を返す
+// 一致しない場合はcurrentをそのまま返す
+func evalRules(rules []ignoreRule, rel string, isDir, current bool) bool {
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		target := rel
+		if r.name {
+			target = path.Base(rel)
+		}
+		if r.re.MatchString(target) {
+			current = !r.negate
+		}
+	}
+	return current
+}
+
+// parseIgnore は .gitignore の行をパターンに変換する
+// 空行と # で始まる行は読み飛ばす
+func parseIgnore(lines []string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var r ignoreRule
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		r.name = !strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+		if err != nil {
+			continue
+		}
+		r.re = re
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// globToRegexp は .gitignore のglobを正規表現に変換する
+// ** は任意の階層、* と ? は / 以外の文字、[...] は文字クラスに一致する
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
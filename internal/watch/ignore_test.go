@@ -0,0 +1,86 @@
+package watch
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnore_Match(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".gitignore"), "# 依存関係\nnode_modules/\n*.log\n!keep.log\n/build\ndocs/**/*.tmp\n\\#notes\n")
+	writeFile(t, filepath.Join(root, "web", ".gitignore"), "dist/\n!important.log\n")
+	writeFile(t, filepath.Join(root, ".git", "info", "exclude"), "scratch/\n")
+
+	ig, err := NewIgnore(root, []string{"*.snap", "vendor/"})
+	require.NoError(t, err)
+	require.NoError(t, ig.LoadDir("web"))
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "main.go", want: false},
+		{path: "node_modules", isDir: true, want: true},
+		{path: "web/node_modules/react/index.js", want: true},
+		{path: "node_modules", want: false},
+		{path: "app.log", want: true},
+		{path: "logs/app.log", want: true},
+		{path: "keep.log", want: false},
+		{path: "build", isDir: true, want: true},
+		{path: "build/out.bin", want: true},
+		{path: "src/build", isDir: true, want: false},
+		{path: "docs/a/b/c.tmp", want: true},
+		{path: "docs/c.tmp", want: true},
+		{path: "#notes", want: true},
+		{path: "web/dist/app.js", want: true},
+		{path: "dist/app.js", want: false},
+		{path: "web/important.log", want: false},
+		{path: "scratch/x.txt", want: true},
+		{path: "ui/__snapshots__/a.snap", want: true},
+		{path: "vendor/lib.go", want: true},
+		{path: ".git/HEAD", want: true},
+		{path: "ccforge/ccforge.db-wal", want: true},
+		{path: "ccforge/auth/.history.jsonl", want: true},
+		{path: "ccforge/auth/tasks.md", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, ig.Match(tt.path, tt.isDir))
+		})
+	}
+}
+
+func TestIgnore_LoadDirReload(t *testing.T) {
+	root := t.TempDir()
+	ig, err := NewIgnore(root, nil)
+	require.NoError(t, err)
+	assert.False(t, ig.Match("out.txt", false))
+
+	writeFile(t, filepath.Join(root, ".gitignore"), "out.txt\n")
+	require.NoError(t, ig.LoadDir(""))
+	assert.True(t, ig.Match("out.txt", false))
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{glob: "*.go", want: `[^/]*\.go`},
+		{glob: "**/foo", want: `(?:.*/)?foo`},
+		{glob: "foo/**", want: `foo/.*`},
+		{glob: "a/**/b", want: `a/(?:.*/)?b`},
+		{glob: "file?.[!ch]", want: `file[^/]\.[^ch]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			assert.Equal(t, tt.want, globToRegexp(tt.glob))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package watch
+
+import (
+	"sort"
+	"time"
+)
+
+// maxTimelineEntries はタイムラインに残す変更の最大数 (古いものから捨てる)
+const maxTimelineEntries = 5000
+
+// Timeline はセッション中のファイルの変更の記録
+// 変更を起きた順に残し、ファイルごとにセッション開始時点からの正味の変更をまとめる
+type Timeline struct {
+	sessionID string
+	started   time.Time
+	entries   []Change
+	dropped   int           // 上限を超えて捨てた変更の数
+	files     map[string]Op // ファイルごとの正味の変更
+}
+
+// FileChange はセッション開始時点からのファイルの正味の変更
+type FileChange struct {
+	Path string    // ルートからの相対パス
+	Op   Op        // 正味の変更の種類
+	Last time.Time // 最後に変更を検出した日時
+}
+
+// NewTimeline はセッションのタイムラインを作成する
+func NewTimeline(sessionID string, started time.Time) *Timeline {
+	return &Timeline{sessionID: sessionID, started: started, files: make(map[string]Op)}
+}
+
+// SessionID はセッションIDを返す (不明な場合は空)
+func (t *Timeline) SessionID() string {
+	return t.sessionID
+}
+
+// Started は記録を始めた日時を返す
+func (t *Timeline) Started() time.Time {
+	return t.started
+}
+
+// Record は変更を記録する
+func (t *Timeline) Record(changes []Change) {
+	for _, c := range changes {
+		t.entries = append(t.entries, c)
+		if op, ok := mergeOp(t.files[c.Path], c.Op); ok {
+			t.files[c.Path] = op
+		} else {
+			delete(t.files, c.Path)
+		}
+	}
+	if over := len(t.entries) - maxTimelineEntries; over > 0 {
+		t.entries = append([]Change(nil), t.entries[over:]...)
+		t.dropped += over
+	}
+}
+
+// Entries は記録した変更を新しい順に返す
+func (t *Timeline) Entries() []Change {
+	entries := make([]Change, len(t.entries))
+	for i, c := range t.entries {
+		entries[len(t.entries)-1-i] = c
+	}
+	return entries
+}
+
+// Dropped は上限を超えて捨てた変更の数を返す
+func (t *Timeline) Dropped() int {
+	return t.dropped
+}
+
+// Files は正味の変更があるファイルをパスの順に返す
+func (t *Timeline) Files() []FileChange {
+	last := make(map[string]time.Time, len(t.files))
+	for _, c := range t.entries {
+		last[c.Path] = c.Time
+	}
+	files := make([]FileChange, 0, len(t.files))
+	for p, op := range t.files {
+		files = append(files, FileChange{Path: p, Op: op, Last: last[p]})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// Count は正味の変更があるファイルの数を返す
+func (t *Timeline) Count() int {
+	return len(t.files)
+}
+
+// mergeOp はそれまでの正味の変更に次の変更を重ねる
+// 作成して削除したファイルのように変更が残らない場合はfalseを返す
+func mergeOp(prev, next Op) (Op, bool) {
+	switch {
+	case prev == 0:
+		return next, true
+	case prev == Created && next == Deleted:
+		return 0, false
+	case prev == Created:
+		return Created, true
+	case prev == Deleted && next == Created:
+		return Modified, true
+	default:
+		return next, true
+	}
+}
@@ -0,0 +1,75 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeline_Record(t *testing.T) {
+	at := func(sec int) time.Time { return time.Date(2026, 3, 1, 9, 0, sec, 0, time.UTC) }
+	tl := NewTimeline("s1", at(0))
+
+	tl.Record([]Change{
+		{Time: at(1), Path: "main.go", Op: Modified},
+		{Time: at(1), Path: "tmp.txt", Op: Created},
+	})
+	tl.Record([]Change{
+		{Time: at(2), Path: "new.go", Op: Created},
+		{Time: at(2), Path: "old.go", Op: Deleted},
+		{Time: at(3), Path: "tmp.txt", Op: Deleted},
+		{Time: at(3), Path: "new.go", Op: Modified},
+		{Time: at(4), Path: "old.go", Op: Created},
+	})
+
+	assert.Equal(t, "s1", tl.SessionID())
+	assert.Equal(t, at(0), tl.Started())
+	assert.Len(t, tl.Entries(), 7)
+	assert.Equal(t, Change{Time: at(4), Path: "old.go", Op: Created}, tl.Entries()[0])
+
+	// 作成して削除したファイルは残らない
+	assert.Equal(t, 3, tl.Count())
+	assert.Equal(t, []FileChange{
+		{Path: "main.go", Op: Modified, Last: at(1)},
+		{Path: "new.go", Op: Created, Last: at(3)},
+		{Path: "old.go", Op: Modified, Last: at(4)},
+	}, tl.Files())
+}
+
+func TestTimeline_Limit(t *testing.T) {
+	tl := NewTimeline("", time.Now())
+	changes := make([]Change, maxTimelineEntries+10)
+	for i := range changes {
+		changes[i] = Change{Path: "main.go", Op: Modified}
+	}
+	tl.Record(changes)
+
+	assert.Len(t, tl.Entries(), maxTimelineEntries)
+	assert.Equal(t, 10, tl.Dropped())
+	assert.Equal(t, 1, tl.Count())
+}
+
+func TestMergeOp(t *testing.T) {
+	tests := []struct {
+		name   string
+		prev   Op
+		next   Op
+		want   Op
+		wantOK bool
+	}{
+		{name: "最初の変更", next: Modified, want: Modified, wantOK: true},
+		{name: "作成後の変更", prev: Created, next: Modified, want: Created, wantOK: true},
+		{name: "作成後の削除", prev: Created, next: Deleted, wantOK: false},
+		{name: "削除後の作成", prev: Deleted, next: Created, want: Modified, wantOK: true},
+		{name: "変更後の削除", prev: Modified, next: Deleted, want: Deleted, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mergeOp(tt.prev, tt.next)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
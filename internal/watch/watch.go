@@ -0,0 +1,88 @@
+// Package watch はファイルの変更を監視する
+// タスクのディレクトリ (ccforge/) の変更は種類付きのイベントとして、
+// プロジェクト全体の変更は .gitignore で除外したうえでファイルごとの変更として通知する
+// エディタの保存などで短時間に続く変更はまとめて通知する
+package watch
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/mzkmnk/ccforge/internal/tasks"
+)
+
+// Kind はイベントの種類
+type Kind int
+
+const (
+	// SpecChanged はタスクのSpecsファイルの作成・変更・削除
+	SpecChanged Kind = iota + 1
+	// TaskAdded はタスクのディレクトリの追加
+	TaskAdded
+	// TaskRemoved はタスクのディレクトリの削除 (アーカイブを含む)
+	TaskRemoved
+)
+
+// String はイベントの種類の名前を返す
+func (k Kind) String() string {
+	switch k {
+	case SpecChanged:
+		return "spec_changed"
+	case TaskAdded:
+		return "task_added"
+	case TaskRemoved:
+		return "task_removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event はタスクの変更
+type Event struct {
+	Kind Kind   // 種類
+	Task string // タスク名
+	File string // 変更されたSpecsファイル名 (SpecChanged のみ)
+}
+
+// Watcher は変更をまとめて通知する
+// 実装は FSWatcher で、テストでは Fake に差し替える
+type Watcher interface {
+	// Events は変更のまとまりを届けるチャネルを返す (Close で閉じられる)
+	Events() <-chan []Event
+	// Close は監視を終了する
+	Close() error
+}
+
+// isSpecFile はファイル名がSpecsファイルかを判定する
+func isSpecFile(name string) bool {
+	for _, spec := range tasks.SpecFiles {
+		if spec.FileName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isTaskDir はパスがタスクのディレクトリかを判定する
+func isTaskDir(path string) bool {
+	if tasks.ValidateName(filepath.Base(path)) != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// listTasks はディレクトリ直下のタスク名を返す
+func listTasks(root string) map[string]bool {
+	names := make(map[string]bool)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return names
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && tasks.ValidateName(entry.Name()) == nil {
+			names[entry.Name()] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,74 @@
+package watch
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKind_String(t *testing.T) {
+	tests := []struct {
+		kind Kind
+		want string
+	}{
+		{kind: SpecChanged, want: "spec_changed"},
+		{kind: TaskAdded, want: "task_added"},
+		{kind: TaskRemoved, want: "task_removed"},
+		{kind: Kind(0), want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.kind.String())
+		})
+	}
+}
+
+func TestIsSpecFile(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "requirements.md", want: true},
+		{name: "design.md", want: true},
+		{name: "tasks.md", want: true},
+		{name: ".meta.json", want: false},
+		{name: "tasks.md~", want: false},
+		{name: "notes.md", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isSpecFile(tt.name))
+		})
+	}
+}
+
+func TestListTasks(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "auth", "tasks.md"), "")
+	writeFile(t, filepath.Join(root, "billing", "design.md"), "")
+	writeFile(t, filepath.Join(root, ".archive", "old", "tasks.md"), "")
+	writeFile(t, filepath.Join(root, "config.toml"), "")
+
+	assert.Equal(t, map[string]bool{"auth": true, "billing": true}, listTasks(root))
+	assert.Empty(t, listTasks(filepath.Join(root, "missing")))
+}
+
+func TestFake(t *testing.T) {
+	f := NewFake()
+	var w Watcher = f
+
+	f.Send(Event{Kind: TaskAdded, Task: "auth"}, Event{Kind: SpecChanged, Task: "auth", File: "tasks.md"})
+	assert.Equal(t, []Event{
+		{Kind: TaskAdded, Task: "auth"},
+		{Kind: SpecChanged, Task: "auth", File: "tasks.md"},
+	}, <-w.Events())
+
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	_, ok := <-w.Events()
+	assert.False(t, ok)
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mzkmnk/ccforge/internal/config"
 	"github.com/mzkmnk/ccforge/internal/tui"
 )
 
@@ -16,23 +20,65 @@ type Application struct {
 	forceError bool // テスト用エラー強制フラグ
 }
 
+// globalOptions は全てのコマンドで使えるオプション
+type globalOptions struct {
+	project string   // プロジェクトのルート (省略時は作業ディレクトリから探索)
+	config  string   // 設定ファイル (省略時はグローバル設定とプロジェクト設定)
+	verbose bool     // デバッグログを出力するか
+	logFile string   // ログファイル (省略時はキャッシュディレクトリ)
+	set     []string // 設定の上書き (key=value)
+}
+
+// registerGlobalFlags は全てのコマンドで使えるフラグを登録する
+func registerGlobalFlags(fs *flag.FlagSet, g *globalOptions) {
+	fs.StringVar(&g.project, "project", g.project, "プロジェクトのルートディレクトリ (省略時は ccforge/ か .git を含む親ディレクトリ)")
+	fs.StringVar(&g.config, "config", g.config, "読み込む設定ファイル (省略時はグローバル設定とプロジェクト設定)")
+	fs.BoolVar(&g.verbose, "v", g.verbose, "デバッグログを出力")
+	fs.BoolVar(&g.verbose, "verbose", g.verbose, "デバッグログを出力")
+	fs.StringVar(&g.logFile, "log-file", g.logFile, "ログファイルのパス")
+	fs.Func("set", "設定を上書きする (key=value、複数指定可)", func(value string) error {
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("key=value の形式で指定してください: %s", value)
+		}
+		g.set = append(g.set, value)
+		return nil
+	})
+}
+
+// cliOptions はルートコマンドの解析結果
+type cliOptions struct {
+	globalOptions
+	help    bool     // ヘルプ表示
+	command string   // サブコマンド名 (省略時は空)
+	args    []string // サブコマンドへ渡す引数
+}
+
 // parseCLIArgs はコマンドライン引数を解析する
-func parseCLIArgs(args []string) (help bool, err error) {
+// 最初の位置引数をサブコマンド名とし、以降をサブコマンドの引数とする
+func parseCLIArgs(args []string) (cliOptions, error) {
+	var opts cliOptions
+
 	fs := flag.NewFlagSet("ccforge", flag.ContinueOnError)
-	fs.BoolVar(&help, "h", false, "ヘルプを表示")
-	fs.BoolVar(&help, "help", false, "ヘルプを表示")
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.help, "h", false, "ヘルプを表示")
+	fs.BoolVar(&opts.help, "help", false, "ヘルプを表示")
+	registerGlobalFlags(fs, &opts.globalOptions)
 
 	// 引数をパース
-	err = fs.Parse(args)
-	if err != nil {
-		return false, err
+	if err := fs.Parse(args); err != nil {
+		return cliOptions{}, err
+	}
+
+	if rest := fs.Args(); len(rest) > 0 {
+		opts.command = rest[0]
+		opts.args = rest[1:]
 	}
 
-	return help, nil
+	return opts, nil
 }
 
 // initializeApp はアプリケーションを初期化する
-func initializeApp() (*Application, error) {
+func initializeApp(opts ...tui.Option) (*Application, error) {
 	// テスト用エラー処理
 	if os.Getenv("CCFORGE_INIT_ERROR") == "true" {
 		return nil, fmt.Errorf("初期化エラー（テスト用）")
@@ -48,7 +94,7 @@ func initializeApp() (*Application, error) {
 	// テストモードでない場合はBubble Teaプログラムを初期化
 	if !testMode {
 		// TUIモデルの作成
-		model := tui.NewModel()
+		model := tui.NewModel(opts...)
 
 		// Bubble Teaプログラムの作成
 		p := tea.NewProgram(model, tea.WithAltScreen())
@@ -58,6 +104,53 @@ func initializeApp() (*Application, error) {
 	return app, nil
 }
 
+// configPaths は読み込む設定ファイルを優先度の低い順に返す
+// explicitが指定されていればそのファイルのみを、なければグローバル設定とプロジェクト設定を返す
+func configPaths(projectRoot, explicit string) ([]string, error) {
+	if explicit != "" {
+		if _, err := os.Stat(explicit); err != nil {
+			return nil, fmt.Errorf("設定ファイルを開けません: %w", err)
+		}
+		return []string{explicit}, nil
+	}
+
+	globalPath, err := config.GlobalPath()
+	if err != nil {
+		return nil, err
+	}
+	return []string{globalPath, config.ProjectPath(projectRoot)}, nil
+}
+
+// loadConfig は設定を読み込む
+// 優先度は低い順に 既定値 < グローバル設定 < プロジェクト設定 < 環境変数 < フラグ
+func loadConfig(projectRoot string, g globalOptions) (*config.Config, error) {
+	paths, err := configPaths(projectRoot, g.config)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	for _, kv := range g.set {
+		key, value, _ := strings.Cut(kv, "=")
+		if err := cfg.Set(key, value); err != nil {
+			return nil, &usageError{err: fmt.Errorf("--set: %w", err)}
+		}
+	}
+	if g.verbose {
+		cfg.Log.Verbose = true
+	}
+	if g.logFile != "" {
+		cfg.Log.File = g.logFile
+	}
+	return cfg, nil
+}
+
 // runApp はアプリケーションを実行する
 func runApp(app *Application) error {
 	if app == nil {
@@ -86,42 +179,41 @@ func runApp(app *Application) error {
 
 // mainFlow はmain関数のロジックを分離した関数（テスト用）
 func mainFlow(args []string) error {
-	// CLIコマンドのパース
-	help, err := parseCLIArgs(args)
+	c, err := newCLI()
 	if err != nil {
-		return fmt.Errorf("引数パースエラー: %w", err)
-	}
-
-	// ヘルプ表示
-	if help {
-		showHelp()
-		return nil
+		return err
 	}
-
-	// アプリケーションの初期化
-	app, err := initializeApp()
-	if err != nil {
-		return fmt.Errorf("初期化エラー: %w", err)
-	}
-
-	// アプリケーションの実行
-	if err := runApp(app); err != nil {
-		return fmt.Errorf("実行エラー: %w", err)
-	}
-
-	return nil
+	return c.run(args)
 }
 
 // showHelp はヘルプメッセージを表示する
-func showHelp() {
+func showHelp(w io.Writer) {
+	var commands strings.Builder
+	for _, sc := range subcommands {
+		fmt.Fprintf(&commands, "  %-14s%s\n", sc.name, sc.summary)
+	}
+
 	help := `
 ccforge - Claude Code TUIアプリケーション
 
 使用法:
   ccforge [オプション]
+  ccforge <コマンド> [引数]
+
+コマンド:
+` + commands.String() + `
+  コマンド省略時はTUIを起動します。
+  各コマンドの詳細は 'ccforge <コマンド> --help' を参照してください。
 
 オプション:
-  -h, --help    このヘルプメッセージを表示
+  -h, --help          このヘルプメッセージを表示
+  --project <dir>     プロジェクトのルート (省略時は ccforge/ か .git を含む親ディレクトリ)
+  --config <file>     読み込む設定ファイル (省略時はグローバル設定とプロジェクト設定)
+  -v, --verbose       デバッグログを出力
+  --log-file <file>   ログファイル (既定: ユーザーのキャッシュディレクトリ/ccforge/ccforge.log)
+  --set <key=value>   設定を上書きする (複数指定可、'ccforge config get' で一覧を表示)
+
+  これらのオプションは各コマンドの後ろにも指定できます。
 
 説明:
   ccforgeは、Claude CodeをラップしたTUIアプリケーションです。
@@ -133,8 +225,13 @@ ccforge - Claude Code TUIアプリケーション
   Tab           フォーカスを切り替え
   ↑/↓          項目を選択
   Enter         選択した項目を実行
+
+終了コード:
+  0  正常終了
+  1  実行時エラー
+  2  引数・使用法の誤り
 `
-	fmt.Print(help)
+	fmt.Fprint(w, help)
 }
 
 func main() {
@@ -143,7 +240,16 @@ func main() {
 
 	// メインフローの実行
 	if err := mainFlow(args); err != nil {
-		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
-		os.Exit(1)
+		// JSON出力時のエラーは出力済み
+		var reported *reportedError
+		if !errors.As(err, &reported) {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+
+			var usageErr *usageError
+			if errors.As(err, &usageErr) {
+				fmt.Fprintln(os.Stderr, usageErr.hint())
+			}
+		}
+		os.Exit(exitCode(err))
 	}
 }
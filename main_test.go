@@ -3,16 +3,19 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 	"testing"
 )
 
 // TestMain_CLIコマンドパース tests
 func TestParseCLIArgs(t *testing.T) {
 	tests := []struct {
-		name     string
-		args     []string
-		wantHelp bool
-		wantErr  bool
+		name        string
+		args        []string
+		wantHelp    bool
+		wantCommand string
+		wantArgs    []string
+		wantErr     bool
 	}{
 		{
 			name:     "正常系_引数なし",
@@ -32,6 +35,12 @@ func TestParseCLIArgs(t *testing.T) {
 			wantHelp: true,
 			wantErr:  false,
 		},
+		{
+			name:        "正常系_サブコマンド",
+			args:        []string{"new", "--start", "auth"},
+			wantCommand: "new",
+			wantArgs:    []string{"--start", "auth"},
+		},
 		{
 			name:     "異常系_不明なフラグ",
 			args:     []string{"-unknown"},
@@ -45,15 +54,21 @@ func TestParseCLIArgs(t *testing.T) {
 			// フラグをリセット
 			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 
-			help, err := parseCLIArgs(tt.args)
+			opts, err := parseCLIArgs(tt.args)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseCLIArgs() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			if help != tt.wantHelp {
-				t.Errorf("parseCLIArgs() help = %v, want %v", help, tt.wantHelp)
+			if opts.help != tt.wantHelp {
+				t.Errorf("parseCLIArgs() help = %v, want %v", opts.help, tt.wantHelp)
+			}
+			if opts.command != tt.wantCommand {
+				t.Errorf("parseCLIArgs() command = %v, want %v", opts.command, tt.wantCommand)
+			}
+			if strings.Join(opts.args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("parseCLIArgs() args = %v, want %v", opts.args, tt.wantArgs)
 			}
 		})
 	}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mzkmnk/ccforge/internal/claude"
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+)
+
+// schemaVersion はJSON出力のスキーマバージョン
+// フィールドの削除や意味の変更など互換性のない変更をした場合に上げる
+const schemaVersion = 1
+
+// outputFormat はサブコマンドの出力形式
+type outputFormat string
+
+const (
+	formatText  outputFormat = "text"  // 人が読むためのテキスト
+	formatTable outputFormat = "table" // 表形式
+	formatJSON  outputFormat = "json"  // バージョン付きJSON
+)
+
+// Set はフラグの値から出力形式を設定する
+func (f *outputFormat) Set(value string) error {
+	switch v := outputFormat(value); v {
+	case formatText, formatTable, formatJSON:
+		*f = v
+		return nil
+	default:
+		return fmt.Errorf("不明な出力形式です: %s (json, table, text のいずれか)", value)
+	}
+}
+
+// String は出力形式の文字列を返す
+func (f *outputFormat) String() string {
+	return string(*f)
+}
+
+// detectFormat はフラグの解析前に引数から出力形式を推定する
+// 引数の誤りをJSONで報告するために使う
+func detectFormat(args []string) outputFormat {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		for _, name := range []string{"-o", "--o", "-output", "--output"} {
+			if value, ok := strings.CutPrefix(arg, name+"="); ok && value == string(formatJSON) {
+				return formatJSON
+			}
+			if arg == name && i+1 < len(args) && args[i+1] == string(formatJSON) {
+				return formatJSON
+			}
+		}
+	}
+	return ""
+}
+
+// result はサブコマンドの実行結果
+// 出力形式ごとに描画方法を持つ
+type result interface {
+	kind() string           // JSONのkind
+	data() any              // JSONのdata
+	writeText(w io.Writer)  // テキスト形式で出力する
+	writeTable(w io.Writer) // 表形式で出力する
+}
+
+// envelope はJSON出力の共通の外枠
+type envelope struct {
+	SchemaVersion int    `json:"schema_version"`
+	Kind          string `json:"kind"`
+	Data          any    `json:"data"`
+}
+
+// errorEnvelope はJSON形式のエラー出力
+type errorEnvelope struct {
+	SchemaVersion int       `json:"schema_version"`
+	Error         errorJSON `json:"error"`
+}
+
+// errorJSON はエラーの内容
+type errorJSON struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// writeResult は実行結果を指定の形式で出力する
+func writeResult(w io.Writer, format outputFormat, res result) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, envelope{SchemaVersion: schemaVersion, Kind: res.kind(), Data: res.data()})
+	case formatTable:
+		res.writeTable(w)
+	default:
+		res.writeText(w)
+	}
+	return nil
+}
+
+// writeErrorJSON はエラーをJSON形式で出力する
+func writeErrorJSON(w io.Writer, err error) error {
+	return writeJSON(w, errorEnvelope{
+		SchemaVersion: schemaVersion,
+		Error: errorJSON{
+			Code:     errorCode(err),
+			Message:  err.Error(),
+			ExitCode: exitCode(err),
+		},
+	})
+}
+
+// writeJSON はインデント付きのJSONを出力する
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("JSONの出力に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// errorCode はエラーの種類を表す識別子を返す
+func errorCode(err error) string {
+	var usageErr *usageError
+	switch {
+	case errors.As(err, &usageErr):
+		return "usage"
+	case errors.Is(err, tasks.ErrTaskNotFound):
+		return "task_not_found"
+	case errors.Is(err, tasks.ErrTaskExists):
+		return "task_exists"
+	case errors.Is(err, git.ErrNotRepository):
+		return "not_git_repository"
+	case errors.Is(err, git.ErrDirtyTree):
+		return "dirty_tree"
+	case errors.Is(err, git.ErrBranchNotFound):
+		return "branch_not_found"
+	case errors.Is(err, git.ErrWorktreeDirty):
+		return "worktree_dirty"
+	case errors.Is(err, git.ErrCheckpointNotFound):
+		return "checkpoint_not_found"
+	case errors.Is(err, claude.ErrNotInstalled):
+		return "claude_not_found"
+	case errors.As(err, new(*claude.ExitError)):
+		return "claude_failed"
+	case errors.As(err, new(config.ValidationErrors)):
+		return "config_invalid"
+	default:
+		return "error"
+	}
+}
+
+// reportedError は出力済みのエラー
+// mainで再度表示しないために使う
+type reportedError struct {
+	err error
+}
+
+// Error はエラーメッセージを返す
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap は元のエラーを返す
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// progressJSON はタスクの進捗のJSON表現
+type progressJSON struct {
+	Done    int `json:"done"`
+	Total   int `json:"total"`
+	Percent int `json:"percent"`
+}
+
+// taskJSON はタスクのJSON表現
+type taskJSON struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Archived    bool         `json:"archived"`
+	Progress    progressJSON `json:"progress"`
+	Dir         string       `json:"dir"`
+	Branch      string       `json:"branch,omitempty"`
+	Worktree    string       `json:"worktree,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// newTaskJSON はタスクをJSON表現に変換する
+func newTaskJSON(task tasks.Task) taskJSON {
+	return taskJSON{
+		ID:          task.ID,
+		Name:        task.Name,
+		Description: task.Description,
+		Status:      task.Status().Key(),
+		Archived:    task.Archived,
+		Progress: progressJSON{
+			Done:    task.Progress.Done,
+			Total:   task.Progress.Total,
+			Percent: task.Progress.Percent(),
+		},
+		Dir:       task.Dir,
+		Branch:    task.Branch,
+		Worktree:  task.Worktree,
+		CreatedAt: task.CreatedAt.UTC(),
+		UpdatedAt: task.UpdatedAt.UTC(),
+	}
+}
+
+// statusText はタスクの状態の表示名を返す
+func statusText(task tasks.Task) string {
+	if task.Archived {
+		return "アーカイブ済み"
+	}
+	return task.Status().String()
+}
+
+// progressText はタスクの進捗の表示文字列を返す
+func progressText(p tasks.Progress) string {
+	if p.Total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d (%d%%)", p.Done, p.Total, p.Percent())
+}
+
+// formatTime は日時を表示用に整形する
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// printTable は表示幅を揃えた表を出力する
+func printTable(w io.Writer, headers []string, rows [][]string) {
+	widths := make([]int, len(headers))
+	for _, row := range append([][]string{headers}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], ansi.StringWidth(cell))
+		}
+	}
+
+	for _, row := range append([][]string{headers}, rows...) {
+		var b strings.Builder
+		for i, cell := range row {
+			b.WriteString(cell)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-ansi.StringWidth(cell)+2))
+			}
+		}
+		fmt.Fprintln(w, b.String())
+	}
+}
+
+// messageResult はメッセージのみを出力する結果
+type messageResult struct {
+	kindName string   // JSONのkind
+	payload  any      // JSONのdata
+	lines    []string // テキスト形式の出力
+}
+
+func (r messageResult) kind() string { return r.kindName }
+func (r messageResult) data() any    { return r.payload }
+
+func (r messageResult) writeText(w io.Writer) {
+	for _, line := range r.lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+func (r messageResult) writeTable(w io.Writer) { r.writeText(w) }
+
+// taskListResult は list の結果
+type taskListResult struct {
+	tasks []tasks.Task
+}
+
+func (r taskListResult) kind() string { return "task_list" }
+
+func (r taskListResult) data() any {
+	list := make([]taskJSON, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		list = append(list, newTaskJSON(task))
+	}
+	return struct {
+		Tasks []taskJSON `json:"tasks"`
+	}{Tasks: list}
+}
+
+func (r taskListResult) writeText(w io.Writer) {
+	if len(r.tasks) == 0 {
+		fmt.Fprintln(w, "タスクがありません ('ccforge new <タスク名>' で作成できます)")
+		return
+	}
+	for _, task := range r.tasks {
+		fmt.Fprintf(w, "%s (%s %s)\n", task.Name, statusText(task), progressText(task.Progress))
+	}
+}
+
+func (r taskListResult) writeTable(w io.Writer) {
+	if len(r.tasks) == 0 {
+		r.writeText(w)
+		return
+	}
+
+	rows := make([][]string, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		rows = append(rows, []string{task.Name, statusText(task), progressText(task.Progress), formatTime(task.UpdatedAt)})
+	}
+	printTable(w, []string{"タスク", "状態", "進捗", "更新日時"}, rows)
+}
+
+// specJSON はSpecsファイルのJSON表現
+type specJSON struct {
+	Kind    string `json:"kind"`
+	File    string `json:"file"`
+	Title   string `json:"title"`
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`
+	Content string `json:"content"`
+}
+
+// taskSpecsResult は show の結果
+type taskSpecsResult struct {
+	task  string
+	specs []tasks.Spec
+}
+
+func (r taskSpecsResult) kind() string { return "task_specs" }
+
+func (r taskSpecsResult) data() any {
+	specs := make([]specJSON, 0, len(r.specs))
+	for _, spec := range r.specs {
+		specs = append(specs, specJSON{
+			Kind:    spec.Kind,
+			File:    spec.FileName,
+			Title:   spec.Title,
+			Path:    spec.Path,
+			Exists:  spec.Exists,
+			Content: spec.Content,
+		})
+	}
+	return struct {
+		Task  string     `json:"task"`
+		Specs []specJSON `json:"specs"`
+	}{Task: r.task, Specs: specs}
+}
+
+func (r taskSpecsResult) writeText(w io.Writer) {
+	for i, spec := range r.specs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, "==> %s (%s) <==\n", spec.FileName, spec.Title)
+		if !spec.Exists {
+			fmt.Fprintln(w, "(ファイルがありません)")
+			continue
+		}
+		fmt.Fprint(w, spec.Content)
+		if !strings.HasSuffix(spec.Content, "\n") {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func (r taskSpecsResult) writeTable(w io.Writer) {
+	rows := make([][]string, 0, len(r.specs))
+	for _, spec := range r.specs {
+		lines := "-"
+		if spec.Exists {
+			lines = fmt.Sprint(strings.Count(strings.TrimSuffix(spec.Content, "\n"), "\n") + 1)
+		}
+		rows = append(rows, []string{spec.FileName, spec.Title, lines})
+	}
+	printTable(w, []string{"ファイル", "種別", "行数"}, rows)
+}
+
+// checklistItemJSON はチェックリスト項目のJSON表現
+type checklistItemJSON struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+	Line int    `json:"line"`
+}
+
+// taskStatusResult は status の結果
+type taskStatusResult struct {
+	task  tasks.Task
+	items []tasks.ChecklistItem
+}
+
+func (r taskStatusResult) kind() string { return "task_status" }
+
+func (r taskStatusResult) data() any {
+	items := make([]checklistItemJSON, 0, len(r.items))
+	for _, item := range r.items {
+		items = append(items, checklistItemJSON(item))
+	}
+	return struct {
+		Task  taskJSON            `json:"task"`
+		Items []checklistItemJSON `json:"items"`
+	}{Task: newTaskJSON(r.task), Items: items}
+}
+
+func (r taskStatusResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "タスク:   %s\n", r.task.Name)
+	if r.task.Description != "" {
+		fmt.Fprintf(w, "概要:     %s\n", r.task.Description)
+	}
+	fmt.Fprintf(w, "状態:     %s\n", statusText(r.task))
+	fmt.Fprintf(w, "進捗:     %s\n", progressText(r.task.Progress))
+	if r.task.Branch != "" {
+		fmt.Fprintf(w, "ブランチ: %s\n", r.task.Branch)
+	}
+	if r.task.Worktree != "" {
+		fmt.Fprintf(w, "worktree: %s\n", r.task.Worktree)
+	}
+	fmt.Fprintf(w, "作成日時: %s\n", formatTime(r.task.CreatedAt))
+	fmt.Fprintf(w, "更新日時: %s\n", formatTime(r.task.UpdatedAt))
+
+	var remaining []string
+	for _, item := range r.items {
+		if !item.Done {
+			remaining = append(remaining, item.Text)
+		}
+	}
+	if len(remaining) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "未完了の項目:")
+		for _, text := range remaining {
+			fmt.Fprintf(w, "  - [ ] %s\n", text)
+		}
+	}
+}
+
+func (r taskStatusResult) writeTable(w io.Writer) {
+	if len(r.items) == 0 {
+		fmt.Fprintln(w, "tasks.mdに項目がありません")
+		return
+	}
+
+	rows := make([][]string, 0, len(r.items))
+	for _, item := range r.items {
+		mark := " "
+		if item.Done {
+			mark = "✓"
+		}
+		rows = append(rows, []string{fmt.Sprint(item.Line), mark, item.Text})
+	}
+	printTable(w, []string{"行", "完了", "項目"}, rows)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update が指定された場合はゴールデンファイルを書き換える
+// go test . -run TestJSONOutput -update
+var update = flag.Bool("update", false, "ゴールデンファイルを更新する")
+
+// fixtureTime はゴールデンテスト用の固定日時
+var fixtureTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// setupFixtureProject はゴールデンテスト用のタスクを作成する
+// IDと日時を固定して出力を決定的にする
+func setupFixtureProject(t *testing.T, dir string) {
+	t.Helper()
+
+	m := tasks.NewManager(dir)
+	fixtures := []struct {
+		name, description, checklist string
+	}{
+		{name: "auth", description: "認証処理を整理する", checklist: "# auth タスク\n\n- [x] 要件定義を書く\n- [ ] 実装する\n"},
+		{name: "dashboard", checklist: "- [x] 画面を作る\n"},
+		{name: "old"},
+	}
+
+	for i, f := range fixtures {
+		task, err := m.Create(f.name, f.description)
+		require.NoError(t, err)
+
+		meta := `{"id": "000000000000000` + string(rune('1'+i)) + `", "description": "` + f.description + `", "created_at": "2026-01-01T00:00:00Z"}`
+		require.NoError(t, os.WriteFile(filepath.Join(task.Dir, ".meta.json"), []byte(meta), 0o644))
+		if f.checklist != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(task.Dir, tasks.TasksFile), []byte(f.checklist), 0o644))
+		}
+	}
+
+	_, err := m.Archive("old")
+	require.NoError(t, err)
+
+	// 作成・移動で更新された日時を固定する
+	require.NoError(t, filepath.WalkDir(filepath.Join(dir, tasks.DirName), func(path string, _ os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Chtimes(path, fixtureTime, fixtureTime)
+	}))
+}
+
+// volatilePattern はIDや現在日時など実行ごとに変わる値にマッチする
+var volatilePattern = regexp.MustCompile(`"(id|created_at|updated_at)": "[^"]*"`)
+
+// assertGolden は出力をゴールデンファイルと比較する
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "ゴールデンファイルがありません (-update で作成できます)")
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestJSONOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		stderr   bool // エラー出力を比較する
+		volatile bool // IDと日時を伏せて比較する
+		wantCode int
+	}{
+		{name: "list", args: []string{"list", "-o", "json"}},
+		{name: "list_all", args: []string{"list", "--all", "--output=json"}},
+		{name: "status", args: []string{"status", "auth", "--output", "json"}},
+		{name: "show", args: []string{"show", "dashboard", "-o", "json"}},
+		{name: "show_file", args: []string{"show", "auth", "--file", "tasks", "-o", "json"}},
+		{name: "new", args: []string{"new", "feature", "-d", "新機能", "-o", "json"}, volatile: true},
+		{name: "archive", args: []string{"archive", "dashboard", "-o", "json"}},
+		{name: "config_get", args: []string{"config", "get", "-o", "json"}},
+		{name: "error_not_found", args: []string{"status", "missing", "-o", "json"}, stderr: true, wantCode: exitError},
+		{name: "error_usage", args: []string{"list", "extra", "-o", "json"}, stderr: true, wantCode: exitUsage},
+		{name: "error_bad_flag", args: []string{"show", "--bogus", "-o", "json"}, stderr: true, wantCode: exitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, out, _ := newTestCLI(t)
+			setupFixtureProject(t, c.workDir)
+
+			err := c.run(tt.args)
+			require.Equal(t, tt.wantCode, exitCode(err), "err = %v", err)
+
+			got := out.Bytes()
+			if tt.stderr {
+				assert.Empty(t, out.String())
+				got = c.stderr.(*bytes.Buffer).Bytes()
+
+				var reported *reportedError
+				assert.ErrorAs(t, err, &reported)
+			}
+
+			got = []byte(strings.ReplaceAll(string(got), c.workDir, "$PROJECT"))
+			if tt.volatile {
+				got = volatilePattern.ReplaceAll(got, []byte(`"$1": "<volatile>"`))
+			}
+			assertGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	c, out, _ := newTestCLI(t)
+	setupFixtureProject(t, c.workDir)
+
+	t.Run("listの既定は表形式", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, c.run([]string{"list"}))
+		assert.True(t, strings.HasPrefix(out.String(), "タスク"))
+		assert.Contains(t, out.String(), "auth       進行中  1/2 (50%)")
+	})
+
+	t.Run("listのテキスト形式", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, c.run([]string{"list", "-o", "text"}))
+		assert.Equal(t, "auth (進行中 1/2 (50%))\ndashboard (完了 1/1 (100%))\n", out.String())
+	})
+
+	t.Run("statusのテキスト形式", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, c.run([]string{"status", "auth"}))
+		assert.Contains(t, out.String(), "概要:     認証処理を整理する")
+		assert.Contains(t, out.String(), "未完了の項目:\n  - [ ] 実装する\n")
+	})
+
+	t.Run("statusの表形式", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, c.run([]string{"status", "auth", "-o", "table"}))
+		assert.Contains(t, out.String(), "3   ✓     要件定義を書く")
+	})
+
+	t.Run("不明な出力形式", func(t *testing.T) {
+		err := c.run([]string{"list", "-o", "yaml"})
+		assert.Equal(t, exitUsage, exitCode(err))
+		assert.Contains(t, err.Error(), "不明な出力形式です")
+	})
+
+	t.Run("startは出力形式に対応しない", func(t *testing.T) {
+		err := c.run([]string{"start", "auth", "-o", "json"})
+		assert.Equal(t, exitUsage, exitCode(err))
+	})
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		args []string
+		want outputFormat
+	}{
+		{args: []string{"list", "-o", "json"}, want: formatJSON},
+		{args: []string{"list", "--output=json"}, want: formatJSON},
+		{args: []string{"list", "-o", "table"}, want: ""},
+		{args: []string{"show", "--", "-o", "json"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, detectFormat(tt.args), tt.args)
+	}
+}
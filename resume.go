@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+)
+
+// attachSnapshots はタスクの画面の状態をプロジェクトのデータベースに保存する設定を加える
+// resumeがtrueなら最後に保存した画面を復元する
+// データベースを開けない場合は警告を表示し、保存せずにTUIを起動する
+// 戻り値の関数でデータベースを閉じる
+func (c *cli) attachSnapshots(task *tasks.Task, resume bool, opts *launchOptions) func() {
+	ctx := context.Background()
+
+	st, task, err := c.openTaskStore(ctx, task)
+	if err != nil {
+		slog.Warn("画面の保存を無効にします", "component", "cli", "task", task.Name, "error", err)
+		fmt.Fprintf(c.stderr, "警告: 画面の状態を保存できません: %v\n", err)
+		return func() {}
+	}
+
+	opts.taskID = task.ID
+	opts.sessionID = task.SessionID
+	opts.saveSnapshot = func(snap store.Snapshot) error {
+		return st.SaveSnapshot(context.Background(), snap)
+	}
+
+	if resume {
+		snap, err := st.Snapshot(ctx, task.ID)
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			fmt.Fprintf(c.stderr, "タスク %s の保存された画面はありません。新しく開始します\n", task.Name)
+		case err != nil:
+			fmt.Fprintf(c.stderr, "警告: 画面を復元できません: %v\n", err)
+		default:
+			opts.resume = snap
+		}
+	}
+
+	return func() {
+		if err := st.Close(); err != nil {
+			slog.Warn("データベースを閉じられませんでした", "component", "cli", "error", err)
+		}
+	}
+}
+
+// openTaskStore はプロジェクトのデータベースを開き、タスクを記録する
+// IDのない (手動で作成された) タスクにはIDを割り当てる
+func (c *cli) openTaskStore(ctx context.Context, task *tasks.Task) (*store.Store, *tasks.Task, error) {
+	withID, err := c.manager().EnsureID(task.Name)
+	if err != nil {
+		return nil, task, err
+	}
+	task = withID
+
+	st, err := store.Open(ctx, store.DefaultPath(c.workDir))
+	if err != nil {
+		return nil, task, err
+	}
+	err = st.Update(ctx, func(r *store.Repository) error {
+		return recordTask(ctx, r, *task)
+	})
+	if err != nil {
+		st.Close()
+		return nil, task, err
+	}
+	return st, task, nil
+}
+
+// recordTask はタスクをデータベースに記録する
+// タスク名は一意のため、同名の別タスクがある場合はアーカイブした方の記録の名前に @ID を付ける
+// (アーカイブした後に同じ名前でタスクを作り直した場合)
+func recordTask(ctx context.Context, r *store.Repository, task tasks.Task) error {
+	rec := store.Task{ID: task.ID, Name: task.Name, Description: task.Description, CreatedAt: task.CreatedAt, Archived: task.Archived}
+
+	old, err := r.TaskByName(ctx, task.Name)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+	case err != nil:
+		return err
+	case old.ID == task.ID:
+	case task.Archived:
+		rec.Name = task.Name + "@" + task.ID
+	default:
+		old.Name = old.Name + "@" + old.ID
+		old.Archived = true
+		if err := r.SaveTask(ctx, *old); err != nil {
+			return err
+		}
+	}
+	return r.SaveTask(ctx, rec)
+}
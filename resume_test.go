@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResumeTestCLI はタスクを作成し、TUIの起動時の設定を記録する実行環境を作成する
+func newResumeTestCLI(t *testing.T) (*cli, *launchOptions) {
+	t.Helper()
+	c, _, _ := newTestCLI(t)
+	_, err := tasks.NewManager(c.workDir).Create("auth", "")
+	require.NoError(t, err)
+
+	var opts launchOptions
+	c.launch = func(o launchOptions) error {
+		opts = o
+		return nil
+	}
+	return c, &opts
+}
+
+func TestCLI_StartSavesSnapshot(t *testing.T) {
+	c, opts := newResumeTestCLI(t)
+
+	require.NoError(t, c.run([]string{"start", "auth"}))
+	require.NotNil(t, opts.saveSnapshot)
+	assert.NotEmpty(t, opts.taskID)
+	assert.Nil(t, opts.resume)
+
+	// IDのないタスクにもIDが割り当てられる
+	task, err := tasks.NewManager(c.workDir).Get("auth")
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, opts.taskID)
+
+	// TUIの終了後はデータベースを閉じる
+	assert.Error(t, opts.saveSnapshot(store.Snapshot{TaskID: opts.taskID, SavedAt: time.Now()}))
+}
+
+func TestCLI_StartResume(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		saved      bool
+		wantResume bool
+		wantErrOut string
+	}{
+		{name: "resumeで保存した画面を復元する", args: []string{"start", "auth", "--resume"}, saved: true, wantResume: true},
+		{name: "resumeなしでは復元しない", args: []string{"start", "auth"}, saved: true},
+		{name: "auto_resumeで常に復元する", args: []string{"--set", "ui.auto_resume=true", "start", "auth"}, saved: true, wantResume: true},
+		{name: "保存した画面がなければ新しく開始する", args: []string{"start", "auth", "--resume"}, wantErrOut: "タスク auth の保存された画面はありません"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, opts := newResumeTestCLI(t)
+			errOut := &bytes.Buffer{}
+			c.stderr = errOut
+
+			if tt.saved {
+				// 1回目の起動中に画面を保存する (データベースはTUIの終了後に閉じる)
+				c.launch = func(o launchOptions) error {
+					return o.saveSnapshot(store.Snapshot{
+						TaskID:    o.taskID,
+						SessionID: "s1",
+						Output:    []string{"前回の出力"},
+						Input:     "書きかけ",
+						SavedAt:   time.Now(),
+					})
+				}
+				require.NoError(t, c.run([]string{"start", "auth"}))
+				c.launch = func(o launchOptions) error {
+					*opts = o
+					return nil
+				}
+			}
+
+			require.NoError(t, c.run(tt.args))
+			assert.Contains(t, errOut.String(), tt.wantErrOut)
+			if !tt.wantResume {
+				assert.Nil(t, opts.resume)
+				return
+			}
+			require.NotNil(t, opts.resume)
+			assert.Equal(t, []string{"前回の出力"}, opts.resume.Output)
+			assert.Equal(t, "書きかけ", opts.resume.Input)
+			assert.Equal(t, "s1", opts.resume.SessionID)
+		})
+	}
+}
+
+func TestCLI_StartRecreatedTask(t *testing.T) {
+	c, opts := newResumeTestCLI(t)
+	require.NoError(t, c.run([]string{"start", "auth"}))
+	oldID := opts.taskID
+
+	// 同名のタスクをアーカイブして作り直しても起動できる
+	m := tasks.NewManager(c.workDir)
+	_, err := m.Archive("auth")
+	require.NoError(t, err)
+	_, err = m.Create("auth", "")
+	require.NoError(t, err)
+
+	require.NoError(t, c.run([]string{"start", "auth"}))
+	assert.NotEqual(t, oldID, opts.taskID)
+	assert.NotNil(t, opts.saveSnapshot)
+}
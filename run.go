@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/claude"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+)
+
+// runCommand は run サブコマンドを定義する
+func runCommand(fs *flag.FlagSet) runFunc {
+	var taskName, prompt string
+	var saveHistory, newSession bool
+	fs.StringVar(&taskName, "t", "", "実行するタスク (必須)")
+	fs.StringVar(&taskName, "task", "", "実行するタスク (必須)")
+	fs.StringVar(&prompt, "p", "", "送信するプロンプト (省略時または - の場合は標準入力から読み込む)")
+	fs.StringVar(&prompt, "prompt", "", "送信するプロンプト (省略時または - の場合は標準入力から読み込む)")
+	fs.BoolVar(&saveHistory, "save-history", false, "やり取りをタスクの履歴に追記する")
+	fs.BoolVar(&newSession, "new-session", false, "タスクのセッションを再開せず新しいセッションで実行する")
+
+	return func(c *cli, args []string) (result, error) {
+		if taskName == "" {
+			return nil, newUsageError("run", "--task を指定してください")
+		}
+
+		m := c.manager()
+		task, err := m.Get(taskName)
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := c.readPrompt(prompt)
+		if err != nil {
+			return nil, err
+		}
+
+		req := claude.Request{Prompt: text, SessionID: task.SessionID, Resume: task.SessionID != "", ExtraArgs: args}
+		if newSession || req.SessionID == "" {
+			if req.SessionID, err = claude.NewSessionID(); err != nil {
+				return nil, err
+			}
+			req.Resume = false
+		}
+
+		// Ctrl+Cは子プロセスにも届くため、ccforge自身は終了を待って後始末する
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		runner := c.claude
+		runner.Dir = c.workDir
+		if task.Worktree != "" {
+			runner.Dir = task.Worktree
+		}
+
+		var transcript bytes.Buffer
+		stdout := c.stdout
+		if saveHistory {
+			stdout = io.MultiWriter(c.stdout, &transcript)
+		}
+
+		// プロンプトによる変更を戻せるように送信前の作業ツリーを保存する
+		c.saveCheckpoint(task, text)
+
+		runErr := runner.Run(ctx, req, stdout, c.stderr)
+
+		var exitErr *claude.ExitError
+		if runErr != nil && !errors.As(runErr, &exitErr) {
+			// 起動に失敗した場合は履歴もセッションも残さない
+			return nil, runErr
+		}
+
+		if runErr == nil {
+			if err := m.SetSessionID(task.Name, req.SessionID); err != nil {
+				return nil, err
+			}
+		}
+
+		if saveHistory {
+			entry := tasks.HistoryEntry{
+				Time:      time.Now().UTC(),
+				SessionID: req.SessionID,
+				Prompt:    text,
+				Output:    transcript.String(),
+			}
+			if exitErr != nil {
+				entry.ExitCode = exitErr.Code
+			}
+			if err := m.AppendHistory(task.Name, entry); err != nil {
+				return nil, errors.Join(runErr, err)
+			}
+		}
+
+		return nil, runErr
+	}
+}
+
+// readPrompt はフラグで指定されたプロンプト、または標準入力のプロンプトを返す
+func (c *cli) readPrompt(prompt string) (string, error) {
+	if prompt != "" && prompt != "-" {
+		return prompt, nil
+	}
+
+	if prompt == "" && isTerminal(c.stdin) {
+		return "", newUsageError("run", "--prompt を指定するか標準入力からプロンプトを渡してください")
+	}
+
+	data, err := io.ReadAll(c.stdin)
+	if err != nil {
+		return "", fmt.Errorf("標準入力の読み込みに失敗しました: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", newUsageError("run", "プロンプトが空です")
+	}
+	return string(data), nil
+}
+
+// isTerminal は入力が端末かどうかを判定する
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
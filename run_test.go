@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/claude"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess はClaude Code CLIの代わりに起動される偽のプロセス
+// 受け取った引数と標準入力を出力し、FAKE_CLAUDE_EXITの終了コードで終了する
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	stdin, _ := io.ReadAll(os.Stdin)
+	fmt.Printf("args=%s\n", strings.Join(args, " "))
+	fmt.Printf("stdin=%s\n", stdin)
+
+	code, _ := strconv.Atoi(os.Getenv("FAKE_CLAUDE_EXIT"))
+	os.Exit(code)
+}
+
+// newRunTestCLI は偽のClaude Code CLIを使う実行環境とタスクを作成する
+func newRunTestCLI(t *testing.T, exitCode int) (*cli, *bytes.Buffer) {
+	t.Helper()
+	t.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	t.Setenv("FAKE_CLAUDE_EXIT", strconv.Itoa(exitCode))
+
+	c, out, _ := newTestCLI(t)
+	c.claude = claude.Runner{Bin: os.Args[0], Args: []string{"-test.run=TestHelperProcess", "--"}}
+	c.stdin = strings.NewReader("")
+
+	_, err := tasks.NewManager(c.workDir).Create("auth", "")
+	require.NoError(t, err)
+	return c, out
+}
+
+func TestRun(t *testing.T) {
+	c, out := newRunTestCLI(t, 0)
+	m := tasks.NewManager(c.workDir)
+
+	// 初回は新しいセッションを作成する
+	require.NoError(t, c.run([]string{"run", "--task", "auth", "--prompt", "設計して", "--save-history"}))
+	task, err := m.Get("auth")
+	require.NoError(t, err)
+	require.NotEmpty(t, task.SessionID)
+	assert.Equal(t, "args=-p --session-id "+task.SessionID+"\nstdin=設計して\n", out.String())
+
+	// 2回目以降は同じセッションを再開し、標準入力のプロンプトと追加の引数を渡す
+	out.Reset()
+	c.stdin = strings.NewReader("実装して\n")
+	require.NoError(t, c.run([]string{"run", "-t", "auth", "--", "--model", "sonnet"}))
+	assert.Equal(t, "args=-p --resume "+task.SessionID+" --model sonnet\nstdin=実装して\n\n", out.String())
+
+	// --save-history を指定した実行のみ履歴に残る
+	history, err := m.History("auth")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "設計して", history[0].Prompt)
+	assert.Equal(t, task.SessionID, history[0].SessionID)
+	assert.Contains(t, history[0].Output, "stdin=設計して")
+
+	// --new-session で新しいセッションに切り替える
+	out.Reset()
+	require.NoError(t, c.run([]string{"run", "-t", "auth", "-p", "x", "--new-session"}))
+	renewed, err := m.Get("auth")
+	require.NoError(t, err)
+	assert.NotEqual(t, task.SessionID, renewed.SessionID)
+	assert.Contains(t, out.String(), "--session-id "+renewed.SessionID)
+}
+
+func TestRun_ChildExitCode(t *testing.T) {
+	c, _ := newRunTestCLI(t, 3)
+
+	err := c.run([]string{"run", "-t", "auth", "-p", "x", "--save-history"})
+	assert.Equal(t, 3, exitCode(err))
+
+	// 失敗した実行のセッションは保存しないが履歴には終了コードを残す
+	m := tasks.NewManager(c.workDir)
+	task, err := m.Get("auth")
+	require.NoError(t, err)
+	assert.Empty(t, task.SessionID)
+
+	history, err := m.History("auth")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 3, history[0].ExitCode)
+}
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "タスク未指定", args: []string{"run", "-p", "x"}, wantCode: exitUsage, wantErr: "--task"},
+		{name: "空のプロンプト", args: []string{"run", "-t", "auth"}, stdin: " \n", wantCode: exitUsage, wantErr: "プロンプトが空です"},
+		{name: "--の前の余分な引数", args: []string{"run", "-t", "auth", "-p", "x", "extra"}, wantCode: exitUsage, wantErr: "-- の後に"},
+		{name: "存在しないタスク", args: []string{"run", "-t", "missing", "-p", "x"}, wantCode: exitError, wantErr: "タスクが見つかりません"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newRunTestCLI(t, 0)
+			c.stdin = strings.NewReader(tt.stdin)
+
+			err := c.run(tt.args)
+			assert.Equal(t, tt.wantCode, exitCode(err))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("Claude Code CLIが見つからない", func(t *testing.T) {
+		c, _ := newRunTestCLI(t, 0)
+		c.claude = claude.Runner{Bin: "ccforge-no-such-claude"}
+
+		err := c.run([]string{"run", "-t", "auth", "-p", "x"})
+		assert.ErrorIs(t, err, claude.ErrNotInstalled)
+		assert.Equal(t, exitError, exitCode(err))
+	})
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/store"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+)
+
+// defaultSearchLimit は search で表示する既定の件数
+const defaultSearchLimit = 20
+
+// searchCommand は search サブコマンドを定義する
+func searchCommand(fs *flag.FlagSet) runFunc {
+	var taskName string
+	var limit, open int
+	fs.StringVar(&taskName, "t", "", "検索するタスク (省略時はすべてのタスク)")
+	fs.StringVar(&taskName, "task", "", "検索するタスク (省略時はすべてのタスク)")
+	fs.IntVar(&limit, "n", defaultSearchLimit, "表示する最大件数")
+	fs.IntVar(&limit, "limit", defaultSearchLimit, "表示する最大件数")
+	fs.IntVar(&open, "open", 0, "指定した番号の結果のトランスクリプトを一致した位置から表示する")
+
+	return func(c *cli, args []string) (result, error) {
+		query := strings.Join(args, " ")
+		if strings.TrimSpace(query) == "" {
+			return nil, newUsageError("search", "<検索語> を指定してください")
+		}
+		if limit <= 0 {
+			return nil, newUsageError("search", "--limit には1以上を指定してください")
+		}
+		if open < 0 || open > limit {
+			return nil, newUsageError("search", "--open には1から%dまでの番号を指定してください", limit)
+		}
+
+		ctx := context.Background()
+		st, err := c.openSearchStore(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer st.Close()
+
+		opts := store.SearchOptions{Limit: limit}
+		if taskName != "" {
+			task, err := st.TaskByName(ctx, taskName)
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, fmt.Errorf("%w: %s", tasks.ErrTaskNotFound, taskName)
+			}
+			if err != nil {
+				return nil, err
+			}
+			opts.TaskID = task.ID
+		}
+
+		hits, err := st.Search(ctx, query, opts)
+		if err != nil {
+			return nil, err
+		}
+		if open == 0 {
+			return searchResult{query: query, hits: hits}, nil
+		}
+
+		if open > len(hits) {
+			return nil, newUsageError("search", "--open %d に対応する結果がありません (%d件)", open, len(hits))
+		}
+		hit := hits[open-1]
+		transcript, err := st.SessionTranscript(ctx, hit.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		return transcriptResult{hit: hit, transcript: transcript}, nil
+	}
+}
+
+// searchFunc はTUIから全文検索するための関数を返す
+// 検索のたびにデータベースを開き、未取り込みの実行履歴を索引に加える
+func (c *cli) searchFunc() func(query string) ([]store.SearchHit, error) {
+	return func(query string) ([]store.SearchHit, error) {
+		ctx := context.Background()
+		st, err := c.openSearchStore(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer st.Close()
+		return st.Search(ctx, query, store.SearchOptions{})
+	}
+}
+
+// transcriptFunc はTUIから検索結果のトランスクリプトを読み込むための関数を返す
+func (c *cli) transcriptFunc() func(sessionID string) ([]store.PromptTranscript, error) {
+	return func(sessionID string) ([]store.PromptTranscript, error) {
+		ctx := context.Background()
+		st, err := store.Open(ctx, store.DefaultPath(c.workDir))
+		if err != nil {
+			return nil, err
+		}
+		defer st.Close()
+		return st.SessionTranscript(ctx, sessionID)
+	}
+}
+
+// openSearchStore はプロジェクトのデータベースを開き、検索の前に実行履歴を取り込む
+func (c *cli) openSearchStore(ctx context.Context) (*store.Store, error) {
+	st, err := store.Open(ctx, store.DefaultPath(c.workDir))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.indexHistory(ctx, st); err != nil {
+		st.Close()
+		return nil, err
+	}
+	return st, nil
+}
+
+// indexHistory はタスクの実行履歴 (run --save-history) のうち未取り込みの分をデータベースに取り込む
+// 取り込んだやり取りはトリガーで全文検索の索引に加わる
+func (c *cli) indexHistory(ctx context.Context, st *store.Store) error {
+	m := c.manager()
+	// 同名のタスクは作業中のものを優先するため、アーカイブ済みを先に記録する
+	archived, err := m.ListArchived()
+	if err != nil {
+		return err
+	}
+	active, err := m.List()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range append(archived, active...) {
+		if task.ID == "" {
+			if task.Archived {
+				slog.Debug("IDのないアーカイブ済みのタスクは取り込みません", "component", "cli", "task", task.Name)
+				continue
+			}
+			withID, err := m.EnsureID(task.Name)
+			if err != nil {
+				return err
+			}
+			task = *withID
+		}
+
+		err := st.Update(ctx, func(r *store.Repository) error {
+			return importHistory(ctx, r, task)
+		})
+		if err != nil {
+			return fmt.Errorf("タスク %s の履歴の取り込みに失敗しました: %w", task.Name, err)
+		}
+	}
+	return nil
+}
+
+// importHistory はタスクの実行履歴の続きをセッション、プロンプト、応答として保存する
+func importHistory(ctx context.Context, r *store.Repository, task tasks.Task) error {
+	if err := recordTask(ctx, r, task); err != nil {
+		return err
+	}
+
+	offset, err := r.HistoryOffset(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	entries, next, err := tasks.HistorySince(task, offset)
+	if err != nil {
+		return err
+	}
+	if next == offset {
+		return nil
+	}
+
+	for _, entry := range entries {
+		// セッションIDのない履歴はタスクごとの1つのセッションにまとめる
+		sessionID := entry.SessionID
+		if sessionID == "" {
+			sessionID = task.ID + "-history"
+		}
+		if err := r.StartSession(ctx, store.Session{ID: sessionID, TaskID: task.ID, StartedAt: entry.Time}); err != nil {
+			return err
+		}
+		promptID, err := r.AddPrompt(ctx, sessionID, entry.Prompt, entry.Time)
+		if err != nil {
+			return err
+		}
+		if entry.Output != "" {
+			if _, err := r.AppendChunk(ctx, promptID, store.Stdout, entry.Output, entry.Time); err != nil {
+				return err
+			}
+		}
+		if err := r.CompletePrompt(ctx, promptID, entry.ExitCode, entry.Time); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("実行履歴を取り込みました", "component", "cli", "task", task.Name, "entries", len(entries), "offset", next)
+	return r.SetHistoryOffset(ctx, task.ID, next)
+}
+
+// hitKind は検索結果がプロンプトと応答のどちらに一致したかを返す
+func hitKind(hit store.SearchHit) string {
+	if hit.ChunkID == 0 {
+		return "prompt"
+	}
+	return "response"
+}
+
+// hitKindText は hitKind の表示名を返す
+func hitKindText(hit store.SearchHit) string {
+	if hit.ChunkID == 0 {
+		return "プロンプト"
+	}
+	return "応答"
+}
+
+// searchHitJSON は検索結果のJSON表現
+type searchHitJSON struct {
+	Task      string    `json:"task"`
+	TaskID    string    `json:"task_id"`
+	SessionID string    `json:"session_id"`
+	PromptID  int64     `json:"prompt_id"`
+	ChunkID   int64     `json:"chunk_id"`
+	Kind      string    `json:"kind"`
+	Time      time.Time `json:"time"`
+	Snippet   string    `json:"snippet"`
+	Matches   []int     `json:"matches"`
+}
+
+// searchResult は search の結果
+type searchResult struct {
+	query string
+	hits  []store.SearchHit
+}
+
+func (r searchResult) kind() string { return "search_results" }
+
+func (r searchResult) data() any {
+	hits := make([]searchHitJSON, 0, len(r.hits))
+	for _, hit := range r.hits {
+		hits = append(hits, searchHitJSON{
+			Task:      hit.TaskName,
+			TaskID:    hit.TaskID,
+			SessionID: hit.SessionID,
+			PromptID:  hit.PromptID,
+			ChunkID:   hit.ChunkID,
+			Kind:      hitKind(hit),
+			Time:      hit.Time,
+			Snippet:   hit.Snippet,
+			Matches:   hit.Matches,
+		})
+	}
+	return struct {
+		Query string          `json:"query"`
+		Hits  []searchHitJSON `json:"hits"`
+	}{Query: r.query, Hits: hits}
+}
+
+func (r searchResult) writeText(w io.Writer) {
+	if len(r.hits) == 0 {
+		fmt.Fprintf(w, "%q に一致するトランスクリプトはありません\n", r.query)
+		return
+	}
+	for i, hit := range r.hits {
+		fmt.Fprintf(w, "[%d] %s  %s  %s  セッション %s\n", i+1, hit.TaskName, formatTime(hit.Time), hitKindText(hit), hit.SessionID)
+		fmt.Fprintf(w, "    %s\n", hit.Snippet)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "'ccforge search <検索語> --open <番号>' で一致した位置からトランスクリプトを表示します")
+}
+
+func (r searchResult) writeTable(w io.Writer) {
+	if len(r.hits) == 0 {
+		r.writeText(w)
+		return
+	}
+
+	rows := make([][]string, 0, len(r.hits))
+	for i, hit := range r.hits {
+		rows = append(rows, []string{fmt.Sprint(i + 1), hit.TaskName, formatTime(hit.Time), hitKindText(hit), hit.Snippet})
+	}
+	printTable(w, []string{"番号", "タスク", "日時", "種別", "抜粋"}, rows)
+}
+
+// transcriptPromptJSON はトランスクリプトのプロンプトのJSON表現
+type transcriptPromptJSON struct {
+	ID       int64     `json:"id"`
+	Text     string    `json:"text"`
+	SentAt   time.Time `json:"sent_at"`
+	ExitCode int       `json:"exit_code"`
+	Output   string    `json:"output"`
+	Match    bool      `json:"match"`
+}
+
+// transcriptResult は search --open の結果
+type transcriptResult struct {
+	hit        store.SearchHit
+	transcript []store.PromptTranscript
+}
+
+func (r transcriptResult) kind() string { return "transcript" }
+
+func (r transcriptResult) data() any {
+	prompts := make([]transcriptPromptJSON, 0, len(r.transcript))
+	for _, pt := range r.transcript {
+		prompts = append(prompts, transcriptPromptJSON{
+			ID:       pt.Prompt.ID,
+			Text:     pt.Prompt.Text,
+			SentAt:   pt.Prompt.SentAt,
+			ExitCode: pt.Prompt.ExitCode,
+			Output:   joinChunks(pt.Chunks),
+			Match:    pt.Prompt.ID == r.hit.PromptID,
+		})
+	}
+	return struct {
+		Task      string                 `json:"task"`
+		SessionID string                 `json:"session_id"`
+		Prompts   []transcriptPromptJSON `json:"prompts"`
+	}{Task: r.hit.TaskName, SessionID: r.hit.SessionID, Prompts: prompts}
+}
+
+// writeText は一致したやり取りからセッションの終わりまでを表示する
+func (r transcriptResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "==> %s セッション %s <==\n", r.hit.TaskName, r.hit.SessionID)
+
+	start := 0
+	for i, pt := range r.transcript {
+		if pt.Prompt.ID == r.hit.PromptID {
+			start = i
+			break
+		}
+	}
+	if start > 0 {
+		fmt.Fprintf(w, "(前の%d件のやり取りは省略しました)\n", start)
+	}
+
+	for _, pt := range r.transcript[start:] {
+		fmt.Fprintf(w, "\n> %s (%s)\n", strings.ReplaceAll(strings.TrimRight(pt.Prompt.Text, "\n"), "\n", "\n> "), formatTime(pt.Prompt.SentAt))
+		output := joinChunks(pt.Chunks)
+		fmt.Fprint(w, output)
+		if output != "" && !strings.HasSuffix(output, "\n") {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func (r transcriptResult) writeTable(w io.Writer) { r.writeText(w) }
+
+// joinChunks は応答の断片を受信順につなげる
+func joinChunks(chunks []store.Chunk) string {
+	var b strings.Builder
+	for _, chunk := range chunks {
+		b.WriteString(chunk.Content)
+	}
+	return b.String()
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSearchTestCLI は実行履歴のあるタスクを作成した実行環境を作成する
+func newSearchTestCLI(t *testing.T) (*cli, *bytes.Buffer) {
+	t.Helper()
+	c, out, _ := newTestCLI(t)
+	m := tasks.NewManager(c.workDir)
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	history := map[string][]tasks.HistoryEntry{
+		"auth": {
+			{Time: base, SessionID: "s1", Prompt: "JWTの検証を実装して", Output: "middleware/auth.go を作成しました\n"},
+			{Time: base.Add(time.Minute), SessionID: "s1", Prompt: "テストも追加して", Output: "公開鍵の読み込みのテストを追加しました\n"},
+		},
+		"billing": {
+			{Time: base.Add(time.Hour), Prompt: "請求書のPDFを出力して", Output: "PDFの生成にはgofpdfを使います\n"},
+		},
+	}
+	for name, entries := range history {
+		_, err := m.Create(name, "")
+		require.NoError(t, err)
+		for _, entry := range entries {
+			require.NoError(t, m.AppendHistory(name, entry))
+		}
+	}
+	return c, out
+}
+
+func TestCLI_Search(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantOut   []string
+		unwantOut []string
+	}{
+		{
+			name:    "応答に一致",
+			args:    []string{"search", "公開鍵"},
+			wantOut: []string{"[1] auth", "応答", "公開鍵の読み込みのテストを追加しました", "--open <番号>"},
+		},
+		{
+			name:    "プロンプトに一致",
+			args:    []string{"search", "請求書"},
+			wantOut: []string{"[1] billing", "プロンプト", "請求書のPDFを出力して"},
+		},
+		{
+			name:      "タスクで絞り込む",
+			args:      []string{"search", "--task", "auth", "PDF"},
+			wantOut:   []string{`"PDF" に一致するトランスクリプトはありません`},
+			unwantOut: []string{"billing"},
+		},
+		{
+			name:      "件数を制限する",
+			args:      []string{"search", "-n", "1", "し"},
+			wantOut:   []string{"[1] billing"},
+			unwantOut: []string{"[2]"},
+		},
+		{
+			name:      "一致した位置からトランスクリプトを表示する",
+			args:      []string{"search", "--open", "1", "公開鍵"},
+			wantOut:   []string{"==> auth セッション s1 <==", "(前の1件のやり取りは省略しました)", "> テストも追加して", "公開鍵の読み込みのテストを追加しました"},
+			unwantOut: []string{"JWTの検証を実装して"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, out := newSearchTestCLI(t)
+
+			require.NoError(t, c.run(tt.args))
+			for _, want := range tt.wantOut {
+				assert.Contains(t, out.String(), want)
+			}
+			for _, unwant := range tt.unwantOut {
+				assert.NotContains(t, out.String(), unwant)
+			}
+		})
+	}
+}
+
+func TestCLI_SearchJSON(t *testing.T) {
+	c, out := newSearchTestCLI(t)
+
+	require.NoError(t, c.run([]string{"search", "公開鍵", "-o", "json"}))
+
+	var got struct {
+		Kind string `json:"kind"`
+		Data struct {
+			Query string `json:"query"`
+			Hits  []struct {
+				Task      string `json:"task"`
+				SessionID string `json:"session_id"`
+				Kind      string `json:"kind"`
+				Snippet   string `json:"snippet"`
+				Matches   []int  `json:"matches"`
+			} `json:"hits"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	assert.Equal(t, "search_results", got.Kind)
+	assert.Equal(t, "公開鍵", got.Data.Query)
+	require.Len(t, got.Data.Hits, 1)
+	assert.Equal(t, "auth", got.Data.Hits[0].Task)
+	assert.Equal(t, "s1", got.Data.Hits[0].SessionID)
+	assert.Equal(t, "response", got.Data.Hits[0].Kind)
+	assert.Equal(t, []int{0, 1, 2}, got.Data.Hits[0].Matches)
+}
+
+func TestCLI_SearchImportsIncrementally(t *testing.T) {
+	c, out := newSearchTestCLI(t)
+
+	require.NoError(t, c.run([]string{"search", "追加"}))
+	assert.Contains(t, out.String(), "[2] auth")
+	assert.NotContains(t, out.String(), "[3]")
+
+	// 追記した分だけを取り込み、既存の履歴は重複しない
+	require.NoError(t, tasks.NewManager(c.workDir).AppendHistory("auth", tasks.HistoryEntry{
+		Time:      time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+		SessionID: "s2",
+		Prompt:    "READMEに追加して",
+	}))
+	out.Reset()
+	require.NoError(t, c.run([]string{"search", "追加"}))
+	assert.Contains(t, out.String(), "[1] auth")
+	assert.Contains(t, out.String(), "セッション s2")
+	assert.Contains(t, out.String(), "[3] auth")
+	assert.NotContains(t, out.String(), "[4]")
+}
+
+func TestCLI_SearchArchivedTask(t *testing.T) {
+	c, out := newSearchTestCLI(t)
+
+	// アーカイブ済みのタスクの履歴も検索できる
+	_, err := tasks.NewManager(c.workDir).Archive("billing")
+	require.NoError(t, err)
+	require.NoError(t, c.run([]string{"search", "請求書"}))
+	assert.Contains(t, out.String(), "[1] billing")
+}
+
+func TestCLI_SearchErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+		usage   bool
+	}{
+		{name: "検索語なし", args: []string{"search"}, wantErr: "<検索語> を指定してください", usage: true},
+		{name: "件数が0", args: []string{"search", "-n", "0", "鍵"}, wantErr: "--limit には1以上", usage: true},
+		{name: "番号が範囲外", args: []string{"search", "--open", "21", "鍵"}, wantErr: "--open には1から20まで", usage: true},
+		{name: "番号に対応する結果なし", args: []string{"search", "--open", "5", "公開鍵"}, wantErr: "--open 5 に対応する結果がありません (1件)", usage: true},
+		{name: "存在しないタスク", args: []string{"search", "--task", "unknown", "鍵"}, wantErr: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newSearchTestCLI(t)
+			err := c.run(tt.args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+
+			var usageErr *usageError
+			assert.Equal(t, tt.usage, errors.As(err, &usageErr))
+			if !tt.usage {
+				assert.ErrorIs(t, err, tasks.ErrTaskNotFound)
+			}
+		})
+	}
+}
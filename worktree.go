@@ -0,0 +1,368 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+)
+
+// worktreeCommand は worktree サブコマンドを定義する
+func worktreeCommand(fs *flag.FlagSet) runFunc {
+	var force bool
+	fs.BoolVar(&force, "force", false, "remove でコミットしていない変更があっても削除する")
+
+	return func(c *cli, args []string) (result, error) {
+		if len(args) == 0 {
+			return nil, newUsageError("worktree", "add, list, remove, prune のいずれかを指定してください")
+		}
+		if force && args[0] != "remove" {
+			return nil, newUsageError("worktree", "--force は remove と一緒に指定してください")
+		}
+
+		repo, err := git.Open(c.workDir)
+		if err != nil {
+			return nil, err
+		}
+
+		switch action, rest := args[0], args[1:]; action {
+		case "add":
+			return c.worktreeAdd(repo, rest)
+		case "list":
+			return c.worktreeList(repo, rest)
+		case "remove":
+			return c.worktreeRemove(repo, rest, force)
+		case "prune":
+			return c.worktreePrune(repo, rest)
+		default:
+			return nil, newUsageError("worktree", "不明な操作です: %s", action)
+		}
+	}
+}
+
+// worktreeAdd は既存のタスクのworktreeを作成する
+func (c *cli) worktreeAdd(repo *git.Repo, args []string) (result, error) {
+	if err := requireArgs("worktree", args, "<タスク名>"); err != nil {
+		return nil, err
+	}
+	task, err := c.manager().Get(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := c.createTaskWorktree(repo, task); err != nil {
+		return nil, err
+	}
+	return worktreeCreatedResult(*task), nil
+}
+
+// worktreeList はタスクのworktreeを一覧表示する
+func (c *cli) worktreeList(repo *git.Repo, args []string) (result, error) {
+	if err := requireArgs("worktree", args); err != nil {
+		return nil, err
+	}
+	worktrees, err := repo.Worktrees()
+	if err != nil {
+		return nil, err
+	}
+	list, err := c.manager().List()
+	if err != nil {
+		return nil, err
+	}
+
+	var res worktreeListResult
+	registered := make(map[string]bool)
+	for _, w := range worktrees {
+		if w.Main {
+			continue
+		}
+		entry := worktreeEntry{Worktree: w, state: worktreeState(w)}
+		for _, task := range list {
+			if task.Worktree != "" && git.SamePath(task.Worktree, w.Path) {
+				entry.task = task.Name
+				registered[task.Name] = true
+			}
+		}
+		res.worktrees = append(res.worktrees, entry)
+	}
+	// 記録はあるがgitに登録されていないworktree
+	for _, task := range list {
+		if task.Worktree != "" && !registered[task.Name] {
+			res.worktrees = append(res.worktrees, worktreeEntry{
+				Worktree: git.Worktree{Path: task.Worktree, Branch: task.Branch},
+				task:     task.Name,
+				state:    "未登録",
+			})
+		}
+	}
+	return res, nil
+}
+
+// worktreeState はworktreeの状態の表示名を返す
+func worktreeState(w git.Worktree) string {
+	switch {
+	case w.Prunable:
+		return "ディレクトリなし"
+	case w.Locked:
+		return "ロック中"
+	}
+	repo, err := git.Open(w.Path)
+	if err != nil {
+		return "不明"
+	}
+	s, err := repo.Summary()
+	if err != nil {
+		return "不明"
+	}
+	if s.Dirty() {
+		return "変更あり"
+	}
+	return "変更なし"
+}
+
+// worktreeRemove はタスクのworktreeを削除して記録を消す
+// ブランチは削除しない
+func (c *cli) worktreeRemove(repo *git.Repo, args []string, force bool) (result, error) {
+	if err := requireArgs("worktree", args, "<タスク名>"); err != nil {
+		return nil, err
+	}
+	m := c.manager()
+	task, err := m.Get(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if task.Worktree == "" {
+		return nil, fmt.Errorf("タスク %s にはworktreeがありません", task.Name)
+	}
+
+	if _, err := os.Stat(task.Worktree); errors.Is(err, os.ErrNotExist) {
+		// ディレクトリが削除済みの場合はgitの登録だけを整理する
+		if _, err := repo.PruneWorktrees(); err != nil {
+			return nil, err
+		}
+	} else {
+		err := repo.RemoveWorktree(task.Worktree, force)
+		if errors.Is(err, git.ErrWorktreeDirty) {
+			return nil, fmt.Errorf("%w (--force で変更ごと削除できます)", err)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := m.SetWorktree(task.Name, ""); err != nil {
+		return nil, err
+	}
+	slog.Info("タスクのworktreeを削除しました", "component", "cli", "task", task.Name, "worktree", task.Worktree, "force", force)
+
+	lines := []string{fmt.Sprintf("タスク %s のworktreeを削除しました: %s", task.Name, task.Worktree)}
+	if task.Branch != "" {
+		lines = append(lines, fmt.Sprintf("ブランチ %s は残しています", task.Branch))
+	}
+	return messageResult{
+		kindName: "worktree_removed",
+		payload: struct {
+			Task     string `json:"task"`
+			Worktree string `json:"worktree"`
+			Branch   string `json:"branch,omitempty"`
+		}{Task: task.Name, Worktree: task.Worktree, Branch: task.Branch},
+		lines: lines,
+	}, nil
+}
+
+// worktreePrune はディレクトリが削除されたworktreeの登録とタスクの記録を整理する
+func (c *cli) worktreePrune(repo *git.Repo, args []string) (result, error) {
+	if err := requireArgs("worktree", args); err != nil {
+		return nil, err
+	}
+	pruned, err := repo.PruneWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	m := c.manager()
+	list, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	var cleared []string
+	for _, task := range list {
+		if task.Worktree == "" {
+			continue
+		}
+		if _, err := os.Stat(task.Worktree); !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err := m.SetWorktree(task.Name, ""); err != nil {
+			return nil, err
+		}
+		cleared = append(cleared, task.Name)
+	}
+
+	paths := make([]string, 0, len(pruned))
+	var lines []string
+	for _, w := range pruned {
+		paths = append(paths, w.Path)
+		lines = append(lines, fmt.Sprintf("登録を削除しました: %s", w.Path))
+	}
+	for _, name := range cleared {
+		lines = append(lines, fmt.Sprintf("タスク %s のworktreeの記録を削除しました", name))
+	}
+	if len(lines) == 0 {
+		lines = []string{"整理するworktreeはありません"}
+	}
+	if cleared == nil {
+		cleared = []string{}
+	}
+	return messageResult{
+		kindName: "worktree_pruned",
+		payload: struct {
+			Pruned []string `json:"pruned"`
+			Tasks  []string `json:"tasks"`
+		}{Pruned: paths, Tasks: cleared},
+		lines: lines,
+	}, nil
+}
+
+// worktreeDir はタスクのworktreeを作成するディレクトリを返す
+// git.worktree_dir が空の場合はプロジェクトと同じ階層の <プロジェクト名>-worktrees
+func (c *cli) worktreeDir() string {
+	dir := c.config.Git.WorktreeDir
+	switch {
+	case dir == "":
+		return filepath.Join(filepath.Dir(c.workDir), filepath.Base(c.workDir)+"-worktrees")
+	case filepath.IsAbs(dir):
+		return dir
+	default:
+		return filepath.Join(c.workDir, dir)
+	}
+}
+
+// createTaskWorktree はタスクのworktreeを作成してタスクのブランチをチェックアウトし、メタ情報に記録する
+// ブランチがない場合は task/<タスク名> を現在のHEADから作成する
+func (c *cli) createTaskWorktree(repo *git.Repo, task *tasks.Task) error {
+	if task.Worktree != "" {
+		return fmt.Errorf("タスク %s にはworktreeがあります: %s", task.Name, task.Worktree)
+	}
+
+	branch := task.Branch
+	if branch == "" {
+		branch = tasks.BranchName(task.Name)
+	}
+	current, err := repo.CurrentBranch()
+	if err != nil {
+		return err
+	}
+	if current == branch {
+		return fmt.Errorf("ブランチ %s はチェックアウト中のためworktreeを作成できません (別のブランチに切り替えてください)", branch)
+	}
+
+	path := filepath.Join(c.worktreeDir(), task.Name)
+	if err := repo.AddWorktree(path, branch); err != nil {
+		return err
+	}
+	m := c.manager()
+	if err := m.SetWorktree(task.Name, path); err != nil {
+		return err
+	}
+	if err := m.SetBranch(task.Name, branch); err != nil {
+		return err
+	}
+	task.Worktree = path
+	task.Branch = branch
+	return nil
+}
+
+// worktreeCreatedResult はworktreeの作成の結果を返す
+func worktreeCreatedResult(task tasks.Task) messageResult {
+	return messageResult{
+		kindName: "worktree_created",
+		payload: struct {
+			Task taskJSON `json:"task"`
+		}{Task: newTaskJSON(task)},
+		lines: []string{fmt.Sprintf("worktree %s を作成してブランチ %s をチェックアウトしました", task.Worktree, task.Branch)},
+	}
+}
+
+// worktreeEntry はworktreeと対応するタスク
+type worktreeEntry struct {
+	git.Worktree
+	task  string // worktreeを使うタスク (ない場合は空)
+	state string // 状態の表示名
+}
+
+// worktreeJSON はworktreeのJSON表現
+type worktreeJSON struct {
+	Path     string `json:"path"`
+	Task     string `json:"task,omitempty"`
+	Branch   string `json:"branch"`
+	Head     string `json:"head"`
+	State    string `json:"state"`
+	Prunable bool   `json:"prunable"`
+}
+
+// worktreeListResult は worktree list の結果
+type worktreeListResult struct {
+	worktrees []worktreeEntry
+}
+
+func (r worktreeListResult) kind() string { return "worktree_list" }
+
+func (r worktreeListResult) data() any {
+	list := make([]worktreeJSON, 0, len(r.worktrees))
+	for _, w := range r.worktrees {
+		list = append(list, worktreeJSON{
+			Path:     w.Path,
+			Task:     w.task,
+			Branch:   w.Branch,
+			Head:     w.Head,
+			State:    w.state,
+			Prunable: w.Prunable,
+		})
+	}
+	return struct {
+		Worktrees []worktreeJSON `json:"worktrees"`
+	}{Worktrees: list}
+}
+
+func (r worktreeListResult) writeText(w io.Writer) {
+	if len(r.worktrees) == 0 {
+		fmt.Fprintln(w, "worktreeがありません ('ccforge worktree add <タスク名>' で作成できます)")
+		return
+	}
+	for _, e := range r.worktrees {
+		fmt.Fprintf(w, "%s %s (%s, %s)\n", worktreeTaskText(e), e.Path, worktreeBranchText(e), e.state)
+	}
+}
+
+func (r worktreeListResult) writeTable(w io.Writer) {
+	if len(r.worktrees) == 0 {
+		r.writeText(w)
+		return
+	}
+	rows := make([][]string, 0, len(r.worktrees))
+	for _, e := range r.worktrees {
+		rows = append(rows, []string{worktreeTaskText(e), worktreeBranchText(e), e.state, e.Path})
+	}
+	printTable(w, []string{"タスク", "ブランチ", "状態", "パス"}, rows)
+}
+
+// worktreeTaskText はworktreeを使うタスクの表示名を返す
+func worktreeTaskText(e worktreeEntry) string {
+	if e.task == "" {
+		return "タスクなし"
+	}
+	return e.task
+}
+
+// worktreeBranchText はworktreeのブランチの表示名を返す
+func worktreeBranchText(e worktreeEntry) string {
+	if e.Branch == "" {
+		return "detached"
+	}
+	return e.Branch
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mzkmnk/ccforge/internal/config"
+	"github.com/mzkmnk/ccforge/internal/git"
+	"github.com/mzkmnk/ccforge/internal/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLI_WorktreeDir(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{name: "既定値", dir: "", want: "/work/project-worktrees"},
+		{name: "絶対パス", dir: "/var/worktrees", want: "/var/worktrees"},
+		{name: "相対パス", dir: ".worktrees", want: "/work/project/.worktrees"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cli{workDir: "/work/project", config: config.Default()}
+			c.config.Git.WorktreeDir = tt.dir
+			assert.Equal(t, tt.want, c.worktreeDir())
+		})
+	}
+}
+
+func TestCLI_NewWorktree(t *testing.T) {
+	c, out, launched := newTestCLI(t)
+	initGitProject(t, c.workDir)
+	want := filepath.Join(filepath.Dir(c.workDir), filepath.Base(c.workDir)+"-worktrees", "auth")
+
+	require.NoError(t, c.run([]string{"new", "--worktree", "--start", "auth"}))
+	assert.Contains(t, out.String(), "worktree "+want+" を作成してブランチ task/auth をチェックアウトしました")
+	assert.FileExists(t, filepath.Join(want, "README.md"))
+	// プロジェクトのブランチは切り替えない
+	assert.Equal(t, "main", currentBranch(t, c.workDir))
+	assert.Equal(t, "task/auth", currentBranch(t, want))
+
+	task, err := tasks.NewManager(c.workDir).Get("auth")
+	require.NoError(t, err)
+	assert.Equal(t, want, task.Worktree)
+	assert.Equal(t, "task/auth", task.Branch)
+
+	// TUIはプロジェクトのタスクとworktreeの差分を表示する
+	require.Len(t, *launched, 1)
+	assert.Equal(t, c.workDir, (*launched)[0].workDir)
+	assert.Equal(t, want, (*launched)[0].worktree)
+
+	// worktreeのタスクではブランチを切り替えない
+	require.NoError(t, c.run([]string{"start", "--checkout", "auth"}))
+	assert.Equal(t, "main", currentBranch(t, c.workDir))
+	assert.Contains(t, c.stderr.(*bytes.Buffer).String(), "ブランチを切り替えません")
+
+	assert.Equal(t, exitUsage, exitCode(c.run([]string{"new", "--branch", "--worktree", "other"})))
+}
+
+func TestCLI_Worktree(t *testing.T) {
+	c, out, launched := newTestCLI(t)
+	initGitProject(t, c.workDir)
+	base := filepath.Join(t.TempDir(), "worktrees")
+	set := "--set=git.worktree_dir=" + base
+
+	// ブランチのあるタスクは既存のブランチをチェックアウトする
+	require.NoError(t, c.run([]string{"new", "--branch", "auth"}))
+	runGitCmd(t, c.workDir, "checkout", "-q", "main")
+	require.NoError(t, c.run([]string{"new", "api"}))
+
+	out.Reset()
+	require.NoError(t, c.run([]string{set, "worktree", "add", "auth"}))
+	require.NoError(t, c.run([]string{set, "worktree", "add", "api"}))
+	assert.Contains(t, out.String(), "ブランチ task/auth をチェックアウトしました")
+	assert.Contains(t, out.String(), "ブランチ task/api をチェックアウトしました")
+	assert.ErrorContains(t, c.run([]string{set, "worktree", "add", "auth"}), "worktreeがあります")
+
+	// 変更のあるworktreeは --force がない限り削除しない
+	authDir := filepath.Join(base, "auth")
+	require.NoError(t, os.WriteFile(filepath.Join(authDir, "README.md"), []byte("# changed\n"), 0o644))
+	out.Reset()
+	require.NoError(t, c.run([]string{"worktree", "list"}))
+	assert.Contains(t, out.String(), "auth "+authDir+" (task/auth, 変更あり)")
+	assert.Contains(t, out.String(), "api "+filepath.Join(base, "api")+" (task/api, 変更なし)")
+
+	err := c.run([]string{"worktree", "remove", "auth"})
+	assert.ErrorIs(t, err, git.ErrWorktreeDirty)
+	assert.ErrorContains(t, err, "--force")
+	assert.DirExists(t, authDir)
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"worktree", "remove", "--force", "auth"}))
+	assert.Contains(t, out.String(), "ブランチ task/auth は残しています")
+	assert.NoDirExists(t, authDir)
+	task, err := tasks.NewManager(c.workDir).Get("auth")
+	require.NoError(t, err)
+	assert.Empty(t, task.Worktree)
+	assert.Equal(t, "task/auth", task.Branch)
+
+	// ディレクトリが削除されたworktreeは起動せずに prune を案内し、prune で整理する
+	require.NoError(t, os.RemoveAll(filepath.Join(base, "api")))
+	assert.ErrorContains(t, c.run([]string{"start", "api"}), "ccforge worktree prune")
+	assert.Empty(t, *launched)
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"worktree", "prune"}))
+	assert.Contains(t, out.String(), "登録を削除しました: "+filepath.Join(base, "api"))
+	assert.Contains(t, out.String(), "タスク api のworktreeの記録を削除しました")
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"worktree", "prune"}))
+	assert.Equal(t, "整理するworktreeはありません\n", out.String())
+
+	out.Reset()
+	require.NoError(t, c.run([]string{"worktree", "list"}))
+	assert.Contains(t, out.String(), "worktreeがありません")
+
+	assert.Equal(t, exitUsage, exitCode(c.run([]string{"worktree"})))
+	assert.Equal(t, exitUsage, exitCode(c.run([]string{"worktree", "move"})))
+	assert.Equal(t, exitUsage, exitCode(c.run([]string{"worktree", "--force", "prune"})))
+}
+
+func TestCLI_WorktreeCurrentBranch(t *testing.T) {
+	c, _, _ := newTestCLI(t)
+	initGitProject(t, c.workDir)
+	require.NoError(t, c.run([]string{"new", "--branch", "auth"}))
+
+	err := c.run([]string{"worktree", "add", "auth"})
+	assert.ErrorContains(t, err, "チェックアウト中")
+}